@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"os"
+	"strings"
 	"os/signal"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/Dophin2009/nao/internal/exporter"
 	"github.com/Dophin2009/nao/internal/naos"
 )
 
@@ -20,6 +24,23 @@ func main() {
 		FullTimestamp: true,
 	})
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			export(os.Args[2:])
+			return
+		case "dump":
+			dumpCatalog(os.Args[2:])
+			return
+		case "load":
+			loadCatalog(os.Args[2:])
+			return
+		case "admin":
+			grantAdmin(os.Args[2:])
+			return
+		}
+	}
+
 	// Read configuration files
 	conf, err := naos.ReadConfigs()
 	if err != nil {
@@ -32,7 +53,7 @@ func main() {
 		log.Fatalf("Failed to initialize application: %v", err)
 		return
 	}
-	defer s.DataLayer.Database.Close()
+	defer s.Close()
 
 	// Launch server in goroutine
 	shttp := s.HTTPServer()
@@ -60,3 +81,132 @@ func main() {
 	println()
 	log.Println("Exiting...")
 }
+
+// export runs the export command, which writes the data of a User to a file
+// or the standard output. The server must not be running.
+func export(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	username := fs.String("user", "", "username of the User to export")
+	format := fs.String("format", "json", fmt.Sprintf("export format; one of %s",
+		strings.Join(exporter.Formats(), ", ")))
+	output := fs.String("o", "", "file to write to instead of standard output")
+	fs.Parse(args)
+	if *username == "" {
+		fs.Usage()
+		log.Fatal("No user given")
+		return
+	}
+
+	conf, err := naos.ReadConfigs()
+	if err != nil {
+		log.Fatalf("Failed to read config: %v", err)
+		return
+	}
+
+	w := os.Stdout
+	if *output != "" {
+		w, err = os.Create(*output)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+			return
+		}
+		defer w.Close()
+	}
+
+	err = naos.ExportLibrary(conf, *username, *format, w)
+	if err != nil {
+		log.Fatalf("Failed to export: %v", err)
+		return
+	}
+}
+
+// dumpCatalog runs the dump command, which writes the global catalog to a
+// directory. The server must not be running.
+func dumpCatalog(args []string) {
+	fs := flag.NewFlagSet("dump", flag.ExitOnError)
+	dir := fs.String("o", "", "directory to write the dump to")
+	fs.Parse(args)
+	if *dir == "" {
+		fs.Usage()
+		log.Fatal("No directory given")
+		return
+	}
+
+	conf, err := naos.ReadConfigs()
+	if err != nil {
+		log.Fatalf("Failed to read config: %v", err)
+		return
+	}
+
+	manifest, err := naos.DumpCatalog(conf, *dir)
+	if err != nil {
+		log.Fatalf("Failed to dump: %v", err)
+		return
+	}
+	for _, b := range manifest.Buckets {
+		log.WithFields(log.Fields{
+			"bucket": b.Name,
+			"count":  b.Count,
+		}).Info("Dumped bucket")
+	}
+}
+
+// loadCatalog runs the load command, which loads a dump written by the dump
+// command into the database. The server must not be running.
+func loadCatalog(args []string) {
+	fs := flag.NewFlagSet("load", flag.ExitOnError)
+	dir := fs.String("i", "", "directory to read the dump from")
+	fs.Parse(args)
+	if *dir == "" {
+		fs.Usage()
+		log.Fatal("No directory given")
+		return
+	}
+
+	conf, err := naos.ReadConfigs()
+	if err != nil {
+		log.Fatalf("Failed to read config: %v", err)
+		return
+	}
+
+	res, err := naos.LoadCatalog(conf, *dir)
+	if err != nil {
+		log.Fatalf("Failed to load: %v", err)
+		return
+	}
+	for _, b := range res.Manifest.Buckets {
+		log.WithFields(log.Fields{
+			"bucket": b.Name,
+			"count":  res.Loaded[b.Name],
+		}).Info("Loaded bucket")
+	}
+}
+
+// grantAdmin runs the admin command, which assigns the admin Role to a User.
+// The server must not be running.
+func grantAdmin(args []string) {
+	fs := flag.NewFlagSet("admin", flag.ExitOnError)
+	username := fs.String("user", "", "username of the User to make an admin")
+	fs.Parse(args)
+	if *username == "" {
+		fs.Usage()
+		log.Fatal("No user given")
+		return
+	}
+
+	conf, err := naos.ReadConfigs()
+	if err != nil {
+		log.Fatalf("Failed to read config: %v", err)
+		return
+	}
+
+	u, err := naos.GrantAdmin(conf, *username)
+	if err != nil {
+		log.Fatalf("Failed to grant admin: %v", err)
+		return
+	}
+	log.WithFields(log.Fields{
+		"id":       u.Meta.ID,
+		"username": u.Username,
+	}).Info("Granted admin Role")
+}
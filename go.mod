@@ -19,4 +19,5 @@ require (
 	github.com/vektah/gqlparser/v2 v2.0.1
 	go.etcd.io/bbolt v1.3.3
 	golang.org/x/crypto v0.0.0-20191011191535-87dc89f01550
+	golang.org/x/text v0.3.0
 )
@@ -0,0 +1,124 @@
+// Package calendar generates iCalendar (RFC 5545) feeds of the episodes of
+// Media.
+package calendar
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is the media type of encoded Calendars.
+const ContentType = "text/calendar; charset=utf-8"
+
+// productID identifies nao as the creator of encoded Calendars.
+const productID = "-//nao//Airing calendar//EN"
+
+// maxLineLength is the number of octets after which content lines are
+// folded.
+const maxLineLength = 75
+
+// Calendar is a named list of Events.
+type Calendar struct {
+	Name string
+	// GeneratedAt is the time the Calendar was generated, which is given as
+	// the time each Event was created.
+	GeneratedAt time.Time
+	Events      []Event
+}
+
+// Event is the broadcast of an episode.
+type Event struct {
+	// UID identifies the Event across every generation of every Calendar it
+	// is in, so that calendar applications update it rather than adding a
+	// copy when it changes.
+	UID     string
+	Summary string
+	// Description is omitted if empty.
+	Description string
+	Start       time.Time
+	// AllDay is true if only the date of Start is known, in which case the
+	// Event lasts the whole day and Duration is ignored.
+	AllDay   bool
+	Duration time.Duration
+}
+
+// Encode writes the given Calendar to the given writer as an iCalendar
+// object.
+func Encode(w io.Writer, c *Calendar) error {
+	bw := bufio.NewWriter(w)
+	line := func(name string, value string) {
+		writeLine(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", productID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escapeText(c.Name))
+	}
+	stamp := formatDateTime(c.GeneratedAt)
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", escapeText(e.UID))
+		line("DTSTAMP", stamp)
+		if e.AllDay {
+			line("DTSTART;VALUE=DATE", formatDate(e.Start))
+			line("DTEND;VALUE=DATE", formatDate(e.Start.AddDate(0, 0, 1)))
+		} else {
+			line("DTSTART", formatDateTime(e.Start))
+			line("DTEND", formatDateTime(e.Start.Add(e.Duration)))
+		}
+		line("SUMMARY", escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escapeText(e.Description))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	return bw.Flush()
+}
+
+// writeLine writes the given content line terminated by CRLF, folding it
+// into lines of at most maxLineLength octets without splitting characters.
+func writeLine(w *bufio.Writer, l string) {
+	limit := maxLineLength
+	for len(l) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(l[i]) {
+			i--
+		}
+		w.WriteString(l[:i])
+		w.WriteString("\r\n ")
+		l = l[i:]
+		// Continuation lines begin with a space
+		limit = maxLineLength - 1
+	}
+	w.WriteString(l)
+	w.WriteString("\r\n")
+}
+
+// textEscaper escapes the characters with special meaning in TEXT values.
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`,
+)
+
+// escapeText returns the given string as a TEXT value.
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// formatDateTime returns the given time as a DATE-TIME value in UTC.
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// formatDate returns the date of the given time as a DATE value.
+func formatDate(t time.Time) string {
+	return t.Format("20060102")
+}
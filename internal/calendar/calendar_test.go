@@ -0,0 +1,94 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Calendar{
+		Name:        "nao: spike",
+		GeneratedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		Events: []Event{
+			{
+				UID:         "media-1-episode-2@nao",
+				Summary:     "Cowboy Bebop - Episode 2",
+				Description: "Stray Dog Strut; a dog, a hacker\nand a bounty",
+				Start:       time.Date(2026, 10, 4, 1, 30, 0, 0, tokyo),
+				Duration:    24 * time.Minute,
+			},
+			{
+				UID:     "media-2-episode-1@nao",
+				Summary: "Monster - Episode 1",
+				Start:   time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC),
+				AllDay:  true,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	err = Encode(&buf, c)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	out := buf.String()
+
+	for _, l := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:nao: spike\r\n",
+		"UID:media-1-episode-2@nao\r\n",
+		"DTSTAMP:20261001T120000Z\r\n",
+		"DTSTART:20261003T163000Z\r\n",
+		"DTEND:20261003T165400Z\r\n",
+		`DESCRIPTION:Stray Dog Strut\; a dog\, a hacker\nand a bounty` + "\r\n",
+		"DTSTART;VALUE=DATE:20261005\r\n",
+		"DTEND;VALUE=DATE:20261006\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, l) {
+			t.Errorf("expected %q in:\n%s", l, out)
+		}
+	}
+	if n := strings.Count(out, "BEGIN:VEVENT"); n != 2 {
+		t.Errorf("expected 2 events, got %d", n)
+	}
+}
+
+func TestEncodeFolding(t *testing.T) {
+	c := &Calendar{
+		Events: []Event{{
+			UID:     "media-1-episode-1@nao",
+			Summary: strings.Repeat("進撃の巨人", 10),
+		}},
+	}
+
+	var buf bytes.Buffer
+	err := Encode(&buf, c)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+
+	var summary string
+	for i, l := range strings.Split(buf.String(), "\r\n") {
+		if len(l) > maxLineLength {
+			t.Errorf("line %d has %d octets", i, len(l))
+		}
+		if strings.HasPrefix(l, "SUMMARY:") {
+			summary = l
+		} else if summary != "" && strings.HasPrefix(l, " ") {
+			summary += l[1:]
+		} else if summary != "" {
+			break
+		}
+	}
+	if summary != "SUMMARY:"+c.Events[0].Summary {
+		t.Errorf("expected unfolded summary %q, got %q",
+			"SUMMARY:"+c.Events[0].Summary, summary)
+	}
+}
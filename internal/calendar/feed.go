@@ -0,0 +1,264 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/data"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+const (
+	// lookBehind is how long before the time a Calendar is generated the
+	// episodes in it may be broadcast, so that recent episodes remain
+	// visible.
+	lookBehind = 14 * 24 * time.Hour
+
+	// lookAhead is how long after the time a Calendar is generated the
+	// episodes in it may be broadcast.
+	lookAhead = 90 * 24 * time.Hour
+
+	// defaultDuration is the length of the Events of episodes without a
+	// known duration.
+	defaultDuration = 30 * time.Minute
+)
+
+// Builder generates the Calendars of Users and Media.
+type Builder struct {
+	UserService       *data.UserService
+	MediaService      *data.MediaService
+	EpisodeService    *data.EpisodeService
+	EpisodeSetService *data.EpisodeSetService
+	UserMediaService  *data.UserMediaService
+	ScheduleService   *data.ScheduleService
+}
+
+// episodeKey identifies an episode of some Media.
+type episodeKey struct {
+	mediaID int
+	number  int
+}
+
+// ForUser returns the Calendar of the episodes of the Media the User with
+// the given ID is watching or planning to watch, broadcast around now.
+func (b *Builder) ForUser(uID int, now time.Time, tx db.Tx) (*Calendar, error) {
+	u, err := b.UserService.GetByID(uID, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get User by id %d: %w", uID, err)
+	}
+
+	umList, err := b.UserMediaService.GetByUser(uID, nil, nil, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get UserMedia by User id %d: %w",
+			uID, err)
+	}
+	var mIDs []int
+	for _, um := range umList {
+		if um.Status != nil && (*um.Status == models.WatchStatusCurrent ||
+			*um.Status == models.WatchStatusPlanning) {
+			mIDs = append(mIDs, um.MediaID)
+		}
+	}
+
+	c := Calendar{
+		Name:        fmt.Sprintf("nao: %s", u.Username),
+		GeneratedAt: now,
+	}
+	err = b.addEvents(&c, mIDs, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ForMedia returns the Calendar of the episodes of the Media with the given
+// ID broadcast around now.
+func (b *Builder) ForMedia(mID int, now time.Time, tx db.Tx) (*Calendar, error) {
+	md, err := b.MediaService.GetByID(mID, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Media by id %d: %w", mID, err)
+	}
+
+	c := Calendar{
+		Name:        preferredTitle(md.Titles),
+		GeneratedAt: now,
+	}
+	err = b.addEvents(&c, []int{mID}, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// addEvents adds to the given Calendar the Events of the episodes of the
+// Media with the given IDs broadcast around the time it is generated, in
+// order. The airing schedule of the Media is preferred to the dates of
+// their Episodes.
+func (b *Builder) addEvents(c *Calendar, mIDs []int, tx db.Tx) error {
+	if len(mIDs) == 0 {
+		return nil
+	}
+	from := c.GeneratedAt.Add(-lookBehind)
+	to := c.GeneratedAt.Add(lookAhead)
+
+	wanted := map[int]bool{}
+	for _, mID := range mIDs {
+		wanted[mID] = true
+	}
+	var scheduleMID *int
+	if len(mIDs) == 1 {
+		scheduleMID = &mIDs[0]
+	}
+	schedule, err := b.ScheduleService.GetSchedule(from, to, scheduleMID, tx)
+	if err != nil {
+		return fmt.Errorf("failed to get airing schedule: %w", err)
+	}
+
+	media := map[int]*models.Media{}
+	getMedia := func(mID int) (*models.Media, error) {
+		md, ok := media[mID]
+		if ok {
+			return md, nil
+		}
+		md, err := b.MediaService.GetByID(mID, tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Media by id %d: %w", mID, err)
+		}
+		media[mID] = md
+		return md, nil
+	}
+
+	added := map[episodeKey]bool{}
+	addedEpisodes := map[int]bool{}
+	for _, sa := range schedule {
+		if !wanted[sa.MediaID] {
+			continue
+		}
+		md, err := getMedia(sa.MediaID)
+		if err != nil {
+			return err
+		}
+
+		var ep *models.Episode
+		if sa.EpisodeID != nil {
+			ep, err = b.EpisodeService.GetByID(*sa.EpisodeID, tx)
+			if err != nil {
+				return fmt.Errorf("failed to get Episode by id %d: %w",
+					*sa.EpisodeID, err)
+			}
+			addedEpisodes[*sa.EpisodeID] = true
+		}
+		added[episodeKey{sa.MediaID, sa.EpisodeNumber}] = true
+		uid := eventUID(sa.MediaID, sa.EpisodeNumber, sa.EpisodeID)
+		c.Events = append(c.Events,
+			newEvent(uid, md, sa.EpisodeNumber, ep, sa.AiringAt, false))
+	}
+
+	// Episodes not in the airing schedule are numbered by their position in
+	// the EpisodeSets of the Media, which is only unique within each set, so
+	// they are identified by their IDs. Numbers are only matched to those in
+	// the schedule for Media with a single EpisodeSet.
+	for _, mID := range mIDs {
+		sets, err := b.EpisodeSetService.GetByMedia(mID, nil, nil, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get EpisodeSets by Media id %d: %w",
+				mID, err)
+		}
+		for _, set := range sets {
+			for i, epID := range set.Episodes {
+				number := i + 1
+				if addedEpisodes[epID] ||
+					(len(sets) == 1 && added[episodeKey{mID, number}]) {
+					continue
+				}
+
+				ep, err := b.EpisodeService.GetByID(epID, tx)
+				if err != nil {
+					return fmt.Errorf("failed to get Episode by id %d: %w",
+						epID, err)
+				}
+				if ep.Date == nil || ep.Date.Before(from) || !ep.Date.Before(to) {
+					continue
+				}
+
+				md, err := getMedia(mID)
+				if err != nil {
+					return err
+				}
+				addedEpisodes[epID] = true
+				uid := eventUID(mID, number, &epID)
+				c.Events = append(c.Events,
+					newEvent(uid, md, number, ep, *ep.Date, isDate(*ep.Date)))
+			}
+		}
+	}
+
+	sort.SliceStable(c.Events, func(i, j int) bool {
+		a, b := c.Events[i], c.Events[j]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+		return a.UID < b.UID
+	})
+	return nil
+}
+
+// eventUID returns the UID of the Event of the broadcast of the episode of
+// the Media with the given ID and number. Episodes are identified by their
+// IDs when they exist, so that the same Episode has the same UID whether its
+// broadcast is scheduled or dated; otherwise, by their number.
+func eventUID(mID int, number int, epID *int) string {
+	if epID != nil {
+		return fmt.Sprintf("episode-%d@nao", *epID)
+	}
+	return fmt.Sprintf("media-%d-episode-%d@nao", mID, number)
+}
+
+// newEvent returns the Event with the given UID of the broadcast of the
+// episode of the given Media with the given number at the given time. The
+// Episode is nil if unknown.
+func newEvent(uid string, md *models.Media, number int, ep *models.Episode,
+	at time.Time, allDay bool) Event {
+	e := Event{
+		UID: uid,
+		Summary: fmt.Sprintf("%s - Episode %d", preferredTitle(md.Titles),
+			number),
+		Start:    at,
+		AllDay:   allDay,
+		Duration: defaultDuration,
+	}
+	if allDay {
+		e.Start = at.UTC()
+	}
+	if ep != nil {
+		e.Description = preferredTitle(ep.Titles)
+		if ep.Duration != nil && *ep.Duration > 0 {
+			e.Duration = time.Duration(*ep.Duration) * time.Minute
+		}
+	}
+	return e
+}
+
+// isDate returns true if the given time is midnight in UTC, as are the
+// times of dates without a time of day.
+func isDate(t time.Time) bool {
+	t = t.UTC()
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 &&
+		t.Nanosecond() == 0
+}
+
+// preferredTitle returns the string of the first primary Title in the given
+// set, or of the first Title if none are primary.
+func preferredTitle(set []models.Title) string {
+	for _, t := range set {
+		if t.Priority == models.TitlePriorityPrimary {
+			return t.String
+		}
+	}
+	if len(set) == 0 {
+		return ""
+	}
+	return set[0].String
+}
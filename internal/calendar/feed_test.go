@@ -0,0 +1,168 @@
+package calendar
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/data"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// testServices are the services of the data layer used by calendar tests.
+type testServices struct {
+	media    *data.MediaService
+	episodes *data.EpisodeService
+	sets     *data.EpisodeSetService
+	airings  *data.AiringService
+}
+
+// newTestBuilder returns a Builder of the given services, connected to a
+// new database with their buckets in a temporary directory.
+func newTestBuilder(t *testing.T) (*Builder, *testServices, *db.DatabaseService) {
+	media := data.NewMediaService(db.PersistHooks{})
+	episodes := data.NewEpisodeService(db.PersistHooks{})
+	sets := data.NewEpisodeSetService(db.PersistHooks{}, episodes, media)
+	slots := data.NewBroadcastSlotService(db.PersistHooks{}, media)
+	airings := data.NewAiringService(db.PersistHooks{}, media, episodes)
+	b := Builder{
+		MediaService:      media,
+		EpisodeService:    episodes,
+		EpisodeSetService: sets,
+		ScheduleService:   data.NewScheduleService(slots, airings),
+	}
+
+	var buckets []string
+	for _, ser := range []db.Service{media, episodes, sets, slots, airings} {
+		buckets = append(buckets, ser.Bucket())
+		if indexed, ok := ser.(interface{ IndexBuckets() []string }); ok {
+			buckets = append(buckets, indexed.IndexBuckets()...)
+		}
+	}
+	driver, err := db.ConnectBoltDatabase(&db.BoltDatabaseConfig{
+		Path:     filepath.Join(t.TempDir(), "db"),
+		FileMode: 0600,
+		Buckets:  buckets,
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	database := &db.DatabaseService{DatabaseDriver: driver}
+	t.Cleanup(func() { database.Close() })
+
+	return &b, &testServices{
+		media:    media,
+		episodes: episodes,
+		sets:     sets,
+		airings:  airings,
+	}, database
+}
+
+func TestForMediaEpisodeSets(t *testing.T) {
+	b, ss, database := newTestBuilder(t)
+	media, episodes, sets := ss.media, ss.episodes, ss.sets
+
+	now := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+	var mID int
+	err := database.Transaction(true, func(tx db.Tx) error {
+		var err error
+		mID, err = media.Create(&models.Media{
+			Titles: []models.Title{{String: "Monogatari"}}}, tx)
+		if err != nil {
+			return err
+		}
+		// Each set has a first episode, broadcast on different days
+		for i := 0; i < 2; i++ {
+			date := now.AddDate(0, 0, i+1)
+			epID, err := episodes.Create(&models.Episode{Date: &date}, tx)
+			if err != nil {
+				return err
+			}
+			_, err = sets.Create(&models.EpisodeSet{MediaID: mID,
+				Episodes: []int{epID}}, tx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to create Media: %v", err)
+	}
+
+	var c *Calendar
+	err = database.Transaction(false, func(tx db.Tx) error {
+		c, err = b.ForMedia(mID, now, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to build calendar: %v", err)
+	}
+	if len(c.Events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", c.Events)
+	}
+	if c.Events[0].UID == c.Events[1].UID {
+		t.Errorf("expected distinct UIDs, got %q twice", c.Events[0].UID)
+	}
+}
+
+// TestForMediaScheduledEpisodeUID tests that the event of a scheduled
+// broadcast of an Episode has the same UID as when only its date is known.
+func TestForMediaScheduledEpisodeUID(t *testing.T) {
+	b, ss, database := newTestBuilder(t)
+
+	now := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+	at := now.AddDate(0, 0, 1)
+	var mID, epID int
+	err := database.Transaction(true, func(tx db.Tx) error {
+		var err error
+		mID, err = ss.media.Create(&models.Media{
+			Titles: []models.Title{{String: "Monogatari"}}}, tx)
+		if err != nil {
+			return err
+		}
+		epID, err = ss.episodes.Create(&models.Episode{Date: &at}, tx)
+		if err != nil {
+			return err
+		}
+		_, err = ss.sets.Create(&models.EpisodeSet{MediaID: mID,
+			Episodes: []int{epID}}, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to create Media: %v", err)
+	}
+
+	build := func() *Calendar {
+		var c *Calendar
+		err := database.Transaction(false, func(tx db.Tx) error {
+			var err error
+			c, err = b.ForMedia(mID, now, tx)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("failed to build calendar: %v", err)
+		}
+		if len(c.Events) != 1 {
+			t.Fatalf("expected 1 event, got %+v", c.Events)
+		}
+		return c
+	}
+
+	dated := build()
+	err = database.Transaction(true, func(tx db.Tx) error {
+		_, err := ss.airings.Create(&models.Airing{MediaID: mID,
+			EpisodeNumber: 1, EpisodeID: &epID, AiringAt: at}, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to create Airing: %v", err)
+	}
+	scheduled := build()
+
+	if dated.Events[0].UID != scheduled.Events[0].UID {
+		t.Errorf("expected UID %q of dated Episode for scheduled broadcast, "+
+			"got %q", dated.Events[0].UID, scheduled.Events[0].UID)
+	}
+}
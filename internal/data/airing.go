@@ -0,0 +1,286 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+// AiringService performs operations on Airings.
+type AiringService struct {
+	MediaService   *MediaService
+	EpisodeService *EpisodeService
+	Hooks          db.PersistHooks
+}
+
+// NewAiringService returns an AiringService.
+func NewAiringService(hooks db.PersistHooks, mediaService *MediaService,
+	episodeService *EpisodeService) *AiringService {
+	airingService := &AiringService{
+		MediaService:   mediaService,
+		EpisodeService: episodeService,
+		Hooks:          hooks,
+	}
+
+	// Add hook to delete Airings on Media deletion
+	deleteAiringOnDeleteMedia := func(mdm db.Model, _ db.Service, tx db.Tx) error {
+		mID := mdm.Metadata().ID
+		err := airingService.DeleteByMedia(mID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Airings by Media ID %d: %w", mID, err)
+		}
+		return nil
+	}
+	mdSerHooks := mediaService.PersistHooks()
+	mdSerHooks.PreDeleteHooks =
+		append(mdSerHooks.PreDeleteHooks, deleteAiringOnDeleteMedia)
+
+	// Add hook to unset the Episodes of Airings on Episode deletion, since
+	// the broadcasts still took place
+	unsetEpisodeOnDeleteEpisode := func(epm db.Model, _ db.Service, tx db.Tx) error {
+		epID := epm.Metadata().ID
+		list, err := airingService.GetFilter(nil, nil, tx, func(a *models.Airing) bool {
+			return a.EpisodeID != nil && *a.EpisodeID == epID
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get Airings by Episode ID %d: %w", epID, err)
+		}
+		for _, a := range list {
+			a.EpisodeID = nil
+			err = airingService.Update(a, tx)
+			if err != nil {
+				return fmt.Errorf("failed to update Airing by ID %d: %w",
+					a.Meta.ID, err)
+			}
+		}
+		return nil
+	}
+	epSerHooks := episodeService.PersistHooks()
+	epSerHooks.PreDeleteHooks =
+		append(epSerHooks.PreDeleteHooks, unsetEpisodeOnDeleteEpisode)
+
+	return airingService
+}
+
+// Create persists the given Airing.
+func (ser *AiringService) Create(a *models.Airing, tx db.Tx) (int, error) {
+	return tx.Database().Create(a, ser, tx)
+}
+
+// Update replaces the value of the Airing with the given ID.
+func (ser *AiringService) Update(a *models.Airing, tx db.Tx) error {
+	return tx.Database().Update(a, ser, tx)
+}
+
+// Delete deletes the Airing with the given ID.
+func (ser *AiringService) Delete(id int, tx db.Tx) error {
+	return tx.Database().Delete(id, ser, tx)
+}
+
+// DeleteByMedia deletes the Airings with the given Media ID.
+func (ser *AiringService) DeleteByMedia(mID int, tx db.Tx) error {
+	return tx.Database().DeleteFilter(ser, tx, func(m db.Model) bool {
+		a, err := ser.AssertType(m)
+		if err != nil {
+			return false
+		}
+		return a.MediaID == mID
+	})
+}
+
+// GetAll retrieves all persisted values of Airing.
+func (ser *AiringService) GetAll(first *int, skip *int, tx db.Tx) ([]*models.Airing, error) {
+	alist, err := tx.Database().GetAll(first, skip, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(alist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to Airings: %w", err)
+	}
+	return list, nil
+}
+
+// GetFilter retrieves all persisted values of Airing that pass the filter.
+func (ser *AiringService) GetFilter(
+	first *int, skip *int, tx db.Tx, keep func(a *models.Airing) bool,
+) ([]*models.Airing, error) {
+	alist, err := tx.Database().GetFilter(first, skip, ser, tx,
+		func(m db.Model) bool {
+			a, err := ser.AssertType(m)
+			if err != nil {
+				return false
+			}
+			return keep(a)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(alist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to Airings: %w", err)
+	}
+	return list, nil
+}
+
+// GetByID retrieves the persisted Airing with the given ID.
+func (ser *AiringService) GetByID(id int, tx db.Tx) (*models.Airing, error) {
+	m, err := tx.Database().GetByID(id, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return a, nil
+}
+
+// GetByMedia retrieves the Airings with the given Media ID, ordered by
+// episode number.
+func (ser *AiringService) GetByMedia(mID int, tx db.Tx) ([]*models.Airing, error) {
+	list, err := ser.GetFilter(nil, nil, tx, func(a *models.Airing) bool {
+		return a.MediaID == mID
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].EpisodeNumber < list[j].EpisodeNumber
+	})
+	return list, nil
+}
+
+// Bucket returns the name of the bucket for Airing.
+func (ser *AiringService) Bucket() string {
+	return "Airing"
+}
+
+// Clean cleans the given Airing for storage.
+func (ser *AiringService) Clean(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// Validate returns an error if the Airing is not valid for the database.
+func (ser *AiringService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// Check if Media with ID specified in new Airing exists
+	_, err = tx.Database().GetRawByID(e.MediaID, ser.MediaService, tx)
+	if err != nil {
+		return &ValidationError{"Airing", "MediaID",
+			fmt.Errorf("failed to get Media with ID %d: %w", e.MediaID, err)}
+	}
+
+	// Check if Episode with ID specified in new Airing exists
+	if e.EpisodeID != nil {
+		_, err = tx.Database().GetRawByID(*e.EpisodeID, ser.EpisodeService, tx)
+		if err != nil {
+			return &ValidationError{"Airing", "EpisodeID",
+				fmt.Errorf("failed to get Episode with ID %d: %w", *e.EpisodeID, err)}
+		}
+	}
+
+	if e.EpisodeNumber < 0 {
+		return &ValidationError{"Airing", "EpisodeNumber",
+			fmt.Errorf("negative episode number %d: %w", e.EpisodeNumber, errInvalid)}
+	}
+	if e.AiringAt.IsZero() {
+		return &ValidationError{"Airing", "AiringAt",
+			fmt.Errorf("airing time: %w", errEmpty)}
+	}
+
+	// Check that no other Airing of the Media has the same episode number
+	others, err := ser.GetFilter(nil, nil, tx, func(a *models.Airing) bool {
+		return a.MediaID == e.MediaID && a.EpisodeNumber == e.EpisodeNumber &&
+			a.Meta.ID != e.Meta.ID
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get Airings by Media ID %d: %w",
+			e.MediaID, err)
+	}
+	if len(others) > 0 {
+		return &ValidationError{"Airing", "EpisodeNumber",
+			fmt.Errorf("episode number %d: %w", e.EpisodeNumber, errAlreadyExists)}
+	}
+	return nil
+}
+
+// Initialize sets initial values for some properties.
+func (ser *AiringService) Initialize(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistOldProperties maintains certain properties of the existing Airing in
+// updates.
+func (ser *AiringService) PersistOldProperties(_ db.Model, _ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistHooks returns the persistence hook functions.
+func (ser *AiringService) PersistHooks() *db.PersistHooks {
+	return &ser.Hooks
+}
+
+// Marshal transforms the given Airing into JSON.
+func (ser *AiringService) Marshal(m db.Model) ([]byte, error) {
+	a, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	buf, err := json.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONMarshal, err)
+	}
+
+	return buf, nil
+}
+
+// Unmarshal parses the given JSON into Airing.
+func (ser *AiringService) Unmarshal(buf []byte) (db.Model, error) {
+	var a models.Airing
+	err := json.Unmarshal(buf, &a)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONUnmarshal, err)
+	}
+	return &a, nil
+}
+
+// AssertType exposes the given db.Model as a Airing.
+func (ser *AiringService) AssertType(m db.Model) (*models.Airing, error) {
+	if m == nil {
+		return nil, fmt.Errorf("model: %w", errNil)
+	}
+
+	a, ok := m.(*models.Airing)
+	if !ok {
+		return nil, fmt.Errorf("model: %w", errors.New("not of Airing type"))
+	}
+	return a, nil
+}
+
+// mapfromModel returns a list of Airing type asserted from the given list of
+// db.Model.
+func (ser *AiringService) mapFromModel(alist []db.Model) ([]*models.Airing, error) {
+	list := make([]*models.Airing, len(alist))
+	var err error
+	for i, a := range alist {
+		list[i], err = ser.AssertType(a)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+	}
+	return list, nil
+}
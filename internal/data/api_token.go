@@ -0,0 +1,418 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/jwt"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+// APITokenPrefix is the prefix of all API tokens, distinguishing them from
+// JSON web tokens.
+const APITokenPrefix = "nao_"
+
+// apiTokenUseInterval is the minimum interval between updates of the last
+// used time of an APIToken, to avoid a write on every request.
+const apiTokenUseInterval = time.Minute
+
+// apiTokenHashIndex is the name of the index bucket that maps token hashes
+// to APIToken IDs.
+const apiTokenHashIndex = "APITokenHash"
+
+// APITokenService performs operations on APIToken.
+type APITokenService struct {
+	UserService *UserService
+	Hooks       db.PersistHooks
+
+	// hashes is the index of the token hashes of APITokens.
+	hashes *tokenHashIndex
+}
+
+// NewAPITokenService returns an APITokenService.
+func NewAPITokenService(hooks db.PersistHooks,
+	userService *UserService) *APITokenService {
+	apiTokenService := &APITokenService{
+		UserService: userService,
+		Hooks:       hooks,
+	}
+	apiTokenService.hashes = newTokenHashIndex(apiTokenHashIndex,
+		apiTokenService, func(m db.Model) (string, error) {
+			t, err := apiTokenService.AssertType(m)
+			if err != nil {
+				return "", err
+			}
+			return t.TokenHash, nil
+		})
+
+	// Add hook to delete APIToken on User deletion
+	deleteAPITokenOnDeleteUser := func(um db.Model, _ db.Service, tx db.Tx) error {
+		uID := um.Metadata().ID
+		err := apiTokenService.DeleteByUser(uID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete APIToken by User ID %d: %w",
+				uID, err)
+		}
+		return nil
+	}
+	uSerHooks := userService.PersistHooks()
+	uSerHooks.PreDeleteHooks =
+		append(uSerHooks.PreDeleteHooks, deleteAPITokenOnDeleteUser)
+
+	return apiTokenService
+}
+
+// Create persists the given APIToken.
+func (ser *APITokenService) Create(t *models.APIToken, tx db.Tx) (int, error) {
+	return tx.Database().Create(t, ser, tx)
+}
+
+// Update replaces the value of the APIToken with the given ID.
+func (ser *APITokenService) Update(t *models.APIToken, tx db.Tx) error {
+	return tx.Database().Update(t, ser, tx)
+}
+
+// Delete deletes the APIToken with the given ID.
+func (ser *APITokenService) Delete(id int, tx db.Tx) error {
+	return tx.Database().Delete(id, ser, tx)
+}
+
+// DeleteByUser deletes the APITokens with the given User ID.
+func (ser *APITokenService) DeleteByUser(uID int, tx db.Tx) error {
+	return tx.Database().DeleteFilter(ser, tx, func(m db.Model) bool {
+		t, err := ser.AssertType(m)
+		if err != nil {
+			return false
+		}
+		return t.UserID == uID
+	})
+}
+
+// Issue creates a new APIToken for the User with the given ID. The APIToken
+// and the token itself are returned; only the hash of the token is persisted.
+func (ser *APITokenService) Issue(uID int, name string,
+	scope models.APITokenScope, expiresAt *time.Time,
+	tx db.Tx) (*models.APIToken, string, error) {
+	tkn, err := jwt.NewOpaqueToken(APITokenPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	t := models.APIToken{
+		UserID:    uID,
+		Name:      name,
+		TokenHash: jwt.HashOpaqueToken(tkn),
+		Scope:     scope,
+		ExpiresAt: expiresAt,
+	}
+	_, err = ser.Create(&t, tx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create APIToken: %w", err)
+	}
+
+	return &t, tkn, nil
+}
+
+// Authenticate retrieves the active APIToken with the given token. It does
+// not record the use of the APIToken; see RecordUse.
+func (ser *APITokenService) Authenticate(tkn string, tx db.Tx) (*models.APIToken, error) {
+	t, err := ser.GetByToken(tkn, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ser.IsActive(t) {
+		return nil, fmt.Errorf("APIToken %d: %w", t.Meta.ID,
+			errors.New("is revoked or expired"))
+	}
+	return t, nil
+}
+
+// UseIsStale returns true if the last used time of the given APIToken is
+// older than apiTokenUseInterval, so that its use should be recorded.
+func (ser *APITokenService) UseIsStale(t *models.APIToken, now time.Time) bool {
+	return t.LastUsed == nil || now.Sub(*t.LastUsed) >= apiTokenUseInterval
+}
+
+// RecordUse sets the last used time of the APIToken with the given ID to the
+// given time, unless it has been recorded within apiTokenUseInterval.
+func (ser *APITokenService) RecordUse(id int, now time.Time, tx db.Tx) error {
+	t, err := ser.GetByID(id, tx)
+	if err != nil {
+		return fmt.Errorf("failed to get APIToken by id %d: %w", id, err)
+	}
+
+	// The use may have been recorded by another request since it was read
+	if !ser.UseIsStale(t, now) {
+		return nil
+	}
+
+	t.LastUsed = &now
+	err = ser.Update(t, tx)
+	if err != nil {
+		return fmt.Errorf("failed to update APIToken by id %d: %w", id, err)
+	}
+	return nil
+}
+
+// Revoke revokes the APIToken with the given ID.
+func (ser *APITokenService) Revoke(id int, tx db.Tx) (*models.APIToken, error) {
+	t, err := ser.GetByID(id, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get APIToken by id %d: %w", id, err)
+	}
+
+	if t.Revoked {
+		return t, nil
+	}
+
+	t.Revoked = true
+	err = ser.Update(t, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update APIToken by id %d: %w", id, err)
+	}
+	return t, nil
+}
+
+// IsActive returns true if the given APIToken has not been revoked and has
+// not expired.
+func (ser *APITokenService) IsActive(t *models.APIToken) bool {
+	return !t.Revoked && (t.ExpiresAt == nil || time.Now().Before(*t.ExpiresAt))
+}
+
+// GetAll retrieves all persisted values of APIToken.
+func (ser *APITokenService) GetAll(first *int, skip *int, tx db.Tx) ([]*models.APIToken, error) {
+	vlist, err := tx.Database().GetAll(first, skip, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to APITokens: %w", err)
+	}
+	return list, nil
+}
+
+// GetFilter retrieves all persisted values of APIToken that pass the filter.
+func (ser *APITokenService) GetFilter(
+	first *int, skip *int, tx db.Tx, keep func(t *models.APIToken) bool,
+) ([]*models.APIToken, error) {
+	vlist, err := tx.Database().GetFilter(first, skip, ser, tx,
+		func(m db.Model) bool {
+			t, err := ser.AssertType(m)
+			if err != nil {
+				return false
+			}
+			return keep(t)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to APITokens: %w", err)
+	}
+	return list, nil
+}
+
+// GetMultiple retrieves the persisted APIToken values specified by the given
+// IDs that pass the filter.
+func (ser *APITokenService) GetMultiple(
+	ids []int, tx db.Tx, keep func(t *models.APIToken) bool,
+) ([]*models.APIToken, error) {
+	vlist, err := tx.Database().GetMultiple(ids, ser, tx,
+		func(m db.Model) bool {
+			t, err := ser.AssertType(m)
+			if err != nil {
+				return false
+			}
+			return keep(t)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to APITokens: %w", err)
+	}
+	return list, nil
+}
+
+// GetByID retrieves the persisted APIToken with the given ID.
+func (ser *APITokenService) GetByID(id int, tx db.Tx) (*models.APIToken, error) {
+	m, err := tx.Database().GetByID(id, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return t, nil
+}
+
+// GetByUser retrieves the persisted APITokens with the given User ID.
+func (ser *APITokenService) GetByUser(
+	uID int, first *int, skip *int, tx db.Tx,
+) ([]*models.APIToken, error) {
+	return ser.GetFilter(first, skip, tx, func(t *models.APIToken) bool {
+		return t.UserID == uID
+	})
+}
+
+// GetByToken retrieves the persisted APIToken with the given token.
+func (ser *APITokenService) GetByToken(tkn string, tx db.Tx) (*models.APIToken, error) {
+	id, err := ser.hashes.get(jwt.HashOpaqueToken(tkn), tx)
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		return nil, fmt.Errorf("API token: %w", errInvalid)
+	}
+	return ser.GetByID(id, tx)
+}
+
+// Reindex rebuilds the token hash index from the persisted APITokens.
+func (ser *APITokenService) Reindex(tx db.Tx) error {
+	return ser.hashes.reindex(tx)
+}
+
+// IndexBuckets returns the names of the index buckets for APIToken.
+func (ser *APITokenService) IndexBuckets() []string {
+	return []string{apiTokenHashIndex}
+}
+
+// Bucket returns the name of the bucket for APIToken.
+func (ser *APITokenService) Bucket() string {
+	return "APIToken"
+}
+
+// Clean cleans the given APIToken for storage.
+func (ser *APITokenService) Clean(m db.Model, _ db.Tx) error {
+	e, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	e.Name = strings.Trim(e.Name, " ")
+	return nil
+}
+
+// Validate returns an error if the APIToken is not valid for the database.
+func (ser *APITokenService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// Check if User with ID specified in APIToken exists
+	_, err = tx.Database().GetRawByID(e.UserID, ser.UserService, tx)
+	if err != nil {
+		return &ValidationError{"APIToken", "UserID",
+			fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)}
+	}
+
+	if strings.Trim(e.Name, " ") == "" {
+		return &ValidationError{"APIToken", "Name",
+			fmt.Errorf("name: %w", errInvalid)}
+	}
+
+	if e.TokenHash == "" {
+		return &ValidationError{"APIToken", "TokenHash",
+			fmt.Errorf("token hash: %w", errNil)}
+	}
+
+	return nil
+}
+
+// Initialize sets initial values for some properties.
+func (ser *APITokenService) Initialize(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistOldProperties maintains certain properties of the existing APIToken
+// in updates.
+func (ser *APITokenService) PersistOldProperties(n db.Model, o db.Model, _ db.Tx) error {
+	nt, err := ser.AssertType(n)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	ot, err := ser.AssertType(o)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// APITokens may not be moved to another User, have their token or scope
+	// changed, or be reinstated once revoked
+	nt.UserID = ot.UserID
+	nt.TokenHash = ot.TokenHash
+	nt.Scope = ot.Scope
+	nt.Revoked = nt.Revoked || ot.Revoked
+	return nil
+}
+
+// PersistHooks returns the persistence hook functions.
+func (ser *APITokenService) PersistHooks() *db.PersistHooks {
+	return &ser.Hooks
+}
+
+// Marshal transforms the given APIToken into JSON.
+func (ser *APITokenService) Marshal(m db.Model) ([]byte, error) {
+	t, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	v, err := json.Marshal(t)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONMarshal, err)
+	}
+
+	return v, nil
+}
+
+// Unmarshal parses the given JSON into APIToken.
+func (ser *APITokenService) Unmarshal(buf []byte) (db.Model, error) {
+	var t models.APIToken
+	err := json.Unmarshal(buf, &t)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONUnmarshal, err)
+	}
+	return &t, nil
+}
+
+// AssertType exposes the given db.Model as an APIToken.
+func (ser *APITokenService) AssertType(m db.Model) (*models.APIToken, error) {
+	if m == nil {
+		return nil, fmt.Errorf("model: %w", errNil)
+	}
+
+	t, ok := m.(*models.APIToken)
+	if !ok {
+		return nil,
+			fmt.Errorf("model: %w", errors.New("not of APIToken type"))
+	}
+	return t, nil
+}
+
+// mapfromModel returns a list of APIToken type asserted from the given list of
+// db.Model.
+func (ser *APITokenService) mapFromModel(vlist []db.Model) ([]*models.APIToken, error) {
+	list := make([]*models.APIToken, len(vlist))
+	var err error
+	for i, v := range vlist {
+		list[i], err = ser.AssertType(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+	}
+	return list, nil
+}
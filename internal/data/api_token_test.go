@@ -0,0 +1,91 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// TestAPITokenGetByToken tests that APITokens are found by their token
+// through the index, including after the index is rebuilt, and not after
+// they are deleted.
+func TestAPITokenGetByToken(t *testing.T) {
+	userService := NewUserService(db.PersistHooks{})
+	ser := NewAPITokenService(db.PersistHooks{}, userService)
+	database, cleanup := newTestDatabase(t, userService, ser)
+	defer cleanup()
+
+	var tkns []string
+	var ids []int
+	err := database.Transaction(true, func(tx db.Tx) error {
+		uID, err := userService.Create(&models.User{
+			Username: "alice",
+			Email:    "alice@example.com",
+			Password: []byte("correct horse battery"),
+		}, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range []string{"first", "second"} {
+			at, tkn, err := ser.Issue(uID, name, models.APITokenScope{},
+				nil, tx)
+			if err != nil {
+				return err
+			}
+			tkns = append(tkns, tkn)
+			ids = append(ids, at.Meta.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to issue APITokens: %v", err)
+	}
+
+	check := func(when string, deleted int) {
+		err := database.Transaction(false, func(tx db.Tx) error {
+			for i, tkn := range tkns {
+				at, err := ser.GetByToken(tkn, tx)
+				if ids[i] == deleted {
+					if err == nil {
+						t.Errorf("%s: found deleted APIToken %d", when, ids[i])
+					}
+					continue
+				}
+				if err != nil {
+					t.Errorf("%s: failed to get APIToken %d: %v", when, ids[i],
+						err)
+				} else if at.Meta.ID != ids[i] {
+					t.Errorf("%s: got APIToken %d, want %d", when, at.Meta.ID,
+						ids[i])
+				}
+			}
+
+			_, err := ser.GetByToken(APITokenPrefix+"unknown", tx)
+			if err == nil {
+				t.Errorf("%s: found APIToken by unknown token", when)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", when, err)
+		}
+	}
+
+	check("created", 0)
+
+	err = database.Transaction(true, ser.Reindex)
+	if err != nil {
+		t.Fatalf("failed to reindex: %v", err)
+	}
+	check("reindexed", 0)
+
+	err = database.Transaction(true, func(tx db.Tx) error {
+		return ser.Delete(ids[0], tx)
+	})
+	if err != nil {
+		t.Fatalf("failed to delete APIToken: %v", err)
+	}
+	check("deleted", ids[0])
+}
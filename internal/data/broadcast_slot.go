@@ -0,0 +1,285 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+// BroadcastSlotService performs operations on BroadcastSlots.
+type BroadcastSlotService struct {
+	MediaService *MediaService
+	Hooks        db.PersistHooks
+}
+
+// NewBroadcastSlotService returns a BroadcastSlotService.
+func NewBroadcastSlotService(hooks db.PersistHooks,
+	mediaService *MediaService) *BroadcastSlotService {
+	broadcastSlotService := &BroadcastSlotService{
+		MediaService: mediaService,
+		Hooks:        hooks,
+	}
+
+	// Add hook to delete BroadcastSlots on Media deletion
+	deleteBroadcastSlotOnDeleteMedia := func(mdm db.Model, _ db.Service, tx db.Tx) error {
+		mID := mdm.Metadata().ID
+		err := broadcastSlotService.DeleteByMedia(mID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete BroadcastSlots by Media ID %d: %w", mID, err)
+		}
+		return nil
+	}
+	mdSerHooks := mediaService.PersistHooks()
+	mdSerHooks.PreDeleteHooks =
+		append(mdSerHooks.PreDeleteHooks, deleteBroadcastSlotOnDeleteMedia)
+
+	return broadcastSlotService
+}
+
+// Create persists the given BroadcastSlot.
+func (ser *BroadcastSlotService) Create(s *models.BroadcastSlot, tx db.Tx) (int, error) {
+	return tx.Database().Create(s, ser, tx)
+}
+
+// Update replaces the value of the BroadcastSlot with the given ID.
+func (ser *BroadcastSlotService) Update(s *models.BroadcastSlot, tx db.Tx) error {
+	return tx.Database().Update(s, ser, tx)
+}
+
+// Delete deletes the BroadcastSlot with the given ID.
+func (ser *BroadcastSlotService) Delete(id int, tx db.Tx) error {
+	return tx.Database().Delete(id, ser, tx)
+}
+
+// DeleteByMedia deletes the BroadcastSlots with the given Media ID.
+func (ser *BroadcastSlotService) DeleteByMedia(mID int, tx db.Tx) error {
+	return tx.Database().DeleteFilter(ser, tx, func(m db.Model) bool {
+		s, err := ser.AssertType(m)
+		if err != nil {
+			return false
+		}
+		return s.MediaID == mID
+	})
+}
+
+// GetAll retrieves all persisted values of BroadcastSlot.
+func (ser *BroadcastSlotService) GetAll(first *int, skip *int, tx db.Tx) ([]*models.BroadcastSlot, error) {
+	slist, err := tx.Database().GetAll(first, skip, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(slist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to BroadcastSlots: %w", err)
+	}
+	return list, nil
+}
+
+// GetFilter retrieves all persisted values of BroadcastSlot that pass the filter.
+func (ser *BroadcastSlotService) GetFilter(
+	first *int, skip *int, tx db.Tx, keep func(s *models.BroadcastSlot) bool,
+) ([]*models.BroadcastSlot, error) {
+	slist, err := tx.Database().GetFilter(first, skip, ser, tx,
+		func(m db.Model) bool {
+			s, err := ser.AssertType(m)
+			if err != nil {
+				return false
+			}
+			return keep(s)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(slist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to BroadcastSlots: %w", err)
+	}
+	return list, nil
+}
+
+// GetByID retrieves the persisted BroadcastSlot with the given ID.
+func (ser *BroadcastSlotService) GetByID(id int, tx db.Tx) (*models.BroadcastSlot, error) {
+	m, err := tx.Database().GetByID(id, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return s, nil
+}
+
+// GetByMedia retrieves the BroadcastSlots with the given Media ID, ordered
+// by start date.
+func (ser *BroadcastSlotService) GetByMedia(mID int, tx db.Tx) ([]*models.BroadcastSlot, error) {
+	list, err := ser.GetFilter(nil, nil, tx, func(s *models.BroadcastSlot) bool {
+		return s.MediaID == mID
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].StartDate.Before(list[j].StartDate)
+	})
+	return list, nil
+}
+
+// Bucket returns the name of the bucket for BroadcastSlot.
+func (ser *BroadcastSlotService) Bucket() string {
+	return "BroadcastSlot"
+}
+
+// Clean cleans the given BroadcastSlot for storage.
+func (ser *BroadcastSlotService) Clean(m db.Model, _ db.Tx) error {
+	e, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	e.Timezone = strings.TrimSpace(e.Timezone)
+	if e.Station != nil {
+		station := strings.TrimSpace(*e.Station)
+		if station == "" {
+			e.Station = nil
+		} else {
+			e.Station = &station
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if the BroadcastSlot is not valid for the
+// database.
+func (ser *BroadcastSlotService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// Check if Media with ID specified in new BroadcastSlot exists
+	_, err = tx.Database().GetRawByID(e.MediaID, ser.MediaService, tx)
+	if err != nil {
+		return &ValidationError{"BroadcastSlot", "MediaID",
+			fmt.Errorf("failed to get Media with ID %d: %w", e.MediaID, err)}
+	}
+
+	if !e.Weekday.IsValid() {
+		return &ValidationError{"BroadcastSlot", "Weekday",
+			fmt.Errorf("weekday %d: %w", int(e.Weekday), errInvalid)}
+	}
+	if e.Hour < 0 || e.Hour > models.MaxBroadcastHour {
+		return &ValidationError{"BroadcastSlot", "Hour",
+			fmt.Errorf("hour %d: %w", e.Hour, errInvalid)}
+	}
+	if e.Minute < 0 || e.Minute > 59 {
+		return &ValidationError{"BroadcastSlot", "Minute",
+			fmt.Errorf("minute %d: %w", e.Minute, errInvalid)}
+	}
+
+	if e.Timezone == "" {
+		return &ValidationError{"BroadcastSlot", "Timezone",
+			fmt.Errorf("timezone: %w", errEmpty)}
+	}
+	_, err = e.Location()
+	if err != nil {
+		return &ValidationError{"BroadcastSlot", "Timezone",
+			fmt.Errorf("timezone %q: %v: %w", e.Timezone, err, errInvalid)}
+	}
+
+	if e.StartDate.IsZero() {
+		return &ValidationError{"BroadcastSlot", "StartDate",
+			fmt.Errorf("start date: %w", errEmpty)}
+	}
+	if e.EndDate != nil && e.EndDate.Before(e.StartDate) {
+		return &ValidationError{"BroadcastSlot", "EndDate",
+			fmt.Errorf("end date before start date: %w", errInvalid)}
+	}
+
+	if e.FirstEpisode < 0 {
+		return &ValidationError{"BroadcastSlot", "FirstEpisode",
+			fmt.Errorf("negative first episode %d: %w", e.FirstEpisode, errInvalid)}
+	}
+	if e.Episodes != nil && *e.Episodes < 1 {
+		return &ValidationError{"BroadcastSlot", "Episodes",
+			fmt.Errorf("episodes %d: %w", *e.Episodes, errInvalid)}
+	}
+	return nil
+}
+
+// Initialize sets initial values for some properties.
+func (ser *BroadcastSlotService) Initialize(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistOldProperties maintains certain properties of the existing BroadcastSlot in
+// updates.
+func (ser *BroadcastSlotService) PersistOldProperties(_ db.Model, _ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistHooks returns the persistence hook functions.
+func (ser *BroadcastSlotService) PersistHooks() *db.PersistHooks {
+	return &ser.Hooks
+}
+
+// Marshal transforms the given BroadcastSlot into JSON.
+func (ser *BroadcastSlotService) Marshal(m db.Model) ([]byte, error) {
+	s, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	buf, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONMarshal, err)
+	}
+
+	return buf, nil
+}
+
+// Unmarshal parses the given JSON into BroadcastSlot.
+func (ser *BroadcastSlotService) Unmarshal(buf []byte) (db.Model, error) {
+	var s models.BroadcastSlot
+	err := json.Unmarshal(buf, &s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONUnmarshal, err)
+	}
+	return &s, nil
+}
+
+// AssertType exposes the given db.Model as a BroadcastSlot.
+func (ser *BroadcastSlotService) AssertType(m db.Model) (*models.BroadcastSlot, error) {
+	if m == nil {
+		return nil, fmt.Errorf("model: %w", errNil)
+	}
+
+	s, ok := m.(*models.BroadcastSlot)
+	if !ok {
+		return nil, fmt.Errorf("model: %w", errors.New("not of BroadcastSlot type"))
+	}
+	return s, nil
+}
+
+// mapfromModel returns a list of BroadcastSlot type asserted from the given list of
+// db.Model.
+func (ser *BroadcastSlotService) mapFromModel(slist []db.Model) ([]*models.BroadcastSlot, error) {
+	list := make([]*models.BroadcastSlot, len(slist))
+	var err error
+	for i, s := range slist {
+		list[i], err = ser.AssertType(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+	}
+	return list, nil
+}
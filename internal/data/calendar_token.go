@@ -0,0 +1,246 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Dophin2009/nao/internal/jwt"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+// CalendarTokenPrefix is the prefix of all calendar tokens, distinguishing
+// them from API tokens.
+const CalendarTokenPrefix = "naocal_"
+
+// calendarTokenHashIndex is the name of the index bucket that maps token
+// hashes to CalendarToken IDs.
+const calendarTokenHashIndex = "CalendarTokenHash"
+
+// CalendarTokenService performs operations on CalendarToken.
+type CalendarTokenService struct {
+	UserService *UserService
+	Hooks       db.PersistHooks
+
+	// hashes is the index of the token hashes of CalendarTokens.
+	hashes *tokenHashIndex
+}
+
+// NewCalendarTokenService returns a CalendarTokenService.
+func NewCalendarTokenService(hooks db.PersistHooks,
+	userService *UserService) *CalendarTokenService {
+	calendarTokenService := &CalendarTokenService{
+		UserService: userService,
+		Hooks:       hooks,
+	}
+	calendarTokenService.hashes = newTokenHashIndex(calendarTokenHashIndex,
+		calendarTokenService, func(m db.Model) (string, error) {
+			t, err := calendarTokenService.AssertType(m)
+			if err != nil {
+				return "", err
+			}
+			return t.TokenHash, nil
+		})
+
+	// Add hook to delete CalendarToken on User deletion
+	deleteCalendarTokenOnDeleteUser := func(um db.Model, _ db.Service, tx db.Tx) error {
+		uID := um.Metadata().ID
+		err := calendarTokenService.DeleteByUser(uID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete CalendarToken by User ID %d: %w",
+				uID, err)
+		}
+		return nil
+	}
+	uSerHooks := userService.PersistHooks()
+	uSerHooks.PreDeleteHooks =
+		append(uSerHooks.PreDeleteHooks, deleteCalendarTokenOnDeleteUser)
+
+	return calendarTokenService
+}
+
+// Create persists the given CalendarToken.
+func (ser *CalendarTokenService) Create(t *models.CalendarToken, tx db.Tx) (int, error) {
+	return tx.Database().Create(t, ser, tx)
+}
+
+// Delete deletes the CalendarToken with the given ID.
+func (ser *CalendarTokenService) Delete(id int, tx db.Tx) error {
+	return tx.Database().Delete(id, ser, tx)
+}
+
+// DeleteByUser deletes the CalendarTokens with the given User ID.
+func (ser *CalendarTokenService) DeleteByUser(uID int, tx db.Tx) error {
+	return tx.Database().DeleteFilter(ser, tx, func(m db.Model) bool {
+		t, err := ser.AssertType(m)
+		if err != nil {
+			return false
+		}
+		return t.UserID == uID
+	})
+}
+
+// Issue creates a new CalendarToken for the User with the given ID,
+// replacing any previous one so that old addresses of the feed stop working.
+// The token itself is returned; only its hash is persisted.
+func (ser *CalendarTokenService) Issue(uID int, tx db.Tx) (string, error) {
+	err := ser.DeleteByUser(uID, tx)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete CalendarToken by User ID %d: %w",
+			uID, err)
+	}
+
+	tkn, err := jwt.NewOpaqueToken(CalendarTokenPrefix)
+	if err != nil {
+		return "", err
+	}
+
+	t := models.CalendarToken{
+		UserID:    uID,
+		TokenHash: jwt.HashOpaqueToken(tkn),
+	}
+	_, err = ser.Create(&t, tx)
+	if err != nil {
+		return "", fmt.Errorf("failed to create CalendarToken: %w", err)
+	}
+
+	return tkn, nil
+}
+
+// GetByID retrieves the persisted CalendarToken with the given ID.
+func (ser *CalendarTokenService) GetByID(id int, tx db.Tx) (*models.CalendarToken, error) {
+	m, err := tx.Database().GetByID(id, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return t, nil
+}
+
+// GetByToken retrieves the persisted CalendarToken with the given token.
+func (ser *CalendarTokenService) GetByToken(tkn string, tx db.Tx) (*models.CalendarToken, error) {
+	id, err := ser.hashes.get(jwt.HashOpaqueToken(tkn), tx)
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		return nil, fmt.Errorf("calendar token: %w", errInvalid)
+	}
+	return ser.GetByID(id, tx)
+}
+
+// Reindex rebuilds the token hash index from the persisted CalendarTokens.
+func (ser *CalendarTokenService) Reindex(tx db.Tx) error {
+	return ser.hashes.reindex(tx)
+}
+
+// IndexBuckets returns the names of the index buckets for CalendarToken.
+func (ser *CalendarTokenService) IndexBuckets() []string {
+	return []string{calendarTokenHashIndex}
+}
+
+// Bucket returns the name of the bucket for CalendarToken.
+func (ser *CalendarTokenService) Bucket() string {
+	return "CalendarToken"
+}
+
+// Clean cleans the given CalendarToken for storage.
+func (ser *CalendarTokenService) Clean(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// Validate returns an error if the CalendarToken is not valid for the
+// database.
+func (ser *CalendarTokenService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// Check if User with ID specified in CalendarToken exists
+	_, err = tx.Database().GetRawByID(e.UserID, ser.UserService, tx)
+	if err != nil {
+		return &ValidationError{"CalendarToken", "UserID",
+			fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)}
+	}
+
+	if e.TokenHash == "" {
+		return &ValidationError{"CalendarToken", "TokenHash",
+			fmt.Errorf("token hash: %w", errNil)}
+	}
+
+	return nil
+}
+
+// Initialize sets initial values for some properties.
+func (ser *CalendarTokenService) Initialize(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistOldProperties maintains certain properties of the existing
+// CalendarToken in updates.
+func (ser *CalendarTokenService) PersistOldProperties(n db.Model, o db.Model, _ db.Tx) error {
+	nt, err := ser.AssertType(n)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	ot, err := ser.AssertType(o)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// CalendarTokens may not be moved to another User or have their token
+	// changed
+	nt.UserID = ot.UserID
+	nt.TokenHash = ot.TokenHash
+	return nil
+}
+
+// PersistHooks returns the persistence hook functions.
+func (ser *CalendarTokenService) PersistHooks() *db.PersistHooks {
+	return &ser.Hooks
+}
+
+// Marshal transforms the given CalendarToken into JSON.
+func (ser *CalendarTokenService) Marshal(m db.Model) ([]byte, error) {
+	t, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	v, err := json.Marshal(t)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONMarshal, err)
+	}
+
+	return v, nil
+}
+
+// Unmarshal parses the given JSON into CalendarToken.
+func (ser *CalendarTokenService) Unmarshal(buf []byte) (db.Model, error) {
+	var t models.CalendarToken
+	err := json.Unmarshal(buf, &t)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONUnmarshal, err)
+	}
+	return &t, nil
+}
+
+// AssertType exposes the given db.Model as a CalendarToken.
+func (ser *CalendarTokenService) AssertType(m db.Model) (*models.CalendarToken, error) {
+	if m == nil {
+		return nil, fmt.Errorf("model: %w", errNil)
+	}
+
+	t, ok := m.(*models.CalendarToken)
+	if !ok {
+		return nil,
+			fmt.Errorf("model: %w", errors.New("not of CalendarToken type"))
+	}
+	return t, nil
+}
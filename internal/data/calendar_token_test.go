@@ -0,0 +1,73 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// TestCalendarTokenGetByToken tests that CalendarTokens are found by their
+// token through the index, including after the index is rebuilt, and that
+// replaced tokens are not.
+func TestCalendarTokenGetByToken(t *testing.T) {
+	userService := NewUserService(db.PersistHooks{})
+	ser := NewCalendarTokenService(db.PersistHooks{}, userService)
+	database, cleanup := newTestDatabase(t, userService, ser)
+	defer cleanup()
+
+	var uID int
+	var tkns []string
+	err := database.Transaction(true, func(tx db.Tx) error {
+		var err error
+		uID, err = userService.Create(&models.User{
+			Username: "alice",
+			Email:    "alice@example.com",
+			Password: []byte("correct horse battery"),
+		}, tx)
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < 2; i++ {
+			tkn, err := ser.Issue(uID, tx)
+			if err != nil {
+				return err
+			}
+			tkns = append(tkns, tkn)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to issue CalendarTokens: %v", err)
+	}
+
+	check := func(when string) {
+		err := database.Transaction(false, func(tx db.Tx) error {
+			_, err := ser.GetByToken(tkns[0], tx)
+			if err == nil {
+				t.Errorf("%s: found CalendarToken by replaced token", when)
+			}
+
+			ct, err := ser.GetByToken(tkns[1], tx)
+			if err != nil {
+				t.Errorf("%s: failed to get CalendarToken: %v", when, err)
+			} else if ct.UserID != uID {
+				t.Errorf("%s: got CalendarToken of User %d, want %d", when,
+					ct.UserID, uID)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", when, err)
+		}
+	}
+
+	check("issued")
+
+	err = database.Transaction(true, ser.Reindex)
+	if err != nil {
+		t.Fatalf("failed to reindex: %v", err)
+	}
+	check("reindexed")
+}
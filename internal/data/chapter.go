@@ -0,0 +1,305 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+// ChapterService performs operations on Chapters.
+type ChapterService struct {
+	MediaService  *MediaService
+	VolumeService *VolumeService
+	Hooks         db.PersistHooks
+}
+
+// NewChapterService returns a ChapterService.
+func NewChapterService(hooks db.PersistHooks, mediaService *MediaService,
+	volumeService *VolumeService) *ChapterService {
+	chapterService := &ChapterService{
+		MediaService:  mediaService,
+		VolumeService: volumeService,
+		Hooks:         hooks,
+	}
+
+	// Add hook to delete Chapters on Media deletion
+	deleteChapterOnDeleteMedia := func(mdm db.Model, _ db.Service, tx db.Tx) error {
+		mID := mdm.Metadata().ID
+		err := chapterService.DeleteByMedia(mID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Chapters by Media ID %d: %w", mID, err)
+		}
+		return nil
+	}
+	mdSerHooks := mediaService.PersistHooks()
+	mdSerHooks.PreDeleteHooks =
+		append(mdSerHooks.PreDeleteHooks, deleteChapterOnDeleteMedia)
+
+	// Add hook to remove Chapters from Volumes on Volume deletion, since
+	// Chapters exist without them
+	unsetVolumeOnDeleteVolume := func(vm db.Model, _ db.Service, tx db.Tx) error {
+		vID := vm.Metadata().ID
+		list, err := chapterService.GetByVolume(vID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Chapters by Volume ID %d: %w", vID, err)
+		}
+		for _, ch := range list {
+			ch.VolumeID = nil
+			err = chapterService.Update(ch, tx)
+			if err != nil {
+				return fmt.Errorf("failed to update Chapter by ID %d: %w",
+					ch.Meta.ID, err)
+			}
+		}
+		return nil
+	}
+	vSerHooks := volumeService.PersistHooks()
+	vSerHooks.PreDeleteHooks =
+		append(vSerHooks.PreDeleteHooks, unsetVolumeOnDeleteVolume)
+
+	return chapterService
+}
+
+// Create persists the given Chapter.
+func (ser *ChapterService) Create(ch *models.Chapter, tx db.Tx) (int, error) {
+	return tx.Database().Create(ch, ser, tx)
+}
+
+// Update replaces the value of the Chapter with the given ID.
+func (ser *ChapterService) Update(ch *models.Chapter, tx db.Tx) error {
+	return tx.Database().Update(ch, ser, tx)
+}
+
+// Delete deletes the Chapter with the given ID.
+func (ser *ChapterService) Delete(id int, tx db.Tx) error {
+	return tx.Database().Delete(id, ser, tx)
+}
+
+// DeleteByMedia deletes the Chapters with the given Media ID.
+func (ser *ChapterService) DeleteByMedia(mID int, tx db.Tx) error {
+	return tx.Database().DeleteFilter(ser, tx, func(m db.Model) bool {
+		ch, err := ser.AssertType(m)
+		if err != nil {
+			return false
+		}
+		return ch.MediaID == mID
+	})
+}
+
+// GetAll retrieves all persisted values of Chapter.
+func (ser *ChapterService) GetAll(first *int, skip *int, tx db.Tx) ([]*models.Chapter, error) {
+	vlist, err := tx.Database().GetAll(first, skip, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to Chapters: %w", err)
+	}
+	return list, nil
+}
+
+// GetFilter retrieves all persisted values of Chapter that pass the filter.
+func (ser *ChapterService) GetFilter(
+	first *int, skip *int, tx db.Tx, keep func(ch *models.Chapter) bool,
+) ([]*models.Chapter, error) {
+	vlist, err := tx.Database().GetFilter(first, skip, ser, tx,
+		func(m db.Model) bool {
+			ch, err := ser.AssertType(m)
+			if err != nil {
+				return false
+			}
+			return keep(ch)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to Chapters: %w", err)
+	}
+	return list, nil
+}
+
+// GetByID retrieves the persisted Chapter with the given ID.
+func (ser *ChapterService) GetByID(id int, tx db.Tx) (*models.Chapter, error) {
+	m, err := tx.Database().GetByID(id, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return ch, nil
+}
+
+// GetByMedia retrieves the Chapters with the given Media ID, ordered by
+// number.
+func (ser *ChapterService) GetByMedia(mID int, tx db.Tx) ([]*models.Chapter, error) {
+	return ser.getSorted(tx, func(ch *models.Chapter) bool {
+		return ch.MediaID == mID
+	})
+}
+
+// GetByVolume retrieves the Chapters with the given Volume ID, ordered by
+// number.
+func (ser *ChapterService) GetByVolume(vID int, tx db.Tx) ([]*models.Chapter, error) {
+	return ser.getSorted(tx, func(ch *models.Chapter) bool {
+		return ch.VolumeID != nil && *ch.VolumeID == vID
+	})
+}
+
+// getSorted retrieves the Chapters that pass the filter, ordered by number.
+func (ser *ChapterService) getSorted(
+	tx db.Tx, keep func(ch *models.Chapter) bool,
+) ([]*models.Chapter, error) {
+	list, err := ser.GetFilter(nil, nil, tx, keep)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Number < list[j].Number
+	})
+	return list, nil
+}
+
+// Bucket returns the name of the bucket for Chapter.
+func (ser *ChapterService) Bucket() string {
+	return "Chapter"
+}
+
+// Clean cleans the given Chapter for storage.
+func (ser *ChapterService) Clean(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// Validate returns an error if the Chapter is not valid for the database.
+func (ser *ChapterService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// Check if Media with ID specified in new Chapter exists
+	_, err = tx.Database().GetRawByID(e.MediaID, ser.MediaService, tx)
+	if err != nil {
+		return &ValidationError{"Chapter", "MediaID",
+			fmt.Errorf("failed to get Media with ID %d: %w", e.MediaID, err)}
+	}
+
+	// Check if Volume with ID specified in new Chapter exists and is of the
+	// same Media
+	if e.VolumeID != nil {
+		v, err := ser.VolumeService.GetByID(*e.VolumeID, tx)
+		if err != nil {
+			return &ValidationError{"Chapter", "VolumeID",
+				fmt.Errorf("failed to get Volume with ID %d: %w", *e.VolumeID, err)}
+		}
+		if v.MediaID != e.MediaID {
+			return &ValidationError{"Chapter", "VolumeID",
+				fmt.Errorf("Volume %d of another Media: %w", v.Meta.ID, errInvalid)}
+		}
+	}
+
+	if e.Number < 0 || math.IsNaN(e.Number) || math.IsInf(e.Number, 0) {
+		return &ValidationError{"Chapter", "Number",
+			fmt.Errorf("number %v: %w", e.Number, errInvalid)}
+	}
+	if e.Pages != nil && *e.Pages < 0 {
+		return &ValidationError{"Chapter", "Pages",
+			fmt.Errorf("negative pages %d: %w", *e.Pages, errInvalid)}
+	}
+
+	// Check that no other Chapter of the Media has the same number
+	others, err := ser.GetFilter(nil, nil, tx, func(ch *models.Chapter) bool {
+		return ch.MediaID == e.MediaID && ch.Number == e.Number &&
+			ch.Meta.ID != e.Meta.ID
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get Chapters by Media ID %d: %w",
+			e.MediaID, err)
+	}
+	if len(others) > 0 {
+		return &ValidationError{"Chapter", "Number",
+			fmt.Errorf("number %v: %w", e.Number, errAlreadyExists)}
+	}
+	return nil
+}
+
+// Initialize sets initial values for some properties.
+func (ser *ChapterService) Initialize(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistOldProperties maintains certain properties of the existing Chapter in
+// updates.
+func (ser *ChapterService) PersistOldProperties(_ db.Model, _ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistHooks returns the persistence hook functions.
+func (ser *ChapterService) PersistHooks() *db.PersistHooks {
+	return &ser.Hooks
+}
+
+// Marshal transforms the given Chapter into JSON.
+func (ser *ChapterService) Marshal(m db.Model) ([]byte, error) {
+	ch, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	buf, err := json.Marshal(ch)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONMarshal, err)
+	}
+
+	return buf, nil
+}
+
+// Unmarshal parses the given JSON into Chapter.
+func (ser *ChapterService) Unmarshal(buf []byte) (db.Model, error) {
+	var ch models.Chapter
+	err := json.Unmarshal(buf, &ch)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONUnmarshal, err)
+	}
+	return &ch, nil
+}
+
+// AssertType exposes the given db.Model as a Chapter.
+func (ser *ChapterService) AssertType(m db.Model) (*models.Chapter, error) {
+	if m == nil {
+		return nil, fmt.Errorf("model: %w", errNil)
+	}
+
+	ch, ok := m.(*models.Chapter)
+	if !ok {
+		return nil, fmt.Errorf("model: %w", errors.New("not of Chapter type"))
+	}
+	return ch, nil
+}
+
+// mapfromModel returns a list of Chapter type asserted from the given list of
+// db.Model.
+func (ser *ChapterService) mapFromModel(vlist []db.Model) ([]*models.Chapter, error) {
+	list := make([]*models.Chapter, len(vlist))
+	var err error
+	for i, v := range vlist {
+		list[i], err = ser.AssertType(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+	}
+	return list, nil
+}
@@ -12,13 +12,28 @@ import (
 // CharacterService performs operations on Characters.
 type CharacterService struct {
 	Hooks db.PersistHooks
+	// externalIDs is the index of the external IDs of Characters.
+	externalIDs *externalIDIndex
 }
 
+// characterExternalIDIndex is the name of the index bucket that maps external
+// IDs to Character IDs.
+const characterExternalIDIndex = "CharacterExternalID"
+
 // NewCharacterService returns a CharacterService.
 func NewCharacterService(hooks db.PersistHooks) *CharacterService {
-	return &CharacterService{
+	ser := &CharacterService{
 		Hooks: hooks,
 	}
+	ser.externalIDs = newExternalIDIndex(characterExternalIDIndex, "Character", ser,
+		func(m db.Model) ([]models.ExternalID, error) {
+			c, err := ser.AssertType(m)
+			if err != nil {
+				return nil, err
+			}
+			return c.ExternalIDs, nil
+		})
+	return ser
 }
 
 // Create persists the given Character.
@@ -112,6 +127,40 @@ func (ser *CharacterService) GetByID(id int, tx db.Tx) (*models.Character, error
 	return c, nil
 }
 
+// GetByExternalID retrieves the persisted Character with the given external ID.
+// Sources are matched case-insensitively and IDs exactly.
+func (ser *CharacterService) GetByExternalID(
+	source string, id string, tx db.Tx,
+) (*models.Character, error) {
+	mID, err := ser.GetIDByExternalID(source, id, tx)
+	if err != nil {
+		return nil, err
+	}
+	if mID == 0 {
+		return nil, fmt.Errorf("Character with external ID %s %q: %w", source, id,
+			errNotFound)
+	}
+	return ser.GetByID(mID, tx)
+}
+
+// GetIDByExternalID returns the ID of the persisted Character with the given
+// external ID, or 0 if there is none.
+func (ser *CharacterService) GetIDByExternalID(
+	source string, id string, tx db.Tx,
+) (int, error) {
+	return ser.externalIDs.get(source, id, tx)
+}
+
+// Reindex rebuilds the external ID index from the persisted Characters.
+func (ser *CharacterService) Reindex(tx db.Tx) error {
+	return ser.externalIDs.reindex(tx)
+}
+
+// IndexBuckets returns the names of the index buckets for Character.
+func (ser *CharacterService) IndexBuckets() []string {
+	return []string{characterExternalIDIndex}
+}
+
 // Bucket returns the name of the bucket for Media.
 func (ser *CharacterService) Bucket() string {
 	return "Character"
@@ -119,20 +168,23 @@ func (ser *CharacterService) Bucket() string {
 
 // Clean cleans the given Character for storage
 func (ser *CharacterService) Clean(m db.Model, _ db.Tx) error {
-	_, err := ser.AssertType(m)
+	e, err := ser.AssertType(m)
 	if err != nil {
 		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
 	}
+
+	cleanExternalIDs(e.ExternalIDs)
 	return nil
 }
 
 // Validate returns an error if the Character is not valid for the database.
-func (ser *CharacterService) Validate(m db.Model, _ db.Tx) error {
-	_, err := ser.AssertType(m)
+func (ser *CharacterService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
 	if err != nil {
 		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
 	}
-	return nil
+
+	return ser.externalIDs.validate(e.ExternalIDs, e.Meta.ID, tx)
 }
 
 // Initialize sets initial values for some properties.
@@ -142,7 +194,20 @@ func (ser *CharacterService) Initialize(_ db.Model, _ db.Tx) error {
 
 // PersistOldProperties maintains certain properties of the existing Character
 // in updates.
-func (ser *CharacterService) PersistOldProperties(_ db.Model, _ db.Model, _ db.Tx) error {
+func (ser *CharacterService) PersistOldProperties(n db.Model, o db.Model, _ db.Tx) error {
+	e, err := ser.AssertType(n)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	oe, err := ser.AssertType(o)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// ExternalIDs are not changed if not given
+	if e.ExternalIDs == nil {
+		e.ExternalIDs = oe.ExternalIDs
+	}
 	return nil
 }
 
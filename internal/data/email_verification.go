@@ -0,0 +1,386 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/jwt"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+// EmailVerificationService performs operations on EmailVerification.
+type EmailVerificationService struct {
+	UserService *UserService
+	Hooks       db.PersistHooks
+}
+
+// NewEmailVerificationService returns a EmailVerificationService.
+func NewEmailVerificationService(hooks db.PersistHooks,
+	userService *UserService) *EmailVerificationService {
+	emailVerificationService := &EmailVerificationService{
+		UserService: userService,
+		Hooks:       hooks,
+	}
+
+	// Add hook to delete EmailVerification on User deletion
+	deleteEmailVerificationOnDeleteUser := func(um db.Model, _ db.Service, tx db.Tx) error {
+		uID := um.Metadata().ID
+		err := emailVerificationService.DeleteByUser(uID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete EmailVerification by User ID %d: %w",
+				uID, err)
+		}
+		return nil
+	}
+
+	// Add hook to invalidate pending EmailVerifications of a User on email
+	// change
+	expireEmailVerificationOnChangeEmail := func(um db.Model, _ db.Service, tx db.Tx) error {
+		uw, ok := um.(*userWrap)
+		if !ok || !uw.changedEmail {
+			return nil
+		}
+
+		uID := um.Metadata().ID
+		err := emailVerificationService.ExpireByUser(uID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to expire EmailVerification by User ID %d: %w",
+				uID, err)
+		}
+		return nil
+	}
+	uSerHooks := userService.PersistHooks()
+	uSerHooks.PreDeleteHooks =
+		append(uSerHooks.PreDeleteHooks, deleteEmailVerificationOnDeleteUser)
+	uSerHooks.PostUpdateHooks =
+		append(uSerHooks.PostUpdateHooks, expireEmailVerificationOnChangeEmail)
+
+	return emailVerificationService
+}
+
+// Create persists the given EmailVerification.
+func (ser *EmailVerificationService) Create(ev *models.EmailVerification, tx db.Tx) (int, error) {
+	return tx.Database().Create(ev, ser, tx)
+}
+
+// Update replaces the value of the EmailVerification with the given ID.
+func (ser *EmailVerificationService) Update(ev *models.EmailVerification, tx db.Tx) error {
+	return tx.Database().Update(ev, ser, tx)
+}
+
+// Delete deletes the EmailVerification with the given ID.
+func (ser *EmailVerificationService) Delete(id int, tx db.Tx) error {
+	return tx.Database().Delete(id, ser, tx)
+}
+
+// DeleteByUser deletes the EmailVerifications with the given User ID.
+func (ser *EmailVerificationService) DeleteByUser(uID int, tx db.Tx) error {
+	return tx.Database().DeleteFilter(ser, tx, func(m db.Model) bool {
+		ev, err := ser.AssertType(m)
+		if err != nil {
+			return false
+		}
+		return ev.UserID == uID
+	})
+}
+
+// Request creates a new EmailVerification of the current email of the User
+// with the given ID that expires after the given duration. Pending
+// EmailVerifications of the User are invalidated. The EmailVerification and
+// its verification token are returned.
+func (ser *EmailVerificationService) Request(uID int, duration time.Duration,
+	tx db.Tx) (*models.EmailVerification, string, error) {
+	u, err := ser.UserService.GetByID(uID, tx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get User by ID %d: %w", uID, err)
+	}
+
+	err = ser.ExpireByUser(uID, tx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tkn, err := jwt.NewOpaqueToken("")
+	if err != nil {
+		return nil, "", err
+	}
+
+	ev := models.EmailVerification{
+		UserID:    uID,
+		Email:     u.Email,
+		TokenHash: jwt.HashOpaqueToken(tkn),
+		ExpiresAt: time.Now().Add(duration),
+	}
+	_, err = ser.Create(&ev, tx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create EmailVerification: %w", err)
+	}
+
+	return &ev, tkn, nil
+}
+
+// Verify marks the active EmailVerification that the given verification
+// token belongs to as used and the email of its User as verified. The token
+// may not be used again. The verified User is returned.
+func (ser *EmailVerificationService) Verify(tkn string, tx db.Tx) (*models.User, error) {
+	ev, err := ser.GetByToken(tkn, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ser.IsActive(ev) {
+		return nil, fmt.Errorf("EmailVerification %d: %w", ev.Meta.ID,
+			errors.New("is used or expired"))
+	}
+
+	ev.Used = true
+	err = ser.Update(ev, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update EmailVerification by id %d: %w",
+			ev.Meta.ID, err)
+	}
+
+	u, err := ser.UserService.VerifyEmail(ev.UserID, ev.Email, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify email of User %d: %w",
+			ev.UserID, err)
+	}
+	return u, nil
+}
+
+// ExpireByUser marks all active EmailVerifications of the User with the given ID
+// as used.
+func (ser *EmailVerificationService) ExpireByUser(uID int, tx db.Tx) error {
+	list, err := ser.GetFilter(nil, nil, tx, func(ev *models.EmailVerification) bool {
+		return ev.UserID == uID && !ev.Used
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get EmailVerifications by User ID %d: %w", uID, err)
+	}
+
+	for _, ev := range list {
+		ev.Used = true
+		err = ser.Update(ev, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update EmailVerification by id %d: %w",
+				ev.Meta.ID, err)
+		}
+	}
+	return nil
+}
+
+// IsActive returns true if the given EmailVerification has not been used and has
+// not expired.
+func (ser *EmailVerificationService) IsActive(ev *models.EmailVerification) bool {
+	return !ev.Used && time.Now().Before(ev.ExpiresAt)
+}
+
+// GetAll retrieves all persisted values of EmailVerification.
+func (ser *EmailVerificationService) GetAll(first *int, skip *int, tx db.Tx) ([]*models.EmailVerification, error) {
+	vlist, err := tx.Database().GetAll(first, skip, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to EmailVerifications: %w", err)
+	}
+	return list, nil
+}
+
+// GetFilter retrieves all persisted values of EmailVerification that pass the
+// filter.
+func (ser *EmailVerificationService) GetFilter(
+	first *int, skip *int, tx db.Tx, keep func(ev *models.EmailVerification) bool,
+) ([]*models.EmailVerification, error) {
+	vlist, err := tx.Database().GetFilter(first, skip, ser, tx,
+		func(m db.Model) bool {
+			ev, err := ser.AssertType(m)
+			if err != nil {
+				return false
+			}
+			return keep(ev)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to EmailVerifications: %w", err)
+	}
+	return list, nil
+}
+
+// GetByID retrieves the persisted EmailVerification with the given ID.
+func (ser *EmailVerificationService) GetByID(id int, tx db.Tx) (*models.EmailVerification, error) {
+	m, err := tx.Database().GetByID(id, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	ev, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return ev, nil
+}
+
+// GetByToken retrieves the persisted EmailVerification with the given
+// verification token.
+func (ser *EmailVerificationService) GetByToken(tkn string, tx db.Tx) (*models.EmailVerification, error) {
+	hash := jwt.HashOpaqueToken(tkn)
+
+	var ev *models.EmailVerification
+	_, err := tx.Database().FindFirst(ser, tx, func(m db.Model) (bool, error) {
+		e, err := ser.AssertType(m)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+
+		if e.TokenHash == hash {
+			ev = e
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate through keys: %w", err)
+	}
+
+	if ev == nil {
+		return nil, fmt.Errorf("verification token: %w", errInvalid)
+	}
+	return ev, nil
+}
+
+// Bucket returns the name of the bucket for EmailVerification.
+func (ser *EmailVerificationService) Bucket() string {
+	return "EmailVerification"
+}
+
+// Clean cleans the given EmailVerification for storage.
+func (ser *EmailVerificationService) Clean(m db.Model, _ db.Tx) error {
+	_, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return nil
+}
+
+// Validate returns an error if the EmailVerification is not valid for the
+// database.
+func (ser *EmailVerificationService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// Check if User with ID specified in EmailVerification exists
+	_, err = tx.Database().GetRawByID(e.UserID, ser.UserService, tx)
+	if err != nil {
+		return &ValidationError{"EmailVerification", "UserID",
+			fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)}
+	}
+
+	if e.Email == "" {
+		return &ValidationError{"EmailVerification", "Email",
+			fmt.Errorf("email: %w", errEmpty)}
+	}
+
+	if e.TokenHash == "" {
+		return &ValidationError{"EmailVerification", "TokenHash",
+			fmt.Errorf("verification token hash: %w", errNil)}
+	}
+
+	return nil
+}
+
+// Initialize sets initial values for some properties.
+func (ser *EmailVerificationService) Initialize(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistOldProperties maintains certain properties of the existing
+// EmailVerification in updates.
+func (ser *EmailVerificationService) PersistOldProperties(n db.Model, o db.Model, _ db.Tx) error {
+	nev, err := ser.AssertType(n)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	oev, err := ser.AssertType(o)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// EmailVerifications may not be moved to another User or email, extended,
+	// or used again
+	nev.UserID = oev.UserID
+	nev.Email = oev.Email
+	nev.TokenHash = oev.TokenHash
+	nev.ExpiresAt = oev.ExpiresAt
+	nev.Used = nev.Used || oev.Used
+	return nil
+}
+
+// PersistHooks returns the persistence hook functions.
+func (ser *EmailVerificationService) PersistHooks() *db.PersistHooks {
+	return &ser.Hooks
+}
+
+// Marshal transforms the given EmailVerification into JSON.
+func (ser *EmailVerificationService) Marshal(m db.Model) ([]byte, error) {
+	ev, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	v, err := json.Marshal(ev)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONMarshal, err)
+	}
+
+	return v, nil
+}
+
+// Unmarshal parses the given JSON into EmailVerification.
+func (ser *EmailVerificationService) Unmarshal(buf []byte) (db.Model, error) {
+	var ev models.EmailVerification
+	err := json.Unmarshal(buf, &ev)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONUnmarshal, err)
+	}
+	return &ev, nil
+}
+
+// AssertType exposes the given db.Model as a EmailVerification.
+func (ser *EmailVerificationService) AssertType(m db.Model) (*models.EmailVerification, error) {
+	if m == nil {
+		return nil, fmt.Errorf("model: %w", errNil)
+	}
+
+	ev, ok := m.(*models.EmailVerification)
+	if !ok {
+		return nil,
+			fmt.Errorf("model: %w", errors.New("not of EmailVerification type"))
+	}
+	return ev, nil
+}
+
+// mapfromModel returns a list of EmailVerification type asserted from the given
+// list of db.Model.
+func (ser *EmailVerificationService) mapFromModel(vlist []db.Model) ([]*models.EmailVerification, error) {
+	list := make([]*models.EmailVerification, len(vlist))
+	var err error
+	for i, v := range vlist {
+		list[i], err = ser.AssertType(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+	}
+	return list, nil
+}
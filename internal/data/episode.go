@@ -230,9 +230,9 @@ func NewEpisodeSetService(hooks db.PersistHooks, episodeService *EpisodeService,
 
 				// Find ID of Episode to be deleted in the list
 				rmID := -1
-				for _, id := range set.Episodes {
+				for i, id := range set.Episodes {
 					if id == epID {
-						rmID = id
+						rmID = i
 						break
 					}
 				}
@@ -428,10 +428,21 @@ func (ser *EpisodeSetService) Validate(m db.Model, tx db.Tx) error {
 		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
 	}
 
+	db := tx.Database()
+
+	// Check if Media with ID specified in EpisodeSet exists
+	_, err = db.GetRawByID(set.MediaID, ser.MediaService, tx)
+	if err != nil {
+		return &ValidationError{"EpisodeSet", "MediaID",
+			fmt.Errorf("failed to get Media with ID %d: %w", set.MediaID, err)}
+	}
+
+	// Check if Episodes with IDs specified in EpisodeSet exist
 	for _, id := range set.Episodes {
-		_, err := tx.Database().GetRawByID(id, ser, tx)
+		_, err := db.GetRawByID(id, ser.EpisodeService, tx)
 		if err != nil {
-			return fmt.Errorf("failed to get Episode with ID %d: %w", id, err)
+			return &ValidationError{"EpisodeSet", "Episodes",
+				fmt.Errorf("failed to get Episode with ID %d: %w", id, err)}
 		}
 	}
 	return nil
@@ -0,0 +1,521 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+// EpisodeProgressService performs operations on EpisodeProgress.
+type EpisodeProgressService struct {
+	UserMediaService  *UserMediaService
+	EpisodeSetService *EpisodeSetService
+	Hooks             db.PersistHooks
+}
+
+// NewEpisodeProgressService returns an EpisodeProgressService.
+func NewEpisodeProgressService(hooks db.PersistHooks,
+	userMediaService *UserMediaService,
+	episodeSetService *EpisodeSetService) *EpisodeProgressService {
+	episodeProgressService := &EpisodeProgressService{
+		UserMediaService:  userMediaService,
+		EpisodeSetService: episodeSetService,
+		Hooks:             hooks,
+	}
+
+	// Add hook to delete EpisodeProgress on UserMedia deletion
+	deleteEpisodeProgressOnDeleteUserMedia := func(umm db.Model, _ db.Service, tx db.Tx) error {
+		umID := umm.Metadata().ID
+		err := episodeProgressService.DeleteByUserMedia(umID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete EpisodeProgress by UserMedia ID %d: %w",
+				umID, err)
+		}
+		return nil
+	}
+	umSerHooks := userMediaService.PersistHooks()
+	umSerHooks.PreDeleteHooks =
+		append(umSerHooks.PreDeleteHooks, deleteEpisodeProgressOnDeleteUserMedia)
+
+	// Add hook to delete EpisodeProgress when the User or Media of its
+	// UserMedia is changed, since it no longer applies
+	deleteEpisodeProgressOnUpdateUserMedia := func(umm db.Model, _ db.Service, tx db.Tx) error {
+		um, err := userMediaService.AssertType(umm)
+		if err != nil {
+			return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+		o, err := userMediaService.GetByID(um.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserMedia by ID %d: %w", um.Meta.ID, err)
+		}
+		if o.UserID == um.UserID && o.MediaID == um.MediaID {
+			return nil
+		}
+
+		err = episodeProgressService.DeleteByUserMedia(um.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete EpisodeProgress by UserMedia ID %d: %w",
+				um.Meta.ID, err)
+		}
+		return nil
+	}
+	umSerHooks.PreUpdateHooks =
+		append(umSerHooks.PreUpdateHooks, deleteEpisodeProgressOnUpdateUserMedia)
+
+	// Add hook to delete EpisodeProgress on EpisodeSet deletion
+	deleteEpisodeProgressOnDeleteEpisodeSet := func(setm db.Model, _ db.Service, tx db.Tx) error {
+		setID := setm.Metadata().ID
+		err := episodeProgressService.DeleteByEpisodeSet(setID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete EpisodeProgress by EpisodeSet ID %d: %w",
+				setID, err)
+		}
+		return nil
+	}
+	setSerHooks := episodeSetService.PersistHooks()
+	setSerHooks.PreDeleteHooks =
+		append(setSerHooks.PreDeleteHooks, deleteEpisodeProgressOnDeleteEpisodeSet)
+
+	return episodeProgressService
+}
+
+// Create persists the given EpisodeProgress.
+func (ser *EpisodeProgressService) Create(ep *models.EpisodeProgress, tx db.Tx) (int, error) {
+	return tx.Database().Create(ep, ser, tx)
+}
+
+// Update replaces the value of the EpisodeProgress with the given ID.
+func (ser *EpisodeProgressService) Update(ep *models.EpisodeProgress, tx db.Tx) error {
+	return tx.Database().Update(ep, ser, tx)
+}
+
+// Delete deletes the EpisodeProgress with the given ID.
+func (ser *EpisodeProgressService) Delete(id int, tx db.Tx) error {
+	return tx.Database().Delete(id, ser, tx)
+}
+
+// DeleteByUserMedia deletes the EpisodeProgress with the given UserMedia ID.
+func (ser *EpisodeProgressService) DeleteByUserMedia(umID int, tx db.Tx) error {
+	return tx.Database().DeleteFilter(ser, tx, func(m db.Model) bool {
+		ep, err := ser.AssertType(m)
+		if err != nil {
+			return false
+		}
+		return ep.UserMediaID == umID
+	})
+}
+
+// DeleteByEpisodeSet deletes the EpisodeProgress with the given EpisodeSet
+// ID.
+func (ser *EpisodeProgressService) DeleteByEpisodeSet(setID int, tx db.Tx) error {
+	return tx.Database().DeleteFilter(ser, tx, func(m db.Model) bool {
+		ep, err := ser.AssertType(m)
+		if err != nil {
+			return false
+		}
+		return ep.EpisodeSetID == setID
+	})
+}
+
+// GetAll retrieves all persisted values of EpisodeProgress.
+func (ser *EpisodeProgressService) GetAll(first *int, skip *int, tx db.Tx) ([]*models.EpisodeProgress, error) {
+	vlist, err := tx.Database().GetAll(first, skip, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to EpisodeProgress: %w", err)
+	}
+	return list, nil
+}
+
+// GetFilter retrieves all persisted values of EpisodeProgress that pass the
+// filter.
+func (ser *EpisodeProgressService) GetFilter(
+	first *int, skip *int, tx db.Tx, keep func(ep *models.EpisodeProgress) bool,
+) ([]*models.EpisodeProgress, error) {
+	vlist, err := tx.Database().GetFilter(first, skip, ser, tx,
+		func(m db.Model) bool {
+			ep, err := ser.AssertType(m)
+			if err != nil {
+				return false
+			}
+			return keep(ep)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to EpisodeProgress: %w", err)
+	}
+	return list, nil
+}
+
+// GetByID retrieves the persisted EpisodeProgress with the given ID.
+func (ser *EpisodeProgressService) GetByID(id int, tx db.Tx) (*models.EpisodeProgress, error) {
+	m, err := tx.Database().GetByID(id, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	ep, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return ep, nil
+}
+
+// GetByUserMedia retrieves the persisted EpisodeProgress with the given
+// UserMedia ID.
+func (ser *EpisodeProgressService) GetByUserMedia(
+	umID int, first *int, skip *int, tx db.Tx,
+) ([]*models.EpisodeProgress, error) {
+	return ser.GetFilter(first, skip, tx, func(ep *models.EpisodeProgress) bool {
+		return ep.UserMediaID == umID
+	})
+}
+
+// MarkWatched marks the first upTo Episodes of the EpisodeSet with the given ID
+// as watched at the given time by the User with the given ID. The UserMedia of
+// the User for the Media and its EpisodeProgress through the EpisodeSet are
+// created if necessary. Marking Episodes of a finished EpisodeSet begins a
+// rewatch of it. The UserMedia is marked as Current while the EpisodeSet is
+// being watched and as Completed once every Episode of it has been watched.
+func (ser *EpisodeProgressService) MarkWatched(uID int, mID int, setID int,
+	upTo int, at time.Time, tx db.Tx) (*models.EpisodeProgress, error) {
+	set, err := ser.EpisodeSetService.GetByID(setID, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get EpisodeSet by ID %d: %w", setID, err)
+	}
+	if set.MediaID != mID {
+		return nil, &ValidationError{"EpisodeProgress", "EpisodeSetID",
+			fmt.Errorf("not of Media %d: %w", mID, errInvalid)}
+	}
+	if upTo < 1 || upTo > len(set.Episodes) {
+		return nil, &ValidationError{"EpisodeProgress", "Episodes",
+			fmt.Errorf("%d of %d episodes: %w", upTo, len(set.Episodes), errInvalid)}
+	}
+
+	um, err := ser.getOrCreateUserMedia(uID, mID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	ep, err := ser.getOrNew(um, setID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if finished(ep, set) {
+		ep.Rewatches++
+	}
+	pass := ep.Rewatches + 1
+
+	// Indices are kept rather than pointers since appending may reallocate
+	watched := make(map[int]int, len(ep.Episodes))
+	for i, w := range ep.Episodes {
+		watched[w.EpisodeID] = i
+	}
+	for _, epID := range set.Episodes[:upTo] {
+		i, ok := watched[epID]
+		if !ok {
+			ep.Episodes = append(ep.Episodes, models.WatchedEpisode{EpisodeID: epID})
+			i = len(ep.Episodes) - 1
+			watched[epID] = i
+		}
+		w := &ep.Episodes[i]
+		if w.Count < pass {
+			w.Count = pass
+			w.WatchedAt = at
+		}
+	}
+
+	if ep.Meta.ID == 0 {
+		_, err = ser.Create(ep, tx)
+	} else {
+		err = ser.Update(ep, tx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to persist EpisodeProgress: %w", err)
+	}
+
+	// The UserMedia is being watched until the EpisodeSet is finished, with
+	// the ongoing WatchedInstance following the progress
+	err = ser.setUserMediaStatus(um, models.WatchStatusCurrent, tx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range um.WatchInstances {
+		if um.WatchInstances[i].Ongoing {
+			um.WatchInstances[i].Episodes = watchedCount(ep, set)
+		}
+	}
+	status := models.WatchStatusCurrent
+	if finished(ep, set) {
+		status = models.WatchStatusCompleted
+	}
+	um.Status = &status
+	err = ser.UserMediaService.Update(um, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update UserMedia by ID %d: %w",
+			um.Meta.ID, err)
+	}
+
+	return ep, nil
+}
+
+// setUserMediaStatus updates the given UserMedia with the given WatchStatus if
+// it does not have it already.
+func (ser *EpisodeProgressService) setUserMediaStatus(um *models.UserMedia,
+	status models.WatchStatus, tx db.Tx) error {
+	if um.Status != nil && *um.Status == status {
+		return nil
+	}
+
+	um.Status = &status
+	err := ser.UserMediaService.Update(um, tx)
+	if err != nil {
+		return fmt.Errorf("failed to update UserMedia by ID %d: %w",
+			um.Meta.ID, err)
+	}
+	return nil
+}
+
+// Watched returns the number of Episodes of the EpisodeSet of the given
+// EpisodeProgress watched in its current pass.
+func (ser *EpisodeProgressService) Watched(ep *models.EpisodeProgress,
+	tx db.Tx) (int, error) {
+	set, err := ser.EpisodeSetService.GetByID(ep.EpisodeSetID, tx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get EpisodeSet by ID %d: %w",
+			ep.EpisodeSetID, err)
+	}
+
+	return watchedCount(ep, set), nil
+}
+
+// getOrCreateUserMedia returns the UserMedia of the User with the given ID for
+// the Media with the given ID, creating it if it does not exist.
+func (ser *EpisodeProgressService) getOrCreateUserMedia(uID int, mID int,
+	tx db.Tx) (*models.UserMedia, error) {
+	first := 1
+	list, err := ser.UserMediaService.GetFilter(&first, nil, tx,
+		func(um *models.UserMedia) bool {
+			return um.UserID == uID && um.MediaID == mID
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get UserMedia: %w", err)
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	um := &models.UserMedia{UserID: uID, MediaID: mID}
+	_, err = ser.UserMediaService.Create(um, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create UserMedia: %w", err)
+	}
+	return um, nil
+}
+
+// getOrNew returns the persisted EpisodeProgress of the given UserMedia
+// through the EpisodeSet with the given ID, or a new unpersisted one if it
+// does not exist.
+func (ser *EpisodeProgressService) getOrNew(um *models.UserMedia, setID int,
+	tx db.Tx) (*models.EpisodeProgress, error) {
+	first := 1
+	list, err := ser.GetFilter(&first, nil, tx, func(ep *models.EpisodeProgress) bool {
+		return ep.UserMediaID == um.Meta.ID && ep.EpisodeSetID == setID
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get EpisodeProgress: %w", err)
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return &models.EpisodeProgress{
+		UserID:       um.UserID,
+		UserMediaID:  um.Meta.ID,
+		EpisodeSetID: setID,
+	}, nil
+}
+
+// finished returns true if every Episode of the given EpisodeSet has been
+// watched in the current pass of the EpisodeProgress.
+func finished(ep *models.EpisodeProgress, set *models.EpisodeSet) bool {
+	if len(set.Episodes) == 0 {
+		return false
+	}
+
+	counts := make(map[int]int, len(ep.Episodes))
+	for _, w := range ep.Episodes {
+		counts[w.EpisodeID] = w.Count
+	}
+	for _, epID := range set.Episodes {
+		if counts[epID] < ep.Rewatches+1 {
+			return false
+		}
+	}
+	return true
+}
+
+// watchedCount returns the number of Episodes of the given EpisodeSet watched
+// in the current pass of the EpisodeProgress.
+func watchedCount(ep *models.EpisodeProgress, set *models.EpisodeSet) int {
+	inSet := make(map[int]bool, len(set.Episodes))
+	for _, epID := range set.Episodes {
+		inSet[epID] = true
+	}
+	n := 0
+	for _, w := range ep.Episodes {
+		if inSet[w.EpisodeID] && w.Count > ep.Rewatches {
+			n++
+		}
+	}
+	return n
+}
+
+// Bucket returns the name of the bucket for EpisodeProgress.
+func (ser *EpisodeProgressService) Bucket() string {
+	return "EpisodeProgress"
+}
+
+// Clean cleans the given EpisodeProgress for storage.
+func (ser *EpisodeProgressService) Clean(m db.Model, _ db.Tx) error {
+	_, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return nil
+}
+
+// Validate returns an error if the EpisodeProgress is not valid for the
+// database.
+func (ser *EpisodeProgressService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// Check if UserMedia with ID specified in EpisodeProgress exists and
+	// belongs to the User
+	um, err := ser.UserMediaService.GetByID(e.UserMediaID, tx)
+	if err != nil {
+		return &ValidationError{"EpisodeProgress", "UserMediaID",
+			fmt.Errorf("failed to get UserMedia with ID %d: %w", e.UserMediaID, err)}
+	}
+	if um.UserID != e.UserID {
+		return &ValidationError{"EpisodeProgress", "UserID",
+			fmt.Errorf("not owner of UserMedia %d: %w", e.UserMediaID, errInvalid)}
+	}
+
+	// Check if EpisodeSet with ID specified in EpisodeProgress exists and
+	// belongs to the Media of the UserMedia
+	set, err := ser.EpisodeSetService.GetByID(e.EpisodeSetID, tx)
+	if err != nil {
+		return &ValidationError{"EpisodeProgress", "EpisodeSetID",
+			fmt.Errorf("failed to get EpisodeSet with ID %d: %w", e.EpisodeSetID, err)}
+	}
+	if set.MediaID != um.MediaID {
+		return &ValidationError{"EpisodeProgress", "EpisodeSetID",
+			fmt.Errorf("not of Media %d: %w", um.MediaID, errInvalid)}
+	}
+
+	if e.Rewatches < 0 {
+		return &ValidationError{"EpisodeProgress", "Rewatches",
+			fmt.Errorf("negative: %w", errInvalid)}
+	}
+
+	// Only one EpisodeProgress may exist for each UserMedia and EpisodeSet
+	first := 1
+	existing, err := ser.GetFilter(&first, nil, tx, func(ep *models.EpisodeProgress) bool {
+		return ep.Meta.ID != e.Meta.ID && ep.UserMediaID == e.UserMediaID &&
+			ep.EpisodeSetID == e.EpisodeSetID
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get EpisodeProgress: %w", err)
+	}
+	if len(existing) > 0 {
+		return &ValidationError{"EpisodeProgress", "EpisodeSetID", errAlreadyExists}
+	}
+
+	return nil
+}
+
+// Initialize sets initial values for some properties.
+func (ser *EpisodeProgressService) Initialize(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistOldProperties maintains certain properties of the existing
+// EpisodeProgress in updates.
+func (ser *EpisodeProgressService) PersistOldProperties(_ db.Model, _ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistHooks returns the persistence hook functions.
+func (ser *EpisodeProgressService) PersistHooks() *db.PersistHooks {
+	return &ser.Hooks
+}
+
+// Marshal transforms the given EpisodeProgress into JSON.
+func (ser *EpisodeProgressService) Marshal(m db.Model) ([]byte, error) {
+	ep, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	v, err := json.Marshal(ep)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONMarshal, err)
+	}
+
+	return v, nil
+}
+
+// Unmarshal parses the given JSON into EpisodeProgress.
+func (ser *EpisodeProgressService) Unmarshal(buf []byte) (db.Model, error) {
+	var ep models.EpisodeProgress
+	err := json.Unmarshal(buf, &ep)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONUnmarshal, err)
+	}
+	return &ep, nil
+}
+
+// AssertType exposes the given db.Model as an EpisodeProgress.
+func (ser *EpisodeProgressService) AssertType(m db.Model) (*models.EpisodeProgress, error) {
+	if m == nil {
+		return nil, fmt.Errorf("model: %w", errNil)
+	}
+
+	ep, ok := m.(*models.EpisodeProgress)
+	if !ok {
+		return nil,
+			fmt.Errorf("model: %w", errors.New("not of EpisodeProgress type"))
+	}
+	return ep, nil
+}
+
+// mapfromModel returns a list of EpisodeProgress type asserted from the given
+// list of db.Model.
+func (ser *EpisodeProgressService) mapFromModel(vlist []db.Model) ([]*models.EpisodeProgress, error) {
+	list := make([]*models.EpisodeProgress, len(vlist))
+	var err error
+	for i, v := range vlist {
+		list[i], err = ser.AssertType(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+	}
+	return list, nil
+}
@@ -1,16 +1,61 @@
 package data
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// errNil is an error returned when some pointer is nil.
 	errNil = errors.New("is nil")
+	// errEmpty is an error returned when some required string is empty.
+	errEmpty = errors.New("is empty")
 	// errInvalid is an error returned when some value is invalid.
 	errInvalid = errors.New("invalid")
 	// errAlreadyExists is an error returned when a unique value already exists.
 	errAlreadyExists = errors.New("already exists")
+	// errNotFound is an error returned when a model with some property does
+	// not exist.
+	errNotFound = errors.New("not found")
+	// errNotGranted is an error returned when a User is not assigned a Role
+	// that grants some Permission.
+	errNotGranted = errors.New("not granted by any assigned role")
 )
 
+// ValidationError is returned by the Validate methods of services when a
+// model is not valid for the database. Field is the name of the offending
+// property of the model.
+type ValidationError struct {
+	Model string
+	Field string
+	Err   error
+}
+
+func (err *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s %s: %v", err.Model, err.Field, err.Err)
+}
+
+// Unwrap returns the underlying error.
+func (err *ValidationError) Unwrap() error {
+	return err.Err
+}
+
+// Reason returns a short machine-readable description of why the model is
+// not valid, or an empty string if it is not known.
+func (err *ValidationError) Reason() string {
+	switch {
+	case errors.Is(err.Err, errAlreadyExists):
+		return "ALREADY_EXISTS"
+	case errors.Is(err.Err, errEmpty), errors.Is(err.Err, errNil):
+		return "MISSING"
+	case errors.Is(err.Err, errWeakPassword):
+		return "WEAK_PASSWORD"
+	case errors.Is(err.Err, errInvalid):
+		return "INVALID"
+	}
+	return ""
+}
+
 const (
 	errmsgModelAssertType = "failed to assert type of model"
 
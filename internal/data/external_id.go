@@ -0,0 +1,238 @@
+package data
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// externalIDIndex maintains an index bucket that maps the external IDs of the
+// models of a service to their IDs, so that models can be looked up by the
+// IDs they have in other services.
+type externalIDIndex struct {
+	// bucket is the name of the index bucket.
+	bucket string
+	// model is the name of the type of the models, used in errors.
+	model string
+	ser   db.Service
+	// ids returns the external IDs of the given model.
+	ids func(m db.Model) ([]models.ExternalID, error)
+}
+
+// newExternalIDIndex returns an externalIDIndex of the models of the given
+// service and adds the hooks that keep it in sync with them to the service.
+func newExternalIDIndex(
+	bucket string, model string, ser db.Service,
+	ids func(m db.Model) ([]models.ExternalID, error),
+) *externalIDIndex {
+	idx := &externalIDIndex{
+		bucket: bucket,
+		model:  model,
+		ser:    ser,
+		ids:    ids,
+	}
+
+	indexOnCreate := func(m db.Model, _ db.Service, tx db.Tx) error {
+		return idx.index(m, tx)
+	}
+	unindexOldOnUpdate := func(m db.Model, _ db.Service, tx db.Tx) error {
+		o, err := tx.Database().GetByID(m.Metadata().ID, idx.ser, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get %s by ID %d: %w", idx.model,
+				m.Metadata().ID, err)
+		}
+		return idx.unindex(o, tx)
+	}
+	indexOnUpdate := func(m db.Model, _ db.Service, tx db.Tx) error {
+		return idx.index(m, tx)
+	}
+	unindexOnDelete := func(m db.Model, _ db.Service, tx db.Tx) error {
+		return idx.unindex(m, tx)
+	}
+
+	hooks := ser.PersistHooks()
+	hooks.PostCreateHooks = append(hooks.PostCreateHooks, indexOnCreate)
+	hooks.PreUpdateHooks = append(hooks.PreUpdateHooks, unindexOldOnUpdate)
+	hooks.PostUpdateHooks = append(hooks.PostUpdateHooks, indexOnUpdate)
+	hooks.PreDeleteHooks = append(hooks.PreDeleteHooks, unindexOnDelete)
+
+	return idx
+}
+
+// get returns the ID of the model with the given external ID, or 0 if there
+// is none.
+func (idx *externalIDIndex) get(source string, id string, tx db.Tx) (int, error) {
+	key := externalIDKey(models.ExternalID{Source: source, ID: id})
+	if key == nil {
+		return 0, nil
+	}
+
+	mID, err := tx.Database().GetIndex(idx.bucket, key, tx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get index %q: %w", idx.bucket, err)
+	}
+	return mID, nil
+}
+
+// reindex rebuilds the index from the persisted models. If several models
+// share an external ID, the one with the lowest ID keeps it.
+func (idx *externalIDIndex) reindex(tx db.Tx) error {
+	err := tx.Database().ClearIndex(idx.bucket, tx)
+	if err != nil {
+		return fmt.Errorf("failed to clear index %q: %w", idx.bucket, err)
+	}
+
+	return tx.Database().DoEach(nil, nil, idx.ser, tx,
+		func(m db.Model, _ db.Service, tx db.Tx) (bool, error) {
+			err := idx.index(m, tx)
+			if err != nil {
+				return true, err
+			}
+			return false, nil
+		}, nil)
+}
+
+// index maps the external IDs of the given model to its ID, unless they are
+// already mapped.
+func (idx *externalIDIndex) index(m db.Model, tx db.Tx) error {
+	ids, err := idx.ids(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	for _, id := range ids {
+		key := externalIDKey(id)
+		if key == nil {
+			continue
+		}
+
+		mID, err := tx.Database().GetIndex(idx.bucket, key, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get index %q: %w", idx.bucket, err)
+		}
+		if mID != 0 {
+			continue
+		}
+
+		err = tx.Database().PutIndex(idx.bucket, key, m.Metadata().ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to put index %q: %w", idx.bucket, err)
+		}
+	}
+	return nil
+}
+
+// unindex removes the mappings of the external IDs of the given model, if
+// they are mapped to its ID.
+func (idx *externalIDIndex) unindex(m db.Model, tx db.Tx) error {
+	ids, err := idx.ids(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	for _, id := range ids {
+		key := externalIDKey(id)
+		if key == nil {
+			continue
+		}
+
+		mID, err := tx.Database().GetIndex(idx.bucket, key, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get index %q: %w", idx.bucket, err)
+		}
+		if mID != m.Metadata().ID {
+			continue
+		}
+
+		err = tx.Database().DeleteIndex(idx.bucket, key, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete index %q: %w", idx.bucket, err)
+		}
+	}
+	return nil
+}
+
+// validate checks that the given external IDs of the model with the given ID
+// have sources and IDs, that their URLs are absolute HTTP URLs, and that none
+// of them is given twice or identifies another model.
+func (idx *externalIDIndex) validate(
+	ids []models.ExternalID, modelID int, tx db.Tx,
+) error {
+	seen := map[string]bool{}
+	for i, id := range ids {
+		if strings.TrimSpace(id.Source) == "" {
+			return &ValidationError{idx.model, "ExternalIDs",
+				fmt.Errorf("external ID %d source: %w", i, errEmpty)}
+		}
+		if strings.TrimSpace(id.ID) == "" {
+			return &ValidationError{idx.model, "ExternalIDs",
+				fmt.Errorf("external ID %d ID: %w", i, errEmpty)}
+		}
+		if id.URL != nil && strings.TrimSpace(*id.URL) != "" &&
+			!isHTTPURL(strings.TrimSpace(*id.URL)) {
+			return &ValidationError{idx.model, "ExternalIDs",
+				fmt.Errorf("external ID %d URL %q: %w", i, *id.URL, errInvalid)}
+		}
+
+		key := externalIDKey(id)
+		if seen[string(key)] {
+			return &ValidationError{idx.model, "ExternalIDs",
+				fmt.Errorf("external ID %s %q: %w", id.Source, id.ID,
+					errAlreadyExists)}
+		}
+		seen[string(key)] = true
+
+		mID, err := tx.Database().GetIndex(idx.bucket, key, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get index %q: %w", idx.bucket, err)
+		}
+		if mID != 0 && mID != modelID {
+			return &ValidationError{idx.model, "ExternalIDs",
+				fmt.Errorf("external ID %s %q: %w", id.Source, id.ID,
+					errAlreadyExists)}
+		}
+	}
+	return nil
+}
+
+// cleanExternalIDs trims the given external IDs, lower-cases their sources,
+// and removes empty URLs.
+func cleanExternalIDs(ids []models.ExternalID) {
+	for i := range ids {
+		id := &ids[i]
+		id.Source = strings.ToLower(strings.TrimSpace(id.Source))
+		id.ID = strings.TrimSpace(id.ID)
+		if id.URL != nil {
+			u := strings.TrimSpace(*id.URL)
+			if u == "" {
+				id.URL = nil
+			} else {
+				id.URL = &u
+			}
+		}
+	}
+}
+
+// externalIDKey returns the key of the given external ID in index buckets,
+// or nil if it has no source or ID. Sources are matched case-insensitively and
+// IDs exactly.
+func externalIDKey(id models.ExternalID) []byte {
+	source := normalizeIdentifier(id.Source)
+	value := strings.TrimSpace(id.ID)
+	if len(source) == 0 || value == "" {
+		return nil
+	}
+	return []byte(string(source) + "\x00" + value)
+}
+
+// isHTTPURL returns true if the given string is an absolute HTTP or HTTPS URL.
+func isHTTPURL(v string) bool {
+	u, err := url.Parse(v)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
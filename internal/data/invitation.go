@@ -0,0 +1,334 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/jwt"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+// InvitationPrefix is the prefix of all invitation codes.
+const InvitationPrefix = "inv_"
+
+// InvitationService performs operations on Invitation.
+type InvitationService struct {
+	UserService *UserService
+	Hooks       db.PersistHooks
+}
+
+// NewInvitationService returns an InvitationService.
+func NewInvitationService(hooks db.PersistHooks,
+	userService *UserService) *InvitationService {
+	invitationService := &InvitationService{
+		UserService: userService,
+		Hooks:       hooks,
+	}
+
+	// Add hook to delete Invitations created by a User on User deletion
+	deleteInvitationOnDeleteUser := func(um db.Model, _ db.Service, tx db.Tx) error {
+		uID := um.Metadata().ID
+		err := invitationService.DeleteByUser(uID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Invitation by User ID %d: %w",
+				uID, err)
+		}
+		return nil
+	}
+	uSerHooks := userService.PersistHooks()
+	uSerHooks.PreDeleteHooks =
+		append(uSerHooks.PreDeleteHooks, deleteInvitationOnDeleteUser)
+
+	return invitationService
+}
+
+// Create persists the given Invitation.
+func (ser *InvitationService) Create(inv *models.Invitation, tx db.Tx) (int, error) {
+	return tx.Database().Create(inv, ser, tx)
+}
+
+// Update replaces the value of the Invitation with the given ID.
+func (ser *InvitationService) Update(inv *models.Invitation, tx db.Tx) error {
+	return tx.Database().Update(inv, ser, tx)
+}
+
+// Delete deletes the Invitation with the given ID.
+func (ser *InvitationService) Delete(id int, tx db.Tx) error {
+	return tx.Database().Delete(id, ser, tx)
+}
+
+// DeleteByUser deletes the Invitations created by the User with the given ID.
+func (ser *InvitationService) DeleteByUser(uID int, tx db.Tx) error {
+	return tx.Database().DeleteFilter(ser, tx, func(m db.Model) bool {
+		inv, err := ser.AssertType(m)
+		if err != nil {
+			return false
+		}
+		return inv.CreatedBy == uID
+	})
+}
+
+// Issue creates a new Invitation by the User with the given ID that expires
+// at the given time, or never if nil. The Invitation and its invitation code
+// are returned; the code cannot be retrieved again.
+func (ser *InvitationService) Issue(createdBy int, expiresAt *time.Time,
+	tx db.Tx) (*models.Invitation, string, error) {
+	code, err := jwt.NewOpaqueToken(InvitationPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	inv := models.Invitation{
+		CreatedBy: createdBy,
+		TokenHash: jwt.HashOpaqueToken(code),
+		ExpiresAt: expiresAt,
+	}
+	_, err = ser.Create(&inv, tx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create Invitation: %w", err)
+	}
+
+	return &inv, code, nil
+}
+
+// Redeem marks the active Invitation that the given invitation code belongs to
+// as used by the User with the given ID and returns it. The code may not be
+// used again.
+func (ser *InvitationService) Redeem(code string, uID int,
+	tx db.Tx) (*models.Invitation, error) {
+	inv, err := ser.GetByToken(code, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ser.IsActive(inv) {
+		return nil, fmt.Errorf("Invitation %d: %w", inv.Meta.ID,
+			errors.New("is used or expired"))
+	}
+
+	inv.UsedBy = &uID
+	err = ser.Update(inv, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update Invitation by id %d: %w",
+			inv.Meta.ID, err)
+	}
+	return inv, nil
+}
+
+// IsActive returns true if the given Invitation has not been used and has
+// not expired.
+func (ser *InvitationService) IsActive(inv *models.Invitation) bool {
+	return inv.UsedBy == nil &&
+		(inv.ExpiresAt == nil || time.Now().Before(*inv.ExpiresAt))
+}
+
+// GetAll retrieves all persisted values of Invitation.
+func (ser *InvitationService) GetAll(first *int, skip *int, tx db.Tx) ([]*models.Invitation, error) {
+	vlist, err := tx.Database().GetAll(first, skip, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to Invitations: %w", err)
+	}
+	return list, nil
+}
+
+// GetFilter retrieves all persisted values of Invitation that pass the
+// filter.
+func (ser *InvitationService) GetFilter(
+	first *int, skip *int, tx db.Tx, keep func(inv *models.Invitation) bool,
+) ([]*models.Invitation, error) {
+	vlist, err := tx.Database().GetFilter(first, skip, ser, tx,
+		func(m db.Model) bool {
+			inv, err := ser.AssertType(m)
+			if err != nil {
+				return false
+			}
+			return keep(inv)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to Invitations: %w", err)
+	}
+	return list, nil
+}
+
+// GetByID retrieves the persisted Invitation with the given ID.
+func (ser *InvitationService) GetByID(id int, tx db.Tx) (*models.Invitation, error) {
+	m, err := tx.Database().GetByID(id, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return inv, nil
+}
+
+// GetByToken retrieves the persisted Invitation with the given invitation
+// code.
+func (ser *InvitationService) GetByToken(tkn string, tx db.Tx) (*models.Invitation, error) {
+	hash := jwt.HashOpaqueToken(tkn)
+
+	var inv *models.Invitation
+	_, err := tx.Database().FindFirst(ser, tx, func(m db.Model) (bool, error) {
+		e, err := ser.AssertType(m)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+
+		if e.TokenHash == hash {
+			inv = e
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate through keys: %w", err)
+	}
+
+	if inv == nil {
+		return nil, fmt.Errorf("invitation code: %w", errInvalid)
+	}
+	return inv, nil
+}
+
+// Bucket returns the name of the bucket for Invitation.
+func (ser *InvitationService) Bucket() string {
+	return "Invitation"
+}
+
+// Clean cleans the given Invitation for storage.
+func (ser *InvitationService) Clean(m db.Model, _ db.Tx) error {
+	_, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return nil
+}
+
+// Validate returns an error if the Invitation is not valid for the database.
+func (ser *InvitationService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// Check if User with ID specified in Invitation exists
+	_, err = tx.Database().GetRawByID(e.CreatedBy, ser.UserService, tx)
+	if err != nil {
+		return &ValidationError{"Invitation", "CreatedBy",
+			fmt.Errorf("failed to get User with ID %d: %w", e.CreatedBy, err)}
+	}
+
+	if e.UsedBy != nil {
+		_, err = tx.Database().GetRawByID(*e.UsedBy, ser.UserService, tx)
+		if err != nil {
+			return &ValidationError{"Invitation", "UsedBy",
+				fmt.Errorf("failed to get User with ID %d: %w", *e.UsedBy, err)}
+		}
+	}
+
+	if e.TokenHash == "" {
+		return &ValidationError{"Invitation", "TokenHash",
+			fmt.Errorf("invitation code hash: %w", errNil)}
+	}
+
+	return nil
+}
+
+// Initialize sets initial values for some properties.
+func (ser *InvitationService) Initialize(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistOldProperties maintains certain properties of the existing
+// Invitation in updates.
+func (ser *InvitationService) PersistOldProperties(n db.Model, o db.Model, _ db.Tx) error {
+	ninv, err := ser.AssertType(n)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	oinv, err := ser.AssertType(o)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// Invitations may not change creator or code, or be used again
+	ninv.CreatedBy = oinv.CreatedBy
+	ninv.TokenHash = oinv.TokenHash
+	if oinv.UsedBy != nil {
+		ninv.UsedBy = oinv.UsedBy
+	}
+	return nil
+}
+
+// PersistHooks returns the persistence hook functions.
+func (ser *InvitationService) PersistHooks() *db.PersistHooks {
+	return &ser.Hooks
+}
+
+// Marshal transforms the given Invitation into JSON.
+func (ser *InvitationService) Marshal(m db.Model) ([]byte, error) {
+	inv, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	v, err := json.Marshal(inv)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONMarshal, err)
+	}
+
+	return v, nil
+}
+
+// Unmarshal parses the given JSON into Invitation.
+func (ser *InvitationService) Unmarshal(buf []byte) (db.Model, error) {
+	var inv models.Invitation
+	err := json.Unmarshal(buf, &inv)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONUnmarshal, err)
+	}
+	return &inv, nil
+}
+
+// AssertType exposes the given db.Model as an Invitation.
+func (ser *InvitationService) AssertType(m db.Model) (*models.Invitation, error) {
+	if m == nil {
+		return nil, fmt.Errorf("model: %w", errNil)
+	}
+
+	inv, ok := m.(*models.Invitation)
+	if !ok {
+		return nil,
+			fmt.Errorf("model: %w", errors.New("not of Invitation type"))
+	}
+	return inv, nil
+}
+
+// mapfromModel returns a list of Invitation type asserted from the given
+// list of db.Model.
+func (ser *InvitationService) mapFromModel(vlist []db.Model) ([]*models.Invitation, error) {
+	list := make([]*models.Invitation, len(vlist))
+	var err error
+	for i, v := range vlist {
+		list[i], err = ser.AssertType(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+	}
+	return list, nil
+}
@@ -1,9 +1,9 @@
 package data
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/Dophin2009/nao/pkg/models"
 	"github.com/Dophin2009/nao/pkg/db"
@@ -12,16 +12,43 @@ import (
 
 // TODO: Fuzzy search of models
 
+// legacyMedia is the representation of Media before Types and Sources were
+// enumerated, when they were free-form strings, read to migrate persisted
+// Media.
+type legacyMedia struct {
+	models.Media
+	Type   *string
+	Source *string
+}
+
 // MediaService performs operations on Media.
 type MediaService struct {
 	Hooks db.PersistHooks
+	// externalIDs is the index of the external IDs of Media.
+	externalIDs *externalIDIndex
+	// seasons is the index of the Seasons Media premiered in.
+	seasons *seasonIndex
 }
 
+// mediaExternalIDIndex is the name of the index bucket that maps external
+// IDs to Media IDs.
+const mediaExternalIDIndex = "MediaExternalID"
+
 // NewMediaService returns a MediaService.
 func NewMediaService(hooks db.PersistHooks) *MediaService {
-	return &MediaService{
+	ser := &MediaService{
 		Hooks: hooks,
 	}
+	ser.externalIDs = newExternalIDIndex(mediaExternalIDIndex, "Media", ser,
+		func(m db.Model) ([]models.ExternalID, error) {
+			md, err := ser.AssertType(m)
+			if err != nil {
+				return nil, err
+			}
+			return md.ExternalIDs, nil
+		})
+	ser.seasons = newSeasonIndex(mediaSeasonIndex, ser)
+	return ser
 }
 
 // Create persists the given Media.
@@ -113,6 +140,67 @@ func (ser *MediaService) GetByID(id int, tx db.Tx) (*models.Media, error) {
 	return md, nil
 }
 
+// GetByExternalID retrieves the persisted Media with the given external ID.
+// Sources are matched case-insensitively and IDs exactly.
+func (ser *MediaService) GetByExternalID(
+	source string, id string, tx db.Tx,
+) (*models.Media, error) {
+	mID, err := ser.GetIDByExternalID(source, id, tx)
+	if err != nil {
+		return nil, err
+	}
+	if mID == 0 {
+		return nil, fmt.Errorf("Media with external ID %s %q: %w", source, id,
+			errNotFound)
+	}
+	return ser.GetByID(mID, tx)
+}
+
+// GetIDByExternalID returns the ID of the persisted Media with the given
+// external ID, or 0 if there is none.
+func (ser *MediaService) GetIDByExternalID(
+	source string, id string, tx db.Tx,
+) (int, error) {
+	return ser.externalIDs.get(source, id, tx)
+}
+
+// GetBySeason retrieves the persisted Media that premiered in the given
+// Season, in order of ID. Media without a complete SeasonPremiered premiered
+// in the Season of their StartDate.
+func (ser *MediaService) GetBySeason(
+	s models.Season, tx db.Tx,
+) ([]*models.Media, error) {
+	ids, err := ser.seasons.get(s, tx)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*models.Media{}, nil
+	}
+	return ser.GetMultiple(ids, tx, func(_ *models.Media) bool { return true })
+}
+
+// CountBySeason returns the number of persisted Media that premiered in each
+// Season that any did, in order of Season.
+func (ser *MediaService) CountBySeason(tx db.Tx) ([]*models.SeasonCount, error) {
+	return ser.seasons.count(tx)
+}
+
+// Reindex rebuilds the external ID and Season indexes from the persisted
+// Media.
+func (ser *MediaService) Reindex(tx db.Tx) error {
+	err := ser.externalIDs.reindex(tx)
+	if err != nil {
+		return err
+	}
+	return ser.seasons.reindex(tx)
+}
+
+// IndexBuckets returns the names of the index buckets for Media.
+func (ser *MediaService) IndexBuckets() []string {
+	return []string{mediaExternalIDIndex, mediaSeasonIndex}
+}
+
 // Bucket returns the name of the bucket for Media.
 func (ser *MediaService) Bucket() string {
 	return "Media"
@@ -125,12 +213,7 @@ func (ser *MediaService) Clean(m db.Model, _ db.Tx) error {
 		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
 	}
 
-	if e.Type != nil {
-		*e.Type = strings.Trim(*e.Type, " ")
-	}
-	if e.Source != nil {
-		*e.Source = strings.Trim(*e.Source, " ")
-	}
+	cleanExternalIDs(e.ExternalIDs)
 
 	if e.SeasonPremiered.Quarter != nil && *e.SeasonPremiered.Quarter > 4 {
 		*e.SeasonPremiered.Quarter = 0
@@ -139,12 +222,22 @@ func (ser *MediaService) Clean(m db.Model, _ db.Tx) error {
 }
 
 // Validate checks if the given Media is valid.
-func (ser *MediaService) Validate(m db.Model, _ db.Tx) error {
-	_, err := ser.AssertType(m)
+func (ser *MediaService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
 	if err != nil {
 		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
 	}
-	return nil
+
+	if e.Type != nil && !e.Type.IsValid() {
+		return &ValidationError{"Media", "Type",
+			fmt.Errorf("type %d: %w", int(*e.Type), errInvalid)}
+	}
+	if e.Source != nil && !e.Source.IsValid() {
+		return &ValidationError{"Media", "Source",
+			fmt.Errorf("source %d: %w", int(*e.Source), errInvalid)}
+	}
+
+	return ser.externalIDs.validate(e.ExternalIDs, e.Meta.ID, tx)
 }
 
 // Initialize sets initial values for some properties.
@@ -154,7 +247,20 @@ func (ser *MediaService) Initialize(_ db.Model, _ db.Tx) error {
 
 // PersistOldProperties maintains certain properties of the existing Media in
 // updates.
-func (ser *MediaService) PersistOldProperties(_ db.Model, _ db.Model, _ db.Tx) error {
+func (ser *MediaService) PersistOldProperties(n db.Model, o db.Model, _ db.Tx) error {
+	e, err := ser.AssertType(n)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	oe, err := ser.AssertType(o)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// ExternalIDs are not changed if not given
+	if e.ExternalIDs == nil {
+		e.ExternalIDs = oe.ExternalIDs
+	}
 	return nil
 }
 
@@ -180,12 +286,91 @@ func (ser *MediaService) Marshal(m db.Model) ([]byte, error) {
 
 // Unmarshal parses the given JSON into Media.
 func (ser *MediaService) Unmarshal(buf []byte) (db.Model, error) {
-	var md models.Media
-	err := json.Unmarshal(buf, &md)
+	var lmd legacyMedia
+	err := json.Unmarshal(buf, &lmd)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", errmsgJSONUnmarshal, err)
 	}
-	return &md, nil
+	md, _ := lmd.migrate()
+	return md, nil
+}
+
+// MigrateLegacy rewrites the persisted Media that are not in their current
+// representation, so that free-form Types and Sources are migrated once
+// rather than on every read. Values that name no Type or Source are dropped
+// and passed to the given function with the ID of their Media. Returns the
+// number of Media rewritten.
+func (ser *MediaService) MigrateLegacy(
+	tx db.Tx, dropped func(id int, field string, value string),
+) (int, error) {
+	list, err := ser.GetAll(nil, nil, tx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get Media: %w", err)
+	}
+
+	n := 0
+	for _, e := range list {
+		id := e.Meta.ID
+		raw, err := tx.Database().GetRawByID(id, ser, tx)
+		if err != nil {
+			return n, fmt.Errorf("failed to get Media by ID %d: %w", id, err)
+		}
+		var lmd legacyMedia
+		err = json.Unmarshal(raw, &lmd)
+		if err != nil {
+			return n, fmt.Errorf("%s: %w", errmsgJSONUnmarshal, err)
+		}
+
+		md, drops := lmd.migrate()
+		buf, err := ser.Marshal(md)
+		if err != nil {
+			return n, err
+		}
+		if bytes.Equal(buf, raw) {
+			continue
+		}
+		for _, d := range drops {
+			dropped(id, d.field, d.value)
+		}
+
+		// The Media is only changed in representation, so it is written
+		// without the validation and hooks of updates
+		err = tx.Database().DatabaseDriver.Update(md, ser, tx)
+		if err != nil {
+			return n, fmt.Errorf("failed to update Media by ID %d: %w", id, err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// droppedValue is a value of a field of a persisted model that could not be
+// migrated.
+type droppedValue struct {
+	field string
+	value string
+}
+
+// migrate returns the Media with free-form Types and Sources migrated to the
+// values they name, and the values dropped because they name none.
+func (lmd *legacyMedia) migrate() (*models.Media, []droppedValue) {
+	md := lmd.Media
+	var dropped []droppedValue
+	if lmd.Type != nil {
+		if t, ok := models.NormalizeMediaType(*lmd.Type); ok {
+			md.Type = &t
+		} else {
+			dropped = append(dropped, droppedValue{"Type", *lmd.Type})
+		}
+	}
+	if lmd.Source != nil {
+		if s, ok := models.NormalizeMediaSource(*lmd.Source); ok {
+			md.Source = &s
+		} else {
+			dropped = append(dropped, droppedValue{"Source", *lmd.Source})
+		}
+	}
+	return &md, dropped
 }
 
 // AssertType exposes the given db.Model as a Media.
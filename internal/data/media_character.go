@@ -272,14 +272,15 @@ func (ser *MediaCharacterService) Validate(m db.Model, tx db.Tx) error {
 	// Check if Media with ID specified in MediaCharacter exists
 	_, err = db.GetRawByID(e.MediaID, ser.MediaService, tx)
 	if err != nil {
-		return fmt.Errorf("failed to get Media with ID %d: %w", e.MediaID, err)
+		return &ValidationError{"MediaCharacter", "MediaID",
+			fmt.Errorf("failed to get Media with ID %d: %w", e.MediaID, err)}
 	}
 
 	// Invalid if both Character and Person are not specified
 	if e.CharacterID == nil && e.PersonID == nil {
 		nsterr := fmt.Errorf("character ID and person ID: %w", errNil)
-		return fmt.Errorf(
-			"either character ID or person ID must be specified: %w", nsterr)
+		return &ValidationError{"MediaCharacter", "CharacterID", fmt.Errorf(
+			"either character ID or person ID must be specified: %w", nsterr)}
 	}
 
 	// Check if Character with ID specified in new MediaCharacter exists
@@ -288,25 +289,26 @@ func (ser *MediaCharacterService) Validate(m db.Model, tx db.Tx) error {
 		// CharacterRole must be present if CharacterID is specified
 		if e.CharacterRole == nil {
 			nsterr := fmt.Errorf("character role: %w", errNil)
-			return fmt.Errorf(
+			return &ValidationError{"MediaCharacter", "CharacterRole", fmt.Errorf(
 				"character role must not be nil if character ID is specified: %w",
 				nsterr,
-			)
+			)}
 		}
 
 		cID := *e.CharacterID
 		_, err = db.GetRawByID(cID, ser.CharacterService, tx)
 		if err != nil {
-			return fmt.Errorf("failed to get Character with ID %d: %w", cID, err)
+			return &ValidationError{"MediaCharacter", "CharacterID",
+				fmt.Errorf("failed to get Character with ID %d: %w", cID, err)}
 		}
 	} else {
 		// CharacterRole must not be specified if CharacterID is not
 		if e.CharacterRole != nil {
 			nsterr := fmt.Errorf("character ID: %w", errNil)
-			return fmt.Errorf(
+			return &ValidationError{"MediaCharacter", "CharacterRole", fmt.Errorf(
 				"character role must be nil if character ID is not specified: %w",
 				nsterr,
-			)
+			)}
 		}
 	}
 
@@ -316,21 +318,22 @@ func (ser *MediaCharacterService) Validate(m db.Model, tx db.Tx) error {
 		// PersonRole must be present if PersonID is specified
 		if e.PersonRole == nil {
 			nsterr := fmt.Errorf("person role: %w", errNil)
-			return fmt.Errorf(
-				"person role must not be nil if person ID is specified: %w", nsterr)
+			return &ValidationError{"MediaCharacter", "PersonRole", fmt.Errorf(
+				"person role must not be nil if person ID is specified: %w", nsterr)}
 		}
 
 		pID := *e.PersonID
 		_, err = db.GetRawByID(pID, ser.PersonService, tx)
 		if err != nil {
-			return fmt.Errorf("failed to get Person with ID %d: %w", pID, err)
+			return &ValidationError{"MediaCharacter", "PersonID",
+				fmt.Errorf("failed to get Person with ID %d: %w", pID, err)}
 		}
 	} else {
 		// PersonRole must not be specified if PersonID is not
 		if e.PersonRole != nil {
 			nsterr := fmt.Errorf("person ID: %w", errNil)
-			return fmt.Errorf(
-				"person role must be nil if person ID is not specified: %w", nsterr)
+			return &ValidationError{"MediaCharacter", "PersonRole", fmt.Errorf(
+				"person role must be nil if person ID is not specified: %w", nsterr)}
 		}
 	}
 
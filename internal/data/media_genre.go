@@ -211,13 +211,15 @@ func (ser *MediaGenreService) Validate(m db.Model, tx db.Tx) error {
 	// Check if Media with ID specified in new MediaGenre exists
 	_, err = db.GetRawByID(e.MediaID, ser.MediaService, tx)
 	if err != nil {
-		return fmt.Errorf("failed to get Media with ID %d: %w", e.MediaID, err)
+		return &ValidationError{"MediaGenre", "MediaID",
+			fmt.Errorf("failed to get Media with ID %d: %w", e.MediaID, err)}
 	}
 
 	// Check if Genre with ID specified in new MediaGenre exists
 	_, err = db.GetRawByID(e.GenreID, ser.GenreService, tx)
 	if err != nil {
-		return fmt.Errorf("failed to get Genre with ID %d: %w", e.GenreID, err)
+		return &ValidationError{"MediaGenre", "GenreID",
+			fmt.Errorf("failed to get Genre with ID %d: %w", e.GenreID, err)}
 	}
 
 	return nil
@@ -221,15 +221,17 @@ func (ser *MediaProducerService) Validate(m db.Model, tx db.Tx) error {
 	db := tx.Database()
 
 	// Check if Media with ID specified in new MediaProducer exists
-	_, err = db.GetRawByID(e.MediaID, ser, tx)
+	_, err = db.GetRawByID(e.MediaID, ser.MediaService, tx)
 	if err != nil {
-		return fmt.Errorf("failed to get Media with ID %d: %w", e.MediaID, err)
+		return &ValidationError{"MediaProducer", "MediaID",
+			fmt.Errorf("failed to get Media with ID %d: %w", e.MediaID, err)}
 	}
 
 	// Check if Producer with ID specified in new MediaProducer exists
-	_, err = db.GetRawByID(e.ProducerID, ser, tx)
+	_, err = db.GetRawByID(e.ProducerID, ser.ProducerService, tx)
 	if err != nil {
-		return fmt.Errorf("failed to get Producer with ID %d: %w", e.ProducerID, err)
+		return &ValidationError{"MediaProducer", "ProducerID",
+			fmt.Errorf("failed to get Producer with ID %d: %w", e.ProducerID, err)}
 	}
 
 	return nil
@@ -215,13 +215,15 @@ func (ser *MediaRelationService) Validate(m db.Model, tx db.Tx) error {
 	// Check if owning Media with ID specified in new MediaRelation exists
 	_, err = db.GetRawByID(e.OwnerID, ser.MediaService, tx)
 	if err != nil {
-		return fmt.Errorf("failed to get Media with ID %d: %w", e.OwnerID, err)
+		return &ValidationError{"MediaRelation", "OwnerID",
+			fmt.Errorf("failed to get Media with ID %d: %w", e.OwnerID, err)}
 	}
 
 	// Check if related Media with ID specified in new MediaRelation exists
 	_, err = db.GetRawByID(e.RelatedID, ser.MediaService, tx)
 	if err != nil {
-		return fmt.Errorf("failed to get Media with ID %d: %w", e.RelatedID, err)
+		return &ValidationError{"MediaRelation", "RelatedID",
+			fmt.Errorf("failed to get Media with ID %d: %w", e.RelatedID, err)}
 	}
 
 	return nil
@@ -0,0 +1,100 @@
+package data
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+// TestMediaMigrateLegacy tests that Media persisted with free-form Types and
+// Sources are rewritten with the values they name, and that values naming
+// none are reported.
+func TestMediaMigrateLegacy(t *testing.T) {
+	ser := NewMediaService(db.PersistHooks{})
+	database, cleanup := newTestDatabase(t, ser)
+	defer cleanup()
+
+	legacy := map[int]string{
+		101: `{"Type":"TV Series","Source":"light novel","Meta":{"ID":101}}`,
+		102: `{"Type":"Pachinko","Source":"Original","Meta":{"ID":102}}`,
+	}
+	err := database.Transaction(true, func(tx db.Tx) error {
+		b := tx.Unwrap().(*bolt.Tx).Bucket([]byte(ser.Bucket()))
+		for id, v := range legacy {
+			key := make([]byte, 8)
+			binary.BigEndian.PutUint64(key, uint64(id))
+			err := b.Put(key, []byte(v))
+			if err != nil {
+				return err
+			}
+		}
+		_, err := ser.Create(&models.Media{}, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to persist legacy Media: %v", err)
+	}
+
+	type drop struct {
+		id           int
+		field, value string
+	}
+	var drops []drop
+	var n int
+	err = database.Transaction(true, func(tx db.Tx) error {
+		var err error
+		n, err = ser.MigrateLegacy(tx, func(id int, field string, value string) {
+			drops = append(drops, drop{id, field, value})
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to migrate Media: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 Media to be migrated, got %d", n)
+	}
+	expected := []drop{{102, "Type", "Pachinko"}}
+	if !reflect.DeepEqual(drops, expected) {
+		t.Errorf("expected dropped values %v, got %v", expected, drops)
+	}
+
+	err = database.Transaction(true, func(tx db.Tx) error {
+		raw, err := tx.Database().GetRawByID(101, ser, tx)
+		if err != nil {
+			return err
+		}
+		md, err := ser.GetByID(101, tx)
+		if err != nil {
+			return err
+		}
+		buf, err := ser.Marshal(md)
+		if err != nil {
+			return err
+		}
+		if string(raw) != string(buf) {
+			t.Errorf("expected Media 101 to be rewritten as %s, got %s", buf, raw)
+		}
+		if md.Type == nil || *md.Type != models.MediaTypeTV ||
+			md.Source == nil || *md.Source != models.MediaSourceLightNovel {
+			t.Errorf("expected TV adapted from a light novel, got %v %v",
+				md.Type, md.Source)
+		}
+
+		n, err = ser.MigrateLegacy(tx, func(int, string, string) {})
+		if err != nil {
+			return err
+		}
+		if n != 0 {
+			t.Errorf("expected no Media to be migrated again, got %d", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to check migrated Media: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// errWeakPassword is an error returned when a password does not meet the
+// password policy.
+var errWeakPassword = errors.New("does not meet password policy")
+
+// PasswordPolicy describes the requirements new passwords must meet.
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters.
+	MinLength int
+	// MaxLength is the maximum number of bytes; passwords are truncated by
+	// bcrypt past 72 bytes. There is no maximum if 0.
+	MaxLength int
+	// RequireUpper requires at least one uppercase letter.
+	RequireUpper bool
+	// RequireLower requires at least one lowercase letter.
+	RequireLower bool
+	// RequireDigit requires at least one digit.
+	RequireDigit bool
+	// RequireSymbol requires at least one character that is not a letter or
+	// digit.
+	RequireSymbol bool
+	// ForbidUsername forbids passwords that contain the username.
+	ForbidUsername bool
+}
+
+// DefaultPasswordPolicy is the PasswordPolicy used if none is configured.
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:      8,
+	MaxLength:      72,
+	ForbidUsername: true,
+}
+
+// Check returns an error describing the first requirement the given password
+// for the User with the given username does not meet.
+func (p *PasswordPolicy) Check(password string, username string) error {
+	if n := utf8.RuneCountInString(password); n < p.MinLength {
+		return fmt.Errorf("must be at least %d characters: %w",
+			p.MinLength, errWeakPassword)
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		return fmt.Errorf("must be at most %d bytes: %w",
+			p.MaxLength, errWeakPassword)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r):
+			symbol = true
+		}
+	}
+	switch {
+	case p.RequireUpper && !upper:
+		return fmt.Errorf("must contain an uppercase letter: %w", errWeakPassword)
+	case p.RequireLower && !lower:
+		return fmt.Errorf("must contain a lowercase letter: %w", errWeakPassword)
+	case p.RequireDigit && !digit:
+		return fmt.Errorf("must contain a digit: %w", errWeakPassword)
+	case p.RequireSymbol && !symbol:
+		return fmt.Errorf("must contain a symbol: %w", errWeakPassword)
+	}
+
+	if p.ForbidUsername && username != "" &&
+		strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return fmt.Errorf("must not contain the username: %w", errWeakPassword)
+	}
+	return nil
+}
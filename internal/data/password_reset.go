@@ -0,0 +1,366 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/jwt"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+// PasswordResetService performs operations on PasswordReset.
+type PasswordResetService struct {
+	UserService *UserService
+	Hooks       db.PersistHooks
+}
+
+// NewPasswordResetService returns a PasswordResetService.
+func NewPasswordResetService(hooks db.PersistHooks,
+	userService *UserService) *PasswordResetService {
+	passwordResetService := &PasswordResetService{
+		UserService: userService,
+		Hooks:       hooks,
+	}
+
+	// Add hook to delete PasswordReset on User deletion
+	deletePasswordResetOnDeleteUser := func(um db.Model, _ db.Service, tx db.Tx) error {
+		uID := um.Metadata().ID
+		err := passwordResetService.DeleteByUser(uID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete PasswordReset by User ID %d: %w",
+				uID, err)
+		}
+		return nil
+	}
+
+	// Add hook to invalidate pending PasswordResets of a User on password
+	// change
+	expirePasswordResetOnChangePassword := func(um db.Model, _ db.Service, tx db.Tx) error {
+		uw, ok := um.(*userWrap)
+		if !ok || !uw.updatedPass {
+			return nil
+		}
+
+		uID := um.Metadata().ID
+		err := passwordResetService.ExpireByUser(uID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to expire PasswordReset by User ID %d: %w",
+				uID, err)
+		}
+		return nil
+	}
+	uSerHooks := userService.PersistHooks()
+	uSerHooks.PreDeleteHooks =
+		append(uSerHooks.PreDeleteHooks, deletePasswordResetOnDeleteUser)
+	uSerHooks.PostUpdateHooks =
+		append(uSerHooks.PostUpdateHooks, expirePasswordResetOnChangePassword)
+
+	return passwordResetService
+}
+
+// Create persists the given PasswordReset.
+func (ser *PasswordResetService) Create(pr *models.PasswordReset, tx db.Tx) (int, error) {
+	return tx.Database().Create(pr, ser, tx)
+}
+
+// Update replaces the value of the PasswordReset with the given ID.
+func (ser *PasswordResetService) Update(pr *models.PasswordReset, tx db.Tx) error {
+	return tx.Database().Update(pr, ser, tx)
+}
+
+// Delete deletes the PasswordReset with the given ID.
+func (ser *PasswordResetService) Delete(id int, tx db.Tx) error {
+	return tx.Database().Delete(id, ser, tx)
+}
+
+// DeleteByUser deletes the PasswordResets with the given User ID.
+func (ser *PasswordResetService) DeleteByUser(uID int, tx db.Tx) error {
+	return tx.Database().DeleteFilter(ser, tx, func(m db.Model) bool {
+		pr, err := ser.AssertType(m)
+		if err != nil {
+			return false
+		}
+		return pr.UserID == uID
+	})
+}
+
+// Request creates a new PasswordReset for the User with the given ID that
+// expires after the given duration. Pending PasswordResets of the User are
+// invalidated. The PasswordReset and its reset token are returned.
+func (ser *PasswordResetService) Request(uID int, duration time.Duration,
+	tx db.Tx) (*models.PasswordReset, string, error) {
+	err := ser.ExpireByUser(uID, tx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tkn, err := jwt.NewOpaqueToken("")
+	if err != nil {
+		return nil, "", err
+	}
+
+	pr := models.PasswordReset{
+		UserID:    uID,
+		TokenHash: jwt.HashOpaqueToken(tkn),
+		ExpiresAt: time.Now().Add(duration),
+	}
+	_, err = ser.Create(&pr, tx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create PasswordReset: %w", err)
+	}
+
+	return &pr, tkn, nil
+}
+
+// Consume marks the active PasswordReset that the given reset token belongs
+// to as used and returns it. The token may not be used again.
+func (ser *PasswordResetService) Consume(tkn string, tx db.Tx) (*models.PasswordReset, error) {
+	pr, err := ser.GetByToken(tkn, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ser.IsActive(pr) {
+		return nil, fmt.Errorf("PasswordReset %d: %w", pr.Meta.ID,
+			errors.New("is used or expired"))
+	}
+
+	pr.Used = true
+	err = ser.Update(pr, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update PasswordReset by id %d: %w",
+			pr.Meta.ID, err)
+	}
+	return pr, nil
+}
+
+// ExpireByUser marks all active PasswordResets of the User with the given ID
+// as used.
+func (ser *PasswordResetService) ExpireByUser(uID int, tx db.Tx) error {
+	list, err := ser.GetFilter(nil, nil, tx, func(pr *models.PasswordReset) bool {
+		return pr.UserID == uID && !pr.Used
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get PasswordResets by User ID %d: %w", uID, err)
+	}
+
+	for _, pr := range list {
+		pr.Used = true
+		err = ser.Update(pr, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update PasswordReset by id %d: %w",
+				pr.Meta.ID, err)
+		}
+	}
+	return nil
+}
+
+// IsActive returns true if the given PasswordReset has not been used and has
+// not expired.
+func (ser *PasswordResetService) IsActive(pr *models.PasswordReset) bool {
+	return !pr.Used && time.Now().Before(pr.ExpiresAt)
+}
+
+// GetAll retrieves all persisted values of PasswordReset.
+func (ser *PasswordResetService) GetAll(first *int, skip *int, tx db.Tx) ([]*models.PasswordReset, error) {
+	vlist, err := tx.Database().GetAll(first, skip, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to PasswordResets: %w", err)
+	}
+	return list, nil
+}
+
+// GetFilter retrieves all persisted values of PasswordReset that pass the
+// filter.
+func (ser *PasswordResetService) GetFilter(
+	first *int, skip *int, tx db.Tx, keep func(pr *models.PasswordReset) bool,
+) ([]*models.PasswordReset, error) {
+	vlist, err := tx.Database().GetFilter(first, skip, ser, tx,
+		func(m db.Model) bool {
+			pr, err := ser.AssertType(m)
+			if err != nil {
+				return false
+			}
+			return keep(pr)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to PasswordResets: %w", err)
+	}
+	return list, nil
+}
+
+// GetByID retrieves the persisted PasswordReset with the given ID.
+func (ser *PasswordResetService) GetByID(id int, tx db.Tx) (*models.PasswordReset, error) {
+	m, err := tx.Database().GetByID(id, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return pr, nil
+}
+
+// GetByToken retrieves the persisted PasswordReset with the given reset
+// token.
+func (ser *PasswordResetService) GetByToken(tkn string, tx db.Tx) (*models.PasswordReset, error) {
+	hash := jwt.HashOpaqueToken(tkn)
+
+	var pr *models.PasswordReset
+	_, err := tx.Database().FindFirst(ser, tx, func(m db.Model) (bool, error) {
+		e, err := ser.AssertType(m)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+
+		if e.TokenHash == hash {
+			pr = e
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate through keys: %w", err)
+	}
+
+	if pr == nil {
+		return nil, fmt.Errorf("reset token: %w", errInvalid)
+	}
+	return pr, nil
+}
+
+// Bucket returns the name of the bucket for PasswordReset.
+func (ser *PasswordResetService) Bucket() string {
+	return "PasswordReset"
+}
+
+// Clean cleans the given PasswordReset for storage.
+func (ser *PasswordResetService) Clean(m db.Model, _ db.Tx) error {
+	_, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return nil
+}
+
+// Validate returns an error if the PasswordReset is not valid for the
+// database.
+func (ser *PasswordResetService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// Check if User with ID specified in PasswordReset exists
+	_, err = tx.Database().GetRawByID(e.UserID, ser.UserService, tx)
+	if err != nil {
+		return &ValidationError{"PasswordReset", "UserID",
+			fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)}
+	}
+
+	if e.TokenHash == "" {
+		return &ValidationError{"PasswordReset", "TokenHash",
+			fmt.Errorf("reset token hash: %w", errNil)}
+	}
+
+	return nil
+}
+
+// Initialize sets initial values for some properties.
+func (ser *PasswordResetService) Initialize(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistOldProperties maintains certain properties of the existing
+// PasswordReset in updates.
+func (ser *PasswordResetService) PersistOldProperties(n db.Model, o db.Model, _ db.Tx) error {
+	npr, err := ser.AssertType(n)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	opr, err := ser.AssertType(o)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// PasswordResets may not be moved to another User, extended, or used
+	// again
+	npr.UserID = opr.UserID
+	npr.TokenHash = opr.TokenHash
+	npr.ExpiresAt = opr.ExpiresAt
+	npr.Used = npr.Used || opr.Used
+	return nil
+}
+
+// PersistHooks returns the persistence hook functions.
+func (ser *PasswordResetService) PersistHooks() *db.PersistHooks {
+	return &ser.Hooks
+}
+
+// Marshal transforms the given PasswordReset into JSON.
+func (ser *PasswordResetService) Marshal(m db.Model) ([]byte, error) {
+	pr, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	v, err := json.Marshal(pr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONMarshal, err)
+	}
+
+	return v, nil
+}
+
+// Unmarshal parses the given JSON into PasswordReset.
+func (ser *PasswordResetService) Unmarshal(buf []byte) (db.Model, error) {
+	var pr models.PasswordReset
+	err := json.Unmarshal(buf, &pr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONUnmarshal, err)
+	}
+	return &pr, nil
+}
+
+// AssertType exposes the given db.Model as a PasswordReset.
+func (ser *PasswordResetService) AssertType(m db.Model) (*models.PasswordReset, error) {
+	if m == nil {
+		return nil, fmt.Errorf("model: %w", errNil)
+	}
+
+	pr, ok := m.(*models.PasswordReset)
+	if !ok {
+		return nil,
+			fmt.Errorf("model: %w", errors.New("not of PasswordReset type"))
+	}
+	return pr, nil
+}
+
+// mapfromModel returns a list of PasswordReset type asserted from the given
+// list of db.Model.
+func (ser *PasswordResetService) mapFromModel(vlist []db.Model) ([]*models.PasswordReset, error) {
+	list := make([]*models.PasswordReset, len(vlist))
+	var err error
+	for i, v := range vlist {
+		list[i], err = ser.AssertType(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+	}
+	return list, nil
+}
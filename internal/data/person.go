@@ -14,13 +14,28 @@ import (
 // PersonService performs operations on Persons.
 type PersonService struct {
 	Hooks db.PersistHooks
+	// externalIDs is the index of the external IDs of Persons.
+	externalIDs *externalIDIndex
 }
 
+// personExternalIDIndex is the name of the index bucket that maps external
+// IDs to Person IDs.
+const personExternalIDIndex = "PersonExternalID"
+
 // NewPersonService returns a PersonService.
 func NewPersonService(hooks db.PersistHooks) *PersonService {
-	return &PersonService{
+	ser := &PersonService{
 		Hooks: hooks,
 	}
+	ser.externalIDs = newExternalIDIndex(personExternalIDIndex, "Person", ser,
+		func(m db.Model) ([]models.ExternalID, error) {
+			p, err := ser.AssertType(m)
+			if err != nil {
+				return nil, err
+			}
+			return p.ExternalIDs, nil
+		})
+	return ser
 }
 
 // Create persists the given Person.
@@ -113,6 +128,40 @@ func (ser *PersonService) GetByID(id int, tx db.Tx) (*models.Person, error) {
 	return p, nil
 }
 
+// GetByExternalID retrieves the persisted Person with the given external ID.
+// Sources are matched case-insensitively and IDs exactly.
+func (ser *PersonService) GetByExternalID(
+	source string, id string, tx db.Tx,
+) (*models.Person, error) {
+	mID, err := ser.GetIDByExternalID(source, id, tx)
+	if err != nil {
+		return nil, err
+	}
+	if mID == 0 {
+		return nil, fmt.Errorf("Person with external ID %s %q: %w", source, id,
+			errNotFound)
+	}
+	return ser.GetByID(mID, tx)
+}
+
+// GetIDByExternalID returns the ID of the persisted Person with the given
+// external ID, or 0 if there is none.
+func (ser *PersonService) GetIDByExternalID(
+	source string, id string, tx db.Tx,
+) (int, error) {
+	return ser.externalIDs.get(source, id, tx)
+}
+
+// Reindex rebuilds the external ID index from the persisted Persons.
+func (ser *PersonService) Reindex(tx db.Tx) error {
+	return ser.externalIDs.reindex(tx)
+}
+
+// IndexBuckets returns the names of the index buckets for Person.
+func (ser *PersonService) IndexBuckets() []string {
+	return []string{personExternalIDIndex}
+}
+
 // Bucket returns the name of the bucket for Person.
 func (ser *PersonService) Bucket() string {
 	return "Person"
@@ -120,20 +169,23 @@ func (ser *PersonService) Bucket() string {
 
 // Clean cleans the given Person for storage.
 func (ser *PersonService) Clean(m db.Model, _ db.Tx) error {
-	_, err := ser.AssertType(m)
+	e, err := ser.AssertType(m)
 	if err != nil {
 		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
 	}
+
+	cleanExternalIDs(e.ExternalIDs)
 	return nil
 }
 
 // Validate returns an error if the Person is not valid for the database.
-func (ser *PersonService) Validate(m db.Model, _ db.Tx) error {
-	_, err := ser.AssertType(m)
+func (ser *PersonService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
 	if err != nil {
 		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
 	}
-	return nil
+
+	return ser.externalIDs.validate(e.ExternalIDs, e.Meta.ID, tx)
 }
 
 // Initialize sets initial values for some properties.
@@ -143,7 +195,20 @@ func (ser *PersonService) Initialize(_ db.Model, _ db.Tx) error {
 
 // PersistOldProperties maintains certain properties of the existing Person in
 // updates.
-func (ser *PersonService) PersistOldProperties(_ db.Model, _ db.Model, _ db.Tx) error {
+func (ser *PersonService) PersistOldProperties(n db.Model, o db.Model, _ db.Tx) error {
+	e, err := ser.AssertType(n)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	oe, err := ser.AssertType(o)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// ExternalIDs are not changed if not given
+	if e.ExternalIDs == nil {
+		e.ExternalIDs = oe.ExternalIDs
+	}
 	return nil
 }
 
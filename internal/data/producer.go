@@ -13,13 +13,28 @@ import (
 // ProducerService performs operations on Producer.
 type ProducerService struct {
 	Hooks db.PersistHooks
+	// externalIDs is the index of the external IDs of Producers.
+	externalIDs *externalIDIndex
 }
 
+// producerExternalIDIndex is the name of the index bucket that maps external
+// IDs to Producer IDs.
+const producerExternalIDIndex = "ProducerExternalID"
+
 // NewProducerService returns a ProducerService.
 func NewProducerService(hooks db.PersistHooks) *ProducerService {
-	return &ProducerService{
+	ser := &ProducerService{
 		Hooks: hooks,
 	}
+	ser.externalIDs = newExternalIDIndex(producerExternalIDIndex, "Producer", ser,
+		func(m db.Model) ([]models.ExternalID, error) {
+			p, err := ser.AssertType(m)
+			if err != nil {
+				return nil, err
+			}
+			return p.ExternalIDs, nil
+		})
+	return ser
 }
 
 // Create persists the given Producer.
@@ -112,6 +127,40 @@ func (ser *ProducerService) GetByID(id int, tx db.Tx) (*models.Producer, error)
 	return p, nil
 }
 
+// GetByExternalID retrieves the persisted Producer with the given external ID.
+// Sources are matched case-insensitively and IDs exactly.
+func (ser *ProducerService) GetByExternalID(
+	source string, id string, tx db.Tx,
+) (*models.Producer, error) {
+	mID, err := ser.GetIDByExternalID(source, id, tx)
+	if err != nil {
+		return nil, err
+	}
+	if mID == 0 {
+		return nil, fmt.Errorf("Producer with external ID %s %q: %w", source, id,
+			errNotFound)
+	}
+	return ser.GetByID(mID, tx)
+}
+
+// GetIDByExternalID returns the ID of the persisted Producer with the given
+// external ID, or 0 if there is none.
+func (ser *ProducerService) GetIDByExternalID(
+	source string, id string, tx db.Tx,
+) (int, error) {
+	return ser.externalIDs.get(source, id, tx)
+}
+
+// Reindex rebuilds the external ID index from the persisted Producers.
+func (ser *ProducerService) Reindex(tx db.Tx) error {
+	return ser.externalIDs.reindex(tx)
+}
+
+// IndexBuckets returns the names of the index buckets for Producer.
+func (ser *ProducerService) IndexBuckets() []string {
+	return []string{producerExternalIDIndex}
+}
+
 // Bucket returns the name of the bucket for Producer.
 func (ser *ProducerService) Bucket() string {
 	return "Producer"
@@ -124,6 +173,8 @@ func (ser *ProducerService) Clean(m db.Model, _ db.Tx) error {
 		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
 	}
 
+	cleanExternalIDs(e.ExternalIDs)
+
 	for i, t := range e.Types {
 		e.Types[i] = strings.Trim(t, " ")
 	}
@@ -131,12 +182,13 @@ func (ser *ProducerService) Clean(m db.Model, _ db.Tx) error {
 }
 
 // Validate returns an error if the Producer is not valid for the database.
-func (ser *ProducerService) Validate(m db.Model, _ db.Tx) error {
-	_, err := ser.AssertType(m)
+func (ser *ProducerService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
 	if err != nil {
 		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
 	}
-	return nil
+
+	return ser.externalIDs.validate(e.ExternalIDs, e.Meta.ID, tx)
 }
 
 // Initialize sets initial values for some properties.
@@ -146,7 +198,20 @@ func (ser *ProducerService) Initialize(_ db.Model, _ db.Tx) error {
 
 // PersistOldProperties maintains certain properties of the existing Producer
 // in updates.
-func (ser *ProducerService) PersistOldProperties(_ db.Model, _ db.Model, _ db.Tx) error {
+func (ser *ProducerService) PersistOldProperties(n db.Model, o db.Model, _ db.Tx) error {
+	e, err := ser.AssertType(n)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	oe, err := ser.AssertType(o)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// ExternalIDs are not changed if not given
+	if e.ExternalIDs == nil {
+		e.ExternalIDs = oe.ExternalIDs
+	}
 	return nil
 }
 
@@ -0,0 +1,83 @@
+package data
+
+import "github.com/Dophin2009/nao/pkg/models"
+
+var (
+	// MediaModelTypes are the names of the types of global Media data.
+	MediaModelTypes = []string{
+		"Airing", "BroadcastSlot", "Chapter", "Character", "Episode",
+		"EpisodeSet", "Genre", "Media", "MediaCharacter", "MediaGenre",
+		"MediaProducer", "MediaRelation", "Person", "Producer", "Volume",
+	}
+	// UserModelTypes are the names of the types of data owned by Users.
+	UserModelTypes = []string{
+		"UserCharacter", "UserEpisode", "UserMedia", "UserMediaList",
+		"UserPerson",
+	}
+	// AccountModelTypes are the names of the types of account data, which
+	// may only be managed by administrators besides the owning User.
+	AccountModelTypes = []string{
+		"APIToken", "CalendarToken", "Invitation", "Role", "Session",
+		"User",
+	}
+)
+
+// rolePermissions maps each Role to the set of Permissions it grants.
+var rolePermissions = map[models.Role]map[models.Permission]bool{}
+
+func init() {
+	grant := func(role models.Role, types []string, actions ...models.Action) {
+		// Each Role grants the Permissions of less privileged Roles
+		for _, r := range models.Roles {
+			if r < role {
+				continue
+			}
+			if rolePermissions[r] == nil {
+				rolePermissions[r] = map[models.Permission]bool{}
+			}
+			for _, t := range types {
+				for _, a := range actions {
+					rolePermissions[r][models.Permission{Model: t, Action: a}] = true
+				}
+			}
+		}
+	}
+
+	grant(models.RoleViewer, MediaModelTypes, models.ActionRead)
+	grant(models.RoleViewer, UserModelTypes, models.ActionRead)
+	grant(models.RoleViewer, []string{"User"}, models.ActionRead)
+
+	grant(models.RoleContributor, MediaModelTypes,
+		models.ActionCreate, models.ActionUpdate)
+
+	grant(models.RoleModerator, MediaModelTypes, models.ActionDelete)
+	grant(models.RoleModerator, UserModelTypes,
+		models.ActionCreate, models.ActionUpdate, models.ActionDelete)
+
+	grant(models.RoleAdmin, AccountModelTypes, models.ActionRead,
+		models.ActionCreate, models.ActionUpdate, models.ActionDelete)
+}
+
+// RolePermissions returns the Permissions granted by the given Role.
+func RolePermissions(role models.Role) []models.Permission {
+	var list []models.Permission
+	for _, types := range [][]string{
+		MediaModelTypes, UserModelTypes, AccountModelTypes,
+	} {
+		for _, t := range types {
+			for _, a := range []models.Action{models.ActionRead,
+				models.ActionCreate, models.ActionUpdate, models.ActionDelete} {
+				p := models.Permission{Model: t, Action: a}
+				if rolePermissions[role][p] {
+					list = append(list, p)
+				}
+			}
+		}
+	}
+	return list
+}
+
+// RoleGrants returns true if the given Role grants the given Permission.
+func RoleGrants(role models.Role, perm models.Permission) bool {
+	return rolePermissions[role][perm]
+}
@@ -0,0 +1,178 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// maxScheduleRange is the longest range of time schedules are computed for.
+const maxScheduleRange = 366 * 24 * time.Hour
+
+// ScheduleService computes the airing schedules of Media from their
+// BroadcastSlots and Airings.
+type ScheduleService struct {
+	BroadcastSlotService *BroadcastSlotService
+	AiringService        *AiringService
+}
+
+// NewScheduleService returns a ScheduleService.
+func NewScheduleService(broadcastSlotService *BroadcastSlotService,
+	airingService *AiringService) *ScheduleService {
+	return &ScheduleService{
+		BroadcastSlotService: broadcastSlotService,
+		AiringService:        airingService,
+	}
+}
+
+// scheduleKey identifies an episode of some Media in a schedule.
+type scheduleKey struct {
+	mediaID int
+	episode int
+}
+
+// GetSchedule retrieves the broadcasts from from, inclusive, to to,
+// exclusive, of the episodes of the Media with the given ID, or of all Media
+// if nil, ordered by time. Airings replace the broadcasts BroadcastSlots give
+// of the same episodes.
+func (ser *ScheduleService) GetSchedule(
+	from time.Time, to time.Time, mID *int, tx db.Tx,
+) ([]*models.ScheduledAiring, error) {
+	if !to.After(from) || to.Sub(from) > maxScheduleRange {
+		return nil, fmt.Errorf("range from %s to %s: %w",
+			from.Format(time.RFC3339), to.Format(time.RFC3339), errInvalid)
+	}
+
+	airings, slots, err := ser.get(mID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*models.ScheduledAiring
+	scheduled := map[scheduleKey]bool{}
+	for _, a := range airings {
+		scheduled[scheduleKey{a.MediaID, a.EpisodeNumber}] = true
+		if !a.AiringAt.Before(from) && a.AiringAt.Before(to) {
+			list = append(list, airingSchedule(a))
+		}
+	}
+	for _, s := range slots {
+		err = s.Occurrences(from, func(ep int, at time.Time) bool {
+			if !at.Before(to) {
+				return false
+			}
+			key := scheduleKey{s.MediaID, ep}
+			if !scheduled[key] {
+				scheduled[key] = true
+				list = append(list, slotSchedule(s, ep, at))
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get broadcasts of BroadcastSlot %d: %w",
+				s.Meta.ID, err)
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if !a.AiringAt.Equal(b.AiringAt) {
+			return a.AiringAt.Before(b.AiringAt)
+		}
+		if a.MediaID != b.MediaID {
+			return a.MediaID < b.MediaID
+		}
+		return a.EpisodeNumber < b.EpisodeNumber
+	})
+	return list, nil
+}
+
+// GetNext retrieves the first broadcast after now of an episode of the Media
+// with the given ID, or nil if none is known.
+func (ser *ScheduleService) GetNext(
+	mID int, now time.Time, tx db.Tx,
+) (*models.ScheduledAiring, error) {
+	airings, slots, err := ser.get(&mID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var next *models.ScheduledAiring
+	recorded := map[int]bool{}
+	for _, a := range airings {
+		recorded[a.EpisodeNumber] = true
+		if a.AiringAt.After(now) &&
+			(next == nil || a.AiringAt.Before(next.AiringAt)) {
+			next = airingSchedule(a)
+		}
+	}
+	for _, s := range slots {
+		err = s.Occurrences(now, func(ep int, at time.Time) bool {
+			if next != nil && !at.Before(next.AiringAt) {
+				return false
+			}
+			if !at.After(now) || recorded[ep] {
+				return true
+			}
+			next = slotSchedule(s, ep, at)
+			return false
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get broadcasts of BroadcastSlot %d: %w",
+				s.Meta.ID, err)
+		}
+	}
+	return next, nil
+}
+
+// get retrieves the Airings and BroadcastSlots of the Media with the given
+// ID, or of all Media if nil.
+func (ser *ScheduleService) get(mID *int, tx db.Tx) (
+	[]*models.Airing, []*models.BroadcastSlot, error,
+) {
+	airings, err := ser.AiringService.GetFilter(nil, nil, tx,
+		func(a *models.Airing) bool {
+			return mID == nil || a.MediaID == *mID
+		})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get Airings: %w", err)
+	}
+
+	slots, err := ser.BroadcastSlotService.GetFilter(nil, nil, tx,
+		func(s *models.BroadcastSlot) bool {
+			return mID == nil || s.MediaID == *mID
+		})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get BroadcastSlots: %w", err)
+	}
+	return airings, slots, nil
+}
+
+// airingSchedule returns the broadcast recorded by the given Airing.
+func airingSchedule(a *models.Airing) *models.ScheduledAiring {
+	id := a.Meta.ID
+	return &models.ScheduledAiring{
+		MediaID:       a.MediaID,
+		EpisodeNumber: a.EpisodeNumber,
+		EpisodeID:     a.EpisodeID,
+		AiringAt:      a.AiringAt,
+		AiringID:      &id,
+	}
+}
+
+// slotSchedule returns the broadcast of the episode with the given number at
+// the given time in the given BroadcastSlot.
+func slotSchedule(
+	s *models.BroadcastSlot, ep int, at time.Time,
+) *models.ScheduledAiring {
+	id := s.Meta.ID
+	return &models.ScheduledAiring{
+		MediaID:         s.MediaID,
+		EpisodeNumber:   ep,
+		AiringAt:        at,
+		BroadcastSlotID: &id,
+	}
+}
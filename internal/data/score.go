@@ -0,0 +1,18 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// validateScore returns an error if the given score of a model of the given
+// name is given but not within the scale scores are stored in.
+func validateScore(model string, score *int) error {
+	if score != nil && !models.ValidScore(*score) {
+		return &ValidationError{model, "Score",
+			fmt.Errorf("%d not between 0 and %d: %w", *score, models.ScoreMax,
+				errInvalid)}
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package data
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// mediaSeasonIndex is the name of the index bucket that maps the Seasons
+// Media premiered in to their IDs.
+const mediaSeasonIndex = "MediaSeason"
+
+// seasonIndex maintains an index bucket that maps the Seasons Media premiered
+// in to their IDs, so that the Media of a Season can be listed without
+// reading every Media. Keys are the Season followed by the big-endian ID of
+// the Media, so that keys are ordered by Season and then ID, and each Season
+// is a prefix.
+type seasonIndex struct {
+	// bucket is the name of the index bucket.
+	bucket string
+	ser    *MediaService
+}
+
+// newSeasonIndex returns a seasonIndex of the Media of the given service and
+// adds the hooks that keep it in sync with them to the service.
+func newSeasonIndex(bucket string, ser *MediaService) *seasonIndex {
+	idx := &seasonIndex{
+		bucket: bucket,
+		ser:    ser,
+	}
+
+	indexOnCreate := func(m db.Model, _ db.Service, tx db.Tx) error {
+		return idx.index(m, tx)
+	}
+	unindexOldOnUpdate := func(m db.Model, _ db.Service, tx db.Tx) error {
+		o, err := tx.Database().GetByID(m.Metadata().ID, idx.ser, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Media by ID %d: %w",
+				m.Metadata().ID, err)
+		}
+		return idx.unindex(o, tx)
+	}
+	indexOnUpdate := func(m db.Model, _ db.Service, tx db.Tx) error {
+		return idx.index(m, tx)
+	}
+	unindexOnDelete := func(m db.Model, _ db.Service, tx db.Tx) error {
+		return idx.unindex(m, tx)
+	}
+
+	hooks := ser.PersistHooks()
+	hooks.PostCreateHooks = append(hooks.PostCreateHooks, indexOnCreate)
+	hooks.PreUpdateHooks = append(hooks.PreUpdateHooks, unindexOldOnUpdate)
+	hooks.PostUpdateHooks = append(hooks.PostUpdateHooks, indexOnUpdate)
+	hooks.PreDeleteHooks = append(hooks.PreDeleteHooks, unindexOnDelete)
+
+	return idx
+}
+
+// get returns the IDs of the Media that premiered in the given Season, in
+// order of ID.
+func (idx *seasonIndex) get(s models.Season, tx db.Tx) ([]int, error) {
+	prefix := seasonKey(s)
+	if prefix == nil {
+		return nil, nil
+	}
+
+	var ids []int
+	err := tx.Database().ScanIndex(idx.bucket, prefix, tx,
+		func(_ []byte, id int) (bool, error) {
+			ids = append(ids, id)
+			return false, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan index %q: %w", idx.bucket, err)
+	}
+	return ids, nil
+}
+
+// count returns the number of Media that premiered in each Season that any
+// did, in order of Season.
+func (idx *seasonIndex) count(tx db.Tx) ([]*models.SeasonCount, error) {
+	var counts []*models.SeasonCount
+	var last string
+	err := tx.Database().ScanIndex(idx.bucket, nil, tx,
+		func(key []byte, _ int) (bool, error) {
+			s, err := parseSeasonKey(key)
+			if err != nil {
+				return true, fmt.Errorf("key %q of index %q: %w", key,
+					idx.bucket, err)
+			}
+
+			prefix := string(key[:seasonKeyLength])
+			if prefix != last {
+				counts = append(counts, &models.SeasonCount{Season: s})
+				last = prefix
+			}
+			counts[len(counts)-1].Count++
+			return false, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan index %q: %w", idx.bucket, err)
+	}
+	return counts, nil
+}
+
+// reindex rebuilds the index from the persisted Media.
+func (idx *seasonIndex) reindex(tx db.Tx) error {
+	err := tx.Database().ClearIndex(idx.bucket, tx)
+	if err != nil {
+		return fmt.Errorf("failed to clear index %q: %w", idx.bucket, err)
+	}
+
+	return tx.Database().DoEach(nil, nil, idx.ser, tx,
+		func(m db.Model, _ db.Service, tx db.Tx) (bool, error) {
+			err := idx.index(m, tx)
+			if err != nil {
+				return true, err
+			}
+			return false, nil
+		}, nil)
+}
+
+// index maps the Season the given Media premiered in to its ID.
+func (idx *seasonIndex) index(m db.Model, tx db.Tx) error {
+	key, err := idx.key(m)
+	if err != nil || key == nil {
+		return err
+	}
+
+	err = tx.Database().PutIndex(idx.bucket, key, m.Metadata().ID, tx)
+	if err != nil {
+		return fmt.Errorf("failed to put index %q: %w", idx.bucket, err)
+	}
+	return nil
+}
+
+// unindex removes the mapping of the Season the given Media premiered in.
+func (idx *seasonIndex) unindex(m db.Model, tx db.Tx) error {
+	key, err := idx.key(m)
+	if err != nil || key == nil {
+		return err
+	}
+
+	err = tx.Database().DeleteIndex(idx.bucket, key, tx)
+	if err != nil {
+		return fmt.Errorf("failed to delete index %q: %w", idx.bucket, err)
+	}
+	return nil
+}
+
+// key returns the key of the given Media in the index, or nil if the Season
+// it premiered in is unknown.
+func (idx *seasonIndex) key(m db.Model) ([]byte, error) {
+	md, err := idx.ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	s, ok := md.Premiered()
+	if !ok {
+		return nil, nil
+	}
+	prefix := seasonKey(s)
+	if prefix == nil {
+		return nil, nil
+	}
+	id := make([]byte, 8)
+	binary.BigEndian.PutUint64(id, uint64(md.Meta.ID))
+	return append(prefix, id...), nil
+}
+
+// seasonKeyLength is the length of the prefixes of the keys of Seasons.
+const seasonKeyLength = 6
+
+// seasonKey returns the prefix of the keys of the Media that premiered in
+// the given Season, the four-digit year followed by the quarter, or nil if
+// the Season is not complete or its year has more than four digits.
+func seasonKey(s models.Season) []byte {
+	if !s.IsComplete() || *s.Year < 0 || *s.Year > 9999 {
+		return nil
+	}
+	return []byte(fmt.Sprintf("%04d%d/", *s.Year, int(*s.Quarter)))
+}
+
+// parseSeasonKey returns the Season of the given key.
+func parseSeasonKey(key []byte) (models.Season, error) {
+	if len(key) < seasonKeyLength {
+		return models.Season{}, errInvalid
+	}
+	year, err := strconv.Atoi(string(key[:4]))
+	if err != nil {
+		return models.Season{}, errInvalid
+	}
+	q := models.Quarter(key[4] - '0')
+	if !q.IsValid() {
+		return models.Season{}, errInvalid
+	}
+	return models.NewSeason(year, q), nil
+}
@@ -0,0 +1,158 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// SeasonChartService lists the Media that premiered in each Season.
+type SeasonChartService struct {
+	MediaService     *MediaService
+	UserMediaService *UserMediaService
+}
+
+// NewSeasonChartService returns a SeasonChartService.
+func NewSeasonChartService(mediaService *MediaService,
+	userMediaService *UserMediaService) *SeasonChartService {
+	return &SeasonChartService{
+		MediaService:     mediaService,
+		UserMediaService: userMediaService,
+	}
+}
+
+// GetChart retrieves the Media that premiered in the given Season with the
+// given MediaType, or of any type if nil, in the given order. Ties are
+// ordered by ID.
+func (ser *SeasonChartService) GetChart(
+	s models.Season, mType *models.MediaType, order models.SeasonSort,
+	tx db.Tx,
+) ([]*models.Media, error) {
+	if !s.IsComplete() {
+		return nil, fmt.Errorf("season: %w", errInvalid)
+	}
+	if mType != nil && !mType.IsValid() {
+		return nil, fmt.Errorf("type %d: %w", int(*mType), errInvalid)
+	}
+	if !order.IsValid() {
+		return nil, fmt.Errorf("sort %d: %w", int(order), errInvalid)
+	}
+
+	all, err := ser.MediaService.GetBySeason(s, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Media by Season: %w", err)
+	}
+	list := make([]*models.Media, 0, len(all))
+	for _, md := range all {
+		if mType == nil || (md.Type != nil && *md.Type == *mType) {
+			list = append(list, md)
+		}
+	}
+
+	var less func(a, b *models.Media) bool
+	switch order {
+	case models.SeasonSortPopularity:
+		popularity, err := ser.popularity(list, tx)
+		if err != nil {
+			return nil, err
+		}
+		less = func(a, b *models.Media) bool {
+			return popularity[a.Meta.ID] > popularity[b.Meta.ID]
+		}
+	case models.SeasonSortTitle:
+		less = func(a, b *models.Media) bool {
+			return sortTitle(a) < sortTitle(b)
+		}
+	case models.SeasonSortStartDate:
+		less = func(a, b *models.Media) bool {
+			if a.StartDate == nil || b.StartDate == nil {
+				return a.StartDate != nil && b.StartDate == nil
+			}
+			return a.StartDate.Before(*b.StartDate)
+		}
+	}
+
+	// Media are retrieved in order of ID, which breaks ties
+	sort.SliceStable(list, func(i, j int) bool {
+		return less(list[i], list[j])
+	})
+	return list, nil
+}
+
+// GetCounts returns the number of Media with the given MediaType, or of any
+// type if nil, that premiered in each Season that any did, in order of
+// Season.
+func (ser *SeasonChartService) GetCounts(
+	mType *models.MediaType, tx db.Tx,
+) ([]*models.SeasonCount, error) {
+	if mType == nil {
+		return ser.MediaService.CountBySeason(tx)
+	}
+	if !mType.IsValid() {
+		return nil, fmt.Errorf("type %d: %w", int(*mType), errInvalid)
+	}
+
+	counts, err := ser.MediaService.CountBySeason(tx)
+	if err != nil {
+		return nil, err
+	}
+	var typed []*models.SeasonCount
+	for _, c := range counts {
+		list, err := ser.MediaService.GetBySeason(c.Season, tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Media by Season: %w", err)
+		}
+
+		n := 0
+		for _, md := range list {
+			if md.Type != nil && *md.Type == *mType {
+				n++
+			}
+		}
+		if n > 0 {
+			typed = append(typed,
+				&models.SeasonCount{Season: c.Season, Count: n})
+		}
+	}
+	return typed, nil
+}
+
+// popularity returns the number of UserMedia of each of the given Media, by
+// ID.
+func (ser *SeasonChartService) popularity(
+	list []*models.Media, tx db.Tx,
+) (map[int]int, error) {
+	popularity := make(map[int]int, len(list))
+	for _, md := range list {
+		popularity[md.Meta.ID] = 0
+	}
+
+	_, err := ser.UserMediaService.GetFilter(nil, nil, tx,
+		func(um *models.UserMedia) bool {
+			if _, ok := popularity[um.MediaID]; ok {
+				popularity[um.MediaID]++
+			}
+			return false
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get UserMedia: %w", err)
+	}
+	return popularity, nil
+}
+
+// sortTitle returns the lower-cased string of the first primary Title of the
+// given Media, or of its first Title if none are primary.
+func sortTitle(md *models.Media) string {
+	for _, t := range md.Titles {
+		if t.Priority == models.TitlePriorityPrimary {
+			return strings.ToLower(t.String)
+		}
+	}
+	if len(md.Titles) == 0 {
+		return ""
+	}
+	return strings.ToLower(md.Titles[0].String)
+}
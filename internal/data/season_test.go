@@ -0,0 +1,172 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func TestSeasonKey(t *testing.T) {
+	s := models.NewSeason(1998, models.QuarterFall)
+	key := seasonKey(s)
+	if string(key) != "19984/" {
+		t.Errorf("expected key %q, got %q", "19984/", key)
+	}
+	parsed, err := parseSeasonKey(append(key, 0, 0, 0, 0, 0, 0, 0, 1))
+	if err != nil {
+		t.Fatalf("failed to parse key: %v", err)
+	}
+	if *parsed.Year != 1998 || *parsed.Quarter != models.QuarterFall {
+		t.Errorf("expected fall 1998, got %d %d", *parsed.Year, *parsed.Quarter)
+	}
+
+	year := 1998
+	for _, s := range []models.Season{
+		{Year: &year},
+		models.NewSeason(10000, models.QuarterFall),
+		models.NewSeason(-1, models.QuarterFall),
+	} {
+		if key := seasonKey(s); key != nil {
+			t.Errorf("expected no key for %+v, got %q", s, key)
+		}
+	}
+	for _, key := range []string{"1998", "19985/", "199x4/"} {
+		if _, err := parseSeasonKey([]byte(key)); err == nil {
+			t.Errorf("expected error parsing %q", key)
+		}
+	}
+}
+
+func TestMediaSeasonIndex(t *testing.T) {
+	ser := NewMediaService(db.PersistHooks{})
+	database, cleanup := newTestDatabase(t, ser)
+	defer cleanup()
+
+	fall98 := models.NewSeason(1998, models.QuarterFall)
+	spring98 := models.NewSeason(1998, models.QuarterSpring)
+	winter99 := models.NewSeason(1999, models.QuarterWinter)
+	april98 := time.Date(1998, 4, 3, 0, 0, 0, 0, time.UTC)
+
+	var bebopID, trigunID, lainID int
+	err := database.Transaction(true, func(tx db.Tx) error {
+		var err error
+		bebopID, err = ser.Create(&models.Media{SeasonPremiered: fall98}, tx)
+		if err != nil {
+			return err
+		}
+		// The Season of the StartDate is used without a SeasonPremiered
+		trigunID, err = ser.Create(&models.Media{StartDate: &april98}, tx)
+		if err != nil {
+			return err
+		}
+		lainID, err = ser.Create(&models.Media{SeasonPremiered: fall98,
+			StartDate: &april98}, tx)
+		if err != nil {
+			return err
+		}
+		_, err = ser.Create(&models.Media{}, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to create Media: %v", err)
+	}
+
+	// seasonIDs returns the IDs of the Media of each of the given Seasons.
+	seasonIDs := func(seasons ...models.Season) [][]int {
+		all := make([][]int, len(seasons))
+		err := database.Transaction(false, func(tx db.Tx) error {
+			for i, s := range seasons {
+				list, err := ser.GetBySeason(s, tx)
+				if err != nil {
+					return err
+				}
+				all[i] = []int{}
+				for _, md := range list {
+					all[i] = append(all[i], md.Meta.ID)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to get Media by Season: %v", err)
+		}
+		return all
+	}
+	counts := func() map[string]int {
+		m := map[string]int{}
+		err := database.Transaction(false, func(tx db.Tx) error {
+			list, err := ser.CountBySeason(tx)
+			if err != nil {
+				return err
+			}
+			for _, c := range list {
+				m[string(seasonKey(c.Season))] = c.Count
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to count Media by Season: %v", err)
+		}
+		return m
+	}
+
+	expected := [][]int{{bebopID, lainID}, {trigunID}, {}}
+	if ids := seasonIDs(fall98, spring98, winter99); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected IDs %v, got %v", expected, ids)
+	}
+	expectedCounts := map[string]int{"19984/": 2, "19982/": 1}
+	if c := counts(); !reflect.DeepEqual(c, expectedCounts) {
+		t.Errorf("expected counts %v, got %v", expectedCounts, c)
+	}
+
+	// Changing the Season or StartDate moves the Media in the index
+	err = database.Transaction(true, func(tx db.Tx) error {
+		bebop, err := ser.GetByID(bebopID, tx)
+		if err != nil {
+			return err
+		}
+		bebop.SeasonPremiered = winter99
+		err = ser.Update(bebop, tx)
+		if err != nil {
+			return err
+		}
+
+		trigun, err := ser.GetByID(trigunID, tx)
+		if err != nil {
+			return err
+		}
+		january99 := time.Date(1999, 1, 10, 0, 0, 0, 0, time.UTC)
+		trigun.StartDate = &january99
+		err = ser.Update(trigun, tx)
+		if err != nil {
+			return err
+		}
+		return ser.Delete(lainID, tx)
+	})
+	if err != nil {
+		t.Fatalf("failed to update Media: %v", err)
+	}
+
+	expected = [][]int{{}, {}, {bebopID, trigunID}}
+	if ids := seasonIDs(fall98, spring98, winter99); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected IDs %v after update, got %v", expected, ids)
+	}
+	expectedCounts = map[string]int{"19991/": 2}
+	if c := counts(); !reflect.DeepEqual(c, expectedCounts) {
+		t.Errorf("expected counts %v after update, got %v", expectedCounts, c)
+	}
+
+	// Reindexing gives the same index
+	err = database.Transaction(true, func(tx db.Tx) error {
+		return ser.Reindex(tx)
+	})
+	if err != nil {
+		t.Fatalf("failed to reindex Media: %v", err)
+	}
+	if c := counts(); !reflect.DeepEqual(c, expectedCounts) {
+		t.Errorf("expected counts %v after reindex, got %v", expectedCounts, c)
+	}
+}
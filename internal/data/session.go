@@ -0,0 +1,421 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/jwt"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+// SessionService performs operations on Session.
+type SessionService struct {
+	UserService *UserService
+	Hooks       db.PersistHooks
+}
+
+// NewSessionService returns a SessionService.
+func NewSessionService(hooks db.PersistHooks,
+	userService *UserService) *SessionService {
+	sessionService := &SessionService{
+		UserService: userService,
+		Hooks:       hooks,
+	}
+
+	// Add hook to delete Session on User deletion
+	deleteSessionOnDeleteUser := func(um db.Model, _ db.Service, tx db.Tx) error {
+		uID := um.Metadata().ID
+		err := sessionService.DeleteByUser(uID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Session by User ID %d: %w",
+				uID, err)
+		}
+		return nil
+	}
+
+	// Add hook to revoke all Sessions of a User on password change
+	revokeSessionOnChangePassword := func(um db.Model, _ db.Service, tx db.Tx) error {
+		uw, ok := um.(*userWrap)
+		if !ok || !uw.updatedPass {
+			return nil
+		}
+
+		uID := um.Metadata().ID
+		_, err := sessionService.RevokeByUser(uID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to revoke Session by User ID %d: %w",
+				uID, err)
+		}
+		return nil
+	}
+	uSerHooks := userService.PersistHooks()
+	uSerHooks.PreDeleteHooks =
+		append(uSerHooks.PreDeleteHooks, deleteSessionOnDeleteUser)
+	uSerHooks.PostUpdateHooks =
+		append(uSerHooks.PostUpdateHooks, revokeSessionOnChangePassword)
+
+	return sessionService
+}
+
+// Create persists the given Session.
+func (ser *SessionService) Create(s *models.Session, tx db.Tx) (int, error) {
+	return tx.Database().Create(s, ser, tx)
+}
+
+// Update replaces the value of the Session with the given ID.
+func (ser *SessionService) Update(s *models.Session, tx db.Tx) error {
+	return tx.Database().Update(s, ser, tx)
+}
+
+// Delete deletes the Session with the given ID.
+func (ser *SessionService) Delete(id int, tx db.Tx) error {
+	return tx.Database().Delete(id, ser, tx)
+}
+
+// DeleteByUser deletes the Sessions with the given User ID.
+func (ser *SessionService) DeleteByUser(uID int, tx db.Tx) error {
+	return tx.Database().DeleteFilter(ser, tx, func(m db.Model) bool {
+		s, err := ser.AssertType(m)
+		if err != nil {
+			return false
+		}
+		return s.UserID == uID
+	})
+}
+
+// Start creates a new Session for the User with the given ID that expires
+// after the given duration. The Session and its refresh token are returned.
+func (ser *SessionService) Start(uID int, device string,
+	duration time.Duration, tx db.Tx) (*models.Session, string, error) {
+	tkn, err := jwt.NewOpaqueToken("")
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	s := models.Session{
+		UserID:           uID,
+		RefreshTokenHash: jwt.HashOpaqueToken(tkn),
+		Device:           device,
+		LastUsed:         now,
+		ExpiresAt:        now.Add(duration),
+	}
+	_, err = ser.Create(&s, tx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create Session: %w", err)
+	}
+
+	return &s, tkn, nil
+}
+
+// Refresh replaces the refresh token of the active Session that the given
+// refresh token belongs to and extends its expiration by the given duration.
+// The Session and its new refresh token are returned; the given token may
+// not be used again.
+func (ser *SessionService) Refresh(tkn string, duration time.Duration,
+	tx db.Tx) (*models.Session, string, error) {
+	s, err := ser.GetByRefreshToken(tkn, tx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !ser.IsActive(s) {
+		return nil, "", fmt.Errorf("Session %d: %w", s.Meta.ID,
+			errors.New("is revoked or expired"))
+	}
+
+	next, err := jwt.NewOpaqueToken("")
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	s.RefreshTokenHash = jwt.HashOpaqueToken(next)
+	s.LastUsed = now
+	s.ExpiresAt = now.Add(duration)
+	err = ser.Update(s, tx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to update Session by id %d: %w",
+			s.Meta.ID, err)
+	}
+
+	return s, next, nil
+}
+
+// Revoke revokes the Session with the given ID.
+func (ser *SessionService) Revoke(id int, tx db.Tx) (*models.Session, error) {
+	s, err := ser.GetByID(id, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Session by id %d: %w", id, err)
+	}
+
+	if s.Revoked {
+		return s, nil
+	}
+
+	s.Revoked = true
+	err = ser.Update(s, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update Session by id %d: %w", id, err)
+	}
+	return s, nil
+}
+
+// RevokeByUser revokes all active Sessions of the User with the given ID and
+// returns the number of Sessions revoked.
+func (ser *SessionService) RevokeByUser(uID int, tx db.Tx) (int, error) {
+	list, err := ser.GetFilter(nil, nil, tx, func(s *models.Session) bool {
+		return s.UserID == uID && !s.Revoked
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get Sessions by User ID %d: %w", uID, err)
+	}
+
+	for _, s := range list {
+		s.Revoked = true
+		err = ser.Update(s, tx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update Session by id %d: %w",
+				s.Meta.ID, err)
+		}
+	}
+	return len(list), nil
+}
+
+// IsActive returns true if the given Session has not been revoked and has not
+// expired.
+func (ser *SessionService) IsActive(s *models.Session) bool {
+	return !s.Revoked && time.Now().Before(s.ExpiresAt)
+}
+
+// GetAll retrieves all persisted values of Session.
+func (ser *SessionService) GetAll(first *int, skip *int, tx db.Tx) ([]*models.Session, error) {
+	vlist, err := tx.Database().GetAll(first, skip, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to Sessions: %w", err)
+	}
+	return list, nil
+}
+
+// GetFilter retrieves all persisted values of Session that pass the filter.
+func (ser *SessionService) GetFilter(
+	first *int, skip *int, tx db.Tx, keep func(s *models.Session) bool,
+) ([]*models.Session, error) {
+	vlist, err := tx.Database().GetFilter(first, skip, ser, tx,
+		func(m db.Model) bool {
+			s, err := ser.AssertType(m)
+			if err != nil {
+				return false
+			}
+			return keep(s)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to Sessions: %w", err)
+	}
+	return list, nil
+}
+
+// GetMultiple retrieves the persisted Session values specified by the given
+// IDs that pass the filter.
+func (ser *SessionService) GetMultiple(
+	ids []int, tx db.Tx, keep func(s *models.Session) bool,
+) ([]*models.Session, error) {
+	vlist, err := tx.Database().GetMultiple(ids, ser, tx,
+		func(m db.Model) bool {
+			s, err := ser.AssertType(m)
+			if err != nil {
+				return false
+			}
+			return keep(s)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to Sessions: %w", err)
+	}
+	return list, nil
+}
+
+// GetByID retrieves the persisted Session with the given ID.
+func (ser *SessionService) GetByID(id int, tx db.Tx) (*models.Session, error) {
+	m, err := tx.Database().GetByID(id, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return s, nil
+}
+
+// GetByUser retrieves the persisted Sessions with the given User ID.
+func (ser *SessionService) GetByUser(
+	uID int, first *int, skip *int, tx db.Tx,
+) ([]*models.Session, error) {
+	return ser.GetFilter(first, skip, tx, func(s *models.Session) bool {
+		return s.UserID == uID
+	})
+}
+
+// GetByRefreshToken retrieves the persisted Session with the given current
+// refresh token.
+func (ser *SessionService) GetByRefreshToken(tkn string, tx db.Tx) (*models.Session, error) {
+	hash := jwt.HashOpaqueToken(tkn)
+
+	var s *models.Session
+	_, err := tx.Database().FindFirst(ser, tx, func(m db.Model) (bool, error) {
+		e, err := ser.AssertType(m)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+
+		if e.RefreshTokenHash == hash {
+			s = e
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate through keys: %w", err)
+	}
+
+	if s == nil {
+		return nil, fmt.Errorf("refresh token: %w", errInvalid)
+	}
+	return s, nil
+}
+
+// Bucket returns the name of the bucket for Session.
+func (ser *SessionService) Bucket() string {
+	return "Session"
+}
+
+// Clean cleans the given Session for storage.
+func (ser *SessionService) Clean(m db.Model, _ db.Tx) error {
+	_, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return nil
+}
+
+// Validate returns an error if the Session is not valid for the database.
+func (ser *SessionService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// Check if User with ID specified in Session exists
+	_, err = tx.Database().GetRawByID(e.UserID, ser.UserService, tx)
+	if err != nil {
+		return &ValidationError{"Session", "UserID",
+			fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)}
+	}
+
+	if e.RefreshTokenHash == "" {
+		return &ValidationError{"Session", "RefreshTokenHash",
+			fmt.Errorf("refresh token hash: %w", errNil)}
+	}
+
+	return nil
+}
+
+// Initialize sets initial values for some properties.
+func (ser *SessionService) Initialize(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistOldProperties maintains certain properties of the existing Session
+// in updates.
+func (ser *SessionService) PersistOldProperties(n db.Model, o db.Model, _ db.Tx) error {
+	ns, err := ser.AssertType(n)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	os, err := ser.AssertType(o)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// Sessions may not be moved to another User or reinstated once revoked
+	ns.UserID = os.UserID
+	ns.Revoked = ns.Revoked || os.Revoked
+	return nil
+}
+
+// PersistHooks returns the persistence hook functions.
+func (ser *SessionService) PersistHooks() *db.PersistHooks {
+	return &ser.Hooks
+}
+
+// Marshal transforms the given Session into JSON.
+func (ser *SessionService) Marshal(m db.Model) ([]byte, error) {
+	s, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	v, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONMarshal, err)
+	}
+
+	return v, nil
+}
+
+// Unmarshal parses the given JSON into Session.
+func (ser *SessionService) Unmarshal(buf []byte) (db.Model, error) {
+	var s models.Session
+	err := json.Unmarshal(buf, &s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONUnmarshal, err)
+	}
+	return &s, nil
+}
+
+// AssertType exposes the given db.Model as a Session.
+func (ser *SessionService) AssertType(m db.Model) (*models.Session, error) {
+	if m == nil {
+		return nil, fmt.Errorf("model: %w", errNil)
+	}
+
+	s, ok := m.(*models.Session)
+	if !ok {
+		return nil,
+			fmt.Errorf("model: %w", errors.New("not of Session type"))
+	}
+	return s, nil
+}
+
+// mapfromModel returns a list of Session type asserted from the given list of
+// db.Model.
+func (ser *SessionService) mapFromModel(vlist []db.Model) ([]*models.Session, error) {
+	list := make([]*models.Session, len(vlist))
+	var err error
+	for i, v := range vlist {
+		list[i], err = ser.AssertType(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,109 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/db"
+)
+
+// tokenHashIndex maintains an index bucket that maps the token hashes of the
+// models of a service to their IDs, so that models can be looked up by their
+// token without reading every model. Token hashes may not be changed by
+// updates, so the index is only maintained on creation and deletion.
+type tokenHashIndex struct {
+	// bucket is the name of the index bucket.
+	bucket string
+	ser    db.Service
+	// hash returns the token hash of the given model.
+	hash func(m db.Model) (string, error)
+}
+
+// newTokenHashIndex returns a tokenHashIndex of the models of the given
+// service and adds the hooks that keep it in sync with them to the service.
+func newTokenHashIndex(
+	bucket string, ser db.Service, hash func(m db.Model) (string, error),
+) *tokenHashIndex {
+	idx := &tokenHashIndex{
+		bucket: bucket,
+		ser:    ser,
+		hash:   hash,
+	}
+
+	indexOnCreate := func(m db.Model, _ db.Service, tx db.Tx) error {
+		return idx.index(m, tx)
+	}
+	unindexOnDelete := func(m db.Model, _ db.Service, tx db.Tx) error {
+		return idx.unindex(m, tx)
+	}
+
+	hooks := ser.PersistHooks()
+	hooks.PostCreateHooks = append(hooks.PostCreateHooks, indexOnCreate)
+	hooks.PreDeleteHooks = append(hooks.PreDeleteHooks, unindexOnDelete)
+
+	return idx
+}
+
+// get returns the ID of the model with the given token hash, or 0 if there is
+// none.
+func (idx *tokenHashIndex) get(hash string, tx db.Tx) (int, error) {
+	if hash == "" {
+		return 0, nil
+	}
+
+	id, err := tx.Database().GetIndex(idx.bucket, []byte(hash), tx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get index %q: %w", idx.bucket, err)
+	}
+	return id, nil
+}
+
+// reindex rebuilds the index from the persisted models.
+func (idx *tokenHashIndex) reindex(tx db.Tx) error {
+	err := tx.Database().ClearIndex(idx.bucket, tx)
+	if err != nil {
+		return fmt.Errorf("failed to clear index %q: %w", idx.bucket, err)
+	}
+
+	return tx.Database().DoEach(nil, nil, idx.ser, tx,
+		func(m db.Model, _ db.Service, tx db.Tx) (bool, error) {
+			err := idx.index(m, tx)
+			if err != nil {
+				return true, err
+			}
+			return false, nil
+		}, nil)
+}
+
+// index maps the token hash of the given model to its ID.
+func (idx *tokenHashIndex) index(m db.Model, tx db.Tx) error {
+	hash, err := idx.hash(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	if hash == "" {
+		return nil
+	}
+
+	err = tx.Database().PutIndex(idx.bucket, []byte(hash), m.Metadata().ID, tx)
+	if err != nil {
+		return fmt.Errorf("failed to put index %q: %w", idx.bucket, err)
+	}
+	return nil
+}
+
+// unindex removes the mapping of the token hash of the given model.
+func (idx *tokenHashIndex) unindex(m db.Model, tx db.Tx) error {
+	hash, err := idx.hash(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	if hash == "" {
+		return nil
+	}
+
+	err = tx.Database().DeleteIndex(idx.bucket, []byte(hash), tx)
+	if err != nil {
+		return fmt.Errorf("failed to delete index %q: %w", idx.bucket, err)
+	}
+	return nil
+}
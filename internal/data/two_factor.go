@@ -0,0 +1,226 @@
+package data
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/jwt"
+	"github.com/Dophin2009/nao/internal/totp"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// recoveryCodeCount is the number of recovery codes generated for a User.
+const recoveryCodeCount = 10
+
+var (
+	// errInvalidTwoFactorCode is an error returned when a TOTP or recovery
+	// code does not match.
+	errInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	// errTwoFactorEnabled is an error returned when enrolling a User that
+	// has already enabled two-factor authentication.
+	errTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+	// errTwoFactorDisabled is an error returned when an operation requires
+	// two-factor authentication that has not been enabled or enrolled in.
+	errTwoFactorDisabled = errors.New("two-factor authentication is not enabled")
+)
+
+// RequiresTwoFactor returns true if the given User is assigned a Role that
+// requires two-factor authentication.
+func (ser *UserService) RequiresTwoFactor(u *models.User) bool {
+	for _, r := range u.Roles {
+		if ser.roleRequiresTwoFactor(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ser *UserService) roleRequiresTwoFactor(role models.Role) bool {
+	for _, r := range ser.TwoFactorRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// EnrollTwoFactor generates a new TOTP secret for the User with the given ID,
+// replacing any pending one. Two-factor authentication is not enabled until
+// confirmed with EnableTwoFactor. The secret is returned.
+func (ser *UserService) EnrollTwoFactor(userID int, tx db.Tx) (*models.User, string, error) {
+	u, err := ser.GetByID(userID, tx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get User by ID %d: %w", userID, err)
+	}
+
+	if u.TwoFactor.Enabled {
+		return nil, "", errTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	u.TwoFactor = models.TwoFactor{Secret: secret}
+
+	err = ser.updateTwoFactor(u, tx)
+	if err != nil {
+		return nil, "", err
+	}
+	return u, secret, nil
+}
+
+// EnableTwoFactor enables two-factor authentication for the User with the
+// given ID if the given code matches the enrolled secret. The recovery codes
+// of the User are returned; they cannot be retrieved again.
+func (ser *UserService) EnableTwoFactor(userID int, code string, tx db.Tx) ([]string, error) {
+	u, err := ser.GetByID(userID, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get User by ID %d: %w", userID, err)
+	}
+
+	if u.TwoFactor.Enabled {
+		return nil, errTwoFactorEnabled
+	}
+	if u.TwoFactor.Secret == "" {
+		return nil, fmt.Errorf("not enrolled: %w", errTwoFactorDisabled)
+	}
+
+	counter, ok, err := totp.Validate(u.TwoFactor.Secret, code, time.Now(),
+		u.TwoFactor.LastCounter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate code: %w", err)
+	}
+	if !ok {
+		return nil, errInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	u.TwoFactor.Enabled = true
+	u.TwoFactor.LastCounter = counter
+	u.TwoFactor.RecoveryCodeHashes = hashes
+
+	err = ser.updateTwoFactor(u, tx)
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// VerifyTwoFactor checks the given TOTP or recovery code of the User with the
+// given ID. The code is recorded as used and may not be used again.
+func (ser *UserService) VerifyTwoFactor(userID int, code string, tx db.Tx) error {
+	u, err := ser.GetByID(userID, tx)
+	if err != nil {
+		return fmt.Errorf("failed to get User by ID %d: %w", userID, err)
+	}
+
+	if !u.TwoFactor.Enabled {
+		return errTwoFactorDisabled
+	}
+
+	counter, ok, err := totp.Validate(u.TwoFactor.Secret, code, time.Now(),
+		u.TwoFactor.LastCounter)
+	if err != nil {
+		return fmt.Errorf("failed to validate code: %w", err)
+	}
+	if ok {
+		u.TwoFactor.LastCounter = counter
+		return ser.updateTwoFactor(u, tx)
+	}
+
+	// Recovery codes are removed once used
+	hash := jwt.HashOpaqueToken(normalizeRecoveryCode(code))
+	for i, h := range u.TwoFactor.RecoveryCodeHashes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			hashes := u.TwoFactor.RecoveryCodeHashes
+			u.TwoFactor.RecoveryCodeHashes =
+				append(hashes[:i:i], hashes[i+1:]...)
+			return ser.updateTwoFactor(u, tx)
+		}
+	}
+	return errInvalidTwoFactorCode
+}
+
+// DisableTwoFactor disables two-factor authentication for the User with the
+// given ID if the given TOTP or recovery code matches.
+func (ser *UserService) DisableTwoFactor(userID int, code string, tx db.Tx) error {
+	err := ser.VerifyTwoFactor(userID, code, tx)
+	if err != nil {
+		return err
+	}
+
+	u, err := ser.GetByID(userID, tx)
+	if err != nil {
+		return fmt.Errorf("failed to get User by ID %d: %w", userID, err)
+	}
+	u.TwoFactor = models.TwoFactor{}
+
+	return ser.updateTwoFactor(u, tx)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the User with the
+// given ID if the given TOTP or recovery code matches. The new recovery codes
+// are returned; they cannot be retrieved again.
+func (ser *UserService) RegenerateRecoveryCodes(userID int, code string,
+	tx db.Tx) ([]string, error) {
+	err := ser.VerifyTwoFactor(userID, code, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := ser.GetByID(userID, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get User by ID %d: %w", userID, err)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	u.TwoFactor.RecoveryCodeHashes = hashes
+
+	err = ser.updateTwoFactor(u, tx)
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (ser *UserService) updateTwoFactor(u *models.User, tx db.Tx) error {
+	uw := &userWrap{updatedTwoFactor: true, User: u}
+	return ser.update(uw, tx)
+}
+
+// newRecoveryCodes returns new recovery codes and their hashes.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		_, err := rand.Read(b)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		h := hex.EncodeToString(b)
+		codes[i] = h[:5] + "-" + h[5:]
+		hashes[i] = jwt.HashOpaqueToken(normalizeRecoveryCode(codes[i]))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode returns the given recovery code without separators
+// and in lowercase, so that codes may be entered loosely.
+func normalizeRecoveryCode(code string) string {
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return strings.ToLower(code)
+}
@@ -1,42 +1,112 @@
 package data
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"net/mail"
 	"strings"
 
 	"github.com/Dophin2009/nao/pkg/models"
 	"github.com/Dophin2009/nao/pkg/db"
 	json "github.com/json-iterator/go"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
 )
 
 type userWrap struct {
-	updatedPass bool
+	updatedPass    bool
+	updatedRoles   bool
+	updatedFriends bool
+	// updatedVerified is true if EmailVerified is being set.
+	updatedVerified bool
+	// updatedTwoFactor is true if TwoFactor is being set.
+	updatedTwoFactor bool
+	// changedEmail is set when persisting old properties on update; true if
+	// the email of the User is different from the persisted one.
+	changedEmail bool
+	// newPass is the unhashed new password of the User, checked against the
+	// password policy, if one is being set.
+	newPass *string
 	*models.User
 }
 
+// legacyUserPermission is the representation of User permissions before
+// Roles were introduced, read to migrate persisted Users.
+type legacyUserPermission struct {
+	WriteMedia bool
+	WriteUsers bool
+}
+
 // UserService performs operations on User.
 type UserService struct {
-	Hooks db.PersistHooks
+	// PasswordPolicy is the policy new passwords must meet.
+	PasswordPolicy PasswordPolicy
+	// TwoFactorRoles are the Roles that only grant Permissions to Users that
+	// have enabled two-factor authentication.
+	TwoFactorRoles []models.Role
+	// FirstUserAdmin makes the first User created an admin so that Roles can
+	// be assigned to others. It must not be set if anyone may register.
+	FirstUserAdmin bool
+	Hooks          db.PersistHooks
 }
 
+const (
+	// userUsernameIndex is the name of the index bucket that maps normalized
+	// usernames to User IDs.
+	userUsernameIndex = "UserUsername"
+	// userEmailIndex is the name of the index bucket that maps normalized
+	// emails to User IDs.
+	userEmailIndex = "UserEmail"
+)
+
 // NewUserService returns a UserService.
 func NewUserService(hooks db.PersistHooks) *UserService {
-	return &UserService{
-		Hooks: hooks,
+	userService := &UserService{
+		PasswordPolicy: DefaultPasswordPolicy,
+		Hooks:          hooks,
+	}
+
+	// Keep the username and email indexes in sync with persisted Users
+	indexOnCreate := func(m db.Model, _ db.Service, tx db.Tx) error {
+		return userService.index(m, tx)
+	}
+	unindexOldOnUpdate := func(m db.Model, _ db.Service, tx db.Tx) error {
+		o, err := tx.Database().GetByID(m.Metadata().ID, userService, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get User by ID %d: %w",
+				m.Metadata().ID, err)
+		}
+		return userService.unindex(o, tx)
+	}
+	indexOnUpdate := func(m db.Model, _ db.Service, tx db.Tx) error {
+		return userService.index(m, tx)
+	}
+	unindexOnDelete := func(m db.Model, _ db.Service, tx db.Tx) error {
+		return userService.unindex(m, tx)
 	}
+
+	uSerHooks := userService.PersistHooks()
+	uSerHooks.PostCreateHooks = append(uSerHooks.PostCreateHooks, indexOnCreate)
+	uSerHooks.PreUpdateHooks =
+		append(uSerHooks.PreUpdateHooks, unindexOldOnUpdate)
+	uSerHooks.PostUpdateHooks = append(uSerHooks.PostUpdateHooks, indexOnUpdate)
+	uSerHooks.PreDeleteHooks = append(uSerHooks.PreDeleteHooks, unindexOnDelete)
+
+	return userService
 }
 
 // Create persists the given User.
 func (ser *UserService) Create(u *models.User, tx db.Tx) (int, error) {
-	uw := userWrap{false, u}
+	pass := string(u.Password)
+	uw := userWrap{newPass: &pass, User: u}
 	return tx.Database().Create(&uw, ser, tx)
 }
 
 // Update rulaces the value of the User with the given ID.
 func (ser *UserService) Update(u *models.User, tx db.Tx) error {
-	uw := &userWrap{false, u}
+	uw := &userWrap{User: u}
 	return ser.update(uw, tx)
 }
 
@@ -125,48 +195,344 @@ func (ser *UserService) GetByID(id int, tx db.Tx) (*models.User, error) {
 }
 
 // GetByUsername retrieves a single instance of User with the given username.
+// Usernames are matched case-insensitively after Unicode normalization.
 func (ser *UserService) GetByUsername(username string, tx db.Tx) (*models.User, error) {
-	var e models.User
-	_, err := tx.Database().FindFirst(ser, tx, func(m db.Model) (bool, error) {
-		u, err := ser.AssertType(m)
+	return ser.getByIndex(userUsernameIndex, "username", username, tx)
+}
+
+// GetByEmail retrieves a single instance of User with the given email. Emails
+// are matched case-insensitively after Unicode normalization.
+func (ser *UserService) GetByEmail(email string, tx db.Tx) (*models.User, error) {
+	return ser.getByIndex(userEmailIndex, "email", email, tx)
+}
+
+func (ser *UserService) getByIndex(
+	index string, name string, value string, tx db.Tx,
+) (*models.User, error) {
+	id, err := tx.Database().GetIndex(index, normalizeIdentifier(value), tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s index: %w", name, err)
+	}
+	if id == 0 {
+		return nil, fmt.Errorf("User with %s %q: %w", name, value, errNotFound)
+	}
+
+	return ser.GetByID(id, tx)
+}
+
+// Reindex rebuilds the username and email indexes from the persisted Users.
+// If several Users share a normalized username or email, the one with the
+// lowest ID keeps it.
+func (ser *UserService) Reindex(tx db.Tx) error {
+	for _, index := range ser.IndexBuckets() {
+		err := tx.Database().ClearIndex(index, tx)
 		if err != nil {
-			return false, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+			return fmt.Errorf("failed to clear index %q: %w", index, err)
 		}
+	}
+
+	return tx.Database().DoEach(nil, nil, ser, tx,
+		func(m db.Model, _ db.Service, tx db.Tx) (bool, error) {
+			err := ser.index(m, tx)
+			if err != nil {
+				return true, err
+			}
+			return false, nil
+		}, nil)
+}
 
-		if u.Username == username {
-			e = *u
-			return true, nil
+// IndexBuckets returns the names of the index buckets for User.
+func (ser *UserService) IndexBuckets() []string {
+	return []string{userUsernameIndex, userEmailIndex}
+}
+
+// index maps the username and email of the given User to its ID, unless they
+// are already mapped.
+func (ser *UserService) index(m db.Model, tx db.Tx) error {
+	u, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	for _, idx := range ser.identifiers(u) {
+		key := normalizeIdentifier(idx.value)
+		if len(key) == 0 {
+			continue
 		}
 
-		return false, nil
-	})
+		id, err := tx.Database().GetIndex(idx.index, key, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get index %q: %w", idx.index, err)
+		}
+		if id != 0 {
+			continue
+		}
+
+		err = tx.Database().PutIndex(idx.index, key, u.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to put index %q: %w", idx.index, err)
+		}
+	}
+	return nil
+}
+
+// unindex removes the mappings of the username and email of the given User,
+// if they are mapped to its ID.
+func (ser *UserService) unindex(m db.Model, tx db.Tx) error {
+	u, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	for _, idx := range ser.identifiers(u) {
+		key := normalizeIdentifier(idx.value)
+		if len(key) == 0 {
+			continue
+		}
+
+		id, err := tx.Database().GetIndex(idx.index, key, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get index %q: %w", idx.index, err)
+		}
+		if id != u.Meta.ID {
+			continue
+		}
+
+		err = tx.Database().DeleteIndex(idx.index, key, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete index %q: %w", idx.index, err)
+		}
+	}
+	return nil
+}
+
+// isTaken returns true if the given value is mapped in the index to a User
+// other than the one with the given ID.
+func (ser *UserService) isTaken(
+	index string, value string, userID int, tx db.Tx,
+) (bool, error) {
+	id, err := tx.Database().GetIndex(index, normalizeIdentifier(value), tx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to iterate through keys: %w", err)
+		return false, fmt.Errorf("failed to get index %q: %w", index, err)
 	}
+	return id != 0 && id != userID, nil
+}
+
+type userIdentifier struct {
+	index string
+	value string
+}
+
+func (ser *UserService) identifiers(u *models.User) []userIdentifier {
+	return []userIdentifier{
+		{userUsernameIndex, u.Username},
+		{userEmailIndex, u.Email},
+	}
+}
 
-	return &e, nil
+// GetByRole retrieves the persisted Users that are assigned the given Role.
+func (ser *UserService) GetByRole(
+	role models.Role, first *int, skip *int, tx db.Tx,
+) ([]*models.User, error) {
+	return ser.GetFilter(first, skip, tx, func(u *models.User) bool {
+		for _, r := range u.Roles {
+			if r == role {
+				return true
+			}
+		}
+		return false
+	})
 }
 
-// Authorize checks if the user with the given ID has permissions that meet
-// the requirements.
-func (ser *UserService) Authorize(userID int, req *models.UserPermission, tx db.Tx) (*models.User, error) {
+// Authorize checks if the user with the given ID is assigned a Role that
+// grants the given Permission.
+func (ser *UserService) Authorize(userID int, perm *models.Permission, tx db.Tx) (*models.User, error) {
 	user, err := ser.GetByID(userID, tx)
 	if err != nil {
 		return nil, err
 	}
 
-	if !ser.RequirementsMet(&user.Permissions, req) {
-		return nil, errors.New("insufficient permissions")
+	if !ser.HasPermission(user, perm) {
+		return nil, fmt.Errorf("%s %s: %w", perm.Action, perm.Model, errNotGranted)
 	}
 	return user, nil
 }
 
-// RequirementsMet checks if the given permissions satisfy the required
-// permissions.
-func (ser *UserService) RequirementsMet(
-	perm *models.UserPermission, req *models.UserPermission) bool {
-	return !(req.WriteMedia && !perm.WriteMedia) &&
-		!(req.WriteUsers && !perm.WriteUsers)
+// HasPermission checks if any of the Roles assigned to the given User grant
+// the given Permission. Roles that require two-factor authentication grant
+// nothing until the User enables it.
+func (ser *UserService) HasPermission(u *models.User, perm *models.Permission) bool {
+	for _, r := range u.Roles {
+		if !u.TwoFactor.Enabled && ser.roleRequiresTwoFactor(r) {
+			continue
+		}
+		if RoleGrants(r, *perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssignRole assigns the given Role to the User with the given ID.
+func (ser *UserService) AssignRole(userID int, role models.Role, tx db.Tx) (*models.User, error) {
+	u, err := ser.GetByID(userID, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get User by ID %d: %w", userID, err)
+	}
+
+	for _, r := range u.Roles {
+		if r == role {
+			return u, nil
+		}
+	}
+	u.Roles = append(u.Roles, role)
+
+	uw := &userWrap{updatedRoles: true, User: u}
+	err = ser.update(uw, tx)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// UnassignRole removes the given Role from the User with the given ID.
+func (ser *UserService) UnassignRole(userID int, role models.Role, tx db.Tx) (*models.User, error) {
+	u, err := ser.GetByID(userID, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get User by ID %d: %w", userID, err)
+	}
+
+	roles := []models.Role{}
+	for _, r := range u.Roles {
+		if r != role {
+			roles = append(roles, r)
+		}
+	}
+	u.Roles = roles
+
+	uw := &userWrap{updatedRoles: true, User: u}
+	err = ser.update(uw, tx)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// VerifyEmail marks the email of the User with the given ID as verified, if it
+// is still the given email.
+func (ser *UserService) VerifyEmail(userID int, email string, tx db.Tx) (*models.User, error) {
+	u, err := ser.GetByID(userID, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get User by ID %d: %w", userID, err)
+	}
+
+	if !bytes.Equal(normalizeIdentifier(u.Email), normalizeIdentifier(email)) {
+		return nil, fmt.Errorf("email %q: %w", email,
+			errors.New("is no longer the email of the User"))
+	}
+	u.EmailVerified = true
+
+	uw := &userWrap{updatedVerified: true, User: u}
+	err = ser.update(uw, tx)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// AddFriend adds the User with the given friend ID to the friends of the User
+// with the given ID.
+func (ser *UserService) AddFriend(userID int, friendID int, tx db.Tx) (*models.User, error) {
+	u, err := ser.GetByID(userID, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get User by ID %d: %w", userID, err)
+	}
+
+	for _, f := range u.Friends {
+		if f == friendID {
+			return u, nil
+		}
+	}
+	u.Friends = append(u.Friends, friendID)
+
+	uw := &userWrap{updatedFriends: true, User: u}
+	err = ser.update(uw, tx)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// RemoveFriend removes the User with the given friend ID from the friends of
+// the User with the given ID.
+func (ser *UserService) RemoveFriend(userID int, friendID int, tx db.Tx) (*models.User, error) {
+	u, err := ser.GetByID(userID, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get User by ID %d: %w", userID, err)
+	}
+
+	friends := []int{}
+	for _, f := range u.Friends {
+		if f != friendID {
+			friends = append(friends, f)
+		}
+	}
+	u.Friends = friends
+
+	uw := &userWrap{updatedFriends: true, User: u}
+	err = ser.update(uw, tx)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// CanView checks if the given viewer may view data of the given owner with
+// the given Visibility. The viewer is nil for unauthenticated requests. Users
+// that may update all Users may view all data.
+func (ser *UserService) CanView(
+	owner *models.User, viewer *models.User, vis models.Visibility) bool {
+	if vis == models.VisibilityPublic {
+		return true
+	}
+	if viewer == nil {
+		return false
+	}
+	if viewer.Meta.ID == owner.Meta.ID ||
+		ser.HasPermission(viewer, &models.Permission{
+			Model: "User", Action: models.ActionUpdate}) {
+		return true
+	}
+
+	if vis == models.VisibilityFriends {
+		for _, f := range owner.Friends {
+			if f == viewer.Meta.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (ser *UserService) migrateLegacyPermissions(
+	perm *legacyUserPermission) []models.Role {
+	switch {
+	case perm.WriteUsers:
+		return []models.Role{models.RoleAdmin}
+	case perm.WriteMedia:
+		return []models.Role{models.RoleContributor}
+	}
+	return []models.Role{models.RoleViewer}
+}
+
+// defaultPrivacy sets the privacy settings that are not given to public.
+func (ser *UserService) defaultPrivacy(p *models.UserPrivacy) {
+	if p.Profile == 0 {
+		p.Profile = models.VisibilityPublic
+	}
+	if p.Lists == 0 {
+		p.Lists = models.VisibilityPublic
+	}
 }
 
 // AuthenticateWithPassword checks if the password for the User given by the
@@ -201,7 +567,7 @@ func (ser *UserService) ChangePassword(userID int, password string, tx db.Tx) er
 	}
 	u.Password = pass
 
-	uw := &userWrap{true, u}
+	uw := &userWrap{updatedPass: true, newPass: &password, User: u}
 	err = ser.update(uw, tx)
 	if err != nil {
 		return err
@@ -245,17 +611,80 @@ func (ser *UserService) Validate(m db.Model, tx db.Tx) error {
 	}
 	u := uw.User
 
-	// Check that username does not already exist
-	sameUsername, err := ser.GetByUsername(u.Username, tx)
-	if sameUsername != nil {
-		return fmt.Errorf("username %q: %w", u.Username, errAlreadyExists)
+	if uw.newPass != nil {
+		err := ser.PasswordPolicy.Check(*uw.newPass, u.Username)
+		if err != nil {
+			return &ValidationError{"User", "Password", err}
+		}
+	}
+
+	// Check that username and email are given and not used by another User
+	if len(normalizeIdentifier(u.Username)) == 0 {
+		return &ValidationError{"User", "Username",
+			fmt.Errorf("username: %w", errEmpty)}
+	}
+	taken, err := ser.isTaken(userUsernameIndex, u.Username, u.Meta.ID, tx)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return &ValidationError{"User", "Username",
+			fmt.Errorf("username %q: %w", u.Username, errAlreadyExists)}
+	}
+
+	if !isEmail(strings.TrimSpace(u.Email)) {
+		return &ValidationError{"User", "Email",
+			fmt.Errorf("email %q: %w", u.Email, errInvalid)}
+	}
+	taken, err = ser.isTaken(userEmailIndex, u.Email, u.Meta.ID, tx)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return &ValidationError{"User", "Email",
+			fmt.Errorf("email %q: %w", u.Email, errAlreadyExists)}
+	}
+
+	for _, r := range u.Roles {
+		if !r.IsValid() {
+			return &ValidationError{"User", "Roles",
+				fmt.Errorf("role %d: %w", r, errInvalid)}
+		}
+	}
+
+	// Privacy settings that are not given are defaulted or unchanged
+	if u.Privacy.Profile != 0 && !u.Privacy.Profile.IsValid() {
+		return &ValidationError{"User", "Privacy",
+			fmt.Errorf("profile visibility %d: %w", u.Privacy.Profile, errInvalid)}
+	}
+	if u.Privacy.Lists != 0 && !u.Privacy.Lists.IsValid() {
+		return &ValidationError{"User", "Privacy",
+			fmt.Errorf("lists visibility %d: %w", u.Privacy.Lists, errInvalid)}
+	}
+
+	if u.ScoreSystem != 0 && !u.ScoreSystem.IsValid() {
+		return &ValidationError{"User", "ScoreSystem",
+			fmt.Errorf("%d: %w", u.ScoreSystem, errInvalid)}
+	}
+
+	// Check that friends exist
+	for _, f := range u.Friends {
+		if f == u.Meta.ID {
+			return &ValidationError{"User", "Friends",
+				fmt.Errorf("friend %d: is the User: %w", f, errInvalid)}
+		}
+		_, err := ser.GetByID(f, tx)
+		if err != nil {
+			return &ValidationError{"User", "Friends",
+				fmt.Errorf("failed to get User with ID %d: %w", f, err)}
+		}
 	}
 
 	return nil
 }
 
 // Initialize sets initial values for some properties.
-func (ser *UserService) Initialize(m db.Model, _ db.Tx) error {
+func (ser *UserService) Initialize(m db.Model, tx db.Tx) error {
 	uw, err := ser.assertWrapType(m)
 	if err != nil {
 		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
@@ -266,6 +695,27 @@ func (ser *UserService) Initialize(m db.Model, _ db.Tx) error {
 		return fmt.Errorf("failed to generate password hash: %w", err)
 	}
 	uw.User.Password = pass
+
+	// Users are viewers unless given other Roles; the first User may be an
+	// admin so that Roles can be assigned to others
+	if len(uw.User.Roles) == 0 {
+		uw.User.Roles = []models.Role{models.RoleViewer}
+		if ser.FirstUserAdmin {
+			first := 1
+			existing, err := ser.GetAll(&first, nil, tx)
+			if err != nil {
+				return fmt.Errorf("failed to get Users: %w", err)
+			}
+			if len(existing) == 0 {
+				uw.User.Roles = []models.Role{models.RoleAdmin}
+			}
+		}
+	}
+
+	ser.defaultPrivacy(&uw.User.Privacy)
+	if uw.User.ScoreSystem == 0 {
+		uw.User.ScoreSystem = models.DefaultScoreSystem
+	}
 	return nil
 }
 
@@ -286,6 +736,45 @@ func (ser *UserService) PersistOldProperties(n db.Model, o db.Model, _ db.Tx) er
 	if !nuw.updatedPass {
 		nuw.User.Password = ouw.User.Password
 	}
+
+	// Roles may not be changed directly through update; must use AssignRole
+	// or UnassignRole
+	if !nuw.updatedRoles {
+		nuw.User.Roles = ouw.User.Roles
+	}
+
+	// Friends may not be changed directly through update; must use AddFriend
+	// or RemoveFriend
+	if !nuw.updatedFriends {
+		nuw.User.Friends = ouw.User.Friends
+	}
+
+	// TwoFactor may not be changed directly through update; must use
+	// EnrollTwoFactor, EnableTwoFactor, or DisableTwoFactor
+	if !nuw.updatedTwoFactor {
+		nuw.User.TwoFactor = ouw.User.TwoFactor
+	}
+
+	// EmailVerified may not be changed directly through update; must use
+	// VerifyEmail. A changed email must be verified again.
+	nuw.changedEmail = !bytes.Equal(normalizeIdentifier(nuw.User.Email),
+		normalizeIdentifier(ouw.User.Email))
+	if !nuw.updatedVerified {
+		nuw.User.EmailVerified = ouw.User.EmailVerified && !nuw.changedEmail
+	}
+
+	// Privacy settings that are not given are not changed
+	if nuw.User.Privacy.Profile == 0 {
+		nuw.User.Privacy.Profile = ouw.User.Privacy.Profile
+	}
+	if nuw.User.Privacy.Lists == 0 {
+		nuw.User.Privacy.Lists = ouw.User.Privacy.Lists
+	}
+
+	// The ScoreSystem is not changed if not given
+	if nuw.User.ScoreSystem == 0 {
+		nuw.User.ScoreSystem = ouw.User.ScoreSystem
+	}
 	return nil
 }
 
@@ -316,7 +805,18 @@ func (ser *UserService) Unmarshal(buf []byte) (db.Model, error) {
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", errmsgJSONUnmarshal, err)
 	}
-	return &userWrap{false, &u}, nil
+	// Migrate Users persisted with permission flags instead of Roles
+	if len(u.Roles) == 0 {
+		var legacy struct{ Permissions *legacyUserPermission }
+		err = json.Unmarshal(buf, &legacy)
+		if err == nil && legacy.Permissions != nil {
+			u.Roles = ser.migrateLegacyPermissions(legacy.Permissions)
+		}
+	}
+	// Users persisted before privacy settings were introduced are public
+	ser.defaultPrivacy(&u.Privacy)
+
+	return &userWrap{User: &u}, nil
 }
 
 func (ser *UserService) assertWrapType(m db.Model) (*userWrap, error) {
@@ -361,3 +861,27 @@ func (ser *UserService) mapFromModel(vlist []db.Model) ([]*models.User, error) {
 	}
 	return list, nil
 }
+
+// NormalizeIdentifier returns the form of the given username or email that
+// Users are looked up by, so that spellings of it that identify the same User
+// are the same.
+func NormalizeIdentifier(v string) string {
+	return string(normalizeIdentifier(v))
+}
+
+// normalizeIdentifier returns the key under which the given username or email
+// is indexed: surrounding whitespace is trimmed, compatibility-equivalent
+// Unicode sequences are composed, and case is folded.
+func normalizeIdentifier(v string) []byte {
+	v = norm.NFKC.String(strings.TrimSpace(v))
+	return []byte(norm.NFKC.String(cases.Fold().String(v)))
+}
+
+// isEmail returns true if the given string is a bare email address.
+func isEmail(v string) bool {
+	addr, err := mail.ParseAddress(v)
+	if err != nil {
+		return false
+	}
+	return addr.Name == "" && addr.Address == v
+}
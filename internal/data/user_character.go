@@ -214,18 +214,19 @@ func (ser *UserCharacterService) Validate(m db.Model, tx db.Tx) error {
 	// Get User bucket, exit if error
 	_, err = db.GetRawByID(e.UserID, ser.UserService, tx)
 	if err != nil {
-		return fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)
+		return &ValidationError{"UserCharacter", "UserID",
+			fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)}
 	}
 
 	// Check if Character with ID specified in UserCharacter exists
 	// Get Character bucket, exit if error
 	_, err = db.GetRawByID(e.CharacterID, ser.CharacterService, tx)
 	if err != nil {
-		return fmt.Errorf(
-			"failed to get Character with ID %d: %w", e.CharacterID, err)
+		return &ValidationError{"UserCharacter", "CharacterID",
+			fmt.Errorf("failed to get Character with ID %d: %w", e.CharacterID, err)}
 	}
 
-	return nil
+	return validateScore("UserCharacter", e.Score)
 }
 
 // Initialize sets initial values for some properties.
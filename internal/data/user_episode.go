@@ -214,17 +214,18 @@ func (ser *UserEpisodeService) Validate(m db.Model, tx db.Tx) error {
 	// Check if User with ID specified in UserEpisode exists
 	_, err = db.GetRawByID(e.UserID, ser.UserService, tx)
 	if err != nil {
-		return fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)
+		return &ValidationError{"UserEpisode", "UserID",
+			fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)}
 	}
 
 	// Check if Episode with ID specified in UserEpisode exists
 	_, err = db.GetRawByID(e.EpisodeID, ser.EpisodeService, tx)
 	if err != nil {
-		return fmt.Errorf(
-			"failed to get Episode with ID %d: %w", e.EpisodeID, err)
+		return &ValidationError{"UserEpisode", "EpisodeID",
+			fmt.Errorf("failed to get Episode with ID %d: %w", e.EpisodeID, err)}
 	}
 
-	return nil
+	return validateScore("UserEpisode", e.Score)
 }
 
 // Initialize sets initial values for some properties.
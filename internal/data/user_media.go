@@ -3,6 +3,7 @@ package data
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Dophin2009/nao/pkg/models"
 	"github.com/Dophin2009/nao/pkg/db"
@@ -213,27 +214,60 @@ func (ser *UserMediaService) Validate(m db.Model, tx db.Tx) error {
 	// Check if User with ID specified in UserMedia exists
 	_, err = db.GetRawByID(e.UserID, ser.UserService, tx)
 	if err != nil {
-		return fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)
+		return &ValidationError{"UserMedia", "UserID",
+			fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)}
 	}
 
 	// Check if Media with ID specified in MediaCharacter exists
 	_, err = db.GetRawByID(e.MediaID, ser.MediaService, tx)
 	if err != nil {
-		return fmt.Errorf("failed to get Media with ID %d: %w", e.MediaID, err)
+		return &ValidationError{"UserMedia", "MediaID",
+			fmt.Errorf("failed to get Media with ID %d: %w", e.MediaID, err)}
 	}
 
-	return nil
+	err = validateScore("UserMedia", e.Score)
+	if err != nil {
+		return err
+	}
+
+	return validateWatchInstances(e)
 }
 
 // Initialize sets initial values for some properties.
-func (ser *UserMediaService) Initialize(_ db.Model, _ db.Tx) error {
-	return nil
+func (ser *UserMediaService) Initialize(m db.Model, _ db.Tx) error {
+	um, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	um.StatusHistory = nil
+	applyWatchStatus(um, nil, time.Now())
+	return validateWatchInstances(um)
 }
 
 // PersistOldProperties maintains certain properties of the existing UserMedia
-// in updates.
-func (ser *UserMediaService) PersistOldProperties(_ db.Model, _ db.Model, _ db.Tx) error {
-	return nil
+// in updates. The WatchStatus may only change along the allowed transitions,
+// which are recorded in the StatusHistory.
+func (ser *UserMediaService) PersistOldProperties(n db.Model, o db.Model, _ db.Tx) error {
+	um, err := ser.AssertType(n)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	oum, err := ser.AssertType(o)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	if !canTransition(oum.Status, um.Status) {
+		return &ValidationError{"UserMedia", "Status",
+			fmt.Errorf("from %s to %s: %w", oum.Status, um.Status, errInvalid)}
+	}
+
+	um.StatusHistory = oum.StatusHistory
+	applyWatchStatus(um, oum.Status, time.Now())
+	// The changed WatchedInstances are validated again, as Validate is called
+	// before they are changed
+	return validateWatchInstances(um)
 }
 
 // PersistHooks returns the persistence hook functions.
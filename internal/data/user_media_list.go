@@ -51,9 +51,9 @@ func NewUserMediaListService(hooks db.PersistHooks, userService *UserService,
 
 				// Find ID of UserMedia to be deleted in the list
 				rmID := -1
-				for _, id := range uml.UserMedia {
+				for i, id := range uml.UserMedia {
 					if id == umID {
-						rmID = id
+						rmID = i
 						break
 					}
 				}
@@ -185,6 +185,15 @@ func (ser *UserMediaListService) GetByID(id int, tx db.Tx) (*models.UserMediaLis
 	return uml, nil
 }
 
+// GetByUser retrieves the persisted UserMediaLists with the given User ID.
+func (ser *UserMediaListService) GetByUser(
+	uID int, first *int, skip *int, tx db.Tx,
+) ([]*models.UserMediaList, error) {
+	return ser.GetFilter(first, skip, tx, func(uml *models.UserMediaList) bool {
+		return uml.UserID == uID
+	})
+}
+
 // Bucket returns the name of the bucket for UserMediaList.
 func (ser *UserMediaListService) Bucket() string {
 	return "UserMediaList"
@@ -212,14 +221,16 @@ func (ser *UserMediaListService) Validate(m db.Model, tx db.Tx) error {
 	// Check if User with ID specified in UserMediaList exists
 	_, err = db.GetRawByID(e.UserID, ser.UserService, tx)
 	if err != nil {
-		return fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)
+		return &ValidationError{"UserMediaList", "UserID",
+			fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)}
 	}
 
 	// Check if UserMedia with IDs specified in UserMediaList exist
 	for _, umID := range e.UserMedia {
 		_, err = db.GetRawByID(umID, ser.UserMediaService, tx)
 		if err != nil {
-			return fmt.Errorf("failed to get UserMedia with ID %d: %w", umID, err)
+			return &ValidationError{"UserMediaList", "UserMedia",
+				fmt.Errorf("failed to get UserMedia with ID %d: %w", umID, err)}
 		}
 	}
 
@@ -213,17 +213,18 @@ func (ser *UserPersonService) Validate(m db.Model, tx db.Tx) error {
 	// Check if User with ID specified in UserPerson exists
 	_, err = db.GetRawByID(e.UserID, ser.UserService, tx)
 	if err != nil {
-		return fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)
+		return &ValidationError{"UserPerson", "UserID",
+			fmt.Errorf("failed to get User with ID %d: %w", e.UserID, err)}
 	}
 
 	// Check if Person with ID specified in UserPerson exists
 	_, err = db.GetRawByID(e.PersonID, ser.PersonService, tx)
 	if err != nil {
-		return fmt.Errorf(
-			"failed to get Person with ID %d: %w", e.PersonID, err)
+		return &ValidationError{"UserPerson", "PersonID",
+			fmt.Errorf("failed to get Person with ID %d: %w", e.PersonID, err)}
 	}
 
-	return nil
+	return validateScore("UserPerson", e.Score)
 }
 
 // Initialize sets initial values for some properties.
@@ -0,0 +1,260 @@
+package data
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// newTestDatabase returns a database in a new temporary directory with the
+// buckets of the given services, and a function that closes and removes it.
+func newTestDatabase(t *testing.T, services ...db.Service) (*db.DatabaseService,
+	func()) {
+	dir, err := ioutil.TempDir("", "nao-data")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+
+	var buckets []string
+	for _, ser := range services {
+		buckets = append(buckets, ser.Bucket())
+		if indexed, ok := ser.(interface{ IndexBuckets() []string }); ok {
+			buckets = append(buckets, indexed.IndexBuckets()...)
+		}
+	}
+	driver, err := db.ConnectBoltDatabase(&db.BoltDatabaseConfig{
+		Path:     filepath.Join(dir, "db"),
+		FileMode: 0600,
+		Buckets:  buckets,
+	})
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	database := &db.DatabaseService{DatabaseDriver: driver}
+	return database, func() {
+		database.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// validationField returns the Field of the given ValidationError, or an
+// empty string if it is not one.
+func validationField(err error) string {
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		return ""
+	}
+	return verr.Field
+}
+
+func TestUserUniqueness(t *testing.T) {
+	ser := NewUserService(db.PersistHooks{})
+	database, cleanup := newTestDatabase(t, ser)
+	defer cleanup()
+
+	create := func(username string, email string) error {
+		return database.Transaction(true, func(tx db.Tx) error {
+			_, err := ser.Create(&models.User{
+				Username: username,
+				Email:    email,
+				Password: []byte("correct horse battery"),
+			}, tx)
+			return err
+		})
+	}
+
+	err := create("Alice", "Alice@example.com")
+	if err != nil {
+		t.Fatalf("failed to create User: %v", err)
+	}
+
+	cases := []struct {
+		username, email string
+		field           string
+	}{
+		{"alice", "other@example.com", "Username"},
+		{" ALICE ", "other@example.com", "Username"},
+		// Fullwidth letters are compatibility-equivalent to ASCII ones
+		{"Ａｌｉｃｅ", "other@example.com", "Username"},
+		{"bob", "alice@EXAMPLE.com", "Email"},
+		{"bob", "not an email", "Email"},
+		{"", "other@example.com", "Username"},
+	}
+	for _, c := range cases {
+		err = create(c.username, c.email)
+		if f := validationField(err); f != c.field {
+			t.Errorf("expected %s error creating %q <%s>, got %v", c.field,
+				c.username, c.email, err)
+		}
+	}
+
+	// Usernames and emails are unique separately
+	err = create("alice@example.com", "bob@example.com")
+	if err != nil {
+		t.Errorf("expected email-shaped username to be allowed, got %v", err)
+	}
+
+	err = database.Transaction(false, func(tx db.Tx) error {
+		u, err := ser.GetByUsername("ａｌｉｃｅ", tx)
+		if err != nil {
+			return err
+		}
+		if u.Username != "Alice" {
+			t.Errorf("expected Alice, got %q", u.Username)
+		}
+		u, err = ser.GetByEmail("ALICE@example.com", tx)
+		if err != nil {
+			return err
+		}
+		if u.Username != "Alice" {
+			t.Errorf("expected Alice by email, got %q", u.Username)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to get User: %v", err)
+	}
+}
+
+func TestUserUpdateIndex(t *testing.T) {
+	ser := NewUserService(db.PersistHooks{})
+	database, cleanup := newTestDatabase(t, ser)
+	defer cleanup()
+
+	var id int
+	err := database.Transaction(true, func(tx db.Tx) error {
+		var err error
+		id, err = ser.Create(&models.User{
+			Username: "Alice",
+			Email:    "alice@example.com",
+			Password: []byte("correct horse battery"),
+		}, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to create User: %v", err)
+	}
+
+	update := func(username string) error {
+		return database.Transaction(true, func(tx db.Tx) error {
+			u, err := ser.GetByID(id, tx)
+			if err != nil {
+				return err
+			}
+			u.Username = username
+			return ser.Update(u, tx)
+		})
+	}
+
+	// A User does not conflict with its own username
+	err = update("ALICE")
+	if err != nil {
+		t.Fatalf("failed to update User with own username: %v", err)
+	}
+
+	// Renaming frees the old username
+	err = update("Alicia")
+	if err != nil {
+		t.Fatalf("failed to rename User: %v", err)
+	}
+	err = database.Transaction(true, func(tx db.Tx) error {
+		_, err := ser.GetByUsername("alice", tx)
+		if !errors.Is(err, errNotFound) {
+			t.Errorf("expected old username not found, got %v", err)
+		}
+		_, err = ser.Create(&models.User{
+			Username: "alice",
+			Email:    "other@example.com",
+			Password: []byte("correct horse battery"),
+		}, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to create User with freed username: %v", err)
+	}
+
+	// Reindexing restores the same mappings
+	err = database.Transaction(true, func(tx db.Tx) error {
+		err := ser.Reindex(tx)
+		if err != nil {
+			return err
+		}
+		u, err := ser.GetByUsername("ALICIA", tx)
+		if err != nil {
+			return err
+		}
+		if u.Meta.ID != id {
+			t.Errorf("expected User %d, got %d", id, u.Meta.ID)
+		}
+		u, err = ser.GetByUsername("alice", tx)
+		if err != nil {
+			return err
+		}
+		if u.Meta.ID == id {
+			t.Errorf("expected new User by old username, got %d", id)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to reindex: %v", err)
+	}
+}
+
+func TestNormalizeIdentifier(t *testing.T) {
+	for _, v := range []string{"Admin", " admin ", "ＡＤＭＩＮ", "ａｄｍｉｎ"} {
+		if n := NormalizeIdentifier(v); n != "admin" {
+			t.Errorf("expected %q to normalize to %q, got %q", v, "admin", n)
+		}
+	}
+}
+
+func TestUserFirstUserAdmin(t *testing.T) {
+	for _, firstUserAdmin := range []bool{false, true} {
+		ser := NewUserService(db.PersistHooks{})
+		ser.FirstUserAdmin = firstUserAdmin
+		database, cleanup := newTestDatabase(t, ser)
+
+		var roles [][]models.Role
+		for _, username := range []string{"alice", "bob"} {
+			err := database.Transaction(true, func(tx db.Tx) error {
+				id, err := ser.Create(&models.User{
+					Username: username,
+					Email:    username + "@example.com",
+					Password: []byte("correct horse battery"),
+				}, tx)
+				if err != nil {
+					return err
+				}
+				u, err := ser.GetByID(id, tx)
+				if err != nil {
+					return err
+				}
+				roles = append(roles, u.Roles)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("failed to create User %q: %v", username, err)
+			}
+		}
+		cleanup()
+
+		first := models.RoleViewer
+		if firstUserAdmin {
+			first = models.RoleAdmin
+		}
+		if len(roles[0]) != 1 || roles[0][0] != first {
+			t.Errorf("FirstUserAdmin %t: first User has Roles %v, want [%v]",
+				firstUserAdmin, roles[0], first)
+		}
+		if len(roles[1]) != 1 || roles[1][0] != models.RoleViewer {
+			t.Errorf("FirstUserAdmin %t: second User has Roles %v, want [%v]",
+				firstUserAdmin, roles[1], models.RoleViewer)
+		}
+	}
+}
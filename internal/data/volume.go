@@ -0,0 +1,247 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+// VolumeService performs operations on Volumes.
+type VolumeService struct {
+	MediaService *MediaService
+	Hooks        db.PersistHooks
+}
+
+// NewVolumeService returns a VolumeService.
+func NewVolumeService(hooks db.PersistHooks,
+	mediaService *MediaService) *VolumeService {
+	volumeService := &VolumeService{
+		MediaService: mediaService,
+		Hooks:        hooks,
+	}
+
+	// Add hook to delete Volumes on Media deletion
+	deleteVolumeOnDeleteMedia := func(mdm db.Model, _ db.Service, tx db.Tx) error {
+		mID := mdm.Metadata().ID
+		err := volumeService.DeleteByMedia(mID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Volumes by Media ID %d: %w", mID, err)
+		}
+		return nil
+	}
+	mdSerHooks := mediaService.PersistHooks()
+	mdSerHooks.PreDeleteHooks =
+		append(mdSerHooks.PreDeleteHooks, deleteVolumeOnDeleteMedia)
+
+	return volumeService
+}
+
+// Create persists the given Volume.
+func (ser *VolumeService) Create(v *models.Volume, tx db.Tx) (int, error) {
+	return tx.Database().Create(v, ser, tx)
+}
+
+// Update replaces the value of the Volume with the given ID.
+func (ser *VolumeService) Update(v *models.Volume, tx db.Tx) error {
+	return tx.Database().Update(v, ser, tx)
+}
+
+// Delete deletes the Volume with the given ID.
+func (ser *VolumeService) Delete(id int, tx db.Tx) error {
+	return tx.Database().Delete(id, ser, tx)
+}
+
+// DeleteByMedia deletes the Volumes with the given Media ID.
+func (ser *VolumeService) DeleteByMedia(mID int, tx db.Tx) error {
+	return tx.Database().DeleteFilter(ser, tx, func(m db.Model) bool {
+		v, err := ser.AssertType(m)
+		if err != nil {
+			return false
+		}
+		return v.MediaID == mID
+	})
+}
+
+// GetAll retrieves all persisted values of Volume.
+func (ser *VolumeService) GetAll(first *int, skip *int, tx db.Tx) ([]*models.Volume, error) {
+	vlist, err := tx.Database().GetAll(first, skip, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to Volumes: %w", err)
+	}
+	return list, nil
+}
+
+// GetFilter retrieves all persisted values of Volume that pass the filter.
+func (ser *VolumeService) GetFilter(
+	first *int, skip *int, tx db.Tx, keep func(v *models.Volume) bool,
+) ([]*models.Volume, error) {
+	vlist, err := tx.Database().GetFilter(first, skip, ser, tx,
+		func(m db.Model) bool {
+			v, err := ser.AssertType(m)
+			if err != nil {
+				return false
+			}
+			return keep(v)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ser.mapFromModel(vlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map db.Models to Volumes: %w", err)
+	}
+	return list, nil
+}
+
+// GetByID retrieves the persisted Volume with the given ID.
+func (ser *VolumeService) GetByID(id int, tx db.Tx) (*models.Volume, error) {
+	m, err := tx.Database().GetByID(id, ser, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+	return v, nil
+}
+
+// GetByMedia retrieves the Volumes with the given Media ID, ordered by
+// number.
+func (ser *VolumeService) GetByMedia(mID int, tx db.Tx) ([]*models.Volume, error) {
+	list, err := ser.GetFilter(nil, nil, tx, func(v *models.Volume) bool {
+		return v.MediaID == mID
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Number < list[j].Number
+	})
+	return list, nil
+}
+
+// Bucket returns the name of the bucket for Volume.
+func (ser *VolumeService) Bucket() string {
+	return "Volume"
+}
+
+// Clean cleans the given Volume for storage.
+func (ser *VolumeService) Clean(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// Validate returns an error if the Volume is not valid for the database.
+func (ser *VolumeService) Validate(m db.Model, tx db.Tx) error {
+	e, err := ser.AssertType(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	// Check if Media with ID specified in new Volume exists
+	_, err = tx.Database().GetRawByID(e.MediaID, ser.MediaService, tx)
+	if err != nil {
+		return &ValidationError{"Volume", "MediaID",
+			fmt.Errorf("failed to get Media with ID %d: %w", e.MediaID, err)}
+	}
+
+	if e.Number < 0 {
+		return &ValidationError{"Volume", "Number",
+			fmt.Errorf("negative number %d: %w", e.Number, errInvalid)}
+	}
+
+	// Check that no other Volume of the Media has the same number
+	others, err := ser.GetFilter(nil, nil, tx, func(v *models.Volume) bool {
+		return v.MediaID == e.MediaID && v.Number == e.Number &&
+			v.Meta.ID != e.Meta.ID
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get Volumes by Media ID %d: %w",
+			e.MediaID, err)
+	}
+	if len(others) > 0 {
+		return &ValidationError{"Volume", "Number",
+			fmt.Errorf("number %d: %w", e.Number, errAlreadyExists)}
+	}
+	return nil
+}
+
+// Initialize sets initial values for some properties.
+func (ser *VolumeService) Initialize(_ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistOldProperties maintains certain properties of the existing Volume in
+// updates.
+func (ser *VolumeService) PersistOldProperties(_ db.Model, _ db.Model, _ db.Tx) error {
+	return nil
+}
+
+// PersistHooks returns the persistence hook functions.
+func (ser *VolumeService) PersistHooks() *db.PersistHooks {
+	return &ser.Hooks
+}
+
+// Marshal transforms the given Volume into JSON.
+func (ser *VolumeService) Marshal(m db.Model) ([]byte, error) {
+	v, err := ser.AssertType(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+	}
+
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONMarshal, err)
+	}
+
+	return buf, nil
+}
+
+// Unmarshal parses the given JSON into Volume.
+func (ser *VolumeService) Unmarshal(buf []byte) (db.Model, error) {
+	var v models.Volume
+	err := json.Unmarshal(buf, &v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errmsgJSONUnmarshal, err)
+	}
+	return &v, nil
+}
+
+// AssertType exposes the given db.Model as a Volume.
+func (ser *VolumeService) AssertType(m db.Model) (*models.Volume, error) {
+	if m == nil {
+		return nil, fmt.Errorf("model: %w", errNil)
+	}
+
+	v, ok := m.(*models.Volume)
+	if !ok {
+		return nil, fmt.Errorf("model: %w", errors.New("not of Volume type"))
+	}
+	return v, nil
+}
+
+// mapfromModel returns a list of Volume type asserted from the given list of
+// db.Model.
+func (ser *VolumeService) mapFromModel(vlist []db.Model) ([]*models.Volume, error) {
+	list := make([]*models.Volume, len(vlist))
+	var err error
+	for i, v := range vlist {
+		list[i], err = ser.AssertType(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errmsgModelAssertType, err)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,154 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// watchStatusTransitions maps each WatchStatus to those that UserMedia may
+// change to from it. Moving from Completed to Current begins a rewatch.
+var watchStatusTransitions = map[models.WatchStatus][]models.WatchStatus{
+	models.WatchStatusPlanning: {
+		models.WatchStatusCurrent, models.WatchStatusDropped,
+	},
+	models.WatchStatusCurrent: {
+		models.WatchStatusCompleted, models.WatchStatusDropped,
+		models.WatchStatusHold,
+	},
+	models.WatchStatusHold: {
+		models.WatchStatusCurrent, models.WatchStatusCompleted,
+		models.WatchStatusDropped,
+	},
+	models.WatchStatusDropped: {
+		models.WatchStatusCurrent, models.WatchStatusPlanning,
+	},
+	models.WatchStatusCompleted: {
+		models.WatchStatusCurrent,
+	},
+}
+
+// canTransition returns true if UserMedia may change from the first
+// WatchStatus to the second. UserMedia that have never been given a
+// WatchStatus may be given any, but a WatchStatus may not be cleared, so that
+// the transitions cannot be bypassed through none.
+func canTransition(from *models.WatchStatus, to *models.WatchStatus) bool {
+	if from == nil {
+		return true
+	}
+	if to == nil {
+		return false
+	}
+	if *from == *to {
+		return true
+	}
+	for _, s := range watchStatusTransitions[*from] {
+		if s == *to {
+			return true
+		}
+	}
+	return false
+}
+
+// watching returns true if the given WatchStatus is one that UserMedia has
+// an ongoing WatchedInstance in.
+func watching(s *models.WatchStatus) bool {
+	return s != nil &&
+		(*s == models.WatchStatusCurrent || *s == models.WatchStatusHold)
+}
+
+// applyWatchStatus records the change of the WatchStatus of the given
+// UserMedia from the given one, if any, and brings its WatchedInstances in
+// line with the new one: a watch is started if the UserMedia is being watched
+// without one ongoing, and the ongoing watch is ended otherwise. Dates are
+// set to the given time, but never so that a watch ends before it starts.
+func applyWatchStatus(um *models.UserMedia, from *models.WatchStatus,
+	now time.Time) {
+	changed := (from == nil) != (um.Status == nil) ||
+		(from != nil && *from != *um.Status)
+	if changed {
+		um.StatusHistory = append(um.StatusHistory, models.StatusChange{
+			From: from,
+			To:   um.Status,
+			At:   now,
+		})
+	}
+
+	ongoing := -1
+	for i, w := range um.WatchInstances {
+		if w.Ongoing {
+			ongoing = i
+			break
+		}
+	}
+
+	if watching(um.Status) {
+		if ongoing < 0 {
+			um.WatchInstances = append(um.WatchInstances,
+				models.WatchedInstance{Ongoing: true, StartDate: &now})
+			return
+		}
+		w := &um.WatchInstances[ongoing]
+		if w.StartDate == nil {
+			start := now
+			if w.EndDate != nil && w.EndDate.Before(start) {
+				start = *w.EndDate
+			}
+			w.StartDate = &start
+		}
+		return
+	}
+
+	if ongoing >= 0 {
+		w := &um.WatchInstances[ongoing]
+		w.Ongoing = false
+		if w.EndDate == nil {
+			end := now
+			if w.StartDate != nil && w.StartDate.After(end) {
+				end = *w.StartDate
+			}
+			w.EndDate = &end
+		}
+	} else if changed && um.Status != nil &&
+		*um.Status == models.WatchStatusCompleted && len(um.WatchInstances) == 0 {
+		// Completing without any watches still counts as one
+		um.WatchInstances = append(um.WatchInstances,
+			models.WatchedInstance{EndDate: &now})
+	}
+}
+
+// validateWatchInstances returns an error if the WatchedInstances of the given
+// UserMedia are inconsistent with each other.
+func validateWatchInstances(um *models.UserMedia) error {
+	if um.Status != nil && !um.Status.IsValid() {
+		return &ValidationError{"UserMedia", "Status",
+			fmt.Errorf("%d: %w", int(*um.Status), errInvalid)}
+	}
+
+	ongoing := 0
+	for i, w := range um.WatchInstances {
+		if w.Episodes < 0 {
+			return &ValidationError{"UserMedia", "WatchInstances",
+				fmt.Errorf("instance %d: negative episodes: %w", i, errInvalid)}
+		}
+		if w.Chapters < 0 || w.Volumes < 0 {
+			return &ValidationError{"UserMedia", "WatchInstances",
+				fmt.Errorf("instance %d: negative chapters or volumes: %w", i,
+					errInvalid)}
+		}
+		if w.StartDate != nil && w.EndDate != nil &&
+			w.EndDate.Before(*w.StartDate) {
+			return &ValidationError{"UserMedia", "WatchInstances",
+				fmt.Errorf("instance %d: ends before start: %w", i, errInvalid)}
+		}
+		if w.Ongoing {
+			ongoing++
+		}
+	}
+	if ongoing > 1 {
+		return &ValidationError{"UserMedia", "WatchInstances",
+			fmt.Errorf("%d ongoing instances: %w", ongoing, errInvalid)}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func TestCanTransition(t *testing.T) {
+	status := func(s models.WatchStatus) *models.WatchStatus { return &s }
+	cases := []struct {
+		from, to *models.WatchStatus
+		expected bool
+	}{
+		{nil, status(models.WatchStatusCompleted), true},
+		{status(models.WatchStatusDropped), nil, false},
+		{status(models.WatchStatusCompleted), nil, false},
+		{status(models.WatchStatusHold), status(models.WatchStatusHold), true},
+		{status(models.WatchStatusPlanning), status(models.WatchStatusCurrent), true},
+		{status(models.WatchStatusPlanning), status(models.WatchStatusCompleted), false},
+		{status(models.WatchStatusPlanning), status(models.WatchStatusHold), false},
+		{status(models.WatchStatusCurrent), status(models.WatchStatusCompleted), true},
+		{status(models.WatchStatusCurrent), status(models.WatchStatusPlanning), false},
+		{status(models.WatchStatusHold), status(models.WatchStatusCompleted), true},
+		{status(models.WatchStatusDropped), status(models.WatchStatusPlanning), true},
+		{status(models.WatchStatusDropped), status(models.WatchStatusCompleted), false},
+		{status(models.WatchStatusCompleted), status(models.WatchStatusCurrent), true},
+		{status(models.WatchStatusCompleted), status(models.WatchStatusDropped), false},
+	}
+	for _, c := range cases {
+		if actual := canTransition(c.from, c.to); actual != c.expected {
+			t.Errorf("expected transition from %v to %v allowed to be %t",
+				c.from, c.to, c.expected)
+		}
+	}
+}
+
+func TestApplyWatchStatus(t *testing.T) {
+	status := func(s models.WatchStatus) *models.WatchStatus { return &s }
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// Starting to watch starts a watch
+	um := models.UserMedia{Status: status(models.WatchStatusCurrent)}
+	applyWatchStatus(&um, status(models.WatchStatusPlanning), now)
+	if len(um.WatchInstances) != 1 || !um.WatchInstances[0].Ongoing ||
+		um.WatchInstances[0].StartDate == nil ||
+		!um.WatchInstances[0].StartDate.Equal(now) {
+		t.Errorf("expected watch started now, got %+v", um.WatchInstances)
+	}
+	if len(um.StatusHistory) != 1 || *um.StatusHistory[0].From !=
+		models.WatchStatusPlanning || !um.StatusHistory[0].At.Equal(now) {
+		t.Errorf("unexpected status history %+v", um.StatusHistory)
+	}
+
+	// Completing ends the ongoing watch, keeping its start
+	um = models.UserMedia{
+		Status: status(models.WatchStatusCompleted),
+		WatchInstances: []models.WatchedInstance{
+			{Ongoing: true, StartDate: &start}},
+	}
+	applyWatchStatus(&um, status(models.WatchStatusCurrent), now)
+	if len(um.WatchInstances) != 1 || um.WatchInstances[0].Ongoing ||
+		!um.WatchInstances[0].StartDate.Equal(start) ||
+		um.WatchInstances[0].EndDate == nil ||
+		!um.WatchInstances[0].EndDate.Equal(now) {
+		t.Errorf("expected watch ended now, got %+v", um.WatchInstances)
+	}
+
+	// Completing without any watches counts as one
+	um = models.UserMedia{Status: status(models.WatchStatusCompleted)}
+	applyWatchStatus(&um, nil, now)
+	if len(um.WatchInstances) != 1 || um.WatchInstances[0].Ongoing ||
+		um.WatchInstances[0].StartDate != nil ||
+		!um.WatchInstances[0].EndDate.Equal(now) {
+		t.Errorf("expected completed watch, got %+v", um.WatchInstances)
+	}
+
+	// Completing with finished watches does not add another
+	um = models.UserMedia{
+		Status: status(models.WatchStatusCompleted),
+		WatchInstances: []models.WatchedInstance{
+			{StartDate: &start, EndDate: &start}},
+	}
+	applyWatchStatus(&um, status(models.WatchStatusHold), now)
+	if len(um.WatchInstances) != 1 || !um.WatchInstances[0].EndDate.Equal(start) {
+		t.Errorf("expected existing watch only, got %+v", um.WatchInstances)
+	}
+
+	// Rewatching starts a new watch after the completed one
+	um.Status = status(models.WatchStatusCurrent)
+	applyWatchStatus(&um, status(models.WatchStatusCompleted), now)
+	if len(um.WatchInstances) != 2 || um.WatchInstances[0].Ongoing ||
+		!um.WatchInstances[1].Ongoing ||
+		!um.WatchInstances[1].StartDate.Equal(now) {
+		t.Errorf("expected rewatch started, got %+v", um.WatchInstances)
+	}
+
+	// Ending a watch that starts later does not end it before its start
+	later := now.AddDate(0, 1, 0)
+	um = models.UserMedia{
+		Status: status(models.WatchStatusDropped),
+		WatchInstances: []models.WatchedInstance{
+			{Ongoing: true, StartDate: &later}},
+	}
+	applyWatchStatus(&um, status(models.WatchStatusCurrent), now)
+	if !um.WatchInstances[0].EndDate.Equal(later) ||
+		validateWatchInstances(&um) != nil {
+		t.Errorf("expected watch ended at its start, got %+v", um.WatchInstances)
+	}
+
+	// Starting a watch that already ended does not start it after its end
+	um = models.UserMedia{
+		Status: status(models.WatchStatusCurrent),
+		WatchInstances: []models.WatchedInstance{
+			{Ongoing: true, EndDate: &start}},
+	}
+	applyWatchStatus(&um, status(models.WatchStatusHold), now)
+	if !um.WatchInstances[0].StartDate.Equal(start) ||
+		validateWatchInstances(&um) != nil {
+		t.Errorf("expected watch started at its end, got %+v", um.WatchInstances)
+	}
+
+	// An unchanged status keeps the dates of the ongoing watch
+	um = models.UserMedia{
+		Status: status(models.WatchStatusHold),
+		WatchInstances: []models.WatchedInstance{
+			{Ongoing: true, StartDate: &start}},
+	}
+	applyWatchStatus(&um, status(models.WatchStatusHold), now)
+	if len(um.StatusHistory) != 0 || len(um.WatchInstances) != 1 ||
+		!um.WatchInstances[0].StartDate.Equal(start) {
+		t.Errorf("expected nothing changed, got %+v %+v", um.StatusHistory,
+			um.WatchInstances)
+	}
+}
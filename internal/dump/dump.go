@@ -0,0 +1,386 @@
+// Package dump dumps the global catalog of an instance, which is all of the
+// data not owned by Users, and loads it into another. A dump is a directory
+// with a newline-delimited JSON file for each bucket, containing its models
+// as they are persisted, and a manifest that describes the files and the
+// schema version they were written with.
+package dump
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/data"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+const (
+	// Format identifies the manifests of dumps.
+	Format = "nao-dump"
+	// SchemaVersion is the version of the schema of the models in dumps
+	// written by this version of nao, incremented whenever their encoding
+	// changes. Dumps with an earlier version are migrated as they are loaded;
+	// those with a later version cannot be loaded.
+	//
+	// 	1: the initial schema
+	// 	2: Media, Characters, Persons and Producers have external IDs
+	// 	3: Volumes and Chapters
+	// 	4: Media Types and Sources are enum names, not free-form strings
+	// 	5: BroadcastSlots and Airings
+	SchemaVersion = 5
+	// ManifestFile is the name of the manifest file of dumps.
+	ManifestFile = "manifest.json"
+)
+
+// Manifest describes a dump.
+type Manifest struct {
+	Format        string           `json:"format"`
+	SchemaVersion int              `json:"schemaVersion"`
+	CreatedAt     time.Time        `json:"createdAt"`
+	Buckets       []BucketManifest `json:"buckets"`
+}
+
+// BucketManifest describes the file of a bucket in a dump.
+type BucketManifest struct {
+	Name string `json:"name"`
+	// File is the name of the file in the dump directory.
+	File string `json:"file"`
+	// Count is the number of models in the file.
+	Count int `json:"count"`
+}
+
+// Catalog dumps and loads the models of the global catalog.
+type Catalog struct {
+	GenreService          *data.GenreService
+	CharacterService      *data.CharacterService
+	PersonService         *data.PersonService
+	ProducerService       *data.ProducerService
+	EpisodeService        *data.EpisodeService
+	MediaService          *data.MediaService
+	EpisodeSetService     *data.EpisodeSetService
+	VolumeService         *data.VolumeService
+	ChapterService        *data.ChapterService
+	BroadcastSlotService  *data.BroadcastSlotService
+	AiringService         *data.AiringService
+	MediaCharacterService *data.MediaCharacterService
+	MediaGenreService     *data.MediaGenreService
+	MediaProducerService  *data.MediaProducerService
+	MediaRelationService  *data.MediaRelationService
+}
+
+// bucket is a bucket of the catalog.
+type bucket struct {
+	service db.Service
+	// remap replaces the IDs of the models the given model refers to, which
+	// are in buckets earlier in the catalog, with those they were loaded as,
+	// and drops the external IDs of the given model that identify persisted
+	// models.
+	remap func(m db.Model, ids idMap, tx db.Tx) error
+}
+
+// buckets returns the buckets of the catalog, each after those its models
+// refer to.
+func (c *Catalog) buckets() []bucket {
+	return []bucket{
+		{service: c.GenreService},
+		{service: c.CharacterService, remap: func(m db.Model, _ idMap, tx db.Tx) error {
+			ch, err := c.CharacterService.AssertType(m)
+			if err != nil {
+				return err
+			}
+			ch.ExternalIDs, err = unclaimedExternalIDs(ch.ExternalIDs,
+				c.CharacterService.GetIDByExternalID, tx)
+			return err
+		}},
+		{service: c.PersonService, remap: func(m db.Model, _ idMap, tx db.Tx) error {
+			p, err := c.PersonService.AssertType(m)
+			if err != nil {
+				return err
+			}
+			p.ExternalIDs, err = unclaimedExternalIDs(p.ExternalIDs,
+				c.PersonService.GetIDByExternalID, tx)
+			return err
+		}},
+		{service: c.ProducerService, remap: func(m db.Model, _ idMap, tx db.Tx) error {
+			p, err := c.ProducerService.AssertType(m)
+			if err != nil {
+				return err
+			}
+			p.ExternalIDs, err = unclaimedExternalIDs(p.ExternalIDs,
+				c.ProducerService.GetIDByExternalID, tx)
+			return err
+		}},
+		{service: c.EpisodeService},
+		{service: c.MediaService, remap: func(m db.Model, _ idMap, tx db.Tx) error {
+			md, err := c.MediaService.AssertType(m)
+			if err != nil {
+				return err
+			}
+			md.ExternalIDs, err = unclaimedExternalIDs(md.ExternalIDs,
+				c.MediaService.GetIDByExternalID, tx)
+			return err
+		}},
+		{service: c.EpisodeSetService, remap: func(m db.Model, ids idMap, _ db.Tx) error {
+			set, err := c.EpisodeSetService.AssertType(m)
+			if err != nil {
+				return err
+			}
+			set.MediaID, err = ids.get(c.MediaService, set.MediaID)
+			if err != nil {
+				return err
+			}
+			for i, epID := range set.Episodes {
+				set.Episodes[i], err = ids.get(c.EpisodeService, epID)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{service: c.VolumeService, remap: func(m db.Model, ids idMap, _ db.Tx) error {
+			v, err := c.VolumeService.AssertType(m)
+			if err != nil {
+				return err
+			}
+			v.MediaID, err = ids.get(c.MediaService, v.MediaID)
+			return err
+		}},
+		{service: c.ChapterService, remap: func(m db.Model, ids idMap, _ db.Tx) error {
+			ch, err := c.ChapterService.AssertType(m)
+			if err != nil {
+				return err
+			}
+			ch.MediaID, err = ids.get(c.MediaService, ch.MediaID)
+			if err != nil {
+				return err
+			}
+			if ch.VolumeID != nil {
+				vID, err := ids.get(c.VolumeService, *ch.VolumeID)
+				if err != nil {
+					return err
+				}
+				ch.VolumeID = &vID
+			}
+			return nil
+		}},
+		{service: c.BroadcastSlotService, remap: func(m db.Model, ids idMap, _ db.Tx) error {
+			s, err := c.BroadcastSlotService.AssertType(m)
+			if err != nil {
+				return err
+			}
+			s.MediaID, err = ids.get(c.MediaService, s.MediaID)
+			return err
+		}},
+		{service: c.AiringService, remap: func(m db.Model, ids idMap, _ db.Tx) error {
+			a, err := c.AiringService.AssertType(m)
+			if err != nil {
+				return err
+			}
+			a.MediaID, err = ids.get(c.MediaService, a.MediaID)
+			if err != nil {
+				return err
+			}
+			if a.EpisodeID != nil {
+				epID, err := ids.get(c.EpisodeService, *a.EpisodeID)
+				if err != nil {
+					return err
+				}
+				a.EpisodeID = &epID
+			}
+			return nil
+		}},
+		{service: c.MediaCharacterService, remap: func(m db.Model, ids idMap, _ db.Tx) error {
+			mc, err := c.MediaCharacterService.AssertType(m)
+			if err != nil {
+				return err
+			}
+			mc.MediaID, err = ids.get(c.MediaService, mc.MediaID)
+			if err != nil {
+				return err
+			}
+			if mc.CharacterID != nil {
+				*mc.CharacterID, err = ids.get(c.CharacterService, *mc.CharacterID)
+				if err != nil {
+					return err
+				}
+			}
+			if mc.PersonID != nil {
+				*mc.PersonID, err = ids.get(c.PersonService, *mc.PersonID)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{service: c.MediaGenreService, remap: func(m db.Model, ids idMap, _ db.Tx) error {
+			mg, err := c.MediaGenreService.AssertType(m)
+			if err != nil {
+				return err
+			}
+			mg.MediaID, err = ids.get(c.MediaService, mg.MediaID)
+			if err != nil {
+				return err
+			}
+			mg.GenreID, err = ids.get(c.GenreService, mg.GenreID)
+			return err
+		}},
+		{service: c.MediaProducerService, remap: func(m db.Model, ids idMap, _ db.Tx) error {
+			mp, err := c.MediaProducerService.AssertType(m)
+			if err != nil {
+				return err
+			}
+			mp.MediaID, err = ids.get(c.MediaService, mp.MediaID)
+			if err != nil {
+				return err
+			}
+			mp.ProducerID, err = ids.get(c.ProducerService, mp.ProducerID)
+			return err
+		}},
+		{service: c.MediaRelationService, remap: func(m db.Model, ids idMap, _ db.Tx) error {
+			mr, err := c.MediaRelationService.AssertType(m)
+			if err != nil {
+				return err
+			}
+			mr.OwnerID, err = ids.get(c.MediaService, mr.OwnerID)
+			if err != nil {
+				return err
+			}
+			mr.RelatedID, err = ids.get(c.MediaService, mr.RelatedID)
+			return err
+		}},
+	}
+}
+
+// unclaimedExternalIDs returns those of the given external IDs that do not
+// identify persisted models according to the given lookup, so that a dump may
+// be loaded into a database that already has some of its catalog.
+func unclaimedExternalIDs(
+	ids []models.ExternalID,
+	lookup func(source string, id string, tx db.Tx) (int, error),
+	tx db.Tx,
+) ([]models.ExternalID, error) {
+	var free []models.ExternalID
+	for _, id := range ids {
+		owner, err := lookup(id.Source, id.ID, tx)
+		if err != nil {
+			return nil, err
+		}
+		if owner == 0 {
+			free = append(free, id)
+		}
+	}
+	return free, nil
+}
+
+// Dump writes the models of the catalog that are not deleted to the given
+// directory, which is created if it does not exist. The manifest is written
+// last, so that incomplete dumps have none.
+func (c *Catalog) Dump(dir string, tx db.Tx) (*Manifest, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dump directory: %w", err)
+	}
+
+	manifest := Manifest{
+		Format:        Format,
+		SchemaVersion: SchemaVersion,
+		CreatedAt:     time.Now(),
+	}
+	for _, b := range c.buckets() {
+		bm, err := dumpBucket(dir, b.service, tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dump bucket %q: %w",
+				b.service.Bucket(), err)
+		}
+		manifest.Buckets = append(manifest.Buckets, *bm)
+	}
+
+	buf, err := json.MarshalIndent(&manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	err = writeFile(filepath.Join(dir, ManifestFile), buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// dumpBucket writes the models of the given service that are not deleted to
+// its file in the given directory.
+func dumpBucket(dir string, ser db.Service, tx db.Tx) (*BucketManifest, error) {
+	bm := BucketManifest{
+		Name: ser.Bucket(),
+		File: ser.Bucket() + ".ndjson",
+	}
+
+	f, err := os.Create(filepath.Join(dir, bm.File))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	err = tx.Database().DoEach(nil, nil, ser, tx,
+		func(m db.Model, ser db.Service, _ db.Tx) (bool, error) {
+			buf, err := ser.Marshal(m)
+			if err != nil {
+				return true, err
+			}
+			_, err = w.Write(append(buf, '\n'))
+			if err != nil {
+				return true, err
+			}
+			bm.Count++
+			return false, nil
+		}, func(m db.Model) bool {
+			return m.Metadata().DeletedAt == nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.Flush()
+	if err != nil {
+		return nil, err
+	}
+	return &bm, f.Close()
+}
+
+// writeFile writes the given content to the file with the given name,
+// replacing it if it exists.
+func writeFile(name string, content []byte) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(content)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// errMissingReference is returned when a model refers to one that is not in
+// the dump.
+var errMissingReference = errors.New("missing reference")
+
+// idMap maps the IDs of the models in a dump to those they were loaded as, by
+// bucket.
+type idMap map[string]map[int]int
+
+// get returns the ID the model of the given service with the given ID in the
+// dump was loaded as.
+func (ids idMap) get(ser db.Service, id int) (int, error) {
+	newID, ok := ids[ser.Bucket()][id]
+	if !ok {
+		return 0, fmt.Errorf("%s %d: %w", ser.Bucket(), id, errMissingReference)
+	}
+	return newID, nil
+}
@@ -0,0 +1,337 @@
+package dump
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/data"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+// newTestCatalog returns a Catalog backed by a new database in a temporary
+// directory. The database is closed when the test finishes.
+func newTestCatalog(t *testing.T) (*Catalog, *db.DatabaseService) {
+	media := data.NewMediaService(db.PersistHooks{})
+	episodes := data.NewEpisodeService(db.PersistHooks{})
+	characters := data.NewCharacterService(db.PersistHooks{})
+	persons := data.NewPersonService(db.PersistHooks{})
+	producers := data.NewProducerService(db.PersistHooks{})
+	genres := data.NewGenreService(db.PersistHooks{})
+	volumes := data.NewVolumeService(db.PersistHooks{}, media)
+	c := Catalog{
+		GenreService:     genres,
+		CharacterService: characters,
+		PersonService:    persons,
+		ProducerService:  producers,
+		EpisodeService:   episodes,
+		MediaService:     media,
+		EpisodeSetService: data.NewEpisodeSetService(db.PersistHooks{},
+			episodes, media),
+		VolumeService: volumes,
+		ChapterService: data.NewChapterService(db.PersistHooks{},
+			media, volumes),
+		BroadcastSlotService: data.NewBroadcastSlotService(db.PersistHooks{},
+			media),
+		AiringService: data.NewAiringService(db.PersistHooks{},
+			media, episodes),
+		MediaCharacterService: data.NewMediaCharacterService(db.PersistHooks{},
+			media, characters, persons),
+		MediaGenreService: data.NewMediaGenreService(db.PersistHooks{},
+			media, genres),
+		MediaProducerService: data.NewMediaProducer(db.PersistHooks{},
+			media, producers),
+		MediaRelationService: data.NewMediaRelationService(db.PersistHooks{},
+			media),
+	}
+
+	var buckets []string
+	for _, b := range c.buckets() {
+		buckets = append(buckets, b.service.Bucket())
+		if indexed, ok := b.service.(interface{ IndexBuckets() []string }); ok {
+			buckets = append(buckets, indexed.IndexBuckets()...)
+		}
+	}
+	driver, err := db.ConnectBoltDatabase(&db.BoltDatabaseConfig{
+		Path:     filepath.Join(t.TempDir(), "db"),
+		FileMode: 0600,
+		Buckets:  buckets,
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	database := &db.DatabaseService{DatabaseDriver: driver}
+	t.Cleanup(func() { database.Close() })
+	return &c, database
+}
+
+func TestDumpLoad(t *testing.T) {
+	dir := t.TempDir()
+	c, database := newTestCatalog(t)
+
+	title := func(s string) []models.Title { return []models.Title{{String: s}} }
+	err := database.Transaction(true, func(tx db.Tx) error {
+		gID, err := c.GenreService.Create(&models.Genre{Names: title("Space")}, tx)
+		if err != nil {
+			return err
+		}
+		cID, err := c.CharacterService.Create(
+			&models.Character{Names: title("Spike Spiegel")}, tx)
+		if err != nil {
+			return err
+		}
+		epID, err := c.EpisodeService.Create(&models.Episode{Titles: title("Asteroid Blues")}, tx)
+		if err != nil {
+			return err
+		}
+		bebopID, err := c.MediaService.Create(&models.Media{
+			Titles:      title("Cowboy Bebop"),
+			ExternalIDs: []models.ExternalID{{Source: "anilist", ID: "1"}},
+		}, tx)
+		if err != nil {
+			return err
+		}
+		movieID, err := c.MediaService.Create(&models.Media{Titles: title("Knockin' on Heaven's Door")}, tx)
+		if err != nil {
+			return err
+		}
+		deletedID, err := c.MediaService.Create(&models.Media{Titles: title("Deleted")}, tx)
+		if err != nil {
+			return err
+		}
+		err = c.MediaService.Delete(deletedID, tx)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.EpisodeSetService.Create(&models.EpisodeSet{
+			MediaID: bebopID, Episodes: []int{epID}}, tx)
+		if err != nil {
+			return err
+		}
+		_, err = c.MediaGenreService.Create(&models.MediaGenre{
+			MediaID: bebopID, GenreID: gID}, tx)
+		if err != nil {
+			return err
+		}
+		role := "main"
+		_, err = c.MediaCharacterService.Create(&models.MediaCharacter{
+			MediaID: bebopID, CharacterID: &cID, CharacterRole: &role}, tx)
+		if err != nil {
+			return err
+		}
+		_, err = c.MediaRelationService.Create(&models.MediaRelation{
+			OwnerID: bebopID, RelatedID: movieID, Relationship: "sequel"}, tx)
+		if err != nil {
+			return err
+		}
+		vID, err := c.VolumeService.Create(&models.Volume{
+			MediaID: bebopID, Number: 1}, tx)
+		if err != nil {
+			return err
+		}
+		_, err = c.ChapterService.Create(&models.Chapter{
+			MediaID: bebopID, VolumeID: &vID, Number: 1}, tx)
+		if err != nil {
+			return err
+		}
+		_, err = c.AiringService.Create(&models.Airing{MediaID: bebopID,
+			EpisodeNumber: 1, EpisodeID: &epID,
+			AiringAt: time.Date(1998, 10, 23, 9, 0, 0, 0, time.UTC)}, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to create catalog: %v", err)
+	}
+
+	dumpDir := filepath.Join(dir, "dump")
+	var manifest *Manifest
+	err = database.Transaction(false, func(tx db.Tx) error {
+		manifest, err = c.Dump(dumpDir, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to dump catalog: %v", err)
+	}
+	counts := map[string]int{}
+	for _, bm := range manifest.Buckets {
+		counts[bm.Name] = bm.Count
+	}
+	if counts["Media"] != 2 || counts["MediaRelation"] != 1 ||
+		counts["EpisodeSet"] != 1 || counts["Producer"] != 0 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+
+	// Loading into the same database duplicates the catalog with new IDs
+	var res *Result
+	err = database.Transaction(true, func(tx db.Tx) error {
+		res, err = c.Load(dumpDir, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to load dump: %v", err)
+	}
+	expectedMedia := map[int]int{1: 4, 2: 5}
+	if !reflect.DeepEqual(res.IDs["Media"], expectedMedia) {
+		t.Errorf("expected Media IDs %v, got %v", expectedMedia, res.IDs["Media"])
+	}
+
+	err = database.Transaction(false, func(tx db.Tx) error {
+		mr, err := c.MediaRelationService.GetByID(res.IDs["MediaRelation"][1], tx)
+		if err != nil {
+			return err
+		}
+		if mr.OwnerID != 4 || mr.RelatedID != 5 {
+			t.Errorf("expected relation of Media 4 to 5, got %d to %d",
+				mr.OwnerID, mr.RelatedID)
+		}
+
+		set, err := c.EpisodeSetService.GetByID(res.IDs["EpisodeSet"][1], tx)
+		if err != nil {
+			return err
+		}
+		if set.MediaID != 4 || !reflect.DeepEqual(set.Episodes, []int{2}) {
+			t.Errorf("unexpected EpisodeSet %+v", set)
+		}
+
+		a, err := c.AiringService.GetByID(res.IDs["Airing"][1], tx)
+		if err != nil {
+			return err
+		}
+		if a.MediaID != 4 || a.EpisodeID == nil || *a.EpisodeID != 2 {
+			t.Errorf("unexpected Airing %+v", a)
+		}
+
+		ch, err := c.ChapterService.GetByID(res.IDs["Chapter"][1], tx)
+		if err != nil {
+			return err
+		}
+		if ch.MediaID != 4 || ch.VolumeID == nil ||
+			*ch.VolumeID != res.IDs["Volume"][1] {
+			t.Errorf("unexpected Chapter %+v", ch)
+		}
+
+		// External IDs keep identifying the Media they were persisted with
+		bebop, err := c.MediaService.GetByID(4, tx)
+		if err != nil {
+			return err
+		}
+		if len(bebop.ExternalIDs) != 0 {
+			t.Errorf("expected no external IDs, got %v", bebop.ExternalIDs)
+		}
+		mID, err := c.MediaService.GetIDByExternalID("anilist", "1", tx)
+		if err != nil {
+			return err
+		}
+		if mID != 1 {
+			t.Errorf("expected external ID of Media 1, got %d", mID)
+		}
+
+		mc, err := c.MediaCharacterService.GetByID(res.IDs["MediaCharacter"][1], tx)
+		if err != nil {
+			return err
+		}
+		if mc.CharacterID == nil || *mc.CharacterID != 2 {
+			t.Errorf("unexpected MediaCharacter %+v", mc)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to get loaded models: %v", err)
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, m := range []Manifest{
+		{Format: "other", SchemaVersion: SchemaVersion},
+		{Format: Format, SchemaVersion: SchemaVersion + 1},
+	} {
+		buf, _ := json.Marshal(&m)
+		err := ioutil.WriteFile(filepath.Join(dir, ManifestFile), buf, 0644)
+		if err != nil {
+			t.Fatalf("failed to write manifest: %v", err)
+		}
+		_, err = ReadManifest(dir)
+		if err == nil {
+			t.Errorf("expected error for manifest %+v", m)
+		}
+	}
+
+	// Dumps of earlier versions are still read
+	for v := 1; v <= SchemaVersion; v++ {
+		buf, _ := json.Marshal(&Manifest{Format: Format, SchemaVersion: v})
+		err := ioutil.WriteFile(filepath.Join(dir, ManifestFile), buf, 0644)
+		if err != nil {
+			t.Fatalf("failed to write manifest: %v", err)
+		}
+		_, err = ReadManifest(dir)
+		if err != nil {
+			t.Errorf("expected version %d to be read, got %v", v, err)
+		}
+	}
+}
+
+func TestLoadVersion1(t *testing.T) {
+	dir := t.TempDir()
+	c, database := newTestCatalog(t)
+
+	// Types and Sources were free-form strings before version 4
+	dumpDir := filepath.Join(dir, "dump")
+	err := os.MkdirAll(dumpDir, 0755)
+	if err != nil {
+		t.Fatalf("failed to create dump directory: %v", err)
+	}
+	lines := `{"Titles":[{"String":"Cowboy Bebop"}],"Type":" tv ","Source":"Original","Meta":{"ID":7}}
+{"Titles":[{"String":"Unknown"}],"Type":"Pachinko","Meta":{"ID":8}}
+`
+	err = ioutil.WriteFile(filepath.Join(dumpDir, "Media.ndjson"),
+		[]byte(lines), 0644)
+	if err != nil {
+		t.Fatalf("failed to write dump: %v", err)
+	}
+	buf, _ := json.Marshal(&Manifest{Format: Format, SchemaVersion: 1,
+		Buckets: []BucketManifest{{Name: "Media", File: "Media.ndjson", Count: 2}}})
+	err = ioutil.WriteFile(filepath.Join(dumpDir, ManifestFile), buf, 0644)
+	if err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+
+	var res *Result
+	err = database.Transaction(true, func(tx db.Tx) error {
+		res, err = c.Load(dumpDir, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to load dump: %v", err)
+	}
+
+	err = database.Transaction(false, func(tx db.Tx) error {
+		bebop, err := c.MediaService.GetByID(res.IDs["Media"][7], tx)
+		if err != nil {
+			return err
+		}
+		if bebop.Type == nil || *bebop.Type != models.MediaTypeTV ||
+			bebop.Source == nil || *bebop.Source != models.MediaSourceOriginal {
+			t.Errorf("unexpected type and source %v %v", bebop.Type,
+				bebop.Source)
+		}
+		unknown, err := c.MediaService.GetByID(res.IDs["Media"][8], tx)
+		if err != nil {
+			return err
+		}
+		if unknown.Type != nil {
+			t.Errorf("expected unknown type to be dropped, got %v", *unknown.Type)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to get loaded Media: %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package dump
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	json "github.com/json-iterator/go"
+)
+
+// maxLineSize is the maximum size of a model in a dump file.
+const maxLineSize = 64 * 1024 * 1024
+
+// Result describes what loading a dump did.
+type Result struct {
+	Manifest *Manifest
+	// Loaded is the number of models loaded into each bucket.
+	Loaded map[string]int
+	// IDs maps the IDs of the models in the dump to those they were loaded
+	// as, by bucket.
+	IDs map[string]map[int]int
+}
+
+// ReadManifest reads the manifest of the dump in the given directory and
+// checks that the dump may be loaded.
+func ReadManifest(dir string) (*Manifest, error) {
+	buf, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	err = json.Unmarshal(buf, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+	if manifest.Format != Format {
+		return nil, fmt.Errorf("unknown dump format %q", manifest.Format)
+	}
+	if manifest.SchemaVersion < 1 || manifest.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("unsupported dump schema version %d; expected at most %d",
+			manifest.SchemaVersion, SchemaVersion)
+	}
+	return &manifest, nil
+}
+
+// Load creates the models in the dump in the given directory with new IDs,
+// replacing the IDs of the models they refer to with those they were loaded
+// as, so that the catalog may already contain models. Those models are not
+// changed or matched to the loaded ones. Load should be called in a single
+// transaction that is rolled back if it fails, so that nothing is loaded.
+func (c *Catalog) Load(dir string, tx db.Tx) (*Result, error) {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := c.buckets()
+	known := map[string]bool{}
+	for _, b := range buckets {
+		known[b.service.Bucket()] = true
+	}
+	files := map[string]*BucketManifest{}
+	for i := range manifest.Buckets {
+		bm := &manifest.Buckets[i]
+		if !known[bm.Name] {
+			return nil, fmt.Errorf("unknown bucket %q in dump", bm.Name)
+		}
+		files[bm.Name] = bm
+	}
+
+	res := Result{
+		Manifest: manifest,
+		Loaded:   map[string]int{},
+		IDs:      map[string]map[int]int{},
+	}
+	for _, b := range buckets {
+		name := b.service.Bucket()
+		res.IDs[name] = map[int]int{}
+		bm, ok := files[name]
+		if !ok {
+			continue
+		}
+
+		err = loadBucket(dir, bm, b, res.IDs, tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bucket %q: %w", name, err)
+		}
+		res.Loaded[name] = len(res.IDs[name])
+	}
+	return &res, nil
+}
+
+// loadBucket creates the models in the file of the given bucket, recording
+// the IDs they were loaded as.
+func loadBucket(dir string, bm *BucketManifest, b bucket, ids idMap,
+	tx db.Tx) error {
+	f, err := os.Open(filepath.Join(dir, bm.File))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		count++
+
+		m, err := b.service.Unmarshal(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", count, err)
+		}
+		meta := m.Metadata()
+		oldID := meta.ID
+		*meta = db.ModelMetadata{}
+
+		if b.remap != nil {
+			err = b.remap(m, ids, tx)
+			if err != nil {
+				return fmt.Errorf("%s %d: %w", bm.Name, oldID, err)
+			}
+		}
+		newID, err := tx.Database().Create(m, b.service, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create %s %d: %w", bm.Name, oldID, err)
+		}
+		ids[bm.Name][oldID] = newID
+	}
+	err = scanner.Err()
+	if err != nil {
+		return err
+	}
+
+	if count != bm.Count {
+		return fmt.Errorf("expected %d models but read %d", bm.Count, count)
+	}
+	return nil
+}
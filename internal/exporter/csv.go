@@ -0,0 +1,137 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func init() {
+	Register("csv", csvEncoder{})
+}
+
+// csvHeader is the header row of CSV exports.
+var csvHeader = []string{
+	"kind", "id", "ref_id", "title", "status", "score", "progress", "repeats",
+	"start_date", "finish_date", "comments", "lists",
+}
+
+// csvEncoder writes Libraries as flat CSV, with one row for each UserMedia,
+// UserEpisode, UserCharacter, UserPerson and UserMediaList, distinguished by
+// the kind column. The ref_id column is the ID of the Media, Episode,
+// Character or Person the row refers to, scores are from 0 to 100, and the
+// progress of UserMediaLists is their number of UserMedia. The progress,
+// repeats and dates of UserMedia are recorded as by most services: progress
+// through read Media is counted in chapters, the dates belong to the first
+// watch, and a repeat in progress is not counted.
+type csvEncoder struct{}
+
+func (csvEncoder) ContentType() string { return "text/csv" }
+
+func (csvEncoder) Extension() string { return "csv" }
+
+func (csvEncoder) Encode(w io.Writer, l *Library) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(csvHeader)
+	if err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, um := range l.UserMedia {
+		var title string
+		if md, ok := l.Media[um.MediaID]; ok {
+			title = preferredTitle(md.Titles)
+		}
+		p := userMediaProgress(um, l.reading(um))
+		var status string
+		if um.Status != nil {
+			status = um.Status.String()
+		}
+		err = cw.Write([]string{
+			"media", strconv.Itoa(um.Meta.ID), strconv.Itoa(um.MediaID), title,
+			status, csvInt(um.Score), strconv.Itoa(p.watched),
+			strconv.Itoa(p.repeats), csvDate(p.startDate), csvDate(p.finishDate),
+			joinTitles(um.Comments), strings.Join(l.listNames(um.Meta.ID), "; "),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	for _, uep := range l.UserEpisodes {
+		var title string
+		if ep, ok := l.Episodes[uep.EpisodeID]; ok {
+			title = preferredTitle(ep.Titles)
+		}
+		err = cw.Write(csvRow("episode", uep.Meta.ID, uep.EpisodeID,
+			title, uep.Score, uep.Comments))
+		if err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	for _, uc := range l.UserCharacters {
+		var name string
+		if c, ok := l.Characters[uc.CharacterID]; ok {
+			name = preferredTitle(c.Names)
+		}
+		err = cw.Write(csvRow("character", uc.Meta.ID, uc.CharacterID,
+			name, uc.Score, uc.Comments))
+		if err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	for _, up := range l.UserPersons {
+		var name string
+		if p, ok := l.Persons[up.PersonID]; ok {
+			name = preferredTitle(p.Names)
+		}
+		err = cw.Write(csvRow("person", up.Meta.ID, up.PersonID,
+			name, up.Score, up.Comments))
+		if err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	for _, list := range l.UserMediaLists {
+		err = cw.Write([]string{
+			"list", strconv.Itoa(list.Meta.ID), "", preferredTitle(list.Names),
+			"", "", strconv.Itoa(len(list.UserMedia)), "", "", "",
+			joinTitles(list.Descriptions), "",
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvRow returns the row of the rating of a User for some Episode,
+// Character or Person.
+func csvRow(kind string, id int, refID int, title string, score *int,
+	comments []models.Title) []string {
+	return []string{
+		kind, strconv.Itoa(id), strconv.Itoa(refID), title, "", csvInt(score),
+		"", "", "", "", joinTitles(comments), "",
+	}
+}
+
+// csvInt returns the given number, or an empty string if it is nil.
+func csvInt(i *int) string {
+	if i == nil {
+		return ""
+	}
+	return strconv.Itoa(*i)
+}
+
+// csvDate returns the given date, or an empty string if it is nil.
+func csvDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
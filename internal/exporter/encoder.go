@@ -0,0 +1,57 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Encoder writes Libraries in one format.
+type Encoder interface {
+	Encode(w io.Writer, l *Library) error
+	// ContentType is the media type of the written format.
+	ContentType() string
+	// Extension is the file name extension of the written format, without
+	// the leading dot.
+	Extension() string
+}
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]Encoder{}
+)
+
+// Register makes the given Encoder available by the given format name. It
+// panics if an Encoder is already registered by the name.
+func Register(format string, e Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	if _, ok := encoders[format]; ok {
+		panic(fmt.Sprintf("exporter: encoder already registered for %q", format))
+	}
+	encoders[format] = e
+}
+
+// Formats returns the sorted names of the formats with registered Encoders.
+func Formats() []string {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	formats := make([]string, 0, len(encoders))
+	for f := range encoders {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Lookup returns the Encoder registered by the given format name.
+func Lookup(format string) (Encoder, error) {
+	encodersMu.RLock()
+	e, ok := encoders[format]
+	encodersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+	return e, nil
+}
@@ -0,0 +1,257 @@
+// Package exporter exports the lists of Users, with the catalog data they
+// refer to, in formats that may be imported by other services or by nao
+// again. Each format is written by an Encoder from a Library loaded within
+// a single transaction.
+package exporter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/data"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// Library is the data of a User to be exported.
+type Library struct {
+	User           *models.User
+	UserMedia      []*models.UserMedia
+	UserEpisodes   []*models.UserEpisode
+	UserCharacters []*models.UserCharacter
+	UserPersons    []*models.UserPerson
+	UserMediaLists []*models.UserMediaList
+
+	// Media, Episodes, Characters and Persons are the models referred to by
+	// the data of the User, by ID.
+	Media      map[int]*models.Media
+	Episodes   map[int]*models.Episode
+	Characters map[int]*models.Character
+	Persons    map[int]*models.Person
+
+	// ExportedAt is the time the Library was loaded.
+	ExportedAt time.Time
+}
+
+// Exporter loads the Libraries of Users.
+type Exporter struct {
+	UserService          *data.UserService
+	MediaService         *data.MediaService
+	EpisodeService       *data.EpisodeService
+	CharacterService     *data.CharacterService
+	PersonService        *data.PersonService
+	UserMediaService     *data.UserMediaService
+	UserEpisodeService   *data.UserEpisodeService
+	UserCharacterService *data.UserCharacterService
+	UserPersonService    *data.UserPersonService
+	UserMediaListService *data.UserMediaListService
+}
+
+// Load retrieves the Library of the User with the given ID.
+func (ex *Exporter) Load(uID int, tx db.Tx) (*Library, error) {
+	u, err := ex.UserService.GetByID(uID, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get User by id %d: %w", uID, err)
+	}
+	l := Library{
+		User:       u,
+		Media:      map[int]*models.Media{},
+		Episodes:   map[int]*models.Episode{},
+		Characters: map[int]*models.Character{},
+		Persons:    map[int]*models.Person{},
+		ExportedAt: time.Now(),
+	}
+
+	l.UserMedia, err = ex.UserMediaService.GetByUser(uID, nil, nil, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get UserMedia by User id %d: %w", uID, err)
+	}
+	for _, um := range l.UserMedia {
+		if _, ok := l.Media[um.MediaID]; ok {
+			continue
+		}
+		l.Media[um.MediaID], err = ex.MediaService.GetByID(um.MediaID, tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Media by id %d: %w", um.MediaID, err)
+		}
+	}
+
+	l.UserEpisodes, err = ex.UserEpisodeService.GetByUser(uID, nil, nil, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get UserEpisodes by User id %d: %w", uID, err)
+	}
+	for _, uep := range l.UserEpisodes {
+		if _, ok := l.Episodes[uep.EpisodeID]; ok {
+			continue
+		}
+		l.Episodes[uep.EpisodeID], err = ex.EpisodeService.GetByID(uep.EpisodeID, tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Episode by id %d: %w", uep.EpisodeID, err)
+		}
+	}
+
+	l.UserCharacters, err = ex.UserCharacterService.GetByUser(uID, nil, nil, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get UserCharacters by User id %d: %w", uID, err)
+	}
+	for _, uc := range l.UserCharacters {
+		if _, ok := l.Characters[uc.CharacterID]; ok {
+			continue
+		}
+		l.Characters[uc.CharacterID], err = ex.CharacterService.GetByID(uc.CharacterID, tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Character by id %d: %w", uc.CharacterID, err)
+		}
+	}
+
+	l.UserPersons, err = ex.UserPersonService.GetByUser(uID, nil, nil, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get UserPersons by User id %d: %w", uID, err)
+	}
+	for _, up := range l.UserPersons {
+		if _, ok := l.Persons[up.PersonID]; ok {
+			continue
+		}
+		l.Persons[up.PersonID], err = ex.PersonService.GetByID(up.PersonID, tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Person by id %d: %w", up.PersonID, err)
+		}
+	}
+
+	l.UserMediaLists, err = ex.UserMediaListService.GetByUser(uID, nil, nil, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get UserMediaLists by User id %d: %w", uID, err)
+	}
+	return &l, nil
+}
+
+// listNames returns the names of the UserMediaLists that contain the
+// UserMedia with the given ID.
+func (l *Library) listNames(umID int) []string {
+	var names []string
+	for _, list := range l.UserMediaLists {
+		for _, id := range list.UserMedia {
+			if id == umID {
+				names = append(names, preferredTitle(list.Names))
+				break
+			}
+		}
+	}
+	return names
+}
+
+// preferredTitle returns the string of the first primary Title in the given
+// set, or of the first Title if none are primary.
+func preferredTitle(set []models.Title) string {
+	for _, t := range set {
+		if t.Priority == models.TitlePriorityPrimary {
+			return t.String
+		}
+	}
+	if len(set) == 0 {
+		return ""
+	}
+	return set[0].String
+}
+
+// joinTitles returns the strings of the given Titles separated by blank
+// lines.
+func joinTitles(set []models.Title) string {
+	strs := make([]string, len(set))
+	for i, t := range set {
+		strs[i] = t.String
+	}
+	return strings.Join(strs, "\n\n")
+}
+
+// reading returns true if the Media of the given UserMedia is read, so that
+// progress through it is counted in chapters.
+func (l *Library) reading(um *models.UserMedia) bool {
+	md, ok := l.Media[um.MediaID]
+	return ok && md.Kind() == models.MediaKindManga
+}
+
+// progress is the progress of a User through some Media as recorded by most
+// services, derived from the WatchedInstances of UserMedia. It is the
+// inverse of how the importer derives WatchedInstances.
+type progress struct {
+	status  *models.WatchStatus
+	watched int
+	// volumes is the number of volumes read, if the Media is read.
+	volumes    int
+	startDate  *time.Time
+	finishDate *time.Time
+	// repeats is the number of complete watches after the first.
+	repeats int
+	// repeating is true if the User is watching the Media again, having
+	// progressed through repeatProg of it.
+	repeating  bool
+	repeatProg int
+}
+
+// userMediaProgress returns the progress recorded by the given UserMedia,
+// counted in chapters if reading is true and in episodes otherwise. The
+// dates belong to the first watch, except for a dropped watch, which is the
+// last one.
+func userMediaProgress(um *models.UserMedia, reading bool) progress {
+	p := progress{status: um.Status}
+	count := func(w models.WatchedInstance) int {
+		if reading {
+			return w.Chapters
+		}
+		return w.Episodes
+	}
+	if um.Status == nil || len(um.WatchInstances) == 0 {
+		return p
+	}
+
+	var closed []models.WatchedInstance
+	var ongoing *models.WatchedInstance
+	for i, w := range um.WatchInstances {
+		if w.Ongoing {
+			ongoing = &um.WatchInstances[i]
+		} else {
+			closed = append(closed, w)
+		}
+	}
+
+	first := um.WatchInstances[0]
+	switch *um.Status {
+	case models.WatchStatusCurrent, models.WatchStatusHold:
+		if ongoing != nil && len(closed) > 0 &&
+			*um.Status == models.WatchStatusCurrent {
+			completed := models.WatchStatusCompleted
+			p.status = &completed
+			p.repeating, p.repeatProg = true, count(*ongoing)
+			p.watched = count(closed[0])
+			p.repeats = len(closed) - 1
+			first = closed[0]
+			break
+		}
+		p.repeats = len(closed)
+		if ongoing != nil {
+			p.watched = count(*ongoing)
+			first = *ongoing
+		}
+	case models.WatchStatusCompleted:
+		if len(closed) > 0 {
+			first = closed[0]
+			p.repeats = len(closed) - 1
+		}
+		p.watched = count(first)
+	case models.WatchStatusDropped:
+		if len(closed) > 0 {
+			first = closed[len(closed)-1]
+			p.repeats = len(closed) - 1
+		}
+		p.watched = count(first)
+	case models.WatchStatusPlanning:
+		return p
+	}
+	if reading {
+		p.volumes = first.Volumes
+	}
+	p.startDate, p.finishDate = first.StartDate, first.EndDate
+	return p
+}
@@ -0,0 +1,192 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/importer"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+func testLibrary() *Library {
+	date := func(y int, m time.Month, d int) *time.Time {
+		t := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	status := func(s models.WatchStatus) *models.WatchStatus { return &s }
+	score := func(s int) *int { return &s }
+	manga := models.MediaTypeManga
+
+	return &Library{
+		User: &models.User{Username: "spike", Password: []byte("secret"),
+			Meta: db.ModelMetadata{ID: 1}},
+		UserMedia: []*models.UserMedia{
+			{
+				MediaID: 1, Score: score(90),
+				Status: status(models.WatchStatusCurrent),
+				WatchInstances: []models.WatchedInstance{
+					{Episodes: 26, StartDate: date(2019, 3, 1), EndDate: date(2019, 4, 1)},
+					{Episodes: 26},
+					{Episodes: 4, Ongoing: true},
+				},
+				Comments: []models.Title{{String: "See you, space cowboy"}},
+				Meta:     db.ModelMetadata{ID: 1},
+			},
+			{
+				MediaID: 2, Status: status(models.WatchStatusCurrent),
+				WatchInstances: []models.WatchedInstance{
+					{Chapters: 50, Volumes: 6, Ongoing: true, StartDate: date(2020, 1, 1)},
+				},
+				Meta: db.ModelMetadata{ID: 2},
+			},
+		},
+		UserCharacters: []*models.UserCharacter{
+			{CharacterID: 1, Score: score(100), Meta: db.ModelMetadata{ID: 1}},
+		},
+		UserMediaLists: []*models.UserMediaList{
+			{Names: []models.Title{{String: "Favourites"}}, UserMedia: []int{1},
+				Meta: db.ModelMetadata{ID: 1}},
+		},
+		Media: map[int]*models.Media{
+			1: {
+				Titles:      []models.Title{{String: "Cowboy Bebop"}},
+				ExternalIDs: []models.ExternalID{{Source: importer.SourceMyAnimeList, ID: "1"}},
+				Meta:        db.ModelMetadata{ID: 1},
+			},
+			2: {
+				Titles:      []models.Title{{String: "Monster"}},
+				Type:        &manga,
+				ExternalIDs: []models.ExternalID{{Source: importer.SourceMyAnimeListManga, ID: "1"}},
+				Meta:        db.ModelMetadata{ID: 2},
+			},
+		},
+		Characters: map[int]*models.Character{
+			1: {Names: []models.Title{{String: "Spike Spiegel"}}, Meta: db.ModelMetadata{ID: 1}},
+		},
+		ExportedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestFormats(t *testing.T) {
+	expected := []string{"csv", "json", "myanimelist"}
+	if f := Formats(); !reflect.DeepEqual(f, expected) {
+		t.Errorf("expected formats %v, got %v", expected, f)
+	}
+}
+
+func TestEncodeMyAnimeList(t *testing.T) {
+	l := testLibrary()
+	var buf bytes.Buffer
+	err := malEncoder{}.Encode(&buf, l)
+	if err != nil {
+		t.Fatalf("failed to encode library: %v", err)
+	}
+
+	// Exports are read back by the importer as they were
+	entries, err := importer.ParseMyAnimeList(&buf)
+	if err != nil {
+		t.Fatalf("failed to parse export: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	bebop := entries[0]
+	um := l.UserMedia[0]
+	if bebop.Source != importer.SourceMyAnimeList || bebop.ExternalID != "1" ||
+		bebop.Title() != "Cowboy Bebop" {
+		t.Errorf("unexpected entry %s %s %q", bebop.Source, bebop.ExternalID,
+			bebop.Title())
+	}
+	if *bebop.Status != *um.Status || *bebop.Score != *um.Score {
+		t.Errorf("expected status %v and score %d, got %v and %d", *um.Status,
+			*um.Score, *bebop.Status, *bebop.Score)
+	}
+	if !reflect.DeepEqual(bebop.WatchInstances, um.WatchInstances) {
+		t.Errorf("expected watches %+v, got %+v", um.WatchInstances,
+			bebop.WatchInstances)
+	}
+	if !reflect.DeepEqual(bebop.Comments, um.Comments) {
+		t.Errorf("expected comments %v, got %v", um.Comments, bebop.Comments)
+	}
+
+	monster := entries[1]
+	if monster.Source != importer.SourceMyAnimeListManga ||
+		*monster.Status != models.WatchStatusCurrent || monster.Score != nil {
+		t.Errorf("unexpected entry %+v", monster)
+	}
+	if !reflect.DeepEqual(monster.WatchInstances, l.UserMedia[1].WatchInstances) {
+		t.Errorf("expected watches %+v, got %+v", l.UserMedia[1].WatchInstances,
+			monster.WatchInstances)
+	}
+}
+
+func TestEncodeCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := csvEncoder{}.Encode(&buf, testLibrary())
+	if err != nil {
+		t.Fatalf("failed to encode library: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("expected 5 rows, got %d", len(rows))
+	}
+	expected := []string{"media", "1", "1", "Cowboy Bebop", "Current", "90",
+		"26", "1", "2019-03-01", "2019-04-01", "See you, space cowboy", "Favourites"}
+	if !reflect.DeepEqual(rows[1], expected) {
+		t.Errorf("expected row %q, got %q", expected, rows[1])
+	}
+	if rows[2][3] != "Monster" || rows[2][6] != "50" {
+		t.Errorf("expected progress in chapters, got row %q", rows[2])
+	}
+	if rows[3][0] != "character" || rows[3][3] != "Spike Spiegel" ||
+		rows[4][0] != "list" || rows[4][3] != "Favourites" {
+		t.Errorf("unexpected rows %q", rows[3:])
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	l := testLibrary()
+	var buf bytes.Buffer
+	err := jsonEncoder{}.Encode(&buf, l)
+	if err != nil {
+		t.Fatalf("failed to encode library: %v", err)
+	}
+
+	var export struct {
+		Format    string                  `json:"format"`
+		Version   int                     `json:"version"`
+		User      map[string]interface{}  `json:"user"`
+		Media     []*models.Media         `json:"media"`
+		UserMedia []*models.UserMedia     `json:"userMedia"`
+		Lists     []*models.UserMediaList `json:"userMediaLists"`
+	}
+	err = json.Unmarshal(buf.Bytes(), &export)
+	if err != nil {
+		t.Fatalf("failed to decode export: %v", err)
+	}
+	if export.Format != "nao" || export.Version != JSONVersion {
+		t.Errorf("unexpected format %q version %d", export.Format, export.Version)
+	}
+	if _, ok := export.User["password"]; ok || export.User["username"] != "spike" {
+		t.Errorf("unexpected user %v", export.User)
+	}
+	if len(export.Media) != 2 || export.Media[0].Meta.ID != 1 {
+		t.Errorf("unexpected media %+v", export.Media)
+	}
+	if !reflect.DeepEqual(export.UserMedia, l.UserMedia) {
+		t.Errorf("expected UserMedia %+v, got %+v", l.UserMedia, export.UserMedia)
+	}
+	if !reflect.DeepEqual(export.Lists, l.UserMediaLists) {
+		t.Errorf("expected lists %+v, got %+v", l.UserMediaLists, export.Lists)
+	}
+}
@@ -0,0 +1,157 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+// JSONVersion is the version of the nao JSON export format, incremented when
+// it changes incompatibly.
+const JSONVersion = 1
+
+func init() {
+	Register("json", jsonEncoder{})
+}
+
+// jsonUser is the User of a nao JSON export, without its credentials and
+// account settings.
+type jsonUser struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	// ScoreSystem is nil if not chosen.
+	ScoreSystem *models.ScoreSystem `json:"scoreSystem,omitempty"`
+}
+
+// jsonEncoder writes Libraries as nao JSON exports, which contain the data of
+// the User and the models it refers to as they are persisted, so that
+// nothing is lost. The object has the fields format, version, exportedAt and
+// user, followed by arrays of the models of each type.
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string { return "application/json" }
+
+func (jsonEncoder) Extension() string { return "json" }
+
+func (jsonEncoder) Encode(w io.Writer, l *Library) error {
+	jw := jsonWriter{w: w}
+	jw.write("{")
+	jw.field("format", "nao")
+	jw.write(",")
+	jw.field("version", JSONVersion)
+	jw.write(",")
+	jw.field("exportedAt", l.ExportedAt)
+	jw.write(",")
+	u := jsonUser{ID: l.User.Meta.ID, Username: l.User.Username}
+	if l.User.ScoreSystem != 0 {
+		u.ScoreSystem = &l.User.ScoreSystem
+	}
+	jw.field("user", u)
+
+	mediaIDs := make([]int, 0, len(l.Media))
+	for id := range l.Media {
+		mediaIDs = append(mediaIDs, id)
+	}
+	episodeIDs := make([]int, 0, len(l.Episodes))
+	for id := range l.Episodes {
+		episodeIDs = append(episodeIDs, id)
+	}
+	characterIDs := make([]int, 0, len(l.Characters))
+	for id := range l.Characters {
+		characterIDs = append(characterIDs, id)
+	}
+	personIDs := make([]int, 0, len(l.Persons))
+	for id := range l.Persons {
+		personIDs = append(personIDs, id)
+	}
+	sort.Ints(mediaIDs)
+	sort.Ints(episodeIDs)
+	sort.Ints(characterIDs)
+	sort.Ints(personIDs)
+
+	jw.array("media", len(mediaIDs), func(i int) interface{} {
+		return l.Media[mediaIDs[i]]
+	})
+	jw.array("episodes", len(episodeIDs), func(i int) interface{} {
+		return l.Episodes[episodeIDs[i]]
+	})
+	jw.array("characters", len(characterIDs), func(i int) interface{} {
+		return l.Characters[characterIDs[i]]
+	})
+	jw.array("persons", len(personIDs), func(i int) interface{} {
+		return l.Persons[personIDs[i]]
+	})
+	jw.array("userMedia", len(l.UserMedia), func(i int) interface{} {
+		return l.UserMedia[i]
+	})
+	jw.array("userEpisodes", len(l.UserEpisodes), func(i int) interface{} {
+		return l.UserEpisodes[i]
+	})
+	jw.array("userCharacters", len(l.UserCharacters), func(i int) interface{} {
+		return l.UserCharacters[i]
+	})
+	jw.array("userPersons", len(l.UserPersons), func(i int) interface{} {
+		return l.UserPersons[i]
+	})
+	jw.array("userMediaLists", len(l.UserMediaLists), func(i int) interface{} {
+		return l.UserMediaLists[i]
+	})
+	jw.write("}\n")
+	return jw.err
+}
+
+// jsonWriter writes a JSON object piece by piece, so that it is not held in
+// memory in full. The first error is kept, after which nothing is written.
+type jsonWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (jw *jsonWriter) write(s string) {
+	if jw.err != nil {
+		return
+	}
+	_, err := io.WriteString(jw.w, s)
+	if err != nil {
+		jw.err = fmt.Errorf("failed to write JSON: %w", err)
+	}
+}
+
+func (jw *jsonWriter) value(v interface{}) {
+	if jw.err != nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		jw.err = fmt.Errorf("failed to encode JSON: %w", err)
+		return
+	}
+	jw.write(string(b))
+}
+
+// field writes the field of the object with the given name and value.
+func (jw *jsonWriter) field(name string, v interface{}) {
+	jw.value(name)
+	jw.write(":")
+	jw.value(v)
+}
+
+// array writes a comma, then the field of the object with the given name and
+// the array of the given number of values, one per line, as returned by
+// value.
+func (jw *jsonWriter) array(name string, n int, value func(i int) interface{}) {
+	jw.write(",\n")
+	jw.value(name)
+	jw.write(":[")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			jw.write(",")
+		}
+		jw.write("\n")
+		jw.value(value(i))
+	}
+	jw.write("]")
+}
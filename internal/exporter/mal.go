@@ -0,0 +1,196 @@
+package exporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/importer"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func init() {
+	Register("myanimelist", malEncoder{})
+}
+
+// malStatuses maps WatchStatuses to the statuses of MyAnimeList anime and
+// manga entries.
+var malStatuses = map[models.WatchStatus][2]string{
+	models.WatchStatusCurrent:   {"Watching", "Reading"},
+	models.WatchStatusCompleted: {"Completed", "Completed"},
+	models.WatchStatusHold:      {"On-Hold", "On-Hold"},
+	models.WatchStatusDropped:   {"Dropped", "Dropped"},
+	models.WatchStatusPlanning:  {"Plan to Watch", "Plan to Read"},
+}
+
+// malInfo is the header of a MyAnimeList XML export.
+type malInfo struct {
+	XMLName    xml.Name `xml:"myinfo"`
+	Username   string   `xml:"user_name"`
+	ExportType int      `xml:"user_export_type"`
+}
+
+type malAnime struct {
+	XMLName      xml.Name `xml:"anime"`
+	ID           string   `xml:"series_animedb_id"`
+	Title        string   `xml:"series_title"`
+	Episodes     int      `xml:"series_episodes"`
+	Watched      int      `xml:"my_watched_episodes"`
+	StartDate    string   `xml:"my_start_date"`
+	FinishDate   string   `xml:"my_finish_date"`
+	Score        int      `xml:"my_score"`
+	Status       string   `xml:"my_status"`
+	Comments     string   `xml:"my_comments"`
+	TimesWatched int      `xml:"my_times_watched"`
+	Rewatching   int      `xml:"my_rewatching"`
+	RewatchingEp int      `xml:"my_rewatching_ep"`
+	Tags         string   `xml:"my_tags"`
+	Update       int      `xml:"update_on_import"`
+}
+
+type malManga struct {
+	XMLName      xml.Name `xml:"manga"`
+	ID           string   `xml:"manga_mangadb_id"`
+	Title        string   `xml:"manga_title"`
+	Chapters     int      `xml:"manga_chapters"`
+	ReadVolumes  int      `xml:"my_read_volumes"`
+	Read         int      `xml:"my_read_chapters"`
+	StartDate    string   `xml:"my_start_date"`
+	FinishDate   string   `xml:"my_finish_date"`
+	Score        int      `xml:"my_score"`
+	Status       string   `xml:"my_status"`
+	Comments     string   `xml:"my_comments"`
+	TimesRead    int      `xml:"my_times_read"`
+	Rereading    int      `xml:"my_rereading"`
+	RereadingChp int      `xml:"my_rereading_chap"`
+	Tags         string   `xml:"my_tags"`
+	Update       int      `xml:"update_on_import"`
+}
+
+// malEncoder writes Libraries as MyAnimeList XML exports, which may be
+// imported by MyAnimeList and most other services. Only UserMedia are
+// written; Media that are read, or that have a MyAnimeList manga ID and no
+// anime ID, are written as manga. Custom lists are written as tags.
+type malEncoder struct{}
+
+func (malEncoder) ContentType() string { return "application/xml" }
+
+func (malEncoder) Extension() string { return "xml" }
+
+func (malEncoder) Encode(w io.Writer, l *Library) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return fmt.Errorf("failed to write XML header: %w", err)
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	root := xml.StartElement{Name: xml.Name{Local: "myanimelist"}}
+	err = enc.EncodeToken(root)
+	if err != nil {
+		return fmt.Errorf("failed to encode MyAnimeList export: %w", err)
+	}
+	err = enc.Encode(malInfo{Username: l.User.Username, ExportType: 1})
+	if err != nil {
+		return fmt.Errorf("failed to encode MyAnimeList export: %w", err)
+	}
+
+	for _, um := range l.UserMedia {
+		err = enc.Encode(l.malEntry(um))
+		if err != nil {
+			return fmt.Errorf("failed to encode UserMedia %d: %w", um.Meta.ID, err)
+		}
+	}
+
+	err = enc.EncodeToken(root.End())
+	if err != nil {
+		return fmt.Errorf("failed to encode MyAnimeList export: %w", err)
+	}
+	return enc.Flush()
+}
+
+// malEntry returns the MyAnimeList anime or manga entry of the given
+// UserMedia.
+func (l *Library) malEntry(um *models.UserMedia) interface{} {
+	var title string
+	animeID, mangaID := "0", ""
+	if md, ok := l.Media[um.MediaID]; ok {
+		title = preferredTitle(md.Titles)
+		for _, id := range md.ExternalIDs {
+			switch id.Source {
+			case importer.SourceMyAnimeList:
+				animeID = id.ID
+			case importer.SourceMyAnimeListManga:
+				mangaID = id.ID
+			}
+		}
+	}
+
+	reading := l.reading(um)
+	p := userMediaProgress(um, reading)
+	var status [2]string
+	if p.status != nil {
+		status = malStatuses[*p.status]
+	}
+	score := 0
+	if um.Score != nil {
+		score = int(models.ScoreSystemPoint10.FromInternal(*um.Score))
+	}
+	tags := strings.Join(l.listNames(um.Meta.ID), ", ")
+
+	if reading || (mangaID != "" && animeID == "0") {
+		if mangaID == "" {
+			mangaID = "0"
+		}
+		return malManga{
+			ID:           mangaID,
+			Title:        title,
+			ReadVolumes:  p.volumes,
+			Read:         p.watched,
+			StartDate:    malDate(p.startDate),
+			FinishDate:   malDate(p.finishDate),
+			Score:        score,
+			Status:       status[1],
+			Comments:     joinTitles(um.Comments),
+			TimesRead:    p.repeats,
+			Rereading:    malBool(p.repeating),
+			RereadingChp: p.repeatProg,
+			Tags:         tags,
+			Update:       1,
+		}
+	}
+	return malAnime{
+		ID:           animeID,
+		Title:        title,
+		Watched:      p.watched,
+		StartDate:    malDate(p.startDate),
+		FinishDate:   malDate(p.finishDate),
+		Score:        score,
+		Status:       status[0],
+		Comments:     joinTitles(um.Comments),
+		TimesWatched: p.repeats,
+		Rewatching:   malBool(p.repeating),
+		RewatchingEp: p.repeatProg,
+		Tags:         tags,
+		Update:       1,
+	}
+}
+
+// malDate returns the given date as written by MyAnimeList, which writes
+// unknown dates as zero.
+func malDate(t *time.Time) string {
+	if t == nil {
+		return "0000-00-00"
+	}
+	return t.Format("2006-01-02")
+}
+
+// malBool returns the given flag as written by MyAnimeList.
+func malBool(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,129 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *aPITokenResolver) User(ctx context.Context, obj *models.APIToken) (*models.User, error) {
+	return resolveUserByID(ctx, obj.UserID)
+}
+
+func (r *mutationResolver) CreateAPIToken(ctx context.Context, name string, scope models.APITokenScope, expiresAt *time.Time) (*CreatedAPIToken, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	var t *models.APIToken
+	var tkn string
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.APITokenService
+		t, tkn, err = ser.Issue(u.Meta.ID, name, scope, expiresAt, tx)
+		if err != nil {
+			return fmt.Errorf("failed to issue APIToken: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &CreatedAPIToken{
+		Token:    tkn,
+		APIToken: t,
+	}, nil
+}
+
+func (r *mutationResolver) RevokeAPIToken(ctx context.Context, id int) (*models.APIToken, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	var t *models.APIToken
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.APITokenService
+		t, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get APIToken by id %d: %w", id, err)
+		}
+
+		_, err = authorizeOwner(ctx, ds, t.UserID,
+			permission("APIToken", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+
+		t, err = ser.Revoke(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to revoke APIToken by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return t, nil
+}
+
+func (r *queryResolver) APITokens(ctx context.Context, userID int, first *int, skip *int) ([]*models.APIToken, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	var list []*models.APIToken
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		_, err := authorizeOwner(ctx, ds, userID,
+			permission("APIToken", models.ActionRead), tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.APITokenService
+		list, err = ser.GetByUser(userID, first, skip, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get APITokens by User id %d: %w", userID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return list, nil
+}
+
+// APIToken returns APITokenResolver implementation.
+func (r *Resolver) APIToken() APITokenResolver { return &aPITokenResolver{r} }
+
+type aPITokenResolver struct{ *Resolver }
@@ -0,0 +1,291 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/Dophin2009/nao/internal/data"
+	"github.com/Dophin2009/nao/internal/jwt"
+	"github.com/Dophin2009/nao/internal/web"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+var (
+	// scopeWriteLists is the API token scope requirement for mutating a
+	// User's own data.
+	scopeWriteLists = models.APITokenScope{WriteLists: true}
+	// scopeWriteMedia is the API token scope requirement for mutating global
+	// Media data.
+	scopeWriteMedia = models.APITokenScope{WriteMedia: true}
+)
+
+var (
+	// errUnauthenticated is an error returned when no User is associated with
+	// the request.
+	errUnauthenticated = errors.New("not authenticated")
+	// errForbidden is an error returned when the User making the request does
+	// not have sufficient permissions.
+	errForbidden = errors.New("insufficient permissions")
+	// errTwoFactorRequired is an error returned when a User that has enabled
+	// two-factor authentication is authenticated without a second factor.
+	errTwoFactorRequired = errors.New("two-factor code required")
+)
+
+func getCtxUser(ctx context.Context) (*models.User, error) {
+	v, ok := ctx.Value(web.UserKey).(*models.User)
+	if !ok || v == nil {
+		return nil, errUnauthenticated
+	}
+	return v, nil
+}
+
+func getCtxClaims(ctx context.Context) (*jwt.Claims, error) {
+	v, ok := ctx.Value(web.ClaimsKey).(*jwt.Claims)
+	if !ok || v == nil {
+		return nil, errUnauthenticated
+	}
+	return v, nil
+}
+
+func getCtxAPIToken(ctx context.Context) (*models.APIToken, bool) {
+	v, ok := ctx.Value(web.APITokenKey).(*models.APIToken)
+	return v, ok && v != nil
+}
+
+// authorizeScope checks that the API token the request was authenticated
+// with, if any, has scopes that meet the given requirements.
+func authorizeScope(ctx context.Context, req *models.APITokenScope) error {
+	t, ok := getCtxAPIToken(ctx)
+	if !ok {
+		return nil
+	}
+
+	if (req.WriteLists && !t.Scope.WriteLists) ||
+		(req.WriteMedia && !t.Scope.WriteMedia) {
+		return fmt.Errorf("API token %d: %w", t.Meta.ID, errForbidden)
+	}
+	return nil
+}
+
+// authorizeAccount checks that the request was not authenticated with an API
+// token, which may not be used to manage accounts, Sessions, or other API
+// tokens.
+func authorizeAccount(ctx context.Context) error {
+	t, ok := getCtxAPIToken(ctx)
+	if ok {
+		return fmt.Errorf("API token %d may not manage accounts: %w",
+			t.Meta.ID, errForbidden)
+	}
+	return nil
+}
+
+// permission returns the Permission to perform the given Action on the given
+// type of model.
+func permission(model string, action models.Action) *models.Permission {
+	return &models.Permission{Model: model, Action: action}
+}
+
+// authorizePermissionScope checks that the request was authenticated with
+// credentials that may be used to exercise the given Permission. API tokens
+// may read any data, but require a scope to mutate data and may never manage
+// accounts.
+func authorizePermissionScope(ctx context.Context, perm *models.Permission) error {
+	if containsString(data.AccountModelTypes, perm.Model) {
+		return authorizeAccount(ctx)
+	}
+	if perm.Action == models.ActionRead {
+		return nil
+	}
+	if containsString(data.MediaModelTypes, perm.Model) {
+		return authorizeScope(ctx, &scopeWriteMedia)
+	}
+	return authorizeScope(ctx, &scopeWriteLists)
+}
+
+// authorize checks that the User making the request is assigned a Role that
+// grants the given Permission. The User is retrieved again within the given
+// transaction so that the latest Roles are used.
+func authorize(ctx context.Context, ds *DataService, perm *models.Permission,
+	tx db.Tx) (*models.User, error) {
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = authorizePermissionScope(ctx, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := ds.UserService.Authorize(u.Meta.ID, perm, tx)
+	if err != nil {
+		return nil, fmt.Errorf("User %d: %v: %w", u.Meta.ID, err, errForbidden)
+	}
+	return user, nil
+}
+
+// authorizeOwner checks that the User making the request is either the User
+// with the given ID or is assigned a Role that grants the given Permission.
+func authorizeOwner(ctx context.Context, ds *DataService, uID int,
+	perm *models.Permission, tx db.Tx) (*models.User, error) {
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = authorizePermissionScope(ctx, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	return authorizeOwnerOrPermitted(u, ds, uID, perm, tx)
+}
+
+// authorizeOwnerOrPermitted checks that the given User is either the User with
+// the given ID or is assigned a Role that grants the given Permission,
+// regardless of how the request was authenticated.
+func authorizeOwnerOrPermitted(u *models.User, ds *DataService, uID int,
+	perm *models.Permission, tx db.Tx) (*models.User, error) {
+	user, err := ds.UserService.GetByID(u.Meta.ID, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get User by id %d: %w", u.Meta.ID, err)
+	}
+
+	if user.Meta.ID != uID && !ds.UserService.HasPermission(user, perm) {
+		return nil, fmt.Errorf("User %d: %s %s: %w", user.Meta.ID,
+			perm.Action, perm.Model, errForbidden)
+	}
+	return user, nil
+}
+
+// Auth implements the @auth directive, which restricts a field to
+// authenticated requests.
+func Auth(ctx context.Context, obj interface{},
+	next graphql.Resolver) (interface{}, error) {
+	_, err := getCtxUser(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+	return next(ctx)
+}
+
+// HasPermission implements the @hasPermission directive, which restricts a
+// field to Users assigned a Role that grants the Permission to perform the
+// given Action on the given type of model.
+func HasPermission(ctx context.Context, obj interface{}, next graphql.Resolver,
+	model string, action models.Action) (interface{}, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		_, err := authorize(ctx, ds, permission(model, action), tx)
+		return err
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+	return next(ctx)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// issueToken creates a new access token for the given Session of the given
+// User.
+func (r *Resolver) issueToken(u *models.User, s *models.Session,
+	refreshTkn string) (*AuthPayload, error) {
+	tknstr, err := r.Authenticator.NewToken(u.Username, s.Meta.ID, r.TokenDuration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token: %w", err)
+	}
+
+	return &AuthPayload{
+		Token:        tknstr,
+		RefreshToken: refreshTkn,
+		Session:      s,
+		User:         u,
+	}, nil
+}
+
+// sessionDevice returns the device description given to an authentication
+// mutation, or a placeholder if none was given.
+func sessionDevice(device *string) string {
+	if device == nil || *device == "" {
+		return "unknown"
+	}
+	return *device
+}
+
+// authorizeSession checks that the User making the request owns the Session
+// with the given ID or is assigned a Role that grants the given Action on
+// Sessions.
+func authorizeSession(ctx context.Context, ds *DataService, sID int,
+	action models.Action, tx db.Tx) (*models.Session, error) {
+	s, err := ds.SessionService.GetByID(sID, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Session by id %d: %w", sID, err)
+	}
+
+	_, err = authorizeOwner(ctx, ds, s.UserID, permission("Session", action), tx)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// errorResolve converts known errors from the data layer and authorization
+// checks into GraphQL errors with an error code extension. Other errors are
+// returned unchanged.
+func errorResolve(err error) error {
+	var verr *data.ValidationError
+	var lerr *lockedOutError
+	switch {
+	case errors.As(err, &verr):
+		ext := map[string]interface{}{
+			"code":  "VALIDATION_FAILED",
+			"model": verr.Model,
+			"field": verr.Field,
+		}
+		if reason := verr.Reason(); reason != "" {
+			ext["reason"] = reason
+		}
+		return &gqlerror.Error{Message: verr.Error(), Extensions: ext}
+	case errors.As(err, &lerr):
+		return &gqlerror.Error{
+			Message: lerr.Error(),
+			Extensions: map[string]interface{}{
+				"code":       "LOCKED_OUT",
+				"retryAfter": lerr.until.Format(time.RFC3339),
+			},
+		}
+	case errors.Is(err, errUnauthenticated):
+		return &gqlerror.Error{
+			Message:    err.Error(),
+			Extensions: map[string]interface{}{"code": "UNAUTHENTICATED"},
+		}
+	case errors.Is(err, errForbidden):
+		return &gqlerror.Error{
+			Message:    err.Error(),
+			Extensions: map[string]interface{}{"code": "FORBIDDEN"},
+		}
+	case errors.Is(err, errTwoFactorRequired):
+		return &gqlerror.Error{
+			Message:    err.Error(),
+			Extensions: map[string]interface{}{"code": "TWO_FACTOR_REQUIRED"},
+		}
+	}
+	return err
+}
@@ -0,0 +1,457 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/Dophin2009/nao/internal/data"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	log "github.com/sirupsen/logrus"
+)
+
+func (r *mutationResolver) Login(ctx context.Context, username string, password string, twoFactorCode *string, device *string) (*AuthPayload, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	keys := lockoutKeys(ctx, username)
+	err = r.checkLockout(keys)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	var u *models.User
+	var s *models.Session
+	var refreshTkn string
+	failed := false
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserService
+		err = ser.AuthenticateWithPassword(username, password, tx)
+		if err != nil {
+			failed = true
+			return fmt.Errorf("invalid username or password: %w", errUnauthenticated)
+		}
+
+		u, err = ser.GetByUsername(username, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get User by username %q: %w", username, err)
+		}
+
+		err = verifyTwoFactor(ds, u, twoFactorCode, tx)
+		if err != nil {
+			failed = !errors.Is(err, errTwoFactorRequired)
+			return err
+		}
+
+		s, refreshTkn, err = ds.SessionService.Start(u.Meta.ID,
+			sessionDevice(device), r.RefreshDuration, tx)
+		if err != nil {
+			return fmt.Errorf("failed to start Session: %w", err)
+		}
+		return nil
+	})
+	if failed {
+		r.failLockout(keys)
+	}
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+	r.resetLockout(keys)
+
+	return r.issueToken(u, s, refreshTkn)
+}
+
+func (r *mutationResolver) Register(ctx context.Context, username string, email string, password string, invitation *string, device *string) (*AuthPayload, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	u := models.User{
+		Username: username,
+		Email:    email,
+		Password: []byte(password),
+	}
+	var s *models.Session
+	var refreshTkn string
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		redeem, err := r.checkRegistration(ds, invitation, tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.UserService
+		_, err = ser.Create(&u, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create User: %w", err)
+		}
+
+		if redeem {
+			_, err = ds.InvitationService.Redeem(*invitation, u.Meta.ID, tx)
+			if err != nil {
+				return fmt.Errorf("invalid invitation code: %w", errForbidden)
+			}
+		}
+
+		s, refreshTkn, err = ds.SessionService.Start(u.Meta.ID,
+			sessionDevice(device), r.RefreshDuration, tx)
+		if err != nil {
+			return fmt.Errorf("failed to start Session: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	// The User is registered even if the verification instructions cannot be
+	// delivered; they may be requested again
+	if r.Notifier != nil {
+		err = r.sendEmailVerification(ds, &u)
+		if err != nil {
+			graphql.AddError(ctx, err)
+		}
+	}
+
+	return r.issueToken(&u, s, refreshTkn)
+}
+
+func (r *mutationResolver) Logout(ctx context.Context) (bool, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return false, errorGetDataServices(err)
+	}
+
+	claims, err := getCtxClaims(ctx)
+	if err != nil {
+		return false, errorResolve(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.SessionService
+		_, err = ser.Revoke(claims.SessionID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to revoke Session by id %d: %w",
+				claims.SessionID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, errorResolve(err)
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string) (*AuthPayload, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var u *models.User
+	var s *models.Session
+	var refreshTkn string
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.SessionService
+		s, refreshTkn, err = ser.Refresh(refreshToken, r.RefreshDuration, tx)
+		if err != nil {
+			return fmt.Errorf("invalid refresh token: %v: %w", err, errUnauthenticated)
+		}
+
+		u, err = ds.UserService.GetByID(s.UserID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get User by id %d: %w", s.UserID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return r.issueToken(u, s, refreshTkn)
+}
+
+func (r *mutationResolver) ChangePassword(ctx context.Context, oldPassword string, newPassword string, device *string) (*AuthPayload, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	cu, err := getCtxUser(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	keys := lockoutKeys(ctx, cu.Username)
+	err = r.checkLockout(keys)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	var u *models.User
+	var s *models.Session
+	var refreshTkn string
+	failed := false
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserService
+		err = ser.AuthenticateWithPassword(cu.Username, oldPassword, tx)
+		if err != nil {
+			failed = true
+			return fmt.Errorf("invalid password: %w", errUnauthenticated)
+		}
+
+		// Changing the password revokes all existing Sessions
+		err = ser.ChangePassword(cu.Meta.ID, newPassword, tx)
+		if err != nil {
+			return fmt.Errorf("failed to change password of User %d: %w",
+				cu.Meta.ID, err)
+		}
+
+		u, err = ser.GetByID(cu.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get User by id %d: %w", cu.Meta.ID, err)
+		}
+
+		s, refreshTkn, err = ds.SessionService.Start(u.Meta.ID,
+			sessionDevice(device), r.RefreshDuration, tx)
+		if err != nil {
+			return fmt.Errorf("failed to start Session: %w", err)
+		}
+		return nil
+	})
+	if failed {
+		r.failLockout(keys)
+	}
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+	r.resetLockout(keys)
+
+	return r.issueToken(u, s, refreshTkn)
+}
+
+func (r *mutationResolver) RequestPasswordReset(ctx context.Context, email string) (bool, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return false, errorGetDataServices(err)
+	}
+
+	var u *models.User
+	var pr *models.PasswordReset
+	var tkn string
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		// Whether a User with the email exists is not revealed
+		u, err = ds.UserService.GetByEmail(email, tx)
+		if err != nil {
+			u = nil
+			return nil
+		}
+
+		pr, tkn, err = ds.PasswordResetService.Request(u.Meta.ID,
+			r.PasswordResetDuration, tx)
+		if err != nil {
+			return fmt.Errorf("failed to request PasswordReset: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, errorResolve(err)
+	}
+	if u == nil || r.Notifier == nil {
+		return true, nil
+	}
+
+	// Failing to deliver the instructions is not reported either, so that
+	// whether a User with the email exists is not revealed
+	err = r.Notifier.Notify(r.passwordResetMessage(u, tkn, pr.ExpiresAt))
+	if err != nil {
+		log.Errorf("Failed to send password reset instructions to User %d: %v",
+			u.Meta.ID, err)
+	}
+	return true, nil
+}
+
+func (r *mutationResolver) ResetPassword(ctx context.Context, token string, newPassword string, twoFactorCode *string, device *string) (*AuthPayload, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var u *models.User
+	var s *models.Session
+	var refreshTkn string
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		pr, err := ds.PasswordResetService.Consume(token, tx)
+		if err != nil {
+			return fmt.Errorf("invalid reset token: %w", errUnauthenticated)
+		}
+
+		// The reset token is not consumed if the second factor is missing
+		ser := ds.UserService
+		u, err = ser.GetByID(pr.UserID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get User by id %d: %w", pr.UserID, err)
+		}
+		err = verifyTwoFactor(ds, u, twoFactorCode, tx)
+		if err != nil {
+			return err
+		}
+
+		// Changing the password revokes all existing Sessions
+		err = ser.ChangePassword(pr.UserID, newPassword, tx)
+		if err != nil {
+			return fmt.Errorf("failed to change password of User %d: %w",
+				pr.UserID, err)
+		}
+
+		u, err = ser.GetByID(pr.UserID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get User by id %d: %w", pr.UserID, err)
+		}
+
+		s, refreshTkn, err = ds.SessionService.Start(u.Meta.ID,
+			sessionDevice(device), r.RefreshDuration, tx)
+		if err != nil {
+			return fmt.Errorf("failed to start Session: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+	r.resetLockout(lockoutKeys(ctx, u.Username))
+
+	return r.issueToken(u, s, refreshTkn)
+}
+
+func (r *mutationResolver) RevokeSession(ctx context.Context, id int) (*models.Session, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	var s *models.Session
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		_, err := authorizeSession(ctx, ds, id, models.ActionUpdate, tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.SessionService
+		s, err = ser.Revoke(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to revoke Session by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return s, nil
+}
+
+func (r *mutationResolver) RevokeAllSessions(ctx context.Context, userID int) (int, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return 0, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return 0, errorResolve(err)
+	}
+
+	var n int
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		_, err := authorizeOwner(ctx, ds, userID,
+			permission("Session", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.SessionService
+		n, err = ser.RevokeByUser(userID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to revoke Sessions by User id %d: %w",
+				userID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, errorResolve(err)
+	}
+
+	return n, nil
+}
+
+func (r *queryResolver) Me(ctx context.Context) (*models.User, error) {
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return nil, nil
+	}
+
+	return resolveUserByID(ctx, u.Meta.ID)
+}
+
+func (r *queryResolver) Sessions(ctx context.Context, userID int, first *int, skip *int) ([]*models.Session, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	var list []*models.Session
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		_, err := authorizeOwner(ctx, ds, userID,
+			permission("Session", models.ActionRead), tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.SessionService
+		list, err = ser.GetFilter(first, skip, tx, func(s *models.Session) bool {
+			return s.UserID == userID && ser.IsActive(s)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get Sessions by User id %d: %w", userID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return list, nil
+}
+
+func (r *queryResolver) PasswordPolicy(ctx context.Context) (*data.PasswordPolicy, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	return &ds.UserService.PasswordPolicy, nil
+}
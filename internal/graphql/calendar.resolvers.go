@@ -0,0 +1,75 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/db"
+)
+
+func (r *mutationResolver) ResetCalendarToken(ctx context.Context) (string, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return "", errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return "", errorResolve(err)
+	}
+
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return "", errorResolve(err)
+	}
+
+	var tkn string
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.CalendarTokenService
+		tkn, err = ser.Issue(u.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to issue CalendarToken: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", errorResolve(err)
+	}
+
+	return tkn, nil
+}
+
+func (r *mutationResolver) RevokeCalendarToken(ctx context.Context) (bool, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return false, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return false, errorResolve(err)
+	}
+
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return false, errorResolve(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.CalendarTokenService
+		err = ser.DeleteByUser(u.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete CalendarToken by User id %d: %w",
+				u.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, errorResolve(err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,261 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *chapterResolver) Media(ctx context.Context, obj *models.Chapter) (*models.Media, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var md *models.Media
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.MediaService
+		md, err = ser.GetByID(obj.MediaID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Media by id %d: %w", obj.MediaID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return md, nil
+}
+
+func (r *chapterResolver) Volume(ctx context.Context, obj *models.Chapter) (*models.Volume, error) {
+	if obj.VolumeID == nil {
+		return nil, nil
+	}
+
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var v *models.Volume
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.VolumeService
+		v, err = ser.GetByID(*obj.VolumeID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Volume by id %d: %w", *obj.VolumeID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (r *chapterResolver) Titles(ctx context.Context, obj *models.Chapter, first *int, skip *int) ([]*models.Title, error) {
+	return sliceTitles(obj.Titles, first, skip), nil
+}
+
+func (r *mutationResolver) CreateVolume(ctx context.Context, volume models.Volume) (*models.Volume, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.VolumeService
+		_, err = ser.Create(&volume, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create Volume: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &volume, nil
+}
+
+func (r *mutationResolver) UpdateVolume(ctx context.Context, volume models.Volume) (*models.Volume, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.VolumeService
+		err = ser.Update(&volume, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update Volume by id %d: %w", volume.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &volume, nil
+}
+
+func (r *mutationResolver) DeleteVolume(ctx context.Context, id int) (*models.Volume, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var v *models.Volume
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.VolumeService
+		v, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Volume by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Volume by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return v, nil
+}
+
+func (r *mutationResolver) CreateChapter(ctx context.Context, chapter models.Chapter) (*models.Chapter, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.ChapterService
+		_, err = ser.Create(&chapter, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create Chapter: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &chapter, nil
+}
+
+func (r *mutationResolver) UpdateChapter(ctx context.Context, chapter models.Chapter) (*models.Chapter, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.ChapterService
+		err = ser.Update(&chapter, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update Chapter by id %d: %w", chapter.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &chapter, nil
+}
+
+func (r *mutationResolver) DeleteChapter(ctx context.Context, id int) (*models.Chapter, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var ch *models.Chapter
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.ChapterService
+		ch, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Chapter by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Chapter by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return ch, nil
+}
+
+func (r *volumeResolver) Media(ctx context.Context, obj *models.Volume) (*models.Media, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var md *models.Media
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.MediaService
+		md, err = ser.GetByID(obj.MediaID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Media by id %d: %w", obj.MediaID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return md, nil
+}
+
+func (r *volumeResolver) Titles(ctx context.Context, obj *models.Volume, first *int, skip *int) ([]*models.Title, error) {
+	return sliceTitles(obj.Titles, first, skip), nil
+}
+
+func (r *volumeResolver) Chapters(ctx context.Context, obj *models.Volume, first *int, skip *int) ([]*models.Chapter, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var list []*models.Chapter
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.ChapterService
+		list, err = ser.GetByVolume(obj.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Chapters by Volume id %d: %w",
+				obj.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := calculatePaginationBounds(first, skip, len(list))
+	return list[start:end], nil
+}
+
+// Chapter returns ChapterResolver implementation.
+func (r *Resolver) Chapter() ChapterResolver { return &chapterResolver{r} }
+
+// Volume returns VolumeResolver implementation.
+func (r *Resolver) Volume() VolumeResolver { return &volumeResolver{r} }
+
+type chapterResolver struct{ *Resolver }
+type volumeResolver struct{ *Resolver }
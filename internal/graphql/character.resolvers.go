@@ -42,6 +42,75 @@ func (r *characterResolver) Media(ctx context.Context, obj *models.Character, fi
 	return list, nil
 }
 
+func (r *mutationResolver) CreateCharacter(ctx context.Context, character models.Character) (*models.Character, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.CharacterService
+		_, err = ser.Create(&character, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create Character: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &character, nil
+}
+
+func (r *mutationResolver) UpdateCharacter(ctx context.Context, character models.Character) (*models.Character, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.CharacterService
+		err = ser.Update(&character, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update Character by id %d: %w", character.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &character, nil
+}
+
+func (r *mutationResolver) DeleteCharacter(ctx context.Context, id int) (*models.Character, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var c *models.Character
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.CharacterService
+		c, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Character by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Character by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return c, nil
+}
+
 // Character returns CharacterResolver implementation.
 func (r *Resolver) Character() CharacterResolver { return &characterResolver{r} }
 
@@ -67,6 +67,144 @@ func (r *episodeSetResolver) Episodes(ctx context.Context, obj *models.EpisodeSe
 	return list, nil
 }
 
+func (r *mutationResolver) CreateEpisode(ctx context.Context, episode models.Episode) (*models.Episode, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.EpisodeService
+		_, err = ser.Create(&episode, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create Episode: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &episode, nil
+}
+
+func (r *mutationResolver) UpdateEpisode(ctx context.Context, episode models.Episode) (*models.Episode, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.EpisodeService
+		err = ser.Update(&episode, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update Episode by id %d: %w", episode.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &episode, nil
+}
+
+func (r *mutationResolver) DeleteEpisode(ctx context.Context, id int) (*models.Episode, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var ep *models.Episode
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.EpisodeService
+		ep, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Episode by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Episode by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return ep, nil
+}
+
+func (r *mutationResolver) CreateEpisodeSet(ctx context.Context, episodeSet models.EpisodeSet) (*models.EpisodeSet, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.EpisodeSetService
+		_, err = ser.Create(&episodeSet, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create EpisodeSet: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &episodeSet, nil
+}
+
+func (r *mutationResolver) UpdateEpisodeSet(ctx context.Context, episodeSet models.EpisodeSet) (*models.EpisodeSet, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.EpisodeSetService
+		err = ser.Update(&episodeSet, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update EpisodeSet by id %d: %w", episodeSet.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &episodeSet, nil
+}
+
+func (r *mutationResolver) DeleteEpisodeSet(ctx context.Context, id int) (*models.EpisodeSet, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var set *models.EpisodeSet
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.EpisodeSetService
+		set, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get EpisodeSet by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete EpisodeSet by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return set, nil
+}
+
 // Episode returns EpisodeResolver implementation.
 func (r *Resolver) Episode() EpisodeResolver { return &episodeResolver{r} }
 
@@ -0,0 +1,141 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *episodeProgressResolver) UserMedia(ctx context.Context, obj *models.EpisodeProgress) (*models.UserMedia, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var um *models.UserMedia
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.UserMediaService
+		um, err = ser.GetByID(obj.UserMediaID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserMedia by id %d: %w", obj.UserMediaID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return um, nil
+}
+
+func (r *episodeProgressResolver) EpisodeSet(ctx context.Context, obj *models.EpisodeProgress) (*models.EpisodeSet, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var set *models.EpisodeSet
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.EpisodeSetService
+		set, err = ser.GetByID(obj.EpisodeSetID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get EpisodeSet by id %d: %w", obj.EpisodeSetID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return set, nil
+}
+
+func (r *episodeProgressResolver) Watched(ctx context.Context, obj *models.EpisodeProgress) (int, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return 0, errorGetDataServices(err)
+	}
+
+	var n int
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		n, err = ds.EpisodeProgressService.Watched(obj, tx)
+		return err
+	})
+	if err != nil {
+		return 0, errorResolve(err)
+	}
+
+	return n, nil
+}
+
+func (r *mutationResolver) MarkEpisodesWatched(ctx context.Context, mediaID int, episodeSetID int, upTo int) (*models.EpisodeProgress, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	var ep *models.EpisodeProgress
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		// Progress is tracked as part of the UserMedia
+		_, err := authorizeOwner(ctx, ds, u.Meta.ID,
+			permission("UserMedia", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.EpisodeProgressService
+		ep, err = ser.MarkWatched(u.Meta.ID, mediaID, episodeSetID, upTo,
+			time.Now(), tx)
+		if err != nil {
+			return fmt.Errorf("failed to mark Episodes as watched: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return ep, nil
+}
+
+func (r *watchedEpisodeResolver) Episode(ctx context.Context, obj *models.WatchedEpisode) (*models.Episode, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var ep *models.Episode
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.EpisodeService
+		ep, err = ser.GetByID(obj.EpisodeID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Episode by id %d: %w", obj.EpisodeID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return ep, nil
+}
+
+// EpisodeProgress returns EpisodeProgressResolver implementation.
+func (r *Resolver) EpisodeProgress() EpisodeProgressResolver { return &episodeProgressResolver{r} }
+
+// WatchedEpisode returns WatchedEpisodeResolver implementation.
+func (r *Resolver) WatchedEpisode() WatchedEpisodeResolver { return &watchedEpisodeResolver{r} }
+
+type episodeProgressResolver struct{ *Resolver }
+type watchedEpisodeResolver struct{ *Resolver }
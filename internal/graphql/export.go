@@ -0,0 +1,24 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// AuthorizeExport checks that the User making the request may export the data
+// of the User with the given ID: either the User itself, or one assigned a
+// Role that grants the Permission to update UserMedia. API tokens of any
+// scope may be used, as exporting does not mutate data.
+func AuthorizeExport(ctx context.Context, ds *DataService, uID int,
+	tx db.Tx) error {
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = authorizeOwnerOrPermitted(u, ds, uID,
+		permission("UserMedia", models.ActionUpdate), tx)
+	return err
+}
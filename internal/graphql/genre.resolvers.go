@@ -42,6 +42,75 @@ func (r *genreResolver) Media(ctx context.Context, obj *models.Genre, first *int
 	return list, nil
 }
 
+func (r *mutationResolver) CreateGenre(ctx context.Context, genre models.Genre) (*models.Genre, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.GenreService
+		_, err = ser.Create(&genre, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create Genre: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &genre, nil
+}
+
+func (r *mutationResolver) UpdateGenre(ctx context.Context, genre models.Genre) (*models.Genre, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.GenreService
+		err = ser.Update(&genre, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update Genre by id %d: %w", genre.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &genre, nil
+}
+
+func (r *mutationResolver) DeleteGenre(ctx context.Context, id int) (*models.Genre, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var g *models.Genre
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.GenreService
+		g, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Genre by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Genre by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return g, nil
+}
+
 // Genre returns GenreResolver implementation.
 func (r *Resolver) Genre() GenreResolver { return &genreResolver{r} }
 
@@ -0,0 +1,74 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Dophin2009/nao/internal/importer"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// errDryRun is returned within the transaction of a dry run of an import to
+// roll it back.
+var errDryRun = errors.New("dry run")
+
+// newImporter returns an Importer using the given DataService.
+func newImporter(ds *DataService) *importer.Importer {
+	return &importer.Importer{
+		MediaService:          ds.MediaService,
+		UserMediaService:      ds.UserMediaService,
+		UserMediaListService:  ds.UserMediaListService,
+		GenreService:          ds.GenreService,
+		CharacterService:      ds.CharacterService,
+		MediaGenreService:     ds.MediaGenreService,
+		MediaRelationService:  ds.MediaRelationSerivce,
+		MediaCharacterService: ds.MediaCharacterService,
+	}
+}
+
+// runImport imports the export of the given format read from the given
+// reader for the User with the given ID. The catalog data of the export is
+// only imported if the User making the request may create Media. Nothing is
+// persisted in a dry run.
+func runImport(ctx context.Context, userID int, format string, r io.Reader,
+	dryRun bool) (*importer.Report, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	export, err := importer.Parse(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var report *importer.Report
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		_, err := authorizeOwner(ctx, ds, userID,
+			permission("UserMedia", models.ActionCreate), tx)
+		if err != nil {
+			return err
+		}
+
+		im := newImporter(ds)
+		_, err = authorize(ctx, ds, permission("Media", models.ActionCreate), tx)
+		im.ImportCatalog = err == nil
+
+		report, err = im.Import(userID, export, dryRun, tx)
+		if err != nil {
+			return fmt.Errorf("failed to import %s export: %w", format, err)
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, errorResolve(err)
+	}
+
+	return report, nil
+}
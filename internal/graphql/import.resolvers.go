@@ -0,0 +1,142 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Dophin2009/nao/internal/importer"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *importListResultResolver) Name(ctx context.Context, obj *importer.ListResult) (string, error) {
+	return obj.List.Name, nil
+}
+
+func (r *importListResultResolver) UserMediaList(ctx context.Context, obj *importer.ListResult) (*models.UserMediaList, error) {
+	if obj.UserMediaListID == 0 {
+		return nil, nil
+	}
+
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var l *models.UserMediaList
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.UserMediaListService
+		l, err = ser.GetByID(obj.UserMediaListID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserMediaList by id %d: %w", obj.UserMediaListID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return l, nil
+}
+
+func (r *importMediaResultResolver) Source(ctx context.Context, obj *importer.MediaResult) (string, error) {
+	return obj.Record.Source, nil
+}
+
+func (r *importMediaResultResolver) ExternalID(ctx context.Context, obj *importer.MediaResult) (string, error) {
+	return obj.Record.ExternalID, nil
+}
+
+func (r *importMediaResultResolver) Title(ctx context.Context, obj *importer.MediaResult) (string, error) {
+	if len(obj.Record.Titles) == 0 {
+		return "", nil
+	}
+	return obj.Record.Titles[0].String, nil
+}
+
+func (r *importMediaResultResolver) Media(ctx context.Context, obj *importer.MediaResult) (*models.Media, error) {
+	if obj.MediaID == 0 {
+		return nil, nil
+	}
+	return resolveMediaByID(ctx, obj.MediaID)
+}
+
+func (r *importResultResolver) Source(ctx context.Context, obj *importer.Result) (string, error) {
+	return obj.Entry.Source, nil
+}
+
+func (r *importResultResolver) ExternalID(ctx context.Context, obj *importer.Result) (string, error) {
+	return obj.Entry.ExternalID, nil
+}
+
+func (r *importResultResolver) Title(ctx context.Context, obj *importer.Result) (string, error) {
+	return obj.Entry.Title(), nil
+}
+
+func (r *importResultResolver) Status(ctx context.Context, obj *importer.Result) (*models.WatchStatus, error) {
+	return obj.Entry.Status, nil
+}
+
+func (r *importResultResolver) Score(ctx context.Context, obj *importer.Result) (*int, error) {
+	return obj.Entry.Score, nil
+}
+
+func (r *importResultResolver) Media(ctx context.Context, obj *importer.Result) (*models.Media, error) {
+	if obj.MediaID == 0 {
+		return nil, nil
+	}
+	return resolveMediaByID(ctx, obj.MediaID)
+}
+
+func (r *importResultResolver) UserMedia(ctx context.Context, obj *importer.Result) (*models.UserMedia, error) {
+	if obj.UserMediaID == 0 {
+		return nil, nil
+	}
+
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var um *models.UserMedia
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.UserMediaService
+		um, err = ser.GetByID(obj.UserMediaID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserMedia by id %d: %w", obj.UserMediaID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return um, nil
+}
+
+func (r *mutationResolver) ImportList(ctx context.Context, userID int, format string, data string, dryRun bool) (*importer.Report, error) {
+	return runImport(ctx, userID, format, strings.NewReader(data), dryRun)
+}
+
+func (r *queryResolver) ImportFormats(ctx context.Context) ([]string, error) {
+	return importer.Formats(), nil
+}
+
+// ImportListResult returns ImportListResultResolver implementation.
+func (r *Resolver) ImportListResult() ImportListResultResolver { return &importListResultResolver{r} }
+
+// ImportMediaResult returns ImportMediaResultResolver implementation.
+func (r *Resolver) ImportMediaResult() ImportMediaResultResolver {
+	return &importMediaResultResolver{r}
+}
+
+// ImportResult returns ImportResultResolver implementation.
+func (r *Resolver) ImportResult() ImportResultResolver { return &importResultResolver{r} }
+
+type importListResultResolver struct{ *Resolver }
+type importMediaResultResolver struct{ *Resolver }
+type importResultResolver struct{ *Resolver }
@@ -6,6 +6,7 @@ package graphql
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Dophin2009/nao/pkg/db"
 	"github.com/Dophin2009/nao/pkg/models"
@@ -23,6 +24,14 @@ func (r *mediaResolver) Background(ctx context.Context, obj *models.Media, first
 	return sliceTitles(obj.Titles, first, skip), nil
 }
 
+func (r *mediaResolver) Premiered(ctx context.Context, obj *models.Media) (*models.Season, error) {
+	s, ok := obj.Premiered()
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+
 func (r *mediaResolver) EpisodeSets(ctx context.Context, obj *models.Media, first *int, skip *int) ([]*models.EpisodeSet, error) {
 	ds, err := getCtxDataService(ctx)
 	if err != nil {
@@ -43,6 +52,125 @@ func (r *mediaResolver) EpisodeSets(ctx context.Context, obj *models.Media, firs
 	return list, nil
 }
 
+func (r *mediaResolver) Volumes(ctx context.Context, obj *models.Media, first *int, skip *int) ([]*models.Volume, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var list []*models.Volume
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.VolumeService
+		list, err = ser.GetByMedia(obj.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Volumes by Media id %d: %w",
+				obj.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := calculatePaginationBounds(first, skip, len(list))
+	return list[start:end], nil
+}
+
+func (r *mediaResolver) Chapters(ctx context.Context, obj *models.Media, first *int, skip *int) ([]*models.Chapter, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var list []*models.Chapter
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.ChapterService
+		list, err = ser.GetByMedia(obj.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Chapters by Media id %d: %w",
+				obj.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := calculatePaginationBounds(first, skip, len(list))
+	return list[start:end], nil
+}
+
+func (r *mediaResolver) BroadcastSlots(ctx context.Context, obj *models.Media, first *int, skip *int) ([]*models.BroadcastSlot, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var list []*models.BroadcastSlot
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.BroadcastSlotService
+		list, err = ser.GetByMedia(obj.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get BroadcastSlots by Media id %d: %w",
+				obj.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := calculatePaginationBounds(first, skip, len(list))
+	return list[start:end], nil
+}
+
+func (r *mediaResolver) Airings(ctx context.Context, obj *models.Media, first *int, skip *int) ([]*models.Airing, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var list []*models.Airing
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.AiringService
+		list, err = ser.GetByMedia(obj.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Airings by Media id %d: %w",
+				obj.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := calculatePaginationBounds(first, skip, len(list))
+	return list[start:end], nil
+}
+
+func (r *mediaResolver) NextAiring(ctx context.Context, obj *models.Media) (*models.ScheduledAiring, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var next *models.ScheduledAiring
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.ScheduleService
+		next, err = ser.GetNext(obj.Meta.ID, time.Now(), tx)
+		if err != nil {
+			return fmt.Errorf("failed to get next airing of Media id %d: %w",
+				obj.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return next, nil
+}
+
 func (r *mediaResolver) Producers(ctx context.Context, obj *models.Media, first *int, skip *int) ([]*models.MediaProducer, error) {
 	ds, err := getCtxDataService(ctx)
 	if err != nil {
@@ -103,6 +231,118 @@ func (r *mediaResolver) Genres(ctx context.Context, obj *models.Media, first *in
 	return list, nil
 }
 
+func (r *mutationResolver) CreateMedia(ctx context.Context, media models.Media) (*models.Media, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaService
+		_, err = ser.Create(&media, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create Media: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &media, nil
+}
+
+func (r *mutationResolver) UpdateMedia(ctx context.Context, media models.Media) (*models.Media, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaService
+		err = ser.Update(&media, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update Media by id %d: %w", media.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &media, nil
+}
+
+func (r *mutationResolver) DeleteMedia(ctx context.Context, id int) (*models.Media, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var md *models.Media
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaService
+		md, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Media by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Media by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return md, nil
+}
+
+func (r *queryResolver) MediaByID(ctx context.Context, id int) (*models.Media, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var md *models.Media
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.MediaService
+		md, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Media by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return md, nil
+}
+
+func (r *queryResolver) MediaByExternalID(ctx context.Context, source string, id string) (*models.Media, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var md *models.Media
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.MediaService
+		md, err = ser.GetByExternalID(source, id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Media by external ID %s %q: %w",
+				source, id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return md, nil
+}
+
 // Media returns MediaResolver implementation.
 func (r *Resolver) Media() MediaResolver { return &mediaResolver{r} }
 
@@ -63,6 +63,75 @@ func (r *mediaCharacterResolver) Person(ctx context.Context, obj *models.MediaCh
 	return p, nil
 }
 
+func (r *mutationResolver) CreateMediaCharacter(ctx context.Context, mediaCharacter models.MediaCharacter) (*models.MediaCharacter, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaCharacterService
+		_, err = ser.Create(&mediaCharacter, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create MediaCharacter: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &mediaCharacter, nil
+}
+
+func (r *mutationResolver) UpdateMediaCharacter(ctx context.Context, mediaCharacter models.MediaCharacter) (*models.MediaCharacter, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaCharacterService
+		err = ser.Update(&mediaCharacter, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update MediaCharacter by id %d: %w", mediaCharacter.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &mediaCharacter, nil
+}
+
+func (r *mutationResolver) DeleteMediaCharacter(ctx context.Context, id int) (*models.MediaCharacter, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var mc *models.MediaCharacter
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaCharacterService
+		mc, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get MediaCharacter by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete MediaCharacter by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return mc, nil
+}
+
 // MediaCharacter returns MediaCharacterResolver implementation.
 func (r *Resolver) MediaCharacter() MediaCharacterResolver { return &mediaCharacterResolver{r} }
 
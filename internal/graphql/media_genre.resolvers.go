@@ -34,6 +34,75 @@ func (r *mediaGenreResolver) Genre(ctx context.Context, obj *models.MediaGenre)
 	return g, nil
 }
 
+func (r *mutationResolver) CreateMediaGenre(ctx context.Context, mediaGenre models.MediaGenre) (*models.MediaGenre, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaGenreService
+		_, err = ser.Create(&mediaGenre, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create MediaGenre: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &mediaGenre, nil
+}
+
+func (r *mutationResolver) UpdateMediaGenre(ctx context.Context, mediaGenre models.MediaGenre) (*models.MediaGenre, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaGenreService
+		err = ser.Update(&mediaGenre, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update MediaGenre by id %d: %w", mediaGenre.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &mediaGenre, nil
+}
+
+func (r *mutationResolver) DeleteMediaGenre(ctx context.Context, id int) (*models.MediaGenre, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var mg *models.MediaGenre
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaGenreService
+		mg, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get MediaGenre by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete MediaGenre by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return mg, nil
+}
+
 // MediaGenre returns MediaGenreResolver implementation.
 func (r *Resolver) MediaGenre() MediaGenreResolver { return &mediaGenreResolver{r} }
 
@@ -34,6 +34,75 @@ func (r *mediaProducerResolver) Producer(ctx context.Context, obj *models.MediaP
 	return p, nil
 }
 
+func (r *mutationResolver) CreateMediaProducer(ctx context.Context, mediaProducer models.MediaProducer) (*models.MediaProducer, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaProducerService
+		_, err = ser.Create(&mediaProducer, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create MediaProducer: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &mediaProducer, nil
+}
+
+func (r *mutationResolver) UpdateMediaProducer(ctx context.Context, mediaProducer models.MediaProducer) (*models.MediaProducer, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaProducerService
+		err = ser.Update(&mediaProducer, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update MediaProducer by id %d: %w", mediaProducer.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &mediaProducer, nil
+}
+
+func (r *mutationResolver) DeleteMediaProducer(ctx context.Context, id int) (*models.MediaProducer, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var mp *models.MediaProducer
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaProducerService
+		mp, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get MediaProducer by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete MediaProducer by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return mp, nil
+}
+
 // MediaProducer returns MediaProducerResolver implementation.
 func (r *Resolver) MediaProducer() MediaProducerResolver { return &mediaProducerResolver{r} }
 
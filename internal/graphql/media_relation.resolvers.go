@@ -5,7 +5,9 @@ package graphql
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/Dophin2009/nao/pkg/db"
 	"github.com/Dophin2009/nao/pkg/models"
 )
 
@@ -17,6 +19,75 @@ func (r *mediaRelationResolver) Related(ctx context.Context, obj *models.MediaRe
 	return resolveMediaByID(ctx, obj.RelatedID)
 }
 
+func (r *mutationResolver) CreateMediaRelation(ctx context.Context, mediaRelation models.MediaRelation) (*models.MediaRelation, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaRelationSerivce
+		_, err = ser.Create(&mediaRelation, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create MediaRelation: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &mediaRelation, nil
+}
+
+func (r *mutationResolver) UpdateMediaRelation(ctx context.Context, mediaRelation models.MediaRelation) (*models.MediaRelation, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaRelationSerivce
+		err = ser.Update(&mediaRelation, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update MediaRelation by id %d: %w", mediaRelation.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &mediaRelation, nil
+}
+
+func (r *mutationResolver) DeleteMediaRelation(ctx context.Context, id int) (*models.MediaRelation, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var mr *models.MediaRelation
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.MediaRelationSerivce
+		mr, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get MediaRelation by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete MediaRelation by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return mr, nil
+}
+
 // MediaRelation returns MediaRelationResolver implementation.
 func (r *Resolver) MediaRelation() MediaRelationResolver { return &mediaRelationResolver{r} }
 
@@ -0,0 +1,98 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/data"
+	"github.com/Dophin2009/nao/internal/notify"
+	"github.com/Dophin2009/nao/internal/web"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// lockedOutError is an error returned when authentication is attempted for a
+// username or from an IP address that is locked out.
+type lockedOutError struct {
+	until time.Time
+}
+
+func (e *lockedOutError) Error() string {
+	return fmt.Sprintf("too many failed attempts; try again after %s",
+		e.until.Format(time.RFC3339))
+}
+
+// lockoutKeys returns the Lockout keys of password attempts for the given
+// username: one for the account, shared by every spelling of the username
+// that identifies it, and one for the IP address of the client, if known.
+func lockoutKeys(ctx context.Context, username string) []string {
+	keys := []string{"user:" + data.NormalizeIdentifier(username)}
+	addr, ok := ctx.Value(web.RemoteAddrKey).(string)
+	if ok && addr != "" {
+		keys = append(keys, "ip:"+addr)
+	}
+	return keys
+}
+
+// checkLockout returns an error if any of the given Lockout keys are locked
+// out.
+func (r *Resolver) checkLockout(keys []string) error {
+	if r.Lockout == nil {
+		return nil
+	}
+
+	for _, k := range keys {
+		until, locked := r.Lockout.Locked(k)
+		if locked {
+			return &lockedOutError{until: until}
+		}
+	}
+	return nil
+}
+
+// failLockout records a failed password attempt for the given Lockout keys.
+func (r *Resolver) failLockout(keys []string) {
+	if r.Lockout == nil {
+		return
+	}
+
+	for _, k := range keys {
+		r.Lockout.Fail(k)
+	}
+}
+
+// resetLockout clears the failed password attempts of the account of the
+// given Lockout keys. Attempts from the IP address are kept so that one valid
+// account cannot be used to continue guessing the passwords of others.
+func (r *Resolver) resetLockout(keys []string) {
+	if r.Lockout == nil {
+		return
+	}
+
+	r.Lockout.Reset(keys[0])
+}
+
+// passwordResetMessage returns the Message containing the instructions to
+// reset the password of the given User with the given reset token.
+func (r *Resolver) passwordResetMessage(u *models.User, tkn string,
+	expiresAt time.Time) *notify.Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "A password reset was requested for the account %q.\n\n",
+		u.Username)
+	if r.PasswordResetURL != "" {
+		url := strings.ReplaceAll(r.PasswordResetURL, "{token}", tkn)
+		fmt.Fprintf(&b, "Reset your password at: %s\n", url)
+	} else {
+		fmt.Fprintf(&b, "Reset token: %s\n", tkn)
+	}
+	fmt.Fprintf(&b, "\nThe token may be used once and expires at %s. "+
+		"If you did not request a reset, ignore this message.",
+		expiresAt.Format(time.RFC3339))
+
+	return &notify.Message{
+		To:      u.Email,
+		Subject: "Password reset",
+		Body:    b.String(),
+	}
+}
@@ -0,0 +1,30 @@
+package graphql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/web"
+)
+
+// TestLockoutKeys tests that every spelling of a username that identifies the
+// same account shares its lockout.
+func TestLockoutKeys(t *testing.T) {
+	r := Resolver{Lockout: web.NewLockout(3, time.Minute, time.Minute)}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		r.failLockout(lockoutKeys(ctx, "admin"))
+	}
+	for _, username := range []string{"admin", "Admin", "admin ", "ａｄｍｉｎ"} {
+		err := r.checkLockout(lockoutKeys(ctx, username))
+		if err == nil {
+			t.Errorf("expected %q to be locked out", username)
+		}
+	}
+	err := r.checkLockout(lockoutKeys(ctx, "administrator"))
+	if err != nil {
+		t.Errorf("expected other account not locked out, got %v", err)
+	}
+}
@@ -11,6 +11,75 @@ import (
 	"github.com/Dophin2009/nao/pkg/models"
 )
 
+func (r *mutationResolver) CreatePerson(ctx context.Context, person models.Person) (*models.Person, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.PersonService
+		_, err = ser.Create(&person, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create Person: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &person, nil
+}
+
+func (r *mutationResolver) UpdatePerson(ctx context.Context, person models.Person) (*models.Person, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.PersonService
+		err = ser.Update(&person, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update Person by id %d: %w", person.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &person, nil
+}
+
+func (r *mutationResolver) DeletePerson(ctx context.Context, id int) (*models.Person, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var p *models.Person
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.PersonService
+		p, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Person by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Person by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return p, nil
+}
+
 func (r *personResolver) Names(ctx context.Context, obj *models.Person, first *int, skip *int) ([]*models.Title, error) {
 	return sliceTitles(obj.Names, first, skip), nil
 }
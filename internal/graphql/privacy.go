@@ -0,0 +1,131 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// Owner implements the @owner directive, which restricts a field to the User
+// that owns the object and to Users assigned a Role that grants the
+// Permission to update it. The ID of the owning User is read from the field
+// of the object at the given dot-separated path, such as "userID" or
+// "meta.id".
+func Owner(ctx context.Context, obj interface{}, next graphql.Resolver,
+	field string) (interface{}, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	uID, err := ownerIDField(obj, field)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owner of object: %w", err)
+	}
+
+	// Reading is allowed regardless of API token scopes, so the owner check
+	// is made without them
+	model := graphql.GetFieldContext(ctx).Object
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		_, err := authorizeOwnerOrPermitted(u, ds, uID,
+			permission(model, models.ActionUpdate), tx)
+		return err
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+	return next(ctx)
+}
+
+// ownerIDField returns the integer value of the field of the given object at
+// the given dot-separated path. Field names are matched case-insensitively.
+func ownerIDField(obj interface{}, path string) (int, error) {
+	v := reflect.ValueOf(obj)
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return 0, fmt.Errorf("field %q: %w", name, errors.New("is nil"))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return 0, fmt.Errorf("field %q: %w", name, errors.New("not of struct type"))
+		}
+
+		v = v.FieldByNameFunc(func(n string) bool {
+			return strings.EqualFold(n, name)
+		})
+		if !v.IsValid() {
+			return 0, fmt.Errorf("field %q: %w", name, errors.New("does not exist"))
+		}
+	}
+
+	if v.Kind() != reflect.Int {
+		return 0, fmt.Errorf("field %q: %w", path, errors.New("not of int type"))
+	}
+	return int(v.Int()), nil
+}
+
+// authorizeView checks that the User making the request, if any, may view the
+// data of the User with the given ID. The Visibility of the data is selected
+// from the privacy settings of the owning User by the given function.
+func authorizeView(ctx context.Context, ds *DataService, ownerID int,
+	vis func(p *models.UserPrivacy) models.Visibility, tx db.Tx) error {
+	ser := ds.UserService
+	owner, err := ser.GetByID(ownerID, tx)
+	if err != nil {
+		return fmt.Errorf("failed to get User by id %d: %w", ownerID, err)
+	}
+
+	// Unauthenticated requests may only view public data
+	var viewer *models.User
+	u, err := getCtxUser(ctx)
+	if err == nil {
+		viewer, err = ser.GetByID(u.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get User by id %d: %w", u.Meta.ID, err)
+		}
+	}
+
+	if !ser.CanView(owner, viewer, vis(&owner.Privacy)) {
+		return fmt.Errorf("data of User %d: %w", ownerID, errForbidden)
+	}
+	return nil
+}
+
+func profileVisibility(p *models.UserPrivacy) models.Visibility {
+	return p.Profile
+}
+
+func listsVisibility(p *models.UserPrivacy) models.Visibility {
+	return p.Lists
+}
+
+// resolveView is authorizeView in its own read transaction, for resolvers that
+// otherwise need no database access.
+func resolveView(ctx context.Context, ownerID int,
+	vis func(p *models.UserPrivacy) models.Visibility) error {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		return authorizeView(ctx, ds, ownerID, vis, tx)
+	})
+	if err != nil {
+		return errorResolve(err)
+	}
+	return nil
+}
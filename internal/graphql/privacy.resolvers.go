@@ -0,0 +1,70 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *mutationResolver) AddFriend(ctx context.Context, userID int, friendID int) (*models.User, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var u *models.User
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		_, err := authorizeOwner(ctx, ds, userID,
+			permission("User", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.UserService
+		u, err = ser.AddFriend(userID, friendID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to add friend %d to User by id %d: %w",
+				friendID, userID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return u, nil
+}
+
+func (r *mutationResolver) RemoveFriend(ctx context.Context, userID int, friendID int) (*models.User, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var u *models.User
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		_, err := authorizeOwner(ctx, ds, userID,
+			permission("User", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.UserService
+		u, err = ser.RemoveFriend(userID, friendID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to remove friend %d from User by id %d: %w",
+				friendID, userID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return u, nil
+}
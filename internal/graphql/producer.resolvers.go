@@ -11,6 +11,75 @@ import (
 	"github.com/Dophin2009/nao/pkg/models"
 )
 
+func (r *mutationResolver) CreateProducer(ctx context.Context, producer models.Producer) (*models.Producer, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.ProducerService
+		_, err = ser.Create(&producer, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create Producer: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &producer, nil
+}
+
+func (r *mutationResolver) UpdateProducer(ctx context.Context, producer models.Producer) (*models.Producer, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.ProducerService
+		err = ser.Update(&producer, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update Producer by id %d: %w", producer.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &producer, nil
+}
+
+func (r *mutationResolver) DeleteProducer(ctx context.Context, id int) (*models.Producer, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var p *models.Producer
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.ProducerService
+		p, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Producer by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Producer by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return p, nil
+}
+
 func (r *producerResolver) Titles(ctx context.Context, obj *models.Producer, first *int, skip *int) ([]*models.Title, error) {
 	return sliceTitles(obj.Titles, first, skip), nil
 }
@@ -0,0 +1,88 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/notify"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// checkRegistration returns an error if a new User may not register with the
+// given invitation code under the registration mode. Otherwise, it returns
+// true if the invitation code must be redeemed once the User is created. The
+// first User may always register so that instances can be set up.
+func (r *Resolver) checkRegistration(ds *DataService, invitation *string,
+	tx db.Tx) (redeem bool, err error) {
+	first := 1
+	existing, err := ds.UserService.GetAll(&first, nil, tx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get Users: %w", err)
+	}
+	if len(existing) == 0 {
+		return false, nil
+	}
+
+	switch r.RegistrationMode {
+	case RegistrationModeClosed:
+		return false, fmt.Errorf("registration is closed: %w", errForbidden)
+	case RegistrationModeInvite:
+		if invitation == nil || *invitation == "" {
+			return false, fmt.Errorf("registration requires an invitation: %w",
+				errForbidden)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// sendEmailVerification requests a new EmailVerification for the given User
+// and delivers the instructions to verify its email.
+func (r *Resolver) sendEmailVerification(ds *DataService, u *models.User) error {
+	var ev *models.EmailVerification
+	var tkn string
+	err := ds.Database.Transaction(true, func(tx db.Tx) error {
+		var err error
+		ev, tkn, err = ds.EmailVerificationService.Request(u.Meta.ID,
+			r.EmailVerificationDuration, tx)
+		if err != nil {
+			return fmt.Errorf("failed to request EmailVerification: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = r.Notifier.Notify(r.emailVerificationMessage(u, ev, tkn))
+	if err != nil {
+		return fmt.Errorf("failed to send email verification instructions: %w", err)
+	}
+	return nil
+}
+
+// emailVerificationMessage returns the Message containing the instructions to
+// verify the email of the given User with the given verification token.
+func (r *Resolver) emailVerificationMessage(u *models.User,
+	ev *models.EmailVerification, tkn string) *notify.Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Confirm that this is the email of the account %q.\n\n",
+		u.Username)
+	if r.EmailVerificationURL != "" {
+		url := strings.ReplaceAll(r.EmailVerificationURL, "{token}", tkn)
+		fmt.Fprintf(&b, "Verify your email at: %s\n", url)
+	} else {
+		fmt.Fprintf(&b, "Verification token: %s\n", tkn)
+	}
+	fmt.Fprintf(&b, "\nThe token may be used once and expires at %s. "+
+		"If you did not create this account, ignore this message.",
+		ev.ExpiresAt.Format(time.RFC3339))
+
+	return &notify.Message{
+		To:      ev.Email,
+		Subject: "Verify your email",
+		Body:    b.String(),
+	}
+}
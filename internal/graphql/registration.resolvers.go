@@ -0,0 +1,157 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *invitationResolver) CreatedBy(ctx context.Context, obj *models.Invitation) (*models.User, error) {
+	return resolveUserByID(ctx, obj.CreatedBy)
+}
+
+func (r *invitationResolver) UsedBy(ctx context.Context, obj *models.Invitation) (*models.User, error) {
+	if obj.UsedBy == nil {
+		return nil, nil
+	}
+	return resolveUserByID(ctx, *obj.UsedBy)
+}
+
+func (r *mutationResolver) CreateInvitation(ctx context.Context, expiresAt *time.Time) (*CreatedInvitation, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	var inv *models.Invitation
+	var code string
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.InvitationService
+		inv, code, err = ser.Issue(u.Meta.ID, expiresAt, tx)
+		if err != nil {
+			return fmt.Errorf("failed to issue Invitation: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &CreatedInvitation{
+		Code:       code,
+		Invitation: inv,
+	}, nil
+}
+
+func (r *mutationResolver) DeleteInvitation(ctx context.Context, id int) (*models.Invitation, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var inv *models.Invitation
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.InvitationService
+		inv, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Invitation by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Invitation by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return inv, nil
+}
+
+func (r *mutationResolver) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var u *models.User
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		u, err = ds.EmailVerificationService.Verify(token, tx)
+		if err != nil {
+			return fmt.Errorf("invalid verification token: %w", errUnauthenticated)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return u, nil
+}
+
+func (r *mutationResolver) RequestEmailVerification(ctx context.Context) (bool, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return false, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return false, errorResolve(err)
+	}
+
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return false, errorResolve(err)
+	}
+
+	err = r.sendEmailVerification(ds, u)
+	if err != nil {
+		return false, errorResolve(err)
+	}
+	return true, nil
+}
+
+func (r *queryResolver) RegistrationMode(ctx context.Context) (RegistrationMode, error) {
+	return r.Resolver.RegistrationMode, nil
+}
+
+func (r *queryResolver) Invitations(ctx context.Context, first *int, skip *int) ([]*models.Invitation, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var list []*models.Invitation
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.InvitationService
+		list, err = ser.GetAll(first, skip, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Invitations: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return list, nil
+}
+
+// Invitation returns InvitationResolver implementation.
+func (r *Resolver) Invitation() InvitationResolver { return &invitationResolver{r} }
+
+type invitationResolver struct{ *Resolver }
@@ -4,16 +4,51 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Dophin2009/nao/internal/data"
+	"github.com/Dophin2009/nao/internal/jwt"
+	"github.com/Dophin2009/nao/internal/notify"
+	"github.com/Dophin2009/nao/internal/web"
 	"github.com/Dophin2009/nao/pkg/db"
 	"github.com/Dophin2009/nao/pkg/models"
 )
 
-// TODO: Implement authentication
-
 // Resolver is the root GraphQL resolver object.
-type Resolver struct{}
+type Resolver struct {
+	// Authenticator issues access tokens for authentication mutations.
+	Authenticator *jwt.Authenticator
+	// TokenDuration is the duration issued access tokens are valid for.
+	TokenDuration time.Duration
+	// RefreshDuration is the duration Sessions remain valid for after being
+	// started or refreshed.
+	RefreshDuration time.Duration
+	// Notifier delivers password reset and email verification instructions
+	// to Users.
+	Notifier notify.Notifier
+	// PasswordResetDuration is the duration reset tokens are valid for.
+	PasswordResetDuration time.Duration
+	// PasswordResetURL is the URL of the page Users reset their password on,
+	// included in password reset instructions. The placeholder {token} is
+	// replaced by the reset token.
+	PasswordResetURL string
+	// Lockout tracks failed password attempts by username and IP address.
+	// There is no lockout if nil.
+	Lockout *web.Lockout
+	// RegistrationMode determines who may register. The first User may
+	// always register.
+	RegistrationMode RegistrationMode
+	// EmailVerificationDuration is the duration email verification tokens
+	// are valid for.
+	EmailVerificationDuration time.Duration
+	// EmailVerificationURL is the URL of the page Users verify their email
+	// on, included in verification instructions. The placeholder {token} is
+	// replaced by the verification token.
+	EmailVerificationURL string
+	// TwoFactorIssuer is the issuer shown by authenticator apps for TOTP
+	// secrets.
+	TwoFactorIssuer string
+}
 
 func resolveMediaByID(ctx context.Context, mID int) (*models.Media, error) {
 	ds, err := getCtxDataService(ctx)
@@ -34,6 +69,28 @@ func resolveMediaByID(ctx context.Context, mID int) (*models.Media, error) {
 	return md, nil
 }
 
+func resolveUserByID(ctx context.Context, uID int) (*models.User, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var u *models.User
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.UserService
+		u, err = ser.GetByID(uID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get User by id %d: %w", uID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
 func sliceTitles(
 	objTitles []models.Title, first *int, skip *int,
 ) []*models.Title {
@@ -80,21 +137,37 @@ func calculatePaginationBounds(first *int, skip *int, size int) (int, int) {
 // DataService contains all data layer services required, to be passed around
 // in a context object.
 type DataService struct {
-	Database              db.DatabaseService
-	CharacterService      *data.CharacterService
-	EpisodeService        *data.EpisodeService
-	EpisodeSetService     *data.EpisodeSetService
-	GenreService          *data.GenreService
-	MediaService          *data.MediaService
-	MediaCharacterService *data.MediaCharacterService
-	MediaGenreService     *data.MediaGenreService
-	MediaProducerService  *data.MediaProducerService
-	MediaRelationSerivce  *data.MediaRelationService
-	PersonService         *data.PersonService
-	ProducerService       *data.ProducerService
-	UserService           *data.UserService
-	UserMediaService      *data.UserMediaService
-	UserMediaListService  *data.UserMediaListService
+	Database                 db.DatabaseService
+	AiringService            *data.AiringService
+	APITokenService          *data.APITokenService
+	BroadcastSlotService     *data.BroadcastSlotService
+	CalendarTokenService     *data.CalendarTokenService
+	ChapterService           *data.ChapterService
+	CharacterService         *data.CharacterService
+	EmailVerificationService *data.EmailVerificationService
+	EpisodeService           *data.EpisodeService
+	EpisodeProgressService   *data.EpisodeProgressService
+	EpisodeSetService        *data.EpisodeSetService
+	GenreService             *data.GenreService
+	InvitationService        *data.InvitationService
+	MediaService             *data.MediaService
+	MediaCharacterService    *data.MediaCharacterService
+	MediaGenreService        *data.MediaGenreService
+	MediaProducerService     *data.MediaProducerService
+	MediaRelationSerivce     *data.MediaRelationService
+	PasswordResetService     *data.PasswordResetService
+	PersonService            *data.PersonService
+	ProducerService          *data.ProducerService
+	ScheduleService          *data.ScheduleService
+	SeasonChartService       *data.SeasonChartService
+	SessionService           *data.SessionService
+	UserService              *data.UserService
+	UserCharacterService     *data.UserCharacterService
+	UserEpisodeService       *data.UserEpisodeService
+	UserMediaService         *data.UserMediaService
+	UserMediaListService     *data.UserMediaListService
+	UserPersonService        *data.UserPersonService
+	VolumeService            *data.VolumeService
 }
 
 // DataServiceKey is the context key value for DataServices.
@@ -0,0 +1,101 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dophin2009/nao/internal/data"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *mutationResolver) AssignRole(ctx context.Context, userID int, role models.Role) (*models.User, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var u *models.User
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserService
+		u, err = ser.AssignRole(userID, role, tx)
+		if err != nil {
+			return fmt.Errorf("failed to assign Role %s to User by id %d: %w",
+				role, userID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return u, nil
+}
+
+func (r *mutationResolver) UnassignRole(ctx context.Context, userID int, role models.Role) (*models.User, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var u *models.User
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserService
+		u, err = ser.UnassignRole(userID, role, tx)
+		if err != nil {
+			return fmt.Errorf("failed to unassign Role %s from User by id %d: %w",
+				role, userID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return u, nil
+}
+
+func (r *queryResolver) Roles(ctx context.Context) ([]*RoleDefinition, error) {
+	list := make([]*RoleDefinition, len(models.Roles))
+	for i, role := range models.Roles {
+		perms := data.RolePermissions(role)
+		def := RoleDefinition{
+			Role:        role,
+			Permissions: make([]*models.Permission, len(perms)),
+		}
+		for j := range perms {
+			def.Permissions[j] = &perms[j]
+		}
+		list[i] = &def
+	}
+	return list, nil
+}
+
+func (r *queryResolver) RoleAssignments(ctx context.Context, role *models.Role, first *int, skip *int) ([]*models.User, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var list []*models.User
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.UserService
+		if role == nil {
+			list, err = ser.GetAll(first, skip, tx)
+		} else {
+			list, err = ser.GetByRole(*role, first, skip, tx)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get Users by Role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,361 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *airingResolver) Media(ctx context.Context, obj *models.Airing) (*models.Media, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var md *models.Media
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.MediaService
+		md, err = ser.GetByID(obj.MediaID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Media by id %d: %w", obj.MediaID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return md, nil
+}
+
+func (r *airingResolver) Episode(ctx context.Context, obj *models.Airing) (*models.Episode, error) {
+	if obj.EpisodeID == nil {
+		return nil, nil
+	}
+
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var ep *models.Episode
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.EpisodeService
+		ep, err = ser.GetByID(*obj.EpisodeID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Episode by id %d: %w", *obj.EpisodeID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ep, nil
+}
+
+func (r *broadcastSlotResolver) Media(ctx context.Context, obj *models.BroadcastSlot) (*models.Media, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var md *models.Media
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.MediaService
+		md, err = ser.GetByID(obj.MediaID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Media by id %d: %w", obj.MediaID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return md, nil
+}
+
+func (r *mutationResolver) CreateBroadcastSlot(ctx context.Context, broadcastSlot models.BroadcastSlot) (*models.BroadcastSlot, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.BroadcastSlotService
+		_, err = ser.Create(&broadcastSlot, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create BroadcastSlot: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &broadcastSlot, nil
+}
+
+func (r *mutationResolver) UpdateBroadcastSlot(ctx context.Context, broadcastSlot models.BroadcastSlot) (*models.BroadcastSlot, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.BroadcastSlotService
+		err = ser.Update(&broadcastSlot, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update BroadcastSlot by id %d: %w", broadcastSlot.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &broadcastSlot, nil
+}
+
+func (r *mutationResolver) DeleteBroadcastSlot(ctx context.Context, id int) (*models.BroadcastSlot, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var s *models.BroadcastSlot
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.BroadcastSlotService
+		s, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get BroadcastSlot by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete BroadcastSlot by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return s, nil
+}
+
+func (r *mutationResolver) CreateAiring(ctx context.Context, airing models.Airing) (*models.Airing, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.AiringService
+		_, err = ser.Create(&airing, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create Airing: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &airing, nil
+}
+
+func (r *mutationResolver) UpdateAiring(ctx context.Context, airing models.Airing) (*models.Airing, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.AiringService
+		err = ser.Update(&airing, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update Airing by id %d: %w", airing.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &airing, nil
+}
+
+func (r *mutationResolver) DeleteAiring(ctx context.Context, id int) (*models.Airing, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var a *models.Airing
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.AiringService
+		a, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Airing by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete Airing by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return a, nil
+}
+
+func (r *queryResolver) AiringSchedule(ctx context.Context, from time.Time, to time.Time, mediaID *int, first *int, skip *int) ([]*models.ScheduledAiring, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var list []*models.ScheduledAiring
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.ScheduleService
+		list, err = ser.GetSchedule(from, to, mediaID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get airing schedule: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := calculatePaginationBounds(first, skip, len(list))
+	return list[start:end], nil
+}
+
+func (r *scheduledAiringResolver) Media(ctx context.Context, obj *models.ScheduledAiring) (*models.Media, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var md *models.Media
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.MediaService
+		md, err = ser.GetByID(obj.MediaID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Media by id %d: %w", obj.MediaID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return md, nil
+}
+
+func (r *scheduledAiringResolver) Episode(ctx context.Context, obj *models.ScheduledAiring) (*models.Episode, error) {
+	if obj.EpisodeID == nil {
+		return nil, nil
+	}
+
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var ep *models.Episode
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.EpisodeService
+		ep, err = ser.GetByID(*obj.EpisodeID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Episode by id %d: %w", *obj.EpisodeID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ep, nil
+}
+
+func (r *scheduledAiringResolver) TimeUntilAiring(ctx context.Context, obj *models.ScheduledAiring) (int, error) {
+	return int(obj.AiringAt.Sub(time.Now()) / time.Second), nil
+}
+
+func (r *scheduledAiringResolver) Airing(ctx context.Context, obj *models.ScheduledAiring) (*models.Airing, error) {
+	if obj.AiringID == nil {
+		return nil, nil
+	}
+
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var a *models.Airing
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.AiringService
+		a, err = ser.GetByID(*obj.AiringID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Airing by id %d: %w", *obj.AiringID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (r *scheduledAiringResolver) BroadcastSlot(ctx context.Context, obj *models.ScheduledAiring) (*models.BroadcastSlot, error) {
+	if obj.BroadcastSlotID == nil {
+		return nil, nil
+	}
+
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var s *models.BroadcastSlot
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.BroadcastSlotService
+		s, err = ser.GetByID(*obj.BroadcastSlotID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get BroadcastSlot by id %d: %w", *obj.BroadcastSlotID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Airing returns AiringResolver implementation.
+func (r *Resolver) Airing() AiringResolver { return &airingResolver{r} }
+
+// BroadcastSlot returns BroadcastSlotResolver implementation.
+func (r *Resolver) BroadcastSlot() BroadcastSlotResolver { return &broadcastSlotResolver{r} }
+
+// ScheduledAiring returns ScheduledAiringResolver implementation.
+func (r *Resolver) ScheduledAiring() ScheduledAiringResolver { return &scheduledAiringResolver{r} }
+
+type airingResolver struct{ *Resolver }
+type broadcastSlotResolver struct{ *Resolver }
+type scheduledAiringResolver struct{ *Resolver }
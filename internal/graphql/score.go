@@ -0,0 +1,51 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/Dophin2009/nao/internal/data"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// displayScore converts the given stored score to the given ScoreSystem or,
+// if not given, that of the User making the request. The default ScoreSystem
+// is used for unauthenticated requests.
+func displayScore(ctx context.Context, score *int,
+	system *models.ScoreSystem) *float64 {
+	if score == nil {
+		return nil
+	}
+
+	v := scoreSystem(ctx, system).FromInternal(*score)
+	return &v
+}
+
+// internalScore converts the given score of a model of the given name, in the
+// given ScoreSystem or, if not given, that of the User making the request, to
+// a stored score.
+func internalScore(ctx context.Context, model string, score *float64,
+	system *models.ScoreSystem) (*int, error) {
+	if score == nil {
+		return nil, nil
+	}
+
+	v, err := scoreSystem(ctx, system).ToInternal(*score)
+	if err != nil {
+		return nil, &data.ValidationError{Model: model, Field: "Score", Err: err}
+	}
+	return &v, nil
+}
+
+// scoreSystem returns the given ScoreSystem or, if not given, that of the
+// User making the request. The default ScoreSystem is used for
+// unauthenticated requests.
+func scoreSystem(ctx context.Context,
+	system *models.ScoreSystem) models.ScoreSystem {
+	if system != nil {
+		return *system
+	}
+	if u, err := getCtxUser(ctx); err == nil && u.ScoreSystem != 0 {
+		return u.ScoreSystem
+	}
+	return models.DefaultScoreSystem
+}
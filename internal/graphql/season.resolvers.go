@@ -0,0 +1,64 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *queryResolver) SeasonChart(ctx context.Context, year int, quarter models.Quarter, typeArg *models.MediaType, sort *models.SeasonSort, first *int, skip *int) ([]*models.Media, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	order := models.SeasonSortPopularity
+	if sort != nil {
+		order = *sort
+	}
+
+	var list []*models.Media
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.SeasonChartService
+		list, err = ser.GetChart(models.NewSeason(year, quarter), typeArg,
+			order, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get chart of %v %d: %w", quarter, year,
+				err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := calculatePaginationBounds(first, skip, len(list))
+	return list[start:end], nil
+}
+
+func (r *queryResolver) SeasonCounts(ctx context.Context, typeArg *models.MediaType) ([]*models.SeasonCount, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var list []*models.SeasonCount
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.SeasonChartService
+		list, err = ser.GetCounts(typeArg, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Media counts by season: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
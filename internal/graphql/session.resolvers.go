@@ -0,0 +1,19 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *sessionResolver) User(ctx context.Context, obj *models.Session) (*models.User, error) {
+	return resolveUserByID(ctx, obj.UserID)
+}
+
+// Session returns SessionResolver implementation.
+func (r *Resolver) Session() SessionResolver { return &sessionResolver{r} }
+
+type sessionResolver struct{ *Resolver }
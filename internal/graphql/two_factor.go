@@ -0,0 +1,26 @@
+package graphql
+
+import (
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// verifyTwoFactor checks the given TOTP or recovery code of the given User if
+// it has enabled two-factor authentication.
+func verifyTwoFactor(ds *DataService, u *models.User, code *string,
+	tx db.Tx) error {
+	if !u.TwoFactor.Enabled {
+		return nil
+	}
+	if code == nil || *code == "" {
+		return errTwoFactorRequired
+	}
+
+	err := ds.UserService.VerifyTwoFactor(u.Meta.ID, *code, tx)
+	if err != nil {
+		return fmt.Errorf("invalid two-factor code: %w", errUnauthenticated)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dophin2009/nao/internal/totp"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *mutationResolver) EnrollTwoFactor(ctx context.Context) (*TwoFactorEnrollment, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	var enrolled *models.User
+	var secret string
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		enrolled, secret, err = ds.UserService.EnrollTwoFactor(u.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to enroll User %d in two-factor authentication: %w",
+				u.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &TwoFactorEnrollment{
+		Secret: secret,
+		URI:    totp.URI(r.TwoFactorIssuer, enrolled.Username, secret),
+	}, nil
+}
+
+func (r *mutationResolver) EnableTwoFactor(ctx context.Context, code string) ([]string, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	var codes []string
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		codes, err = ds.UserService.EnableTwoFactor(u.Meta.ID, code, tx)
+		if err != nil {
+			return fmt.Errorf("failed to enable two-factor authentication for User %d: %w",
+				u.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return codes, nil
+}
+
+func (r *mutationResolver) DisableTwoFactor(ctx context.Context, code string) (bool, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return false, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return false, errorResolve(err)
+	}
+
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return false, errorResolve(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		err = ds.UserService.DisableTwoFactor(u.Meta.ID, code, tx)
+		if err != nil {
+			return fmt.Errorf("failed to disable two-factor authentication for User %d: %w",
+				u.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, errorResolve(err)
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) RegenerateRecoveryCodes(ctx context.Context, code string) ([]string, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	u, err := getCtxUser(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	var codes []string
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		codes, err = ds.UserService.RegenerateRecoveryCodes(u.Meta.ID, code, tx)
+		if err != nil {
+			return fmt.Errorf("failed to regenerate recovery codes of User %d: %w",
+				u.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return codes, nil
+}
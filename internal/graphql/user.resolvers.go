@@ -0,0 +1,210 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *mutationResolver) CreateUser(ctx context.Context, user models.User, password string) (*models.User, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserService
+		user.Password = []byte(password)
+		_, err := ser.Create(&user, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create User: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &user, nil
+}
+
+func (r *mutationResolver) UpdateUser(ctx context.Context, user models.User) (*models.User, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		_, err := authorizeOwner(ctx, ds, user.Meta.ID,
+			permission("User", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+
+		// Roles are not changed by updates; they are only changed by
+		// assignRole and unassignRole
+		ser := ds.UserService
+		err = ser.Update(&user, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update User by id %d: %w", user.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &user, nil
+}
+
+func (r *mutationResolver) DeleteUser(ctx context.Context, id int) (*models.User, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = authorizeAccount(ctx)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	var u *models.User
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		_, err := authorizeOwner(ctx, ds, id,
+			permission("User", models.ActionDelete), tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.UserService
+		u, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get User by id %d: %w", id, err)
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete User by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return u, nil
+}
+
+func (r *queryResolver) UserByID(ctx context.Context, id int) (*models.User, error) {
+	return resolveUserByID(ctx, id)
+}
+
+func (r *userResolver) TwoFactorEnabled(ctx context.Context, obj *models.User) (*bool, error) {
+	return &obj.TwoFactor.Enabled, nil
+}
+
+func (r *userResolver) TwoFactorRequired(ctx context.Context, obj *models.User) (*bool, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	required := ds.UserService.RequiresTwoFactor(obj)
+	return &required, nil
+}
+
+func (r *userResolver) Friends(ctx context.Context, obj *models.User, first *int, skip *int) ([]*models.User, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	start, end := calculatePaginationBounds(first, skip, len(obj.Friends))
+	ids := obj.Friends[start:end]
+
+	var list []*models.User
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.UserService
+		list, err = ser.GetMultiple(ids, tx, func(_ *models.User) bool {
+			return true
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get Users by ids: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (r *userResolver) UserMedia(ctx context.Context, obj *models.User, first *int, skip *int) ([]*models.UserMedia, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var list []*models.UserMedia
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		err := authorizeView(ctx, ds, obj.Meta.ID, profileVisibility, tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.UserMediaService
+		list, err = ser.GetByUser(obj.Meta.ID, first, skip, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserMedia by User id %d: %w", obj.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return list, nil
+}
+
+func (r *userResolver) Lists(ctx context.Context, obj *models.User, first *int, skip *int) ([]*models.UserMediaList, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var list []*models.UserMediaList
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		err := authorizeView(ctx, ds, obj.Meta.ID, listsVisibility, tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.UserMediaListService
+		list, err = ser.GetByUser(obj.Meta.ID, first, skip, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserMediaLists by User id %d: %w", obj.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return list, nil
+}
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type userResolver struct{ *Resolver }
@@ -0,0 +1,160 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *mutationResolver) CreateUserCharacter(ctx context.Context, userCharacter models.UserCharacter, score *float64, scoreSystem *models.ScoreSystem) (*models.UserCharacter, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	userCharacter.Score, err = internalScore(ctx, "UserCharacter", score, scoreSystem)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		_, err := authorizeOwner(ctx, ds, userCharacter.UserID,
+			permission("UserCharacter", models.ActionCreate), tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.UserCharacterService
+		_, err = ser.Create(&userCharacter, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create UserCharacter: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &userCharacter, nil
+}
+
+func (r *mutationResolver) UpdateUserCharacter(ctx context.Context, userCharacter models.UserCharacter, score *float64, scoreSystem *models.ScoreSystem) (*models.UserCharacter, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	userCharacter.Score, err = internalScore(ctx, "UserCharacter", score, scoreSystem)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserCharacterService
+		o, err := ser.GetByID(userCharacter.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserCharacter by id %d: %w", userCharacter.Meta.ID, err)
+		}
+
+		// Both the existing and updated owner must be checked to prevent
+		// transferring ownership to or from another User
+		_, err = authorizeOwner(ctx, ds, o.UserID,
+			permission("UserCharacter", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+		_, err = authorizeOwner(ctx, ds, userCharacter.UserID,
+			permission("UserCharacter", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+
+		err = ser.Update(&userCharacter, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update UserCharacter by id %d: %w", userCharacter.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &userCharacter, nil
+}
+
+func (r *mutationResolver) DeleteUserCharacter(ctx context.Context, id int) (*models.UserCharacter, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var uc *models.UserCharacter
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserCharacterService
+		uc, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserCharacter by id %d: %w", id, err)
+		}
+
+		_, err = authorizeOwner(ctx, ds, uc.UserID,
+			permission("UserCharacter", models.ActionDelete), tx)
+		if err != nil {
+			return err
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete UserCharacter by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return uc, nil
+}
+
+func (r *userCharacterResolver) User(ctx context.Context, obj *models.UserCharacter) (*models.User, error) {
+	return resolveUserByID(ctx, obj.UserID)
+}
+
+func (r *userCharacterResolver) Character(ctx context.Context, obj *models.UserCharacter) (*models.Character, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var c *models.Character
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.CharacterService
+		c, err = ser.GetByID(obj.CharacterID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Character by id %d: %w", obj.CharacterID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (r *userCharacterResolver) DisplayScore(ctx context.Context, obj *models.UserCharacter, system *models.ScoreSystem) (*float64, error) {
+	return displayScore(ctx, obj.Score, system), nil
+}
+
+func (r *userCharacterResolver) Comments(ctx context.Context, obj *models.UserCharacter, first *int, skip *int) ([]*models.Title, error) {
+	return sliceTitles(obj.Comments, first, skip), nil
+}
+
+// UserCharacter returns UserCharacterResolver implementation.
+func (r *Resolver) UserCharacter() UserCharacterResolver { return &userCharacterResolver{r} }
+
+type userCharacterResolver struct{ *Resolver }
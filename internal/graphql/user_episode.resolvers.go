@@ -0,0 +1,160 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *mutationResolver) CreateUserEpisode(ctx context.Context, userEpisode models.UserEpisode, score *float64, scoreSystem *models.ScoreSystem) (*models.UserEpisode, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	userEpisode.Score, err = internalScore(ctx, "UserEpisode", score, scoreSystem)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		_, err := authorizeOwner(ctx, ds, userEpisode.UserID,
+			permission("UserEpisode", models.ActionCreate), tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.UserEpisodeService
+		_, err = ser.Create(&userEpisode, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create UserEpisode: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &userEpisode, nil
+}
+
+func (r *mutationResolver) UpdateUserEpisode(ctx context.Context, userEpisode models.UserEpisode, score *float64, scoreSystem *models.ScoreSystem) (*models.UserEpisode, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	userEpisode.Score, err = internalScore(ctx, "UserEpisode", score, scoreSystem)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserEpisodeService
+		o, err := ser.GetByID(userEpisode.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserEpisode by id %d: %w", userEpisode.Meta.ID, err)
+		}
+
+		// Both the existing and updated owner must be checked to prevent
+		// transferring ownership to or from another User
+		_, err = authorizeOwner(ctx, ds, o.UserID,
+			permission("UserEpisode", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+		_, err = authorizeOwner(ctx, ds, userEpisode.UserID,
+			permission("UserEpisode", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+
+		err = ser.Update(&userEpisode, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update UserEpisode by id %d: %w", userEpisode.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &userEpisode, nil
+}
+
+func (r *mutationResolver) DeleteUserEpisode(ctx context.Context, id int) (*models.UserEpisode, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var uep *models.UserEpisode
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserEpisodeService
+		uep, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserEpisode by id %d: %w", id, err)
+		}
+
+		_, err = authorizeOwner(ctx, ds, uep.UserID,
+			permission("UserEpisode", models.ActionDelete), tx)
+		if err != nil {
+			return err
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete UserEpisode by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return uep, nil
+}
+
+func (r *userEpisodeResolver) User(ctx context.Context, obj *models.UserEpisode) (*models.User, error) {
+	return resolveUserByID(ctx, obj.UserID)
+}
+
+func (r *userEpisodeResolver) Episode(ctx context.Context, obj *models.UserEpisode) (*models.Episode, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var ep *models.Episode
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.EpisodeService
+		ep, err = ser.GetByID(obj.EpisodeID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Episode by id %d: %w", obj.EpisodeID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ep, nil
+}
+
+func (r *userEpisodeResolver) DisplayScore(ctx context.Context, obj *models.UserEpisode, system *models.ScoreSystem) (*float64, error) {
+	return displayScore(ctx, obj.Score, system), nil
+}
+
+func (r *userEpisodeResolver) Comments(ctx context.Context, obj *models.UserEpisode, first *int, skip *int) ([]*models.Title, error) {
+	return sliceTitles(obj.Comments, first, skip), nil
+}
+
+// UserEpisode returns UserEpisodeResolver implementation.
+func (r *Resolver) UserEpisode() UserEpisodeResolver { return &userEpisodeResolver{r} }
+
+type userEpisodeResolver struct{ *Resolver }
@@ -0,0 +1,169 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *mutationResolver) CreateUserMedia(ctx context.Context, userMedia models.UserMedia, score *float64, scoreSystem *models.ScoreSystem) (*models.UserMedia, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	userMedia.Score, err = internalScore(ctx, "UserMedia", score, scoreSystem)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		_, err := authorizeOwner(ctx, ds, userMedia.UserID,
+			permission("UserMedia", models.ActionCreate), tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.UserMediaService
+		_, err = ser.Create(&userMedia, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create UserMedia: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &userMedia, nil
+}
+
+func (r *mutationResolver) UpdateUserMedia(ctx context.Context, userMedia models.UserMedia, score *float64, scoreSystem *models.ScoreSystem) (*models.UserMedia, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	userMedia.Score, err = internalScore(ctx, "UserMedia", score, scoreSystem)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserMediaService
+		o, err := ser.GetByID(userMedia.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserMedia by id %d: %w", userMedia.Meta.ID, err)
+		}
+
+		// Both the existing and updated owner must be checked to prevent
+		// transferring ownership to or from another User
+		_, err = authorizeOwner(ctx, ds, o.UserID,
+			permission("UserMedia", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+		_, err = authorizeOwner(ctx, ds, userMedia.UserID,
+			permission("UserMedia", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+
+		err = ser.Update(&userMedia, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update UserMedia by id %d: %w", userMedia.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &userMedia, nil
+}
+
+func (r *mutationResolver) DeleteUserMedia(ctx context.Context, id int) (*models.UserMedia, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var um *models.UserMedia
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserMediaService
+		um, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserMedia by id %d: %w", id, err)
+		}
+
+		_, err = authorizeOwner(ctx, ds, um.UserID,
+			permission("UserMedia", models.ActionDelete), tx)
+		if err != nil {
+			return err
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete UserMedia by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return um, nil
+}
+
+func (r *userMediaResolver) User(ctx context.Context, obj *models.UserMedia) (*models.User, error) {
+	return resolveUserByID(ctx, obj.UserID)
+}
+
+func (r *userMediaResolver) Media(ctx context.Context, obj *models.UserMedia) (*models.Media, error) {
+	return resolveMediaByID(ctx, obj.MediaID)
+}
+
+func (r *userMediaResolver) DisplayScore(ctx context.Context, obj *models.UserMedia, system *models.ScoreSystem) (*float64, error) {
+	return displayScore(ctx, obj.Score, system), nil
+}
+
+func (r *userMediaResolver) Progress(ctx context.Context, obj *models.UserMedia, first *int, skip *int) ([]*models.EpisodeProgress, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var list []*models.EpisodeProgress
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		err := authorizeView(ctx, ds, obj.UserID, listsVisibility, tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.EpisodeProgressService
+		list, err = ser.GetByUserMedia(obj.Meta.ID, first, skip, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get EpisodeProgress by UserMedia id %d: %w", obj.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return list, nil
+}
+
+func (r *userMediaResolver) Comments(ctx context.Context, obj *models.UserMedia, first *int, skip *int) ([]*models.Title, error) {
+	return sliceTitles(obj.Comments, first, skip), nil
+}
+
+// UserMedia returns UserMediaResolver implementation.
+func (r *Resolver) UserMedia() UserMediaResolver { return &userMediaResolver{r} }
+
+type userMediaResolver struct{ *Resolver }
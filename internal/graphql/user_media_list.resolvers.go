@@ -0,0 +1,170 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *mutationResolver) CreateUserMediaList(ctx context.Context, userMediaList models.UserMediaList) (*models.UserMediaList, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		_, err := authorizeOwner(ctx, ds, userMediaList.UserID,
+			permission("UserMediaList", models.ActionCreate), tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.UserMediaListService
+		_, err = ser.Create(&userMediaList, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create UserMediaList: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &userMediaList, nil
+}
+
+func (r *mutationResolver) UpdateUserMediaList(ctx context.Context, userMediaList models.UserMediaList) (*models.UserMediaList, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserMediaListService
+		o, err := ser.GetByID(userMediaList.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserMediaList by id %d: %w", userMediaList.Meta.ID, err)
+		}
+
+		// Both the existing and updated owner must be checked to prevent
+		// transferring ownership to or from another User
+		_, err = authorizeOwner(ctx, ds, o.UserID,
+			permission("UserMediaList", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+		_, err = authorizeOwner(ctx, ds, userMediaList.UserID,
+			permission("UserMediaList", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+
+		err = ser.Update(&userMediaList, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update UserMediaList by id %d: %w", userMediaList.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &userMediaList, nil
+}
+
+func (r *mutationResolver) DeleteUserMediaList(ctx context.Context, id int) (*models.UserMediaList, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var uml *models.UserMediaList
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserMediaListService
+		uml, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserMediaList by id %d: %w", id, err)
+		}
+
+		_, err = authorizeOwner(ctx, ds, uml.UserID,
+			permission("UserMediaList", models.ActionDelete), tx)
+		if err != nil {
+			return err
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete UserMediaList by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return uml, nil
+}
+
+func (r *userMediaListResolver) User(ctx context.Context, obj *models.UserMediaList) (*models.User, error) {
+	return resolveUserByID(ctx, obj.UserID)
+}
+
+func (r *userMediaListResolver) Names(ctx context.Context, obj *models.UserMediaList, first *int, skip *int) ([]*models.Title, error) {
+	err := resolveView(ctx, obj.UserID, listsVisibility)
+	if err != nil {
+		return nil, err
+	}
+
+	return sliceTitles(obj.Names, first, skip), nil
+}
+
+func (r *userMediaListResolver) Descriptions(ctx context.Context, obj *models.UserMediaList, first *int, skip *int) ([]*models.Title, error) {
+	err := resolveView(ctx, obj.UserID, listsVisibility)
+	if err != nil {
+		return nil, err
+	}
+
+	return sliceTitles(obj.Descriptions, first, skip), nil
+}
+
+func (r *userMediaListResolver) UserMedia(ctx context.Context, obj *models.UserMediaList, first *int, skip *int) ([]*models.UserMedia, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	start, end := calculatePaginationBounds(first, skip, len(obj.UserMedia))
+	ids := obj.UserMedia[start:end]
+
+	var list []*models.UserMedia
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		err := authorizeView(ctx, ds, obj.UserID, listsVisibility, tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.UserMediaService
+		list, err = ser.GetMultiple(ids, tx, func(_ *models.UserMedia) bool {
+			return true
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get UserMedia by ids: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return list, nil
+}
+
+// UserMediaList returns UserMediaListResolver implementation.
+func (r *Resolver) UserMediaList() UserMediaListResolver { return &userMediaListResolver{r} }
+
+type userMediaListResolver struct{ *Resolver }
@@ -0,0 +1,160 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func (r *mutationResolver) CreateUserPerson(ctx context.Context, userPerson models.UserPerson, score *float64, scoreSystem *models.ScoreSystem) (*models.UserPerson, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	userPerson.Score, err = internalScore(ctx, "UserPerson", score, scoreSystem)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		_, err := authorizeOwner(ctx, ds, userPerson.UserID,
+			permission("UserPerson", models.ActionCreate), tx)
+		if err != nil {
+			return err
+		}
+
+		ser := ds.UserPersonService
+		_, err = ser.Create(&userPerson, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create UserPerson: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &userPerson, nil
+}
+
+func (r *mutationResolver) UpdateUserPerson(ctx context.Context, userPerson models.UserPerson, score *float64, scoreSystem *models.ScoreSystem) (*models.UserPerson, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	userPerson.Score, err = internalScore(ctx, "UserPerson", score, scoreSystem)
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserPersonService
+		o, err := ser.GetByID(userPerson.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserPerson by id %d: %w", userPerson.Meta.ID, err)
+		}
+
+		// Both the existing and updated owner must be checked to prevent
+		// transferring ownership to or from another User
+		_, err = authorizeOwner(ctx, ds, o.UserID,
+			permission("UserPerson", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+		_, err = authorizeOwner(ctx, ds, userPerson.UserID,
+			permission("UserPerson", models.ActionUpdate), tx)
+		if err != nil {
+			return err
+		}
+
+		err = ser.Update(&userPerson, tx)
+		if err != nil {
+			return fmt.Errorf("failed to update UserPerson by id %d: %w", userPerson.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return &userPerson, nil
+}
+
+func (r *mutationResolver) DeleteUserPerson(ctx context.Context, id int) (*models.UserPerson, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var up *models.UserPerson
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		ser := ds.UserPersonService
+		up, err = ser.GetByID(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get UserPerson by id %d: %w", id, err)
+		}
+
+		_, err = authorizeOwner(ctx, ds, up.UserID,
+			permission("UserPerson", models.ActionDelete), tx)
+		if err != nil {
+			return err
+		}
+
+		err = ser.Delete(id, tx)
+		if err != nil {
+			return fmt.Errorf("failed to delete UserPerson by id %d: %w", id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errorResolve(err)
+	}
+
+	return up, nil
+}
+
+func (r *userPersonResolver) User(ctx context.Context, obj *models.UserPerson) (*models.User, error) {
+	return resolveUserByID(ctx, obj.UserID)
+}
+
+func (r *userPersonResolver) Person(ctx context.Context, obj *models.UserPerson) (*models.Person, error) {
+	ds, err := getCtxDataService(ctx)
+	if err != nil {
+		return nil, errorGetDataServices(err)
+	}
+
+	var p *models.Person
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		ser := ds.PersonService
+		p, err = ser.GetByID(obj.PersonID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Person by id %d: %w", obj.PersonID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (r *userPersonResolver) DisplayScore(ctx context.Context, obj *models.UserPerson, system *models.ScoreSystem) (*float64, error) {
+	return displayScore(ctx, obj.Score, system), nil
+}
+
+func (r *userPersonResolver) Comments(ctx context.Context, obj *models.UserPerson, first *int, skip *int) ([]*models.Title, error) {
+	return sliceTitles(obj.Comments, first, skip), nil
+}
+
+// UserPerson returns UserPersonResolver implementation.
+func (r *Resolver) UserPerson() UserPersonResolver { return &userPersonResolver{r} }
+
+type userPersonResolver struct{ *Resolver }
@@ -0,0 +1,60 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Adapter parses exports of one format into Exports.
+type Adapter interface {
+	Parse(r io.Reader) (*Export, error)
+}
+
+// AdapterFunc is a function that parses exports, used as an Adapter.
+type AdapterFunc func(r io.Reader) (*Export, error)
+
+// Parse calls the function.
+func (f AdapterFunc) Parse(r io.Reader) (*Export, error) {
+	return f(r)
+}
+
+var (
+	adaptersMu sync.RWMutex
+	adapters   = map[string]Adapter{}
+)
+
+// Register makes the given Adapter available by the given format name. It
+// panics if an Adapter is already registered by the name.
+func Register(format string, a Adapter) {
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+	if _, ok := adapters[format]; ok {
+		panic(fmt.Sprintf("importer: adapter already registered for %q", format))
+	}
+	adapters[format] = a
+}
+
+// Formats returns the sorted names of the formats with registered Adapters.
+func Formats() []string {
+	adaptersMu.RLock()
+	defer adaptersMu.RUnlock()
+	formats := make([]string, 0, len(adapters))
+	for f := range adapters {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Parse reads an export of the given format with its registered Adapter.
+func Parse(format string, r io.Reader) (*Export, error) {
+	adaptersMu.RLock()
+	a, ok := adapters[format]
+	adaptersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+	return a.Parse(r)
+}
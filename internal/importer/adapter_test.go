@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+func TestFormats(t *testing.T) {
+	expected := []string{"anilist", "kitsu", "myanimelist"}
+	if f := Formats(); !reflect.DeepEqual(f, expected) {
+		t.Errorf("expected formats %v, got %v", expected, f)
+	}
+
+	_, err := Parse("unknown", strings.NewReader(""))
+	if err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+const anilistExportJSON = `{"data": {"MediaListCollection": {"lists": [
+	{"name": "Completed", "isCustomList": false, "entries": [
+		{"mediaId": 1, "status": "REPEATING", "score": 85, "progress": 4,
+		 "repeat": 1, "notes": "",
+		 "startedAt": {"year": 2019, "month": 3, "day": 1},
+		 "completedAt": {"year": 2019, "month": 4, "day": null},
+		 "media": {"id": 1, "idMal": 1, "type": "ANIME", "format": "TV",
+			"title": {"romaji": "Cowboy Bebop", "english": "Cowboy Bebop",
+				"native": "カウボーイビバップ"},
+			"season": "SPRING", "seasonYear": 1998, "episodes": 26,
+			"genres": ["Action", "Sci-Fi"],
+			"relations": {"edges": [
+				{"relationType": "SIDE_STORY", "node": {"id": 5}}]},
+			"characters": {"edges": [
+				{"role": "MAIN", "node": {"id": 1,
+					"name": {"full": "Spike Spiegel", "native": "スパイク"}}}]}}}
+	]},
+	{"name": "Favourites", "isCustomList": true, "entries": [
+		{"mediaId": 1, "status": "REPEATING", "media": {"id": 1}}
+	]}
+]}}}`
+
+func TestParseAniList(t *testing.T) {
+	ex, err := Parse("anilist", strings.NewReader(anilistExportJSON))
+	if err != nil {
+		t.Fatalf("failed to parse export: %v", err)
+	}
+	if len(ex.Entries) != 1 || len(ex.Media) != 1 || len(ex.Lists) != 1 {
+		t.Fatalf("expected 1 entry, media and list, got %d, %d and %d",
+			len(ex.Entries), len(ex.Media), len(ex.Lists))
+	}
+
+	e := ex.Entries[0]
+	if e.Source != SourceAniList || e.ExternalID != "1" ||
+		e.Title() != "Cowboy Bebop" {
+		t.Errorf("unexpected entry %s %s %q", e.Source, e.ExternalID, e.Title())
+	}
+	if e.Score == nil || *e.Score != 85 {
+		t.Errorf("expected score 85, got %v", e.Score)
+	}
+	if e.Status == nil || *e.Status != models.WatchStatusCurrent {
+		t.Errorf("expected repeating entry to be current, got %v", e.Status)
+	}
+	if len(e.WatchInstances) != 3 || e.WatchInstances[0].Episodes != 26 ||
+		e.WatchInstances[0].EndDate != nil || !e.WatchInstances[2].Ongoing ||
+		e.WatchInstances[2].Episodes != 4 {
+		t.Errorf("unexpected watches %+v", e.WatchInstances)
+	}
+
+	if l := ex.Lists[0]; l.Name != "Favourites" ||
+		!reflect.DeepEqual(l.Entries, []int{0}) {
+		t.Errorf("unexpected list %+v", l)
+	}
+
+	m := ex.Media[0]
+	expectedIDs := []models.ExternalID{
+		{Source: SourceAniList, ID: "1"},
+		{Source: SourceMyAnimeList, ID: "1"},
+	}
+	if ids := m.externalIDs(); !reflect.DeepEqual(ids, expectedIDs) {
+		t.Errorf("expected external IDs %v, got %v", expectedIDs, ids)
+	}
+	if len(m.Titles) != 3 || m.SeasonPremiered.Quarter == nil ||
+		*m.SeasonPremiered.Quarter != models.QuarterSpring {
+		t.Errorf("unexpected titles %v or season %+v", m.Titles,
+			m.SeasonPremiered)
+	}
+	if len(m.Relations) != 1 || m.Relations[0].Relationship != "side story" {
+		t.Errorf("unexpected relations %+v", m.Relations)
+	}
+	if len(m.Characters) != 1 || m.Characters[0].Role != "main" ||
+		len(m.Characters[0].Names) != 2 {
+		t.Errorf("unexpected characters %+v", m.Characters)
+	}
+}
+
+const kitsuExportJSON = `{
+	"data": [
+		{"id": "10", "type": "libraryEntries",
+		 "attributes": {"status": "completed", "progress": 26,
+			"reconsumeCount": 0, "reconsuming": false, "ratingTwenty": 18,
+			"notes": "Classic", "startedAt": "2020-01-01T00:00:00.000Z",
+			"finishedAt": "2020-02-01T00:00:00.000Z"},
+		 "relationships": {"anime": {"data": {"type": "anime", "id": "1"}},
+			"manga": {"data": null}}},
+		{"id": "11", "type": "libraryEntries",
+		 "attributes": {"status": "planned", "progress": 0,
+			"ratingTwenty": null},
+		 "relationships": {"manga": {"data": {"type": "manga", "id": "1"}}}}
+	],
+	"included": [
+		{"id": "1", "type": "anime",
+		 "attributes": {"canonicalTitle": "Cowboy Bebop",
+			"titles": {"en": "Cowboy Bebop", "en_jp": "Cowboy Bebop",
+				"ja_jp": "カウボーイビバップ"},
+			"startDate": "1998-04-03", "subtype": "TV", "episodeCount": 26},
+		 "relationships": {
+			"categories": {"data": [{"type": "categories", "id": "7"}]},
+			"mediaRelationships": {"data": [
+				{"type": "mediaRelationships", "id": "3"}]}}},
+		{"id": "7", "type": "categories", "attributes": {"title": "Space"}},
+		{"id": "3", "type": "mediaRelationships",
+		 "attributes": {"role": "side_story"},
+		 "relationships": {"destination": {"data": {"type": "anime", "id": "5"}}}}
+	]
+}`
+
+func TestParseKitsu(t *testing.T) {
+	ex, err := Parse("kitsu", strings.NewReader(kitsuExportJSON))
+	if err != nil {
+		t.Fatalf("failed to parse export: %v", err)
+	}
+	if len(ex.Entries) != 2 || len(ex.Media) != 1 {
+		t.Fatalf("expected 2 entries and 1 media, got %d and %d",
+			len(ex.Entries), len(ex.Media))
+	}
+
+	bebop := ex.Entries[0]
+	if bebop.Source != SourceKitsu || bebop.Title() != "Cowboy Bebop" {
+		t.Errorf("unexpected entry %s %q", bebop.Source, bebop.Title())
+	}
+	if bebop.Score == nil || *bebop.Score != 90 {
+		t.Errorf("expected score 90, got %v", bebop.Score)
+	}
+	if len(bebop.WatchInstances) != 1 || bebop.WatchInstances[0].EndDate == nil {
+		t.Errorf("unexpected watches %+v", bebop.WatchInstances)
+	}
+
+	manga := ex.Entries[1]
+	if manga.Source != SourceKitsuManga || manga.ExternalID != "1" ||
+		manga.Score != nil || len(manga.Titles) != 0 {
+		t.Errorf("unexpected entry %+v", manga)
+	}
+
+	m := ex.Media[0]
+	if m.Source != SourceKitsu || m.Type == nil || *m.Type != "TV" ||
+		m.StartDate == nil {
+		t.Errorf("unexpected media record %+v", m)
+	}
+	if !reflect.DeepEqual(m.Genres, []string{"Space"}) {
+		t.Errorf("expected genre Space, got %v", m.Genres)
+	}
+	if len(m.Relations) != 1 || m.Relations[0].ExternalID != "5" ||
+		m.Relations[0].Relationship != "side story" {
+		t.Errorf("unexpected relations %+v", m.Relations)
+	}
+}
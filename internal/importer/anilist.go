@@ -0,0 +1,302 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+// SourceAniList is the Source of Entries and MediaRecords exported from
+// AniList, whose anime and manga share IDs.
+const SourceAniList = "anilist"
+
+func init() {
+	Register("anilist", AdapterFunc(ParseAniList))
+}
+
+// anilistStatuses maps the statuses of AniList entries to WatchStatuses.
+// Repeating entries are handled separately.
+var anilistStatuses = map[string]models.WatchStatus{
+	"CURRENT":   models.WatchStatusCurrent,
+	"PLANNING":  models.WatchStatusPlanning,
+	"COMPLETED": models.WatchStatusCompleted,
+	"DROPPED":   models.WatchStatusDropped,
+	"PAUSED":    models.WatchStatusHold,
+	"REPEATING": models.WatchStatusCompleted,
+}
+
+// anilistQuarters maps the seasons of AniList Media to Quarters.
+var anilistQuarters = map[string]models.Quarter{
+	"WINTER": models.QuarterWinter,
+	"SPRING": models.QuarterSpring,
+	"SUMMER": models.QuarterSummer,
+	"FALL":   models.QuarterFall,
+}
+
+// anilistExport is a MediaListCollection of the AniList GraphQL API, either
+// as the response to a query or on its own.
+type anilistExport struct {
+	Data *struct {
+		MediaListCollection anilistCollection `json:"MediaListCollection"`
+	} `json:"data"`
+	anilistCollection
+}
+
+type anilistCollection struct {
+	Lists []struct {
+		Name         string         `json:"name"`
+		IsCustomList bool           `json:"isCustomList"`
+		Entries      []anilistEntry `json:"entries"`
+	} `json:"lists"`
+}
+
+type anilistEntry struct {
+	MediaID     int          `json:"mediaId"`
+	Status      string       `json:"status"`
+	Score       float64      `json:"score"`
+	Progress    int          `json:"progress"`
+	ProgressVol int          `json:"progressVolumes"`
+	Repeat      int          `json:"repeat"`
+	Notes       string       `json:"notes"`
+	StartedAt   anilistDate  `json:"startedAt"`
+	CompletedAt anilistDate  `json:"completedAt"`
+	Media       anilistMedia `json:"media"`
+}
+
+type anilistMedia struct {
+	ID    int    `json:"id"`
+	IDMal *int   `json:"idMal"`
+	Type  string `json:"type"`
+	Title struct {
+		Romaji  string `json:"romaji"`
+		English string `json:"english"`
+		Native  string `json:"native"`
+	} `json:"title"`
+	Format      string      `json:"format"`
+	Source      string      `json:"source"`
+	Description string      `json:"description"`
+	StartDate   anilistDate `json:"startDate"`
+	EndDate     anilistDate `json:"endDate"`
+	Season      string      `json:"season"`
+	SeasonYear  *int        `json:"seasonYear"`
+	Episodes    int         `json:"episodes"`
+	Chapters    int         `json:"chapters"`
+	Volumes     int         `json:"volumes"`
+	Genres      []string    `json:"genres"`
+	Relations   struct {
+		Edges []struct {
+			RelationType string `json:"relationType"`
+			Node         struct {
+				ID int `json:"id"`
+			} `json:"node"`
+		} `json:"edges"`
+	} `json:"relations"`
+	Characters struct {
+		Edges []struct {
+			Role string `json:"role"`
+			Node struct {
+				ID   int `json:"id"`
+				Name struct {
+					Full   string `json:"full"`
+					Native string `json:"native"`
+				} `json:"name"`
+			} `json:"node"`
+		} `json:"edges"`
+	} `json:"characters"`
+}
+
+type anilistDate struct {
+	Year  *int `json:"year"`
+	Month *int `json:"month"`
+	Day   *int `json:"day"`
+}
+
+// time returns the date, or nil if any part of it is unknown.
+func (d anilistDate) time() *time.Time {
+	if d.Year == nil || d.Month == nil || d.Day == nil {
+		return nil
+	}
+	t := time.Date(*d.Year, time.Month(*d.Month), *d.Day, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+// ParseAniList reads a MediaListCollection of the AniList GraphQL API with
+// scores in the POINT_100 format. Entries in several lists are read once,
+// and custom lists are read as Lists. The Media of the entries, if queried,
+// are read as MediaRecords.
+func ParseAniList(r io.Reader) (*Export, error) {
+	var export anilistExport
+	err := json.NewDecoder(r).Decode(&export)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode AniList export: %w", err)
+	}
+	collection := export.anilistCollection
+	if export.Data != nil {
+		collection = export.Data.MediaListCollection
+	}
+
+	var ex Export
+	// The indices of Entries by Media ID
+	indices := map[int]int{}
+	for _, l := range collection.Lists {
+		var list *List
+		if l.IsCustomList {
+			ex.Lists = append(ex.Lists, List{Name: l.Name})
+			list = &ex.Lists[len(ex.Lists)-1]
+		}
+
+		for _, e := range l.Entries {
+			if e.MediaID == 0 {
+				e.MediaID = e.Media.ID
+			}
+			idx, ok := indices[e.MediaID]
+			if !ok {
+				idx = len(ex.Entries)
+				indices[e.MediaID] = idx
+				ex.Entries = append(ex.Entries, e.entry())
+				if e.Media.ID != 0 {
+					ex.Media = append(ex.Media, e.Media.record())
+				}
+			}
+			if list != nil {
+				list.Entries = append(list.Entries, idx)
+			}
+		}
+	}
+	return &ex, nil
+}
+
+// entry returns the Entry of the anilistEntry.
+func (e *anilistEntry) entry() Entry {
+	reading := e.Media.Type == "MANGA"
+	total := e.Media.Episodes
+	if reading {
+		total = e.Media.Chapters
+	}
+
+	rec := entryRecord{
+		source:     SourceAniList,
+		id:         strconv.Itoa(e.MediaID),
+		titles:     e.Media.titles(),
+		comments:   strings.TrimSpace(e.Notes),
+		total:      total,
+		progress:   e.Progress,
+		startDate:  e.StartedAt.time(),
+		finishDate: e.CompletedAt.time(),
+		repeats:    e.Repeat,
+		reading:    reading,
+	}
+	if reading {
+		rec.volumes, rec.totalVolumes = e.ProgressVol, e.Media.Volumes
+	}
+	if status, ok := anilistStatuses[e.Status]; ok {
+		rec.status = &status
+	}
+	if e.Status == "REPEATING" {
+		// Progress is through the ongoing repeat
+		rec.progress = total
+		rec.repeating = true
+		rec.repeatProg = e.Progress
+	}
+	if e.Score > 0 && e.Score <= models.ScoreMax {
+		score := int(e.Score)
+		rec.score = &score
+	}
+	return rec.entry()
+}
+
+// titles returns the titles of the anilistMedia, the romanized one first.
+func (m *anilistMedia) titles() []string {
+	var titles []string
+	for _, t := range []string{m.Title.Romaji, m.Title.English, m.Title.Native} {
+		if t != "" {
+			titles = append(titles, t)
+		}
+	}
+	return titles
+}
+
+// record returns the MediaRecord of the anilistMedia.
+func (m *anilistMedia) record() MediaRecord {
+	r := MediaRecord{
+		Source:     SourceAniList,
+		ExternalID: strconv.Itoa(m.ID),
+		StartDate:  m.StartDate.time(),
+		EndDate:    m.EndDate.time(),
+		Type:       optionalString(m.Format),
+		Origin:     optionalString(m.Source),
+		Genres:     m.Genres,
+	}
+
+	if m.IDMal != nil {
+		source := SourceMyAnimeList
+		if m.Type == "MANGA" {
+			source = SourceMyAnimeListManga
+		}
+		r.OtherIDs = []models.ExternalID{
+			{Source: source, ID: strconv.Itoa(*m.IDMal)},
+		}
+	}
+
+	for _, t := range []models.Title{
+		{String: m.Title.Romaji, Language: "ja-Latn", Priority: models.TitlePriorityPrimary},
+		{String: m.Title.English, Language: "en", Priority: models.TitlePrioritySecondary},
+		{String: m.Title.Native, Language: "ja", Priority: models.TitlePriorityOther},
+	} {
+		if t.String != "" {
+			r.Titles = append(r.Titles, t)
+		}
+	}
+	if m.Description != "" {
+		r.Synopses = []models.Title{{String: m.Description, Language: "en"}}
+	}
+
+	if q, ok := anilistQuarters[m.Season]; ok {
+		r.SeasonPremiered.Quarter = &q
+	}
+	r.SeasonPremiered.Year = m.SeasonYear
+
+	for _, e := range m.Relations.Edges {
+		r.Relations = append(r.Relations, RelationRecord{
+			ExternalID:   strconv.Itoa(e.Node.ID),
+			Relationship: anilistName(e.RelationType),
+		})
+	}
+	for _, e := range m.Characters.Edges {
+		var names []models.Title
+		if e.Node.Name.Full != "" {
+			names = append(names, models.Title{String: e.Node.Name.Full,
+				Language: "ja-Latn", Priority: models.TitlePriorityPrimary})
+		}
+		if e.Node.Name.Native != "" {
+			names = append(names, models.Title{String: e.Node.Name.Native,
+				Language: "ja", Priority: models.TitlePrioritySecondary})
+		}
+		r.Characters = append(r.Characters, CharacterRecord{
+			ExternalID: strconv.Itoa(e.Node.ID),
+			Names:      names,
+			Role:       anilistName(e.Role),
+		})
+	}
+	return r
+}
+
+// anilistName returns the given AniList enum value in lower case with words
+// separated by spaces, such as "side story" for SIDE_STORY.
+func anilistName(v string) string {
+	return strings.ToLower(strings.Replace(v, "_", " ", -1))
+}
+
+// optionalString returns a pointer to the given string, or nil if it is
+// empty.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,338 @@
+package importer
+
+import (
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// importCatalog matches the given MediaRecords to Media by external ID or
+// title. If the catalog is imported, Media are created for those that are
+// unmatched, the external IDs of those that are matched by title are
+// recorded, and their genres, relations and Characters are imported.
+func (im *Importer) importCatalog(records []MediaRecord, m *matcher,
+	tx db.Tx) ([]*MediaResult, error) {
+	var results []*MediaResult
+	for i := range records {
+		r := &records[i]
+		res := &MediaResult{Record: r}
+		results = append(results, res)
+
+		ids := r.externalIDs()
+		res.MediaID = m.byExternalID(ids...)
+		if res.MediaID == 0 {
+			titles := make([]string, len(r.Titles))
+			for j, t := range r.Titles {
+				titles[j] = t.String
+			}
+			res.MediaID, _, _ = m.byTitle(titles, r.kind())
+		}
+		if !im.ImportCatalog {
+			m.link(ids, res.MediaID)
+			continue
+		}
+
+		var md *models.Media
+		if res.MediaID == 0 {
+			md = r.media()
+			id, err := im.MediaService.Create(md, tx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create Media for %s %s: %w",
+					r.Source, r.ExternalID, err)
+			}
+			res.MediaID, res.Created = id, true
+		} else {
+			var err error
+			md, err = im.MediaService.GetByID(res.MediaID, tx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get Media by ID %d: %w",
+					res.MediaID, err)
+			}
+			if addExternalIDs(md, m.unclaimed(ids, md.Meta.ID)) {
+				err = im.MediaService.Update(md, tx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to update Media by ID %d: %w",
+						res.MediaID, err)
+				}
+			}
+		}
+		m.add(md)
+	}
+	if !im.ImportCatalog {
+		return results, nil
+	}
+
+	c, err := im.newCatalog(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, res := range results {
+		err = c.importGenres(res.MediaID, res.Record.Genres, tx)
+		if err != nil {
+			return nil, err
+		}
+		err = c.importRelations(res.MediaID, res.Record, m, tx)
+		if err != nil {
+			return nil, err
+		}
+		err = c.importCharacters(res.MediaID, res.Record, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// catalog holds the Genres and Characters persisted or created while
+// importing the catalog, so that they are not duplicated.
+type catalog struct {
+	*Importer
+	// genres maps normalized names to the IDs of the Genres with them.
+	genres map[string]int
+	// characters maps external IDs to the IDs of the Characters with them.
+	characters map[models.ExternalID]int
+}
+
+// newCatalog returns a catalog of all persisted Genres and Characters.
+func (im *Importer) newCatalog(tx db.Tx) (*catalog, error) {
+	c := catalog{
+		Importer:   im,
+		genres:     map[string]int{},
+		characters: map[models.ExternalID]int{},
+	}
+
+	genres, err := im.GenreService.GetAll(nil, nil, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Genres: %w", err)
+	}
+	for _, g := range genres {
+		for _, n := range g.Names {
+			key := normalizeTitle(n.String)
+			if _, ok := c.genres[key]; !ok {
+				c.genres[key] = g.Meta.ID
+			}
+		}
+	}
+
+	characters, err := im.CharacterService.GetAll(nil, nil, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Characters: %w", err)
+	}
+	for _, ch := range characters {
+		for _, id := range ch.ExternalIDs {
+			id = externalKey(id)
+			if _, ok := c.characters[id]; !ok {
+				c.characters[id] = ch.Meta.ID
+			}
+		}
+	}
+	return &c, nil
+}
+
+// importGenres adds the Genres with the given names to the Media with the
+// given ID, creating those that do not exist.
+func (c *catalog) importGenres(mID int, names []string, tx db.Tx) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	existing, err := c.MediaGenreService.GetByMedia(mID, nil, nil, tx)
+	if err != nil {
+		return fmt.Errorf("failed to get MediaGenres by Media ID %d: %w", mID, err)
+	}
+	has := map[int]bool{}
+	for _, mg := range existing {
+		has[mg.GenreID] = true
+	}
+
+	for _, name := range names {
+		key := normalizeTitle(name)
+		if key == "" {
+			continue
+		}
+		gID, ok := c.genres[key]
+		if !ok {
+			g := models.Genre{Names: []models.Title{{String: name, Language: "en"}}}
+			gID, err = c.GenreService.Create(&g, tx)
+			if err != nil {
+				return fmt.Errorf("failed to create Genre %q: %w", name, err)
+			}
+			c.genres[key] = gID
+		}
+		if has[gID] {
+			continue
+		}
+
+		_, err = c.MediaGenreService.Create(&models.MediaGenre{
+			MediaID: mID,
+			GenreID: gID,
+		}, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create MediaGenre: %w", err)
+		}
+		has[gID] = true
+	}
+	return nil
+}
+
+// importRelations relates the Media with the given ID to the matched Media of
+// the relations of the given MediaRecord. Relations to Media that are not
+// matched are skipped.
+func (c *catalog) importRelations(mID int, r *MediaRecord, m *matcher,
+	tx db.Tx) error {
+	if len(r.Relations) == 0 {
+		return nil
+	}
+
+	existing, err := c.MediaRelationService.GetByOwner(mID, nil, nil, tx)
+	if err != nil {
+		return fmt.Errorf("failed to get MediaRelations by owner ID %d: %w",
+			mID, err)
+	}
+
+	for _, rel := range r.Relations {
+		relatedID := m.byExternalID(
+			models.ExternalID{Source: r.Source, ID: rel.ExternalID})
+		if relatedID == 0 || relatedID == mID {
+			continue
+		}
+
+		exists := false
+		for _, mr := range existing {
+			if mr.RelatedID == relatedID && mr.Relationship == rel.Relationship {
+				exists = true
+				break
+			}
+		}
+		if exists {
+			continue
+		}
+
+		mr := models.MediaRelation{
+			OwnerID:      mID,
+			RelatedID:    relatedID,
+			Relationship: rel.Relationship,
+		}
+		_, err = c.MediaRelationService.Create(&mr, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create MediaRelation: %w", err)
+		}
+		existing = append(existing, &mr)
+	}
+	return nil
+}
+
+// importCharacters adds the Characters of the given MediaRecord to the Media
+// with the given ID, creating those that do not exist.
+func (c *catalog) importCharacters(mID int, r *MediaRecord, tx db.Tx) error {
+	if len(r.Characters) == 0 {
+		return nil
+	}
+
+	existing, err := c.MediaCharacterService.GetByMedia(mID, nil, nil, tx)
+	if err != nil {
+		return fmt.Errorf("failed to get MediaCharacters by Media ID %d: %w",
+			mID, err)
+	}
+	has := map[int]bool{}
+	for _, mc := range existing {
+		if mc.CharacterID != nil {
+			has[*mc.CharacterID] = true
+		}
+	}
+
+	for _, cr := range r.Characters {
+		id := models.ExternalID{Source: r.Source, ID: cr.ExternalID}
+		cID, ok := c.characters[id]
+		if !ok {
+			ch := models.Character{
+				Names:       cr.Names,
+				ExternalIDs: []models.ExternalID{id},
+			}
+			cID, err = c.CharacterService.Create(&ch, tx)
+			if err != nil {
+				return fmt.Errorf("failed to create Character for %s %s: %w",
+					r.Source, cr.ExternalID, err)
+			}
+			c.characters[id] = cID
+		}
+		if has[cID] {
+			continue
+		}
+
+		role := cr.Role
+		_, err = c.MediaCharacterService.Create(&models.MediaCharacter{
+			MediaID:       mID,
+			CharacterID:   &cID,
+			CharacterRole: &role,
+		}, tx)
+		if err != nil {
+			return fmt.Errorf("failed to create MediaCharacter: %w", err)
+		}
+		has[cID] = true
+	}
+	return nil
+}
+
+// media returns new Media described by the MediaRecord.
+func (r *MediaRecord) media() *models.Media {
+	md := &models.Media{
+		Titles:          r.Titles,
+		Synopses:        r.Synopses,
+		StartDate:       r.StartDate,
+		EndDate:         r.EndDate,
+		SeasonPremiered: r.SeasonPremiered,
+		ExternalIDs:     r.externalIDs(),
+	}
+	// Formats and sources that nao does not know are left unset
+	md.Type = r.mediaType()
+	if r.Origin != nil {
+		if s, ok := models.NormalizeMediaSource(*r.Origin); ok {
+			md.Source = &s
+		}
+	}
+	return md
+}
+
+// kind returns the MediaKind of the Media described by the MediaRecord.
+func (r *MediaRecord) kind() models.MediaKind {
+	md := models.Media{Type: r.mediaType()}
+	return md.Kind()
+}
+
+// mediaType returns the MediaType of the format of the MediaRecord, or nil if
+// it is not given or not known.
+func (r *MediaRecord) mediaType() *models.MediaType {
+	if r.Type == nil {
+		return nil
+	}
+	t, ok := models.NormalizeMediaType(*r.Type)
+	if !ok {
+		return nil
+	}
+	return &t
+}
+
+// addExternalIDs adds those of the given external IDs that the given Media
+// does not have to it, returning true if any were.
+func addExternalIDs(md *models.Media, ids []models.ExternalID) bool {
+	added := false
+	for _, id := range ids {
+		if id.Source == "" || id.ID == "" {
+			continue
+		}
+		has := false
+		for _, e := range md.ExternalIDs {
+			if externalKey(e) == externalKey(id) {
+				has = true
+				break
+			}
+		}
+		if !has {
+			md.ExternalIDs = append(md.ExternalIDs, id)
+			added = true
+		}
+	}
+	return added
+}
@@ -0,0 +1,112 @@
+package importer
+
+import (
+	"time"
+
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// maxRepeats is the number of repeats recorded for an entry above which
+// further repeats are ignored, so that absurd counts in an export do not
+// create as many WatchedInstances.
+const maxRepeats = 100
+
+// entryRecord is the progress of a User through some Media as recorded by
+// most services, from which the WatchedInstances of an Entry are derived.
+type entryRecord struct {
+	source     string
+	id         string
+	titles     []string
+	status     *models.WatchStatus
+	score      *int
+	comments   string
+	total      int
+	progress   int
+	startDate  *time.Time
+	finishDate *time.Time
+	// repeats is the number of complete watches after the first.
+	repeats int
+	// repeating is true if the User is watching the Media again, having
+	// progressed through repeatProg of it.
+	repeating  bool
+	repeatProg int
+	// reading is true if the Media is read, so that progress is counted in
+	// chapters rather than episodes. volumes and totalVolumes are the
+	// volumes read and in the Media, if known.
+	reading      bool
+	volumes      int
+	totalVolumes int
+}
+
+// entry returns the Entry described by the entryRecord. The recorded dates
+// belong to the first watch; each repeat, up to maxRepeats, adds a complete
+// watch, and an ongoing repeat makes the Entry current again.
+func (r entryRecord) entry() Entry {
+	e := Entry{
+		Source:     r.source,
+		ExternalID: r.id,
+		Titles:     r.titles,
+		Score:      r.score,
+	}
+	if r.reading {
+		e.Kind = models.MediaKindManga
+	}
+	if r.comments != "" {
+		e.Comments = []models.Title{{String: r.comments}}
+	}
+	if r.status == nil {
+		return e
+	}
+	status := *r.status
+
+	complete := r.total
+	if complete < r.progress {
+		complete = r.progress
+	}
+	completeVolumes := r.totalVolumes
+	if completeVolumes < r.volumes {
+		completeVolumes = r.volumes
+	}
+
+	var ws []models.WatchedInstance
+	switch status {
+	case models.WatchStatusCompleted:
+		w := r.instance(complete, completeVolumes)
+		w.StartDate, w.EndDate = r.startDate, r.finishDate
+		ws = append(ws, w)
+	case models.WatchStatusCurrent, models.WatchStatusHold:
+		w := r.instance(r.progress, r.volumes)
+		w.Ongoing, w.StartDate = true, r.startDate
+		ws = append(ws, w)
+	case models.WatchStatusDropped:
+		w := r.instance(r.progress, r.volumes)
+		w.StartDate, w.EndDate = r.startDate, r.finishDate
+		ws = append(ws, w)
+	}
+	repeats := r.repeats
+	if repeats > maxRepeats {
+		repeats = maxRepeats
+	}
+	for i := 0; i < repeats; i++ {
+		ws = append(ws, r.instance(complete, completeVolumes))
+	}
+	if r.repeating && status == models.WatchStatusCompleted {
+		status = models.WatchStatusCurrent
+		w := r.instance(r.repeatProg, 0)
+		w.Ongoing = true
+		ws = append(ws, w)
+	}
+
+	e.Status = &status
+	e.WatchInstances = ws
+	return e
+}
+
+// instance returns a WatchedInstance with the given progress, counted in
+// chapters and volumes if the Media is read and in episodes otherwise.
+func (r entryRecord) instance(progress int, volumes int) models.WatchedInstance {
+	if r.reading {
+		return models.WatchedInstance{Chapters: progress, Volumes: volumes}
+	}
+	return models.WatchedInstance{Episodes: progress}
+}
@@ -0,0 +1,381 @@
+// Package importer imports the lists of Users and the catalog data they
+// refer to from the exports of other services. Each export format is parsed
+// by an Adapter into an Export, which is matched to existing Media by the
+// external IDs stored on them or by title, so that importing an export again
+// does not duplicate anything.
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/data"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// DefaultMinSimilarity is the similarity of titles above which entries are
+// matched to Media if not configured.
+const DefaultMinSimilarity = 0.85
+
+// Export is the content of an export of some service.
+type Export struct {
+	// Entries are the list entries of the User.
+	Entries []Entry
+	// Lists are the custom lists of the User.
+	Lists []List
+	// Media describes the Media the Entries refer to, if the export includes
+	// catalog data.
+	Media []MediaRecord
+}
+
+// Entry is an entry of an exported list, describing the Media it refers to
+// and the relationship of the User with it.
+type Entry struct {
+	// Source is the name of the service the entry was exported from, which
+	// namespaces ExternalID.
+	Source string
+	// ExternalID is the ID of the Media in the Source.
+	ExternalID string
+	// Titles are the titles of the Media given by the Source, the preferred
+	// one first.
+	Titles []string
+	// Kind is whether the Media is watched or read; only Media of the same
+	// kind are matched by title.
+	Kind           models.MediaKind
+	Status         *models.WatchStatus
+	Score          *int
+	WatchInstances []models.WatchedInstance
+	Comments       []models.Title
+}
+
+// Title returns the preferred title of the Entry, if any.
+func (e *Entry) Title() string {
+	if len(e.Titles) == 0 {
+		return ""
+	}
+	return e.Titles[0]
+}
+
+// List is an exported custom list, imported as a UserMediaList.
+type List struct {
+	Name string
+	// Entries are the indices of the Entries of the Export in the List.
+	Entries []int
+}
+
+// MediaRecord is the catalog data of some Media given by a service.
+type MediaRecord struct {
+	// Source and ExternalID identify the Media in the service, as they do for
+	// Entries.
+	Source     string
+	ExternalID string
+	// OtherIDs are the IDs of the Media in other services given by the
+	// service.
+	OtherIDs        []models.ExternalID
+	Titles          []models.Title
+	Synopses        []models.Title
+	StartDate       *time.Time
+	EndDate         *time.Time
+	SeasonPremiered models.Season
+	Type            *string
+	Origin          *string
+	// Genres are the names of the genres of the Media.
+	Genres     []string
+	Relations  []RelationRecord
+	Characters []CharacterRecord
+}
+
+// RelationRecord is a relation of some Media to another in the same service.
+type RelationRecord struct {
+	ExternalID   string
+	Relationship string
+}
+
+// CharacterRecord is a Character of some Media given by a service.
+type CharacterRecord struct {
+	ExternalID string
+	Names      []models.Title
+	Role       string
+}
+
+// externalIDs returns the IDs of the Media of the MediaRecord in each
+// service.
+func (r *MediaRecord) externalIDs() []models.ExternalID {
+	ids := []models.ExternalID{{Source: r.Source, ID: r.ExternalID}}
+	return append(ids, r.OtherIDs...)
+}
+
+// Result describes what importing an Entry did or, in a dry run, would do.
+type Result struct {
+	Entry *Entry
+	// MediaID is the ID of the Media the Entry was matched to, or 0 if
+	// unmatched.
+	MediaID int
+	// Similarity is the similarity of the titles of the Entry and the matched
+	// Media, from 0 to 1; it is 1 for Entries matched by external ID.
+	Similarity float64
+	// UserMediaID is the ID of the UserMedia created for the Entry or that the
+	// User already had for the Media, or 0 if none.
+	UserMediaID int
+	// Reason describes why the Entry was not imported.
+	Reason string
+}
+
+// MediaResult describes what importing a MediaRecord did or, in a dry run,
+// would do.
+type MediaResult struct {
+	Record *MediaRecord
+	// MediaID is the ID of the Media the MediaRecord was matched to or created
+	// as, or 0 if neither.
+	MediaID int
+	// Created is true if the Media was created from the MediaRecord.
+	Created bool
+}
+
+// ListResult describes what importing a List did or, in a dry run, would do.
+type ListResult struct {
+	List *List
+	// UserMediaListID is the ID of the UserMediaList the List was imported
+	// into, or 0 if in a dry run it would be created.
+	UserMediaListID int
+	// Created is true if the UserMediaList was created for the List.
+	Created bool
+	// Added is the number of UserMedia added to the UserMediaList.
+	Added int
+}
+
+// Report describes the outcome of an import.
+type Report struct {
+	DryRun bool
+	// Imported are the Entries that were, or in a dry run would be, imported.
+	Imported []*Result
+	// Unmatched are the Entries that could not be matched to any Media.
+	Unmatched []*Result
+	// Conflicts are the Entries that were matched to Media but could not be
+	// imported, such as those for Media that the User already has UserMedia
+	// for.
+	Conflicts []*Result
+	// Media are the outcomes of importing the catalog data of the export.
+	Media []*MediaResult
+	// Lists are the outcomes of importing the custom lists of the export.
+	Lists []*ListResult
+}
+
+// Importer imports Exports.
+type Importer struct {
+	MediaService          *data.MediaService
+	UserMediaService      *data.UserMediaService
+	UserMediaListService  *data.UserMediaListService
+	GenreService          *data.GenreService
+	CharacterService      *data.CharacterService
+	MediaGenreService     *data.MediaGenreService
+	MediaRelationService  *data.MediaRelationService
+	MediaCharacterService *data.MediaCharacterService
+	// ImportCatalog is true if the catalog data of Exports is imported;
+	// otherwise it is only used to match Entries to existing Media.
+	ImportCatalog bool
+	// MinSimilarity is the similarity of titles, from 0 to 1, above which
+	// Entries are matched to Media. DefaultMinSimilarity is used if zero.
+	MinSimilarity float64
+}
+
+// Import imports the given Export for the User with the given ID. Entries
+// that are unmatched or conflict with existing UserMedia or each other are
+// skipped.
+//
+// A dry run imports everything the same way, but the caller must roll back
+// the transaction; the IDs of the models created in it are left out of the
+// Report.
+func (im *Importer) Import(uID int, export *Export, dryRun bool,
+	tx db.Tx) (*Report, error) {
+	report := Report{DryRun: dryRun}
+
+	matcher, err := im.newMatcher(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Media, err = im.importCatalog(export.Media, matcher, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := im.UserMediaService.GetByUser(uID, nil, nil, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get UserMedia by User ID %d: %w",
+			uID, err)
+	}
+	taken := map[int]*Result{}
+	for _, um := range existing {
+		taken[um.MediaID] = &Result{
+			UserMediaID: um.Meta.ID,
+			Reason:      fmt.Sprintf("already has UserMedia %d", um.Meta.ID),
+		}
+	}
+
+	// The Results of Entries by index, for Lists
+	results := make([]*Result, len(export.Entries))
+	for i := range export.Entries {
+		e := &export.Entries[i]
+		res := &Result{Entry: e}
+		results[i] = res
+
+		res.MediaID, res.Similarity, res.Reason = matcher.match(e)
+		if res.MediaID == 0 {
+			report.Unmatched = append(report.Unmatched, res)
+			continue
+		}
+		if t, ok := taken[res.MediaID]; ok {
+			res.UserMediaID, res.Reason = t.UserMediaID, t.Reason
+			report.Conflicts = append(report.Conflicts, res)
+			continue
+		}
+
+		um := userMedia(uID, res.MediaID, e)
+		err = im.UserMediaService.Validate(um, tx)
+		if err != nil {
+			res.Reason = err.Error()
+			report.Conflicts = append(report.Conflicts, res)
+			continue
+		}
+
+		res.UserMediaID, err = im.UserMediaService.Create(um, tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create UserMedia for %q: %w",
+				e.Title(), err)
+		}
+		taken[res.MediaID] = &Result{
+			UserMediaID: res.UserMediaID,
+			Reason:      fmt.Sprintf("matched by earlier entry %q", e.Title()),
+		}
+		report.Imported = append(report.Imported, res)
+	}
+
+	report.Lists, err = im.importLists(uID, export.Lists, results, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		report.forgetCreated()
+	}
+	return &report, nil
+}
+
+// importLists adds the UserMedia of the given Results to the UserMediaLists of
+// the User with the given ID with the names of the given Lists, creating them
+// if necessary.
+func (im *Importer) importLists(uID int, lists []List, results []*Result,
+	tx db.Tx) ([]*ListResult, error) {
+	if len(lists) == 0 {
+		return nil, nil
+	}
+
+	existing, err := im.UserMediaListService.GetByUser(uID, nil, nil, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get UserMediaLists by User ID %d: %w",
+			uID, err)
+	}
+
+	var lresults []*ListResult
+	for i := range lists {
+		l := &lists[i]
+		lres := &ListResult{List: l}
+
+		var uml *models.UserMediaList
+		for _, e := range existing {
+			if len(e.Names) > 0 && strings.EqualFold(e.Names[0].String, l.Name) {
+				uml = e
+				break
+			}
+		}
+		if uml == nil {
+			uml = &models.UserMediaList{
+				UserID: uID,
+				Names:  []models.Title{{String: l.Name}},
+			}
+			lres.Created = true
+		}
+
+		contains := map[int]bool{}
+		for _, umID := range uml.UserMedia {
+			contains[umID] = true
+		}
+		for _, idx := range l.Entries {
+			if idx < 0 || idx >= len(results) {
+				continue
+			}
+			umID := results[idx].UserMediaID
+			if umID == 0 || contains[umID] {
+				continue
+			}
+			uml.UserMedia = append(uml.UserMedia, umID)
+			contains[umID] = true
+			lres.Added++
+		}
+
+		if lres.Created {
+			_, err = im.UserMediaListService.Create(uml, tx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create UserMediaList %q: %w",
+					l.Name, err)
+			}
+			existing = append(existing, uml)
+		} else if lres.Added > 0 {
+			err = im.UserMediaListService.Update(uml, tx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update UserMediaList %d: %w",
+					uml.Meta.ID, err)
+			}
+		}
+		lres.UserMediaListID = uml.Meta.ID
+		lresults = append(lresults, lres)
+	}
+	return lresults, nil
+}
+
+// forgetCreated removes the IDs of models created in a dry run from the
+// Report, as they are rolled back.
+func (r *Report) forgetCreated() {
+	created := map[int]bool{}
+	for _, mr := range r.Media {
+		if mr.Created {
+			created[mr.MediaID] = true
+			mr.MediaID = 0
+		}
+	}
+	createdUserMedia := map[int]bool{}
+	for _, res := range r.Imported {
+		createdUserMedia[res.UserMediaID] = true
+	}
+	for _, results := range [][]*Result{r.Imported, r.Conflicts} {
+		for _, res := range results {
+			if createdUserMedia[res.UserMediaID] {
+				res.UserMediaID = 0
+			}
+			if created[res.MediaID] {
+				res.MediaID = 0
+			}
+		}
+	}
+	for _, lr := range r.Lists {
+		if lr.Created {
+			lr.UserMediaListID = 0
+		}
+	}
+}
+
+// userMedia returns new UserMedia of the User with the given ID for the Media
+// with the given ID as described by the given Entry.
+func userMedia(uID int, mID int, e *Entry) *models.UserMedia {
+	return &models.UserMedia{
+		UserID:         uID,
+		MediaID:        mID,
+		Score:          e.Score,
+		Status:         e.Status,
+		WatchInstances: e.WatchInstances,
+		Comments:       e.Comments,
+	}
+}
@@ -0,0 +1,228 @@
+package importer
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dophin2009/nao/internal/data"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// errRollback is returned from transactions to roll them back.
+var errRollback = errors.New("rollback")
+
+// newTestImporter returns an Importer of the catalog backed by a new database
+// in a temporary directory, and the ID of a User to import for. The database
+// is closed when the test finishes.
+func newTestImporter(t *testing.T) (*Importer, int, *db.DatabaseService) {
+	users := data.NewUserService(db.PersistHooks{})
+	media := data.NewMediaService(db.PersistHooks{})
+	genres := data.NewGenreService(db.PersistHooks{})
+	characters := data.NewCharacterService(db.PersistHooks{})
+	persons := data.NewPersonService(db.PersistHooks{})
+	userMedia := data.NewUserMediaService(db.PersistHooks{}, users, media)
+	im := Importer{
+		MediaService:     media,
+		UserMediaService: userMedia,
+		UserMediaListService: data.NewUserMediaListService(db.PersistHooks{},
+			users, userMedia),
+		GenreService:     genres,
+		CharacterService: characters,
+		MediaGenreService: data.NewMediaGenreService(db.PersistHooks{},
+			media, genres),
+		MediaRelationService: data.NewMediaRelationService(db.PersistHooks{},
+			media),
+		MediaCharacterService: data.NewMediaCharacterService(db.PersistHooks{},
+			media, characters, persons),
+		ImportCatalog: true,
+	}
+
+	var buckets []string
+	for _, ser := range []db.Service{users, media, genres, characters,
+		persons, userMedia, im.UserMediaListService, im.MediaGenreService,
+		im.MediaRelationService, im.MediaCharacterService} {
+		buckets = append(buckets, ser.Bucket())
+		if indexed, ok := ser.(interface{ IndexBuckets() []string }); ok {
+			buckets = append(buckets, indexed.IndexBuckets()...)
+		}
+	}
+	driver, err := db.ConnectBoltDatabase(&db.BoltDatabaseConfig{
+		Path:     filepath.Join(t.TempDir(), "db"),
+		FileMode: 0600,
+		Buckets:  buckets,
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	database := &db.DatabaseService{DatabaseDriver: driver}
+	t.Cleanup(func() { database.Close() })
+
+	var uID int
+	err = database.Transaction(true, func(tx db.Tx) error {
+		uID, err = users.Create(&models.User{
+			Username: "someone",
+			Email:    "someone@example.com",
+			Password: []byte("correct horse battery"),
+		}, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to create User: %v", err)
+	}
+	return &im, uID, database
+}
+
+// countCatalog returns the number of Media, UserMedia, Characters, Genres
+// and UserMediaLists persisted.
+func countCatalog(t *testing.T, im *Importer,
+	database *db.DatabaseService) [5]int {
+	var counts [5]int
+	err := database.Transaction(false, func(tx db.Tx) error {
+		media, err := im.MediaService.GetAll(nil, nil, tx)
+		if err != nil {
+			return err
+		}
+		userMedia, err := im.UserMediaService.GetAll(nil, nil, tx)
+		if err != nil {
+			return err
+		}
+		characters, err := im.CharacterService.GetAll(nil, nil, tx)
+		if err != nil {
+			return err
+		}
+		genres, err := im.GenreService.GetAll(nil, nil, tx)
+		if err != nil {
+			return err
+		}
+		lists, err := im.UserMediaListService.GetAll(nil, nil, tx)
+		if err != nil {
+			return err
+		}
+		counts = [5]int{len(media), len(userMedia), len(characters),
+			len(genres), len(lists)}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to count catalog: %v", err)
+	}
+	return counts
+}
+
+// runImport imports the given export of the given format, rolling back the
+// transaction in a dry run.
+func runImport(t *testing.T, im *Importer, uID int, format string,
+	export string, dryRun bool, database *db.DatabaseService) *Report {
+	ex, err := Parse(format, strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("failed to parse export: %v", err)
+	}
+
+	var report *Report
+	err = database.Transaction(true, func(tx db.Tx) error {
+		report, err = im.Import(uID, ex, dryRun, tx)
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			return errRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		t.Fatalf("failed to import: %v", err)
+	}
+	return report
+}
+
+func TestImportIdempotent(t *testing.T) {
+	im, uID, database := newTestImporter(t)
+
+	report := runImport(t, im, uID, "anilist", anilistExportJSON, false,
+		database)
+	if len(report.Imported) != 1 || len(report.Media) != 1 ||
+		!report.Media[0].Created || len(report.Lists) != 1 ||
+		!report.Lists[0].Created || report.Lists[0].Added != 1 {
+		t.Fatalf("unexpected first report %+v", report)
+	}
+	expected := [5]int{1, 1, 1, 2, 1}
+	if counts := countCatalog(t, im, database); counts != expected {
+		t.Errorf("expected counts %v, got %v", expected, counts)
+	}
+
+	report = runImport(t, im, uID, "anilist", anilistExportJSON, false,
+		database)
+	if len(report.Imported) != 0 || len(report.Conflicts) != 1 ||
+		len(report.Media) != 1 || report.Media[0].Created ||
+		len(report.Lists) != 1 || report.Lists[0].Created ||
+		report.Lists[0].Added != 0 {
+		t.Errorf("unexpected second report %+v", report)
+	}
+	if counts := countCatalog(t, im, database); counts != expected {
+		t.Errorf("expected counts %v after second import, got %v", expected,
+			counts)
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	im, uID, database := newTestImporter(t)
+
+	report := runImport(t, im, uID, "anilist", anilistExportJSON, true,
+		database)
+	if !report.DryRun || len(report.Imported) != 1 ||
+		report.Imported[0].UserMediaID != 0 ||
+		report.Imported[0].MediaID != 0 || len(report.Media) != 1 ||
+		!report.Media[0].Created || report.Media[0].MediaID != 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if counts := countCatalog(t, im, database); counts != [5]int{} {
+		t.Errorf("expected nothing persisted, got counts %v", counts)
+	}
+}
+
+func TestImportUnmatchedAndConflicts(t *testing.T) {
+	im, uID, database := newTestImporter(t)
+
+	err := database.Transaction(true, func(tx db.Tx) error {
+		_, err := im.MediaService.Create(&models.Media{
+			Titles: []models.Title{{String: "Cowboy Bebop"}}}, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to create Media: %v", err)
+	}
+
+	// The first two entries match the same Media by title
+	const export = `<myanimelist>
+		<anime>
+			<series_animedb_id>1</series_animedb_id>
+			<series_title>Cowboy Bebop</series_title>
+			<my_status>Plan to Watch</my_status>
+		</anime>
+		<anime>
+			<series_animedb_id>100</series_animedb_id>
+			<series_title>Cowboy Bebop!</series_title>
+			<my_status>Plan to Watch</my_status>
+		</anime>
+		<anime>
+			<series_animedb_id>2</series_animedb_id>
+			<series_title>Trigun</series_title>
+			<my_status>Plan to Watch</my_status>
+		</anime>
+	</myanimelist>`
+	report := runImport(t, im, uID, "myanimelist", export, false, database)
+	if len(report.Imported) != 1 || report.Imported[0].Entry.ExternalID != "1" {
+		t.Errorf("expected first entry imported, got %+v", report.Imported)
+	}
+	if len(report.Conflicts) != 1 ||
+		report.Conflicts[0].Entry.ExternalID != "100" ||
+		report.Conflicts[0].UserMediaID != report.Imported[0].UserMediaID {
+		t.Errorf("expected second entry to conflict, got %+v", report.Conflicts)
+	}
+	if len(report.Unmatched) != 1 ||
+		report.Unmatched[0].Entry.ExternalID != "2" {
+		t.Errorf("expected third entry unmatched, got %+v", report.Unmatched)
+	}
+}
@@ -0,0 +1,297 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Dophin2009/nao/pkg/models"
+	json "github.com/json-iterator/go"
+)
+
+const (
+	// SourceKitsu is the Source of Entries and MediaRecords of anime exported
+	// from Kitsu.
+	SourceKitsu = "kitsu"
+	// SourceKitsuManga is the Source of Entries and MediaRecords of manga
+	// exported from Kitsu, whose IDs are separate from those of anime.
+	SourceKitsuManga = "kitsu-manga"
+)
+
+func init() {
+	Register("kitsu", AdapterFunc(ParseKitsu))
+}
+
+// kitsuStatuses maps the statuses of Kitsu library entries to WatchStatuses.
+var kitsuStatuses = map[string]models.WatchStatus{
+	"current":   models.WatchStatusCurrent,
+	"planned":   models.WatchStatusPlanning,
+	"completed": models.WatchStatusCompleted,
+	"on_hold":   models.WatchStatusHold,
+	"dropped":   models.WatchStatusDropped,
+}
+
+// kitsuExport is a JSON:API document of Kitsu library entries, with the
+// anime and manga they refer to, and optionally their categories and media
+// relationships, included.
+type kitsuExport struct {
+	Data     []kitsuResource `json:"data"`
+	Included []kitsuResource `json:"included"`
+}
+
+type kitsuResource struct {
+	ID            string                       `json:"id"`
+	Type          string                       `json:"type"`
+	Attributes    kitsuAttributes              `json:"attributes"`
+	Relationships map[string]kitsuRelationship `json:"relationships"`
+}
+
+// kitsuAttributes are the attributes of all kinds of Kitsu resources used.
+type kitsuAttributes struct {
+	// Library entries
+	Status         string  `json:"status"`
+	Progress       int     `json:"progress"`
+	ReconsumeCount int     `json:"reconsumeCount"`
+	Reconsuming    bool    `json:"reconsuming"`
+	RatingTwenty   *int    `json:"ratingTwenty"`
+	Notes          string  `json:"notes"`
+	StartedAt      *string `json:"startedAt"`
+	FinishedAt     *string `json:"finishedAt"`
+
+	// Anime and manga
+	CanonicalTitle string            `json:"canonicalTitle"`
+	Titles         map[string]string `json:"titles"`
+	Synopsis       string            `json:"synopsis"`
+	StartDate      *string           `json:"startDate"`
+	EndDate        *string           `json:"endDate"`
+	Subtype        string            `json:"subtype"`
+	EpisodeCount   *int              `json:"episodeCount"`
+	ChapterCount   *int              `json:"chapterCount"`
+
+	// Categories
+	Title string `json:"title"`
+
+	// Media relationships
+	Role string `json:"role"`
+}
+
+type kitsuRelationship struct {
+	Data json.RawMessage `json:"data"`
+}
+
+// kitsuIdentifier identifies a Kitsu resource.
+type kitsuIdentifier struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// one returns the single resource identified by the relationship, if any.
+func (r kitsuRelationship) one() (kitsuIdentifier, bool) {
+	var id kitsuIdentifier
+	err := json.Unmarshal(r.Data, &id)
+	return id, err == nil && id.ID != ""
+}
+
+// many returns the resources identified by the relationship.
+func (r kitsuRelationship) many() []kitsuIdentifier {
+	var ids []kitsuIdentifier
+	err := json.Unmarshal(r.Data, &ids)
+	if err != nil {
+		return nil
+	}
+	return ids
+}
+
+// kitsuTitleLanguages maps the keys of the titles of Kitsu anime and manga to
+// the languages of Titles.
+var kitsuTitleLanguages = map[string]string{
+	"en":    "en",
+	"en_jp": "ja-Latn",
+	"ja_jp": "ja",
+}
+
+// ParseKitsu reads a JSON:API document of Kitsu library entries with their
+// anime and manga included. Included anime and manga are read as
+// MediaRecords with their categories as genres and their included media
+// relationships. Ratings are read from ratingTwenty.
+func ParseKitsu(r io.Reader) (*Export, error) {
+	var export kitsuExport
+	err := json.NewDecoder(r).Decode(&export)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Kitsu export: %w", err)
+	}
+
+	included := map[kitsuIdentifier]*kitsuResource{}
+	for i := range export.Included {
+		res := &export.Included[i]
+		included[kitsuIdentifier{res.ID, res.Type}] = res
+	}
+
+	var ex Export
+	recorded := map[kitsuIdentifier]bool{}
+	for i := range export.Data {
+		le := &export.Data[i]
+		if le.Type != "libraryEntries" {
+			continue
+		}
+
+		var media *kitsuResource
+		var mediaID kitsuIdentifier
+		for _, kind := range []string{"anime", "manga"} {
+			id, ok := le.Relationships[kind].one()
+			if ok {
+				mediaID, media = id, included[id]
+				break
+			}
+		}
+		if mediaID.ID == "" {
+			continue
+		}
+
+		ex.Entries = append(ex.Entries, le.entry(mediaID, media))
+		if media != nil && !recorded[mediaID] {
+			recorded[mediaID] = true
+			ex.Media = append(ex.Media, media.record(included))
+		}
+	}
+	return &ex, nil
+}
+
+// kitsuSource returns the Source of the Kitsu anime or manga with the given
+// type.
+func kitsuSource(kind string) string {
+	if kind == "manga" {
+		return SourceKitsuManga
+	}
+	return SourceKitsu
+}
+
+// entry returns the Entry of the library entry for the anime or manga with
+// the given identifier, which is nil if not included.
+func (le *kitsuResource) entry(id kitsuIdentifier, media *kitsuResource) Entry {
+	a := le.Attributes
+	rec := entryRecord{
+		source:     kitsuSource(id.Type),
+		id:         id.ID,
+		comments:   strings.TrimSpace(a.Notes),
+		progress:   a.Progress,
+		startDate:  kitsuTime(a.StartedAt),
+		finishDate: kitsuTime(a.FinishedAt),
+		repeats:    a.ReconsumeCount,
+		reading:    id.Type == "manga",
+	}
+	if media != nil {
+		rec.titles = media.titles()
+		if id.Type == "manga" && media.Attributes.ChapterCount != nil {
+			rec.total = *media.Attributes.ChapterCount
+		} else if media.Attributes.EpisodeCount != nil {
+			rec.total = *media.Attributes.EpisodeCount
+		}
+	}
+	if status, ok := kitsuStatuses[a.Status]; ok {
+		rec.status = &status
+	}
+	if a.Reconsuming && a.Status == "current" {
+		// Progress is through the ongoing repeat
+		completed := models.WatchStatusCompleted
+		rec.status = &completed
+		rec.repeating = true
+		rec.repeatProg = a.Progress
+		rec.progress = rec.total
+	}
+	if a.RatingTwenty != nil && *a.RatingTwenty > 0 && *a.RatingTwenty <= 20 {
+		score := *a.RatingTwenty * 5
+		rec.score = &score
+	}
+	return rec.entry()
+}
+
+// titles returns the titles of the anime or manga, the canonical one first.
+func (res *kitsuResource) titles() []string {
+	titles := []string{}
+	if res.Attributes.CanonicalTitle != "" {
+		titles = append(titles, res.Attributes.CanonicalTitle)
+	}
+	for _, key := range []string{"en_jp", "en", "ja_jp"} {
+		t := res.Attributes.Titles[key]
+		if t != "" && t != res.Attributes.CanonicalTitle {
+			titles = append(titles, t)
+		}
+	}
+	return titles
+}
+
+// record returns the MediaRecord of the anime or manga, with the given
+// included resources.
+func (res *kitsuResource) record(
+	included map[kitsuIdentifier]*kitsuResource) MediaRecord {
+	a := res.Attributes
+	r := MediaRecord{
+		Source:     kitsuSource(res.Type),
+		ExternalID: res.ID,
+		StartDate:  kitsuTime(a.StartDate),
+		EndDate:    kitsuTime(a.EndDate),
+		Type:       optionalString(a.Subtype),
+	}
+
+	seen := map[string]bool{}
+	for _, key := range []string{"en_jp", "en", "ja_jp"} {
+		s := a.Titles[key]
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		priority := models.TitlePrioritySecondary
+		if s == a.CanonicalTitle {
+			priority = models.TitlePriorityPrimary
+		}
+		r.Titles = append(r.Titles, models.Title{
+			String:   s,
+			Language: kitsuTitleLanguages[key],
+			Priority: priority,
+		})
+	}
+	if len(r.Titles) == 0 && a.CanonicalTitle != "" {
+		r.Titles = []models.Title{{String: a.CanonicalTitle}}
+	}
+	if a.Synopsis != "" {
+		r.Synopses = []models.Title{{String: a.Synopsis, Language: "en"}}
+	}
+
+	for _, id := range res.Relationships["categories"].many() {
+		if c, ok := included[id]; ok && c.Attributes.Title != "" {
+			r.Genres = append(r.Genres, c.Attributes.Title)
+		}
+	}
+	for _, id := range res.Relationships["mediaRelationships"].many() {
+		mr, ok := included[id]
+		if !ok {
+			continue
+		}
+		dest, ok := mr.Relationships["destination"].one()
+		if !ok || kitsuSource(dest.Type) != r.Source {
+			continue
+		}
+		r.Relations = append(r.Relations, RelationRecord{
+			ExternalID:   dest.ID,
+			Relationship: strings.Replace(mr.Attributes.Role, "_", " ", -1),
+		})
+	}
+	return r
+}
+
+// kitsuTime returns the given Kitsu date or timestamp, or nil if it is not
+// given.
+func kitsuTime(s *string) *time.Time {
+	if s == nil {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		t, err := time.Parse(layout, *s)
+		if err == nil {
+			return &t
+		}
+	}
+	return nil
+}
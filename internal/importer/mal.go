@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+const (
+	// SourceMyAnimeList is the Source of Entries of anime exported from
+	// MyAnimeList.
+	SourceMyAnimeList = "myanimelist"
+	// SourceMyAnimeListManga is the Source of Entries of manga exported from
+	// MyAnimeList, whose IDs are separate from those of anime.
+	SourceMyAnimeListManga = "myanimelist-manga"
+)
+
+// malStatuses maps the statuses of MyAnimeList entries, both written and as
+// numbered in older exports, to WatchStatuses.
+var malStatuses = map[string]models.WatchStatus{
+	"watching":      models.WatchStatusCurrent,
+	"reading":       models.WatchStatusCurrent,
+	"completed":     models.WatchStatusCompleted,
+	"on-hold":       models.WatchStatusHold,
+	"dropped":       models.WatchStatusDropped,
+	"plan to watch": models.WatchStatusPlanning,
+	"plan to read":  models.WatchStatusPlanning,
+	"1":             models.WatchStatusCurrent,
+	"2":             models.WatchStatusCompleted,
+	"3":             models.WatchStatusHold,
+	"4":             models.WatchStatusDropped,
+	"6":             models.WatchStatusPlanning,
+}
+
+// malExport is the root element of a MyAnimeList XML export. Numbers are
+// read as strings because MyAnimeList leaves some empty.
+type malExport struct {
+	XMLName xml.Name   `xml:"myanimelist"`
+	Anime   []malAnime `xml:"anime"`
+	Manga   []malManga `xml:"manga"`
+}
+
+type malAnime struct {
+	ID           string `xml:"series_animedb_id"`
+	Title        string `xml:"series_title"`
+	Episodes     string `xml:"series_episodes"`
+	Watched      string `xml:"my_watched_episodes"`
+	StartDate    string `xml:"my_start_date"`
+	FinishDate   string `xml:"my_finish_date"`
+	Score        string `xml:"my_score"`
+	Status       string `xml:"my_status"`
+	Comments     string `xml:"my_comments"`
+	TimesWatched string `xml:"my_times_watched"`
+	Rewatching   string `xml:"my_rewatching"`
+	RewatchingEp string `xml:"my_rewatching_ep"`
+}
+
+type malManga struct {
+	ID           string `xml:"manga_mangadb_id"`
+	Title        string `xml:"manga_title"`
+	Volumes      string `xml:"manga_volumes"`
+	Chapters     string `xml:"manga_chapters"`
+	ReadVolumes  string `xml:"my_read_volumes"`
+	Read         string `xml:"my_read_chapters"`
+	StartDate    string `xml:"my_start_date"`
+	FinishDate   string `xml:"my_finish_date"`
+	Score        string `xml:"my_score"`
+	Status       string `xml:"my_status"`
+	Comments     string `xml:"my_comments"`
+	TimesRead    string `xml:"my_times_read"`
+	Rereading    string `xml:"my_rereading"`
+	RereadingChp string `xml:"my_rereading_chap"`
+}
+
+func init() {
+	Register("myanimelist", AdapterFunc(parseMyAnimeListExport))
+}
+
+// ParseMyAnimeList reads the Entries of a MyAnimeList XML export of an anime
+// or manga list. Progress is counted in episodes, or in chapters and volumes.
+func ParseMyAnimeList(r io.Reader) ([]Entry, error) {
+	var export malExport
+	err := xml.NewDecoder(r).Decode(&export)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode MyAnimeList export: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(export.Anime)+len(export.Manga))
+	for _, a := range export.Anime {
+		entries = append(entries, entryRecord{
+			source:     SourceMyAnimeList,
+			id:         strings.TrimSpace(a.ID),
+			titles:     []string{strings.TrimSpace(a.Title)},
+			status:     malStatus(a.Status),
+			score:      malScore(a.Score),
+			comments:   strings.TrimSpace(a.Comments),
+			total:      malInt(a.Episodes),
+			progress:   malInt(a.Watched),
+			startDate:  malDate(a.StartDate),
+			finishDate: malDate(a.FinishDate),
+			repeats:    malInt(a.TimesWatched),
+			repeating:  malInt(a.Rewatching) != 0,
+			repeatProg: malInt(a.RewatchingEp),
+		}.entry())
+	}
+	for _, m := range export.Manga {
+		entries = append(entries, entryRecord{
+			source:       SourceMyAnimeListManga,
+			id:           strings.TrimSpace(m.ID),
+			titles:       []string{strings.TrimSpace(m.Title)},
+			status:       malStatus(m.Status),
+			score:        malScore(m.Score),
+			comments:     strings.TrimSpace(m.Comments),
+			total:        malInt(m.Chapters),
+			progress:     malInt(m.Read),
+			startDate:    malDate(m.StartDate),
+			finishDate:   malDate(m.FinishDate),
+			repeats:      malInt(m.TimesRead),
+			repeating:    malInt(m.Rereading) != 0,
+			repeatProg:   malInt(m.RereadingChp),
+			reading:      true,
+			volumes:      malInt(m.ReadVolumes),
+			totalVolumes: malInt(m.Volumes),
+		}.entry())
+	}
+	return entries, nil
+}
+
+// parseMyAnimeListExport reads a MyAnimeList XML export, which contains only
+// list entries.
+func parseMyAnimeListExport(r io.Reader) (*Export, error) {
+	entries, err := ParseMyAnimeList(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Export{Entries: entries}, nil
+}
+
+// malStatus returns the WatchStatus of the given MyAnimeList status, or nil if
+// it is unknown.
+func malStatus(s string) *models.WatchStatus {
+	status, ok := malStatuses[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return nil
+	}
+	return &status
+}
+
+// malScore returns the stored score of the given MyAnimeList score, which is
+// from 1 to 10, or nil if unscored.
+func malScore(s string) *int {
+	score := malInt(s)
+	if score <= 0 || score > 10 {
+		return nil
+	}
+	score *= 10
+	return &score
+}
+
+// malInt returns the integer value of the given MyAnimeList number, or 0 if
+// it is empty or not a number.
+func malInt(s string) int {
+	i, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || i < 0 {
+		return 0
+	}
+	return i
+}
+
+// malDate returns the given MyAnimeList date, or nil if it is not given.
+// Dates with an unknown month or day, which MyAnimeList writes as zero, are
+// not given.
+func malDate(s string) *time.Time {
+	t, err := time.Parse("2006-01-02", strings.TrimSpace(s))
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,207 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+const malExportXML = `<?xml version="1.0" encoding="UTF-8" ?>
+<myanimelist>
+	<myinfo>
+		<user_name>someone</user_name>
+		<user_export_type>1</user_export_type>
+	</myinfo>
+	<anime>
+		<series_animedb_id>5114</series_animedb_id>
+		<series_title><![CDATA[Fullmetal Alchemist: Brotherhood]]></series_title>
+		<series_episodes>64</series_episodes>
+		<my_watched_episodes>64</my_watched_episodes>
+		<my_start_date>2010-01-02</my_start_date>
+		<my_finish_date>2010-05-01</my_finish_date>
+		<my_score>10</my_score>
+		<my_status>Completed</my_status>
+		<my_comments><![CDATA[Great]]></my_comments>
+		<my_times_watched>1</my_times_watched>
+		<my_rewatching>1</my_rewatching>
+		<my_rewatching_ep>12</my_rewatching_ep>
+	</anime>
+	<anime>
+		<series_animedb_id>1</series_animedb_id>
+		<series_title><![CDATA[Cowboy Bebop]]></series_title>
+		<series_episodes>26</series_episodes>
+		<my_watched_episodes>3</my_watched_episodes>
+		<my_start_date>2020-00-00</my_start_date>
+		<my_finish_date>0000-00-00</my_finish_date>
+		<my_score>0</my_score>
+		<my_status>On-Hold</my_status>
+		<my_comments><![CDATA[]]></my_comments>
+		<my_times_watched></my_times_watched>
+	</anime>
+	<manga>
+		<manga_mangadb_id>2</manga_mangadb_id>
+		<manga_title><![CDATA[Berserk]]></manga_title>
+		<manga_chapters>0</manga_chapters>
+		<my_read_volumes>4</my_read_volumes>
+		<my_read_chapters>100</my_read_chapters>
+		<my_start_date>0000-00-00</my_start_date>
+		<my_finish_date>0000-00-00</my_finish_date>
+		<my_score>7</my_score>
+		<my_status>Dropped</my_status>
+	</manga>
+</myanimelist>`
+
+func TestParseMyAnimeList(t *testing.T) {
+	entries, err := ParseMyAnimeList(strings.NewReader(malExportXML))
+	if err != nil {
+		t.Fatalf("failed to parse export: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+
+	fma := entries[0]
+	if fma.Source != SourceMyAnimeList || fma.ExternalID != "5114" ||
+		fma.Title() != "Fullmetal Alchemist: Brotherhood" {
+		t.Errorf("unexpected entry %s %s %q", fma.Source, fma.ExternalID,
+			fma.Title())
+	}
+	if fma.Score == nil || *fma.Score != 100 {
+		t.Errorf("expected score 100, got %v", fma.Score)
+	}
+	if fma.Status == nil || *fma.Status != models.WatchStatusCurrent {
+		t.Errorf("expected rewatch to be current, got %v", fma.Status)
+	}
+	if len(fma.WatchInstances) != 3 {
+		t.Fatalf("expected 3 watch instances, got %d", len(fma.WatchInstances))
+	}
+	first := fma.WatchInstances[0]
+	if first.Episodes != 64 || first.Ongoing || first.StartDate == nil ||
+		first.EndDate == nil || first.EndDate.Month() != 5 {
+		t.Errorf("unexpected first watch %+v", first)
+	}
+	if fma.WatchInstances[1].Episodes != 64 || fma.WatchInstances[1].Ongoing {
+		t.Errorf("unexpected repeat %+v", fma.WatchInstances[1])
+	}
+	rewatch := fma.WatchInstances[2]
+	if rewatch.Episodes != 12 || !rewatch.Ongoing {
+		t.Errorf("unexpected rewatch %+v", rewatch)
+	}
+	if len(fma.Comments) != 1 || fma.Comments[0].String != "Great" {
+		t.Errorf("unexpected comments %v", fma.Comments)
+	}
+
+	bebop := entries[1]
+	if bebop.Score != nil || len(bebop.Comments) != 0 {
+		t.Errorf("expected no score or comments, got %v %v", bebop.Score,
+			bebop.Comments)
+	}
+	if bebop.Status == nil || *bebop.Status != models.WatchStatusHold {
+		t.Errorf("expected on hold, got %v", bebop.Status)
+	}
+	if len(bebop.WatchInstances) != 1 || !bebop.WatchInstances[0].Ongoing ||
+		bebop.WatchInstances[0].Episodes != 3 ||
+		bebop.WatchInstances[0].StartDate != nil {
+		t.Errorf("unexpected watches %+v", bebop.WatchInstances)
+	}
+
+	berserk := entries[2]
+	if berserk.Source != SourceMyAnimeListManga {
+		t.Errorf("expected manga source, got %s", berserk.Source)
+	}
+	if berserk.Status == nil || *berserk.Status != models.WatchStatusDropped ||
+		len(berserk.WatchInstances) != 1 ||
+		berserk.WatchInstances[0].Chapters != 100 ||
+		berserk.WatchInstances[0].Volumes != 4 ||
+		berserk.WatchInstances[0].Episodes != 0 {
+		t.Errorf("unexpected dropped entry %v %+v", berserk.Status,
+			berserk.WatchInstances)
+	}
+}
+
+func TestParseMyAnimeListInvalid(t *testing.T) {
+	_, err := ParseMyAnimeList(strings.NewReader("<anilist></anilist>"))
+	if err == nil {
+		t.Error("expected error for non-MyAnimeList document")
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b string
+		min  float64
+	}{
+		{"Fullmetal Alchemist: Brotherhood", "fullmetal alchemist brotherhood", 1},
+		{"Steins;Gate", "Steins Gate", 1},
+		{"Shingeki no Kyojin", "Shingeki no Kyoujin", 0.9},
+	}
+	for _, c := range cases {
+		a := []rune(normalizeTitle(c.a))
+		b := []rune(normalizeTitle(c.b))
+		if s := similarity(a, b, 0); s < c.min {
+			t.Errorf("similarity of %q and %q is %v, expected at least %v",
+				c.a, c.b, s, c.min)
+		}
+	}
+
+	a := []rune(normalizeTitle("Naruto"))
+	b := []rune(normalizeTitle("Naruto: Shippuuden"))
+	if s := similarity(a, b, DefaultMinSimilarity); s >= DefaultMinSimilarity {
+		t.Errorf("expected %q and %q to be dissimilar, got %v", "Naruto",
+			"Naruto: Shippuuden", s)
+	}
+}
+
+func TestParseMyAnimeListRepeats(t *testing.T) {
+	const export = `<myanimelist><anime>
+		<series_animedb_id>1</series_animedb_id>
+		<series_title>Cowboy Bebop</series_title>
+		<series_episodes>26</series_episodes>
+		<my_watched_episodes>26</my_watched_episodes>
+		<my_status>Completed</my_status>
+		<my_times_watched>999999999999</my_times_watched>
+	</anime></myanimelist>`
+	entries, err := ParseMyAnimeList(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("failed to parse export: %v", err)
+	}
+	if n := len(entries[0].WatchInstances); n != maxRepeats+1 {
+		t.Errorf("expected %d watch instances, got %d", maxRepeats+1, n)
+	}
+}
+
+func TestMatchKind(t *testing.T) {
+	manga := models.MediaTypeManga
+	m := matcher{
+		minSimilarity: DefaultMinSimilarity,
+		external:      map[models.ExternalID]int{},
+		exact:         map[string][]int{},
+		kinds:         map[int]models.MediaKind{},
+	}
+	m.add(&models.Media{Meta: db.ModelMetadata{ID: 1},
+		Titles: []models.Title{{String: "Berserk"}}})
+	m.add(&models.Media{Meta: db.ModelMetadata{ID: 2}, Type: &manga,
+		Titles: []models.Title{{String: "Berserk"}}})
+
+	cases := []struct {
+		title    string
+		kind     models.MediaKind
+		expected int
+	}{
+		{"Berserk", models.MediaKindAnime, 1},
+		{"Berserk", models.MediaKindManga, 2},
+		{"Berserk!", models.MediaKindManga, 2},
+		{"Berserkk", models.MediaKindAnime, 1},
+	}
+	for _, c := range cases {
+		e := Entry{Source: SourceMyAnimeListManga, ExternalID: "2",
+			Titles: []string{c.title}, Kind: c.kind}
+		mID, _, reason := m.match(&e)
+		if mID != c.expected {
+			t.Errorf("expected %q of kind %d to match Media %d, got %d (%s)",
+				c.title, c.kind, c.expected, mID, reason)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// matcher matches Entries and MediaRecords to Media by external ID or title.
+type matcher struct {
+	minSimilarity float64
+	// external maps external IDs to the IDs of the Media with them.
+	external map[models.ExternalID]int
+	// exact maps normalized titles to the IDs of the Media with them.
+	exact map[string][]int
+	// titles are the normalized titles of each Media.
+	titles []mediaTitle
+	// kinds maps the IDs of Media to their MediaKinds.
+	kinds map[int]models.MediaKind
+}
+
+// mediaTitle is a normalized title of some Media.
+type mediaTitle struct {
+	mediaID int
+	kind    models.MediaKind
+	title   []rune
+}
+
+// newMatcher returns a matcher of all persisted Media.
+func (im *Importer) newMatcher(tx db.Tx) (*matcher, error) {
+	list, err := im.MediaService.GetAll(nil, nil, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Media: %w", err)
+	}
+
+	m := matcher{
+		minSimilarity: im.MinSimilarity,
+		external:      map[models.ExternalID]int{},
+		exact:         map[string][]int{},
+		kinds:         map[int]models.MediaKind{},
+	}
+	if m.minSimilarity <= 0 {
+		m.minSimilarity = DefaultMinSimilarity
+	}
+	for _, md := range list {
+		m.add(md)
+	}
+	return &m, nil
+}
+
+// add makes the given Media, or the external IDs newly added to it, available
+// for matching.
+func (m *matcher) add(md *models.Media) {
+	for _, id := range md.ExternalIDs {
+		id = externalKey(id)
+		if _, ok := m.external[id]; !ok {
+			m.external[id] = md.Meta.ID
+		}
+	}
+
+	kind := md.Kind()
+	m.kinds[md.Meta.ID] = kind
+
+	seen := map[string]bool{}
+	for _, t := range md.Titles {
+		n := normalizeTitle(t.String)
+		if n == "" || seen[n] || containsInt(m.exact[n], md.Meta.ID) {
+			continue
+		}
+		seen[n] = true
+		m.exact[n] = append(m.exact[n], md.Meta.ID)
+		m.titles = append(m.titles, mediaTitle{md.Meta.ID, kind, []rune(n)})
+	}
+}
+
+// link makes the Media with the given ID matched by the given external IDs,
+// without recording them.
+func (m *matcher) link(ids []models.ExternalID, mID int) {
+	if mID == 0 {
+		return
+	}
+	for _, id := range ids {
+		id = externalKey(id)
+		if _, ok := m.external[id]; !ok {
+			m.external[id] = mID
+		}
+	}
+}
+
+// byExternalID returns the ID of the Media with any of the given external
+// IDs, or 0 if there is none.
+func (m *matcher) byExternalID(ids ...models.ExternalID) int {
+	for _, id := range ids {
+		if id.Source == "" || id.ID == "" {
+			continue
+		}
+		if mID, ok := m.external[externalKey(id)]; ok {
+			return mID
+		}
+	}
+	return 0
+}
+
+// unclaimed returns those of the given external IDs that do not identify
+// Media other than the one with the given ID.
+func (m *matcher) unclaimed(ids []models.ExternalID, mID int) []models.ExternalID {
+	var free []models.ExternalID
+	for _, id := range ids {
+		owner, ok := m.external[externalKey(id)]
+		if !ok || owner == mID {
+			free = append(free, id)
+		}
+	}
+	return free
+}
+
+// externalKey returns the given external ID without its URL, so that it may
+// be used as a key.
+func externalKey(id models.ExternalID) models.ExternalID {
+	return models.ExternalID{Source: id.Source, ID: id.ID}
+}
+
+// match returns the ID of the Media the given Entry refers to and the
+// similarity of their titles, or 0 and the reason if no single Media is
+// matched.
+func (m *matcher) match(e *Entry) (int, float64, string) {
+	mID := m.byExternalID(models.ExternalID{Source: e.Source, ID: e.ExternalID})
+	if mID != 0 {
+		return mID, 1, ""
+	}
+	return m.byTitle(e.Titles, e.Kind)
+}
+
+// byTitle returns the ID of the single Media of the given kind with any of the
+// given titles or, failing that, the most similar one, and the similarity of
+// their titles. 0 and the reason are returned if no single Media is matched.
+func (m *matcher) byTitle(titles []string, kind models.MediaKind) (int,
+	float64, string) {
+	// Titles matched exactly are preferred to similar ones
+	for _, t := range titles {
+		var ids []int
+		for _, id := range m.exact[normalizeTitle(t)] {
+			if m.kinds[id] == kind {
+				ids = append(ids, id)
+			}
+		}
+		switch len(ids) {
+		case 0:
+			continue
+		case 1:
+			return ids[0], 1, ""
+		}
+		return 0, 0, fmt.Sprintf("title %q matches %d Media", t, len(ids))
+	}
+
+	best, bestID, ambiguous := 0.0, 0, false
+	for _, t := range titles {
+		n := []rune(normalizeTitle(t))
+		if len(n) == 0 {
+			continue
+		}
+		for _, mt := range m.titles {
+			if mt.kind != kind {
+				continue
+			}
+			s := similarity(n, mt.title, m.minSimilarity)
+			if s < m.minSimilarity || s < best {
+				continue
+			}
+			if s == best && mt.mediaID != bestID {
+				ambiguous = true
+				continue
+			}
+			if s > best {
+				ambiguous = false
+			}
+			best, bestID = s, mt.mediaID
+		}
+	}
+	if bestID == 0 {
+		return 0, 0, "no Media with a similar title"
+	}
+	if ambiguous {
+		return 0, best, "titles of several Media are equally similar"
+	}
+	return bestID, best, ""
+}
+
+func containsInt(list []int, i int) bool {
+	for _, v := range list {
+		if v == i {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTitle returns the given title in lower case with punctuation
+// removed and spaces collapsed.
+func normalizeTitle(t string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(t) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteRune(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r):
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// similarity returns 1 less the edit distance between the given strings
+// relative to the length of the longer one. Strings with lengths too
+// different to be at least min similar are given 0 without being compared.
+func similarity(a []rune, b []rune, min float64) float64 {
+	longer := len(a)
+	if len(b) > longer {
+		longer = len(b)
+	}
+	if longer == 0 {
+		return 1
+	}
+	diff := len(a) - len(b)
+	if diff < 0 {
+		diff = -diff
+	}
+	if 1-float64(diff)/float64(longer) < min {
+		return 0
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longer)
+}
+
+// levenshtein returns the number of single character insertions, deletions
+// and substitutions needed to change one of the given strings to the other.
+func levenshtein(a []rune, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
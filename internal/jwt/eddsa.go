@@ -0,0 +1,61 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"errors"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// SigningMethodEdDSA signs and verifies tokens with Ed25519 keys.
+var SigningMethodEdDSA = &signingMethodEdDSA{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+// errKeyType is an error returned when a key is of the wrong type for the
+// signing method.
+var errKeyType = errors.New("key is of invalid type")
+
+type signingMethodEdDSA struct{}
+
+// Alg returns the name of the signing method.
+func (m *signingMethodEdDSA) Alg() string {
+	return AlgorithmEdDSA
+}
+
+// Verify checks that the signature of the given string is valid for the
+// given ed25519.PublicKey.
+func (m *signingMethodEdDSA) Verify(signingString, signature string,
+	key interface{}) error {
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return errKeyType
+	}
+
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(pub, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
+
+// Sign returns the signature of the given string with the given
+// ed25519.PrivateKey.
+func (m *signingMethodEdDSA) Sign(signingString string,
+	key interface{}) (string, error) {
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", errKeyType
+	}
+
+	sig := ed25519.Sign(priv, []byte(signingString))
+	return jwt.EncodeSegment(sig), nil
+}
@@ -1,6 +1,10 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -9,78 +13,158 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// headerKeyID is the name of the token header that identifies the key the
+// token was signed with.
+const headerKeyID = "kid"
+
+// keyEnvKey is the name of the variable of the legacy secret key in .env
+// files.
 const keyEnvKey = "JWT_KEY"
 
+// errRevoked is an error returned when a token has been revoked.
+var errRevoked = errors.New("token has been revoked")
+
+// RevocationList reports whether tokens have been revoked before their
+// expiration.
+type RevocationList interface {
+	IsRevoked(claims *Claims) (bool, error)
+}
+
 // Authenticator authenticates JSON web tokens.
 type Authenticator struct {
-	key string
+	// Keys holds the keys tokens are signed and verified with.
+	Keys *KeyRing
+	// Revocations is checked when verifying tokens, if not nil.
+	Revocations RevocationList
 }
 
-// Claims is a custom JWT claims type with username and expiration information.
+// NewAuthenticator returns an Authenticator that signs and verifies tokens
+// with the keys in the given KeyRing and rejects tokens in the given
+// revocation list.
+func NewAuthenticator(keys *KeyRing, revocations RevocationList) *Authenticator {
+	return &Authenticator{
+		Keys:        keys,
+		Revocations: revocations,
+	}
+}
+
+// Claims is a custom JWT claims type with username, session, and expiration
+// information.
 type Claims struct {
-	Username string
+	Username  string
+	SessionID int
 	jwt.StandardClaims
 }
 
-// Verify checks the given HTTP request for a valid JWT.
-func (au *Authenticator) Verify(tokenstr string) error {
+// Verify checks the given token string for validity and returns its claims.
+func (au *Authenticator) Verify(tokenstr string) (*Claims, error) {
 	claims := Claims{}
 	tkn, err := jwt.ParseWithClaims(tokenstr, &claims,
-		func(_ *jwt.Token) (interface{}, error) {
-			return au.key, nil
+		func(tkn *jwt.Token) (interface{}, error) {
+			// Tokens signed before KeyRings were introduced have no key ID
+			kid, _ := tkn.Header[headerKeyID].(string)
+			if kid == "" {
+				kid = LegacyKeyID
+			}
+			k, err := au.Keys.Get(kid)
+			if err != nil {
+				return nil, err
+			}
+
+			if tkn.Method.Alg() != k.Algorithm {
+				return nil, fmt.Errorf("unexpected signing method %q", tkn.Header["alg"])
+			}
+			return k.verifyKey, nil
 		})
 	if err != nil {
-		return fmt.Errorf("failed to parse token string: %w", err)
+		return nil, fmt.Errorf("failed to parse token string: %w", err)
 	}
 
 	if !tkn.Valid {
-		return jwt.ErrSignatureInvalid
+		return nil, jwt.ErrSignatureInvalid
 	}
 
-	return nil
+	if au.Revocations != nil {
+		revoked, err := au.Revocations.IsRevoked(&claims)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check revocation: %w", err)
+		}
+		if revoked {
+			return nil, errRevoked
+		}
+	}
+
+	return &claims, nil
 }
 
-// NewToken returns a new JWT token.
-func (au *Authenticator) NewToken(username string, minDuration time.Duration) (string, error) {
-	expiration := time.Now().Add(minDuration * time.Minute)
+// NewToken returns a new JWT token for the given session that expires after
+// the given duration, signed with the current key.
+func (au *Authenticator) NewToken(username string, sessionID int,
+	duration time.Duration) (string, error) {
+	k, err := au.Keys.Current()
+	if err != nil {
+		return "", err
+	}
+
+	id, err := randomHex(16)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+
+	now := time.Now()
 	claims := Claims{
-		Username: username,
+		Username:  username,
+		SessionID: sessionID,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expiration.Unix(),
+			Id:        id,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(duration).Unix(),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims)
-	tknstr, err := token.SignedString(au.key)
+	token := jwt.NewWithClaims(k.SigningMethod(), &claims)
+	token.Header[headerKeyID] = k.ID
+	tknstr, err := token.SignedString(k.signKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to create signed string: %w", err)
 	}
 	return tknstr, nil
 }
 
-// ReadKeyFromEnv reads the JWT secret key from a .env file at the given path
-// and returns it.
-func ReadKeyFromEnv(filepath string) (string, error) {
-	err := godotenv.Load(filepath)
+// NewOpaqueToken returns a new random token, such as a refresh or API token,
+// with the given prefix.
+func NewOpaqueToken(prefix string) (string, error) {
+	tkn, err := randomHex(32)
 	if err != nil {
-		return "", fmt.Errorf("failed to load env file %q: %w", filepath, err)
+		return "", fmt.Errorf("failed to generate token: %w", err)
 	}
+	return prefix + tkn, nil
+}
 
-	key := os.Getenv(keyEnvKey)
-	return key, nil
+// HashOpaqueToken returns the hash of the given opaque token to be persisted
+// in place of the token itself.
+func HashOpaqueToken(tkn string) string {
+	sum := sha256.Sum256([]byte(tkn))
+	return hex.EncodeToString(sum[:])
 }
 
-// WriteKeyToEnv writes the given JWT secret key to a .env file at the given
-// path.
-func WriteKeyToEnv(key string, filepath string) error {
-	env, err := godotenv.Unmarshal(fmt.Sprintf("%s=%s", keyEnvKey, key))
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal env: %w", err)
+		return "", err
 	}
+	return hex.EncodeToString(b), nil
+}
 
-	err = godotenv.Write(env, filepath)
+// ReadKeyFromEnv reads the secret key that signed tokens before KeyRings were
+// introduced from a .env file at the given path and returns it.
+func ReadKeyFromEnv(filepath string) (string, error) {
+	err := godotenv.Load(filepath)
 	if err != nil {
-		return fmt.Errorf("failed to write env to file: %w", err)
+		return "", fmt.Errorf("failed to load env file %q: %w", filepath, err)
 	}
-	return nil
+
+	key := os.Getenv(keyEnvKey)
+	return key, nil
 }
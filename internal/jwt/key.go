@@ -0,0 +1,247 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	// AlgorithmHS256 is the name of the HMAC SHA-256 signing algorithm.
+	AlgorithmHS256 = "HS256"
+	// AlgorithmRS256 is the name of the RSA SHA-256 signing algorithm.
+	AlgorithmRS256 = "RS256"
+	// AlgorithmEdDSA is the name of the Ed25519 signing algorithm.
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const (
+	rsaKeyBits  = 2048
+	hmacKeySize = 32
+
+	pemTypePKCS8  = "PRIVATE KEY"
+	pemTypePKCS1  = "RSA PRIVATE KEY"
+	pemTypeSecret = "HMAC SECRET KEY"
+
+	pemHeaderKeyID     = "Key-Id"
+	pemHeaderAlgorithm = "Algorithm"
+	pemHeaderCreated   = "Created"
+	pemHeaderRetired   = "Retired"
+)
+
+// errAlgorithm is an error returned when an algorithm is not supported.
+var errAlgorithm = errors.New("unsupported algorithm")
+
+// Key is a key used to sign and verify tokens.
+type Key struct {
+	// ID is the identifier given in the kid header of tokens signed with
+	// the Key.
+	ID        string
+	Algorithm string
+	CreatedAt time.Time
+	// RetiredAt is the time the Key was replaced as the signing key, or nil
+	// if it has not been.
+	RetiredAt *time.Time
+
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// GenerateKey returns a new random Key for the given algorithm.
+func GenerateKey(alg string) (*Key, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key id: %w", err)
+	}
+
+	k := Key{
+		ID:        id,
+		Algorithm: alg,
+		CreatedAt: time.Now(),
+	}
+
+	switch alg {
+	case AlgorithmHS256:
+		secret := make([]byte, hmacKeySize)
+		_, err = rand.Read(secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate secret: %w", err)
+		}
+		k.signKey, k.verifyKey = secret, secret
+	case AlgorithmRS256:
+		priv, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+		}
+		k.signKey, k.verifyKey = priv, &priv.PublicKey
+	case AlgorithmEdDSA:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate Ed25519 key: %w", err)
+		}
+		k.signKey, k.verifyKey = priv, pub
+	default:
+		return nil, fmt.Errorf("%q: %w", alg, errAlgorithm)
+	}
+
+	return &k, nil
+}
+
+// NewSecretKey returns an HS256 Key with the given ID and secret.
+func NewSecretKey(id string, secret []byte) *Key {
+	return &Key{
+		ID:        id,
+		Algorithm: AlgorithmHS256,
+		CreatedAt: time.Now(),
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// ParseKeyPEM parses a Key from the given PEM-encoded private key. RSA keys
+// may be in PKCS #1 or PKCS #8 form, and Ed25519 keys in PKCS #8 form. The
+// given ID is used if the PEM block does not specify one, and CreatedAt is
+// zero if the PEM block does not specify the creation time.
+func ParseKeyPEM(id string, buf []byte) (*Key, error) {
+	block, _ := pem.Decode(buf)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	k := Key{
+		ID:        id,
+		Algorithm: block.Headers[pemHeaderAlgorithm],
+	}
+	if v, ok := block.Headers[pemHeaderKeyID]; ok {
+		k.ID = v
+	}
+	if v, ok := block.Headers[pemHeaderCreated]; ok {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse creation time: %w", err)
+		}
+		k.CreatedAt = t
+	}
+	if v, ok := block.Headers[pemHeaderRetired]; ok {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse retirement time: %w", err)
+		}
+		k.RetiredAt = &t
+	}
+
+	var priv interface{}
+	var err error
+	switch block.Type {
+	case pemTypeSecret:
+		k.Algorithm = AlgorithmHS256
+		k.signKey, k.verifyKey = block.Bytes, block.Bytes
+		return &k, nil
+	case pemTypePKCS1:
+		priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case pemTypePKCS8:
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	switch priv := priv.(type) {
+	case *rsa.PrivateKey:
+		k.Algorithm = AlgorithmRS256
+		k.signKey, k.verifyKey = priv, &priv.PublicKey
+	case ed25519.PrivateKey:
+		k.Algorithm = AlgorithmEdDSA
+		k.signKey, k.verifyKey = priv, priv.Public()
+	default:
+		return nil, fmt.Errorf("private key of type %T: %w", priv, errAlgorithm)
+	}
+
+	return &k, nil
+}
+
+// MarshalPEM encodes the Key and its properties as a PEM block.
+func (k *Key) MarshalPEM() ([]byte, error) {
+	block := pem.Block{
+		Headers: map[string]string{
+			pemHeaderKeyID:     k.ID,
+			pemHeaderAlgorithm: k.Algorithm,
+			pemHeaderCreated:   k.CreatedAt.Format(time.RFC3339),
+		},
+	}
+	if k.RetiredAt != nil {
+		block.Headers[pemHeaderRetired] = k.RetiredAt.Format(time.RFC3339)
+	}
+
+	switch priv := k.signKey.(type) {
+	case []byte:
+		block.Type = pemTypeSecret
+		block.Bytes = priv
+	default:
+		der, err := x509.MarshalPKCS8PrivateKey(priv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal private key: %w", err)
+		}
+		block.Type = pemTypePKCS8
+		block.Bytes = der
+	}
+
+	return pem.EncodeToMemory(&block), nil
+}
+
+// SigningMethod returns the signing method of the algorithm of the Key.
+func (k *Key) SigningMethod() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// JWK is the JSON web key representation of the public part of a Key.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+
+	// Curve and X are the parameters of Ed25519 keys.
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+
+	// N and E are the parameters of RSA keys.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+}
+
+// JWK returns the public JSON web key of the Key. Symmetric keys have no
+// public part, so false is returned for them.
+func (k *Key) JWK() (*JWK, bool) {
+	jwk := JWK{
+		KeyID:     k.ID,
+		Algorithm: k.Algorithm,
+		Use:       "sig",
+	}
+
+	enc := base64.RawURLEncoding
+	switch pub := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = enc.EncodeToString(pub.N.Bytes())
+		jwk.E = enc.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = enc.EncodeToString(pub)
+	default:
+		return nil, false
+	}
+	return &jwk, true
+}
@@ -0,0 +1,354 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const keyFileExt = ".pem"
+
+// LegacyKeyID is the ID of the Key imported from the secret that signed tokens
+// before KeyRings were introduced. Tokens without a key ID are verified with
+// it.
+const LegacyKeyID = "legacy"
+
+var (
+	// errNoSigningKey is an error returned when a KeyRing has no key that may
+	// be used to sign tokens.
+	errNoSigningKey = errors.New("no signing key")
+	// errUnknownKey is an error returned when a key is not in a KeyRing or has
+	// been retired for longer than the grace period.
+	errUnknownKey = errors.New("unknown or expired key")
+)
+
+// KeyRing holds the Keys used to sign and verify tokens. The most recently
+// added Key signs new tokens; Keys replaced by it are retired but continue to
+// verify tokens for a grace period.
+type KeyRing struct {
+	// Dir is the directory Keys are persisted to as PEM files. Keys are only
+	// kept in memory if empty.
+	Dir string
+	// Algorithm is the algorithm of Keys generated by Rotate.
+	Algorithm string
+	// GracePeriod is the duration retired Keys continue to verify tokens for.
+	GracePeriod time.Duration
+
+	// keys is ordered by creation time.
+	keys []*Key
+	mu   sync.RWMutex
+}
+
+// NewKeyRing returns an empty in-memory KeyRing.
+func NewKeyRing(alg string, gracePeriod time.Duration) *KeyRing {
+	return &KeyRing{
+		Algorithm:   alg,
+		GracePeriod: gracePeriod,
+	}
+}
+
+// LoadKeyRing returns a KeyRing with the Keys persisted in the given
+// directory. If there is no Key that may sign tokens, a new one is generated
+// and persisted.
+func LoadKeyRing(dir string, alg string, gracePeriod time.Duration) (*KeyRing, error) {
+	kr := &KeyRing{
+		Dir:         dir,
+		Algorithm:   alg,
+		GracePeriod: gracePeriod,
+	}
+
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key directory %q: %w", dir, err)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key directory %q: %w", dir, err)
+	}
+
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != keyFileExt {
+			continue
+		}
+
+		path := filepath.Join(dir, f.Name())
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file %q: %w", path, err)
+		}
+
+		id := strings.TrimSuffix(f.Name(), keyFileExt)
+		k, err := ParseKeyPEM(id, buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key file %q: %w", path, err)
+		}
+
+		// Keys written without their creation time are taken to have been
+		// created when their file was last modified; the time is written
+		// back so that it stays fixed
+		if k.CreatedAt.IsZero() {
+			k.CreatedAt = f.ModTime()
+			buf, err = k.MarshalPEM()
+			if err != nil {
+				return nil, err
+			}
+			err = ioutil.WriteFile(path, buf, 0600)
+			if err != nil {
+				return nil, fmt.Errorf("failed to write key file %q: %w", path, err)
+			}
+		}
+		kr.keys = append(kr.keys, k)
+	}
+	sort.Slice(kr.keys, func(i, j int) bool {
+		return kr.keys[i].CreatedAt.Before(kr.keys[j].CreatedAt)
+	})
+
+	err = kr.Prune()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := kr.Current(); err != nil {
+		_, err = kr.Rotate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return kr, nil
+}
+
+// ImportLegacySecret persists the given secret, which signed tokens before
+// KeyRings were introduced, as a retired HS256 Key in the given directory if
+// there are no Keys in it yet. Tokens signed with the secret are then
+// verified by the KeyRing loaded from the directory for the grace period.
+// Returns true if the secret was imported.
+func ImportLegacySecret(dir string, secret []byte) (bool, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return false, fmt.Errorf("failed to create key directory %q: %w", dir, err)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return false, fmt.Errorf("failed to read key directory %q: %w", dir, err)
+	}
+	for _, f := range files {
+		if !f.IsDir() && filepath.Ext(f.Name()) == keyFileExt {
+			return false, nil
+		}
+	}
+
+	k := NewSecretKey(LegacyKeyID, secret)
+	k.RetiredAt = &k.CreatedAt
+	kr := KeyRing{Dir: dir}
+	err = kr.persist(k)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Add adds the given Key to the KeyRing as the signing key and retires the
+// previous signing key.
+func (kr *KeyRing) Add(k *Key) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	now := time.Now()
+	for _, o := range kr.keys {
+		if o.RetiredAt != nil {
+			continue
+		}
+
+		o.RetiredAt = &now
+		err := kr.persist(o)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := kr.persist(k)
+	if err != nil {
+		return err
+	}
+	kr.keys = append(kr.keys, k)
+	return nil
+}
+
+// Rotate generates a new Key and adds it as the signing key.
+func (kr *KeyRing) Rotate() (*Key, error) {
+	k, err := GenerateKey(kr.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	err = kr.Add(k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add key: %w", err)
+	}
+	return k, nil
+}
+
+// Prune removes Keys that have been retired for longer than the grace period.
+func (kr *KeyRing) Prune() error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	keep := kr.keys[:0]
+	for _, k := range kr.keys {
+		if kr.accepts(k) {
+			keep = append(keep, k)
+			continue
+		}
+
+		if kr.Dir != "" {
+			err := os.Remove(kr.keyPath(k))
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove key file: %w", err)
+			}
+		}
+	}
+	kr.keys = keep
+	return nil
+}
+
+// Current returns the Key used to sign new tokens.
+func (kr *KeyRing) Current() (*Key, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	for i := len(kr.keys) - 1; i >= 0; i-- {
+		if kr.keys[i].RetiredAt == nil {
+			return kr.keys[i], nil
+		}
+	}
+	return nil, errNoSigningKey
+}
+
+// Get returns the Key with the given ID if it may verify tokens.
+func (kr *KeyRing) Get(id string) (*Key, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	for _, k := range kr.keys {
+		if k.ID == id && kr.accepts(k) {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("key %q: %w", id, errUnknownKey)
+}
+
+// Keys returns all Keys that may verify tokens.
+func (kr *KeyRing) Keys() []*Key {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	var list []*Key
+	for _, k := range kr.keys {
+		if kr.accepts(k) {
+			list = append(list, k)
+		}
+	}
+	return list
+}
+
+// JWKSet is a JSON web key set.
+type JWKSet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the KeyRing that may verify tokens as a
+// JSON web key set. Symmetric keys are omitted.
+func (kr *KeyRing) JWKS() *JWKSet {
+	set := JWKSet{Keys: []*JWK{}}
+	for _, k := range kr.Keys() {
+		if jwk, ok := k.JWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return &set
+}
+
+// RotateEvery starts rotating the signing key once it is older than the
+// given interval and pruning expired Keys in the background. Errors are
+// passed to the given callback. The returned function stops rotation.
+func (kr *KeyRing) RotateEvery(interval time.Duration, onError func(error)) (stop func()) {
+	// Check more often than the interval so that rotation is not delayed by
+	// much when the signing key was created before the KeyRing was loaded.
+	period := interval
+	if period > time.Hour {
+		period = time.Hour
+	}
+
+	done := make(chan struct{})
+	ticker := time.NewTicker(period)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := kr.rotateIfOlder(interval)
+				if err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+func (kr *KeyRing) rotateIfOlder(age time.Duration) error {
+	k, err := kr.Current()
+	if err != nil || time.Since(k.CreatedAt) >= age {
+		_, err = kr.Rotate()
+		if err != nil {
+			return fmt.Errorf("failed to rotate key: %w", err)
+		}
+	}
+
+	err = kr.Prune()
+	if err != nil {
+		return fmt.Errorf("failed to prune keys: %w", err)
+	}
+	return nil
+}
+
+// accepts returns true if the given Key may verify tokens.
+func (kr *KeyRing) accepts(k *Key) bool {
+	return k.RetiredAt == nil || time.Since(*k.RetiredAt) < kr.GracePeriod
+}
+
+func (kr *KeyRing) persist(k *Key) error {
+	if kr.Dir == "" {
+		return nil
+	}
+
+	buf, err := k.MarshalPEM()
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(kr.keyPath(k), buf, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	return nil
+}
+
+func (kr *KeyRing) keyPath(k *Key) string {
+	return filepath.Join(kr.Dir, k.ID+keyFileExt)
+}
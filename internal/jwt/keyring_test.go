@@ -0,0 +1,218 @@
+package jwt
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// TestAuthenticatorAlgorithms tests signing and verifying tokens with keys of
+// each supported algorithm.
+func TestAuthenticatorAlgorithms(t *testing.T) {
+	cases := []string{AlgorithmHS256, AlgorithmRS256, AlgorithmEdDSA}
+
+	for _, alg := range cases {
+		t.Run(alg, func(t *testing.T) {
+			kr := NewKeyRing(alg, time.Minute)
+			_, err := kr.Rotate()
+			if err != nil {
+				t.Fatalf("failed to rotate: %v", err)
+			}
+			au := NewAuthenticator(kr, nil)
+
+			tknstr, err := au.NewToken("user", 1, time.Minute)
+			if err != nil {
+				t.Fatalf("failed to create token: %v", err)
+			}
+
+			claims, err := au.Verify(tknstr)
+			if err != nil {
+				t.Fatalf("failed to verify token: %v", err)
+			}
+			if claims.Username != "user" || claims.SessionID != 1 {
+				t.Fatalf("expected claims for user:1, but got %s:%d",
+					claims.Username, claims.SessionID)
+			}
+
+			expected := 1
+			if alg == AlgorithmHS256 {
+				expected = 0
+			}
+			if n := len(kr.JWKS().Keys); n != expected {
+				t.Fatalf("expected %d keys in JWKS, but got %d", expected, n)
+			}
+		})
+	}
+}
+
+// TestKeyRingRotation tests that tokens signed by retired keys are accepted
+// only within the grace period.
+func TestKeyRingRotation(t *testing.T) {
+	kr := NewKeyRing(AlgorithmEdDSA, time.Hour)
+	_, err := kr.Rotate()
+	if err != nil {
+		t.Fatalf("failed to rotate: %v", err)
+	}
+	au := NewAuthenticator(kr, nil)
+
+	old, err := au.NewToken("user", 1, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+
+	_, err = kr.Rotate()
+	if err != nil {
+		t.Fatalf("failed to rotate: %v", err)
+	}
+	_, err = au.Verify(old)
+	if err != nil {
+		t.Fatalf("expected retired key to verify within grace period: %v", err)
+	}
+	if n := len(kr.JWKS().Keys); n != 2 {
+		t.Fatalf("expected 2 keys in JWKS, but got %d", n)
+	}
+
+	kr.GracePeriod = 0
+	err = kr.Prune()
+	if err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	_, err = au.Verify(old)
+	if err == nil {
+		t.Fatalf("expected retired key to be rejected after grace period")
+	}
+}
+
+// TestLoadKeyRing tests that keys are generated on first load and persisted
+// across loads.
+func TestLoadKeyRing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nao-keys")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, alg := range []string{AlgorithmHS256, AlgorithmRS256, AlgorithmEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			kr, err := LoadKeyRing(dir, alg, time.Hour)
+			if err != nil {
+				t.Fatalf("failed to load key ring: %v", err)
+			}
+			_, err = kr.Rotate()
+			if err != nil {
+				t.Fatalf("failed to rotate: %v", err)
+			}
+			tknstr, err := NewAuthenticator(kr, nil).NewToken("user", 1, time.Minute)
+			if err != nil {
+				t.Fatalf("failed to create token: %v", err)
+			}
+
+			reloaded, err := LoadKeyRing(dir, alg, time.Hour)
+			if err != nil {
+				t.Fatalf("failed to reload key ring: %v", err)
+			}
+			_, err = NewAuthenticator(reloaded, nil).Verify(tknstr)
+			if err != nil {
+				t.Fatalf("failed to verify token with reloaded keys: %v", err)
+			}
+		})
+	}
+}
+
+// TestLoadKeyRingCreatedAt tests that keys persisted without their creation
+// time are given the modification time of their file, which is written back.
+func TestLoadKeyRingCreatedAt(t *testing.T) {
+	dir := t.TempDir()
+
+	k, err := GenerateKey(AlgorithmEdDSA)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	buf, err := k.MarshalPEM()
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	block, _ := pem.Decode(buf)
+	delete(block.Headers, pemHeaderCreated)
+	path := filepath.Join(dir, k.ID+keyFileExt)
+	err = ioutil.WriteFile(path, pem.EncodeToMemory(block), 0600)
+	if err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	err = os.Chtimes(path, modTime, modTime)
+	if err != nil {
+		t.Fatalf("failed to set modification time: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		kr, err := LoadKeyRing(dir, AlgorithmEdDSA, time.Hour)
+		if err != nil {
+			t.Fatalf("failed to load key ring: %v", err)
+		}
+		loaded, err := kr.Get(k.ID)
+		if err != nil {
+			t.Fatalf("failed to get key: %v", err)
+		}
+		if !loaded.CreatedAt.Equal(modTime) {
+			t.Fatalf("load %d: expected creation time %v, but got %v", i,
+				modTime, loaded.CreatedAt)
+		}
+	}
+}
+
+// TestImportLegacySecret tests that tokens signed with the secret used
+// before key rings were introduced are verified once it is imported, and
+// that it is only imported into empty key directories.
+func TestImportLegacySecret(t *testing.T) {
+	dir := t.TempDir()
+	secret := []byte("legacy secret")
+
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		Username:  "user",
+		SessionID: 1,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		},
+	}).SignedString(secret)
+	if err != nil {
+		t.Fatalf("failed to sign legacy token: %v", err)
+	}
+
+	imported, err := ImportLegacySecret(dir, secret)
+	if err != nil {
+		t.Fatalf("failed to import legacy secret: %v", err)
+	}
+	if !imported {
+		t.Fatalf("expected legacy secret to be imported into empty directory")
+	}
+
+	kr, err := LoadKeyRing(dir, AlgorithmEdDSA, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to load key ring: %v", err)
+	}
+	if k, err := kr.Current(); err != nil || k.ID == LegacyKeyID {
+		t.Fatalf("expected a new signing key, but got %v, %v", k, err)
+	}
+	claims, err := NewAuthenticator(kr, nil).Verify(legacy)
+	if err != nil {
+		t.Fatalf("failed to verify legacy token: %v", err)
+	}
+	if claims.Username != "user" || claims.SessionID != 1 {
+		t.Fatalf("expected claims for user:1, but got %s:%d",
+			claims.Username, claims.SessionID)
+	}
+
+	imported, err = ImportLegacySecret(dir, secret)
+	if err != nil {
+		t.Fatalf("failed to import legacy secret: %v", err)
+	}
+	if imported {
+		t.Fatalf("expected legacy secret not to be imported twice")
+	}
+}
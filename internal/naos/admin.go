@@ -0,0 +1,39 @@
+package naos
+
+import (
+	"fmt"
+
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+)
+
+// GrantAdmin assigns the admin Role to the User with the given username,
+// connecting to the configured database. The server must not be running.
+func GrantAdmin(c *Configuration, username string) (*models.User, error) {
+	ds, err := openDataService(c, false)
+	if err != nil {
+		return nil, err
+	}
+	defer ds.Database.Close()
+
+	var u *models.User
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		var err error
+		existing, err := ds.UserService.GetByUsername(username, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get User by username %q: %w",
+				username, err)
+		}
+
+		u, err = ds.UserService.AssignRole(existing.Meta.ID, models.RoleAdmin, tx)
+		if err != nil {
+			return fmt.Errorf("failed to assign admin Role to User %d: %w",
+				existing.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
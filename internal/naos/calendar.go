@@ -0,0 +1,121 @@
+package naos
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Dophin2009/nao/internal/calendar"
+	"github.com/Dophin2009/nao/internal/graphql"
+	"github.com/Dophin2009/nao/internal/web"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// NewUserCalendarHandler returns a GET endpoint handler that serves the
+// iCalendar feed of the episodes of the Media a User is watching or
+// planning to watch. The User is identified by the calendar token in the
+// token path variable, optionally followed by .ics, since calendar
+// applications cannot send credentials.
+func NewUserCalendarHandler(path []string, ds *graphql.DataService) web.Handler {
+	return web.Handler{
+		Method: http.MethodGet,
+		Path:   path,
+		Func: func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+			w.Header().Set(web.HeaderContentType, web.HeaderContentTypeValJSON)
+
+			tkn := strings.TrimSuffix(ps.ByName("token"), ".ics")
+
+			var c *calendar.Calendar
+			msg, status := web.ErrorInternalServer, http.StatusInternalServerError
+			err := ds.Database.Transaction(false, func(tx db.Tx) error {
+				t, err := ds.CalendarTokenService.GetByToken(tkn, tx)
+				if err != nil {
+					msg, status = web.ErrorNotFound, http.StatusNotFound
+					return err
+				}
+
+				c, err = newCalendarBuilder(ds).ForUser(t.UserID, time.Now(), tx)
+				if err != nil {
+					return fmt.Errorf("failed to generate calendar of User %d: %w",
+						t.UserID, err)
+				}
+				return nil
+			})
+			if err != nil {
+				web.EncodeResponseError(msg, err, status, w)
+				return
+			}
+
+			writeCalendar(w, c)
+		},
+	}
+}
+
+// NewMediaCalendarHandler returns a GET endpoint handler that serves the
+// public iCalendar feed of the episodes of the Media with the ID of the id
+// path variable.
+func NewMediaCalendarHandler(path []string, ds *graphql.DataService) web.Handler {
+	return web.Handler{
+		Method: http.MethodGet,
+		Path:   path,
+		Func: func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+			w.Header().Set(web.HeaderContentType, web.HeaderContentTypeValJSON)
+
+			mID, err := web.ParsePathVarInt("id", &ps)
+			if err != nil {
+				web.EncodeResponseErrorBadRequest(web.ErrorPathVariableParsing,
+					err, w)
+				return
+			}
+
+			var c *calendar.Calendar
+			msg, status := web.ErrorInternalServer, http.StatusInternalServerError
+			err = ds.Database.Transaction(false, func(tx db.Tx) error {
+				_, err := ds.MediaService.GetByID(mID, tx)
+				if err != nil {
+					msg, status = web.ErrorNotFound, http.StatusNotFound
+					return fmt.Errorf("failed to get Media by id %d: %w", mID, err)
+				}
+
+				c, err = newCalendarBuilder(ds).ForMedia(mID, time.Now(), tx)
+				if err != nil {
+					return fmt.Errorf("failed to generate calendar of Media %d: %w",
+						mID, err)
+				}
+				return nil
+			})
+			if err != nil {
+				web.EncodeResponseError(msg, err, status, w)
+				return
+			}
+
+			writeCalendar(w, c)
+		},
+	}
+}
+
+// writeCalendar writes the given Calendar as the response.
+func writeCalendar(w http.ResponseWriter, c *calendar.Calendar) {
+	w.Header().Set(web.HeaderContentType, calendar.ContentType)
+	err := calendar.Encode(w, c)
+	if err != nil {
+		// The response has already begun, so the error can only be logged
+		log.Errorf("Failed to write calendar %q: %v", c.Name, err)
+	}
+}
+
+// newCalendarBuilder returns a calendar Builder using the given
+// DataService.
+func newCalendarBuilder(ds *graphql.DataService) *calendar.Builder {
+	return &calendar.Builder{
+		UserService:       ds.UserService,
+		MediaService:      ds.MediaService,
+		EpisodeService:    ds.EpisodeService,
+		EpisodeSetService: ds.EpisodeSetService,
+		UserMediaService:  ds.UserMediaService,
+		ScheduleService:   ds.ScheduleService,
+	}
+}
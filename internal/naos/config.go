@@ -16,6 +16,100 @@ type Configuration struct {
 		Path     string `mapstructure:"path"`
 		Filemode uint32 `mapstructure:"filemode"`
 	} `mapstructure:"db"`
+	JWT struct {
+		// KeyDir is the directory containing the PEM files of the signing
+		// keys. A key is generated if there are none.
+		KeyDir string `mapstructure:"keydir"`
+		// KeyPath is the path of the .env file containing the secret key
+		// that signed tokens before signing keys were kept in KeyDir. It is
+		// imported if KeyDir contains no keys, so that tokens signed with it
+		// remain valid for the grace period.
+		KeyPath string `mapstructure:"keypath"`
+		// Algorithm is the algorithm of generated keys; one of HS256, RS256,
+		// or EdDSA.
+		Algorithm string `mapstructure:"algorithm"`
+		// RotationInterval is the number of hours after which the signing
+		// key is replaced by a new one. Keys are not rotated if 0.
+		RotationInterval int `mapstructure:"rotationinterval"`
+		// GracePeriod is the number of minutes replaced keys continue to
+		// verify tokens for.
+		GracePeriod int `mapstructure:"graceperiod"`
+		// Duration is the number of minutes issued access tokens are valid
+		// for.
+		Duration int `mapstructure:"duration"`
+		// RefreshDuration is the number of minutes Sessions remain valid for
+		// after being started or refreshed.
+		RefreshDuration int `mapstructure:"refreshduration"`
+	} `mapstructure:"jwt"`
+	Password struct {
+		// MinLength is the minimum number of characters of new passwords.
+		MinLength int `mapstructure:"minlength"`
+		// MaxLength is the maximum number of bytes of new passwords.
+		MaxLength     int  `mapstructure:"maxlength"`
+		RequireUpper  bool `mapstructure:"requireupper"`
+		RequireLower  bool `mapstructure:"requirelower"`
+		RequireDigit  bool `mapstructure:"requiredigit"`
+		RequireSymbol bool `mapstructure:"requiresymbol"`
+		// AllowUsername allows passwords that contain the username.
+		AllowUsername bool `mapstructure:"allowusername"`
+		// ResetDuration is the number of minutes password reset tokens are
+		// valid for.
+		ResetDuration int `mapstructure:"resetduration"`
+		// ResetURL is the URL of the page Users reset their password on,
+		// where {token} is replaced by the reset token.
+		ResetURL string `mapstructure:"reseturl"`
+	} `mapstructure:"password"`
+	Lockout struct {
+		// MaxFailures is the number of failed password attempts after which
+		// an account or IP address is locked out. There is no lockout if
+		// negative.
+		MaxFailures int `mapstructure:"maxfailures"`
+		// Window is the number of minutes failed attempts are counted over.
+		Window int `mapstructure:"window"`
+		// Duration is the number of minutes an account or IP address is
+		// locked out for.
+		Duration int `mapstructure:"duration"`
+		// TrustProxy uses the X-Forwarded-For header to determine the IP
+		// address of clients.
+		TrustProxy bool `mapstructure:"trustproxy"`
+	} `mapstructure:"lockout"`
+	TwoFactor struct {
+		// Issuer is the issuer shown by authenticator apps for TOTP secrets.
+		Issuer string `mapstructure:"issuer"`
+		// RequiredRoles are the names of the Roles that grant no Permissions
+		// to Users that have not enabled two-factor authentication.
+		RequiredRoles []string `mapstructure:"requiredroles"`
+	} `mapstructure:"twofactor"`
+	Registration struct {
+		// Mode determines who may register; one of open, invite, or closed.
+		Mode string `mapstructure:"mode"`
+		// VerificationDuration is the number of minutes email verification
+		// tokens are valid for.
+		VerificationDuration int `mapstructure:"verificationduration"`
+		// VerificationURL is the URL of the page Users verify their email
+		// on, where {token} is replaced by the verification token.
+		VerificationURL string `mapstructure:"verificationurl"`
+	} `mapstructure:"registration"`
+	Notify struct {
+		// Method is the method of delivering messages to Users; one of log,
+		// file, or smtp.
+		Method string `mapstructure:"method"`
+		// Dir is the directory messages are written to with the file
+		// method.
+		Dir string `mapstructure:"dir"`
+		// SMTP configures the server messages are sent through with the
+		// smtp method.
+		SMTP struct {
+			Host string `mapstructure:"host"`
+			Port int    `mapstructure:"port"`
+			// Username and Password authenticate with the server; there is
+			// no authentication if Username is empty.
+			Username string `mapstructure:"username"`
+			Password string `mapstructure:"password"`
+			// From is the address messages are sent from.
+			From string `mapstructure:"from"`
+		} `mapstructure:"smtp"`
+	} `mapstructure:"notify"`
 }
 
 // ReadConfigs returns a Configuration object with configuration properties
@@ -33,6 +127,16 @@ func ReadConfigs() (*Configuration, error) {
 	return &conf, nil
 }
 
+// KeyDir returns the default directory of JWT signing keys.
+func KeyDir() string {
+	return filepath.Join(xdg.DataHome, "nao", "keys")
+}
+
+// MessageDir returns the default directory messages to Users are written to.
+func MessageDir() string {
+	return filepath.Join(xdg.DataHome, "nao", "messages")
+}
+
 // ConfigDirs returns a list of configuration directories.
 func ConfigDirs() []string {
 	subdir := "nao"
@@ -0,0 +1,79 @@
+package naos
+
+import (
+	"fmt"
+
+	"github.com/Dophin2009/nao/internal/dump"
+	"github.com/Dophin2009/nao/internal/graphql"
+	"github.com/Dophin2009/nao/pkg/db"
+)
+
+// DumpCatalog writes the global catalog of the configured database to the
+// given directory. The database must not be in use by a running server.
+func DumpCatalog(c *Configuration, dir string) (*dump.Manifest, error) {
+	ds, err := openDataService(c, false)
+	if err != nil {
+		return nil, err
+	}
+	defer ds.Database.Close()
+
+	var manifest *dump.Manifest
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		manifest, err = newCatalog(ds).Dump(dir, tx)
+		if err != nil {
+			return fmt.Errorf("failed to dump catalog: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// LoadCatalog loads the dump in the given directory into the configured
+// database. Nothing is loaded if any model fails to. The database must not
+// be in use by a running server.
+func LoadCatalog(c *Configuration, dir string) (*dump.Result, error) {
+	ds, err := openDataService(c, false)
+	if err != nil {
+		return nil, err
+	}
+	defer ds.Database.Close()
+
+	var res *dump.Result
+	err = ds.Database.Transaction(true, func(tx db.Tx) error {
+		res, err = newCatalog(ds).Load(dir, tx)
+		if err != nil {
+			return fmt.Errorf("failed to load dump: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// newCatalog returns a dump Catalog using the given DataService.
+func newCatalog(ds *graphql.DataService) *dump.Catalog {
+	return &dump.Catalog{
+		GenreService:          ds.GenreService,
+		CharacterService:      ds.CharacterService,
+		PersonService:         ds.PersonService,
+		ProducerService:       ds.ProducerService,
+		EpisodeService:        ds.EpisodeService,
+		MediaService:          ds.MediaService,
+		EpisodeSetService:     ds.EpisodeSetService,
+		VolumeService:         ds.VolumeService,
+		ChapterService:        ds.ChapterService,
+		BroadcastSlotService:  ds.BroadcastSlotService,
+		AiringService:         ds.AiringService,
+		MediaCharacterService: ds.MediaCharacterService,
+		MediaGenreService:     ds.MediaGenreService,
+		MediaProducerService:  ds.MediaProducerService,
+		MediaRelationService:  ds.MediaRelationSerivce,
+	}
+}
@@ -0,0 +1,148 @@
+package naos
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Dophin2009/nao/internal/data"
+	"github.com/Dophin2009/nao/internal/exporter"
+	"github.com/Dophin2009/nao/internal/graphql"
+	"github.com/Dophin2009/nao/internal/jwt"
+	"github.com/Dophin2009/nao/internal/web"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// NewExportHandler returns a GET endpoint handler that streams the data of
+// the User with the ID of the id path variable in the format of the format
+// path variable, such as json, csv, or myanimelist. Requests are
+// authenticated like those of the GraphQL API, and must be made by the User
+// itself or one permitted to update the data of other Users.
+func NewExportHandler(path []string, ds *graphql.DataService,
+	au *jwt.Authenticator, trustProxy bool) web.Handler {
+	authenticate := web.AuthMiddleware(au, NewUserLookup(ds),
+		data.APITokenPrefix, NewAPITokenLookup(ds))
+	remoteAddr := web.RemoteAddrMiddleware(trustProxy)
+	return web.Handler{
+		Method: http.MethodGet,
+		Path:   path,
+		Func: remoteAddr(authenticate(
+			func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+				w.Header().Set(web.HeaderContentType, web.HeaderContentTypeValJSON)
+
+				uID, err := web.ParsePathVarInt("id", &ps)
+				if err != nil {
+					web.EncodeResponseErrorBadRequest(web.ErrorPathVariableParsing,
+						err, w)
+					return
+				}
+				enc, err := exporter.Lookup(ps.ByName("format"))
+				if err != nil {
+					web.EncodeResponseError(web.ErrorPathVariableParsing, err,
+						http.StatusNotFound, w)
+					return
+				}
+				if _, ok := r.Context().Value(web.UserKey).(*models.User); !ok {
+					web.EncodeResponseErrorUnauthorized(web.ErrorAuthentication,
+						errors.New("not authenticated"), w)
+					return
+				}
+
+				var l *exporter.Library
+				msg, status := web.ErrorInternalServer, http.StatusInternalServerError
+				err = ds.Database.Transaction(false, func(tx db.Tx) error {
+					err := graphql.AuthorizeExport(r.Context(), ds, uID, tx)
+					if err != nil {
+						msg, status = web.ErrorForbidden, http.StatusForbidden
+						return err
+					}
+
+					l, err = newExporter(ds).Load(uID, tx)
+					if err != nil {
+						return fmt.Errorf("failed to load library of User %d: %w",
+							uID, err)
+					}
+					return nil
+				})
+				if err != nil {
+					web.EncodeResponseError(msg, err, status, w)
+					return
+				}
+
+				w.Header().Set(web.HeaderContentType, enc.ContentType())
+				w.Header().Set("Content-Disposition", fmt.Sprintf(
+					"attachment; filename=%q", exportFilename(l, enc)))
+				err = enc.Encode(w, l)
+				if err != nil {
+					// The response has already begun, so the error can only be
+					// logged
+					log.Errorf("Failed to export library of User %d: %v", uID, err)
+				}
+			})),
+	}
+}
+
+// ExportLibrary writes the data of the User with the given username in the
+// given format to the given writer, connecting to the configured database
+// itself. The database must not be in use by a running server.
+func ExportLibrary(c *Configuration, username string, format string,
+	w io.Writer) error {
+	enc, err := exporter.Lookup(format)
+	if err != nil {
+		return err
+	}
+
+	ds, err := openDataService(c, false)
+	if err != nil {
+		return err
+	}
+	defer ds.Database.Close()
+
+	var l *exporter.Library
+	err = ds.Database.Transaction(false, func(tx db.Tx) error {
+		u, err := ds.UserService.GetByUsername(username, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get User by username %q: %w",
+				username, err)
+		}
+
+		l, err = newExporter(ds).Load(u.Meta.ID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to load library of User %d: %w",
+				u.Meta.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return enc.Encode(w, l)
+}
+
+// newExporter returns an Exporter using the given DataService.
+func newExporter(ds *graphql.DataService) *exporter.Exporter {
+	return &exporter.Exporter{
+		UserService:          ds.UserService,
+		MediaService:         ds.MediaService,
+		EpisodeService:       ds.EpisodeService,
+		CharacterService:     ds.CharacterService,
+		PersonService:        ds.PersonService,
+		UserMediaService:     ds.UserMediaService,
+		UserEpisodeService:   ds.UserEpisodeService,
+		UserCharacterService: ds.UserCharacterService,
+		UserPersonService:    ds.UserPersonService,
+		UserMediaListService: ds.UserMediaListService,
+	}
+}
+
+// exportFilename returns the name of the file the given Library is exported
+// to by the given Encoder.
+func exportFilename(l *exporter.Library, enc exporter.Encoder) string {
+	return fmt.Sprintf("nao-%s-%s.%s", l.User.Username,
+		l.ExportedAt.Format("2006-01-02"), enc.Extension())
+}
@@ -2,29 +2,153 @@ package naos
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/Dophin2009/nao/internal/data"
 	"github.com/Dophin2009/nao/internal/graphql"
+	"github.com/Dophin2009/nao/internal/jwt"
 	"github.com/Dophin2009/nao/internal/web"
+	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
 	"github.com/friendsofgo/graphiql"
 	"github.com/julienschmidt/httprouter"
 )
 
 // NewGraphQLHandler returns a POST endpoint handler for the GraphQL API.
-func NewGraphQLHandler(path []string, ds *graphql.DataService) web.Handler {
+// Requests are authenticated by bearer tokens verified by the Authenticator
+// of the given Resolver. The X-Forwarded-For header is used to determine the
+// IP address of clients if trustProxy is true.
+func NewGraphQLHandler(path []string, ds *graphql.DataService,
+	resolver *graphql.Resolver, trustProxy bool) web.Handler {
 	cfg := graphql.Config{
-		Resolvers: &graphql.Resolver{},
+		Resolvers: resolver,
+		Directives: graphql.DirectiveRoot{
+			Auth:          graphql.Auth,
+			HasPermission: graphql.HasPermission,
+			Owner:         graphql.Owner,
+		},
 	}
 	gqlHandler := handler.NewDefaultServer(graphql.NewExecutableSchema(cfg))
 
-	ctx := context.WithValue(context.Background(), graphql.DataServiceKey, ds)
+	authenticate := web.AuthMiddleware(resolver.Authenticator, NewUserLookup(ds),
+		data.APITokenPrefix, NewAPITokenLookup(ds))
+	remoteAddr := web.RemoteAddrMiddleware(trustProxy)
 	return web.Handler{
 		Method: http.MethodPost,
 		Path:   path,
+		Func: remoteAddr(authenticate(
+			func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+				ctx := context.WithValue(r.Context(), graphql.DataServiceKey, ds)
+				gqlHandler.ServeHTTP(w, r.WithContext(ctx))
+			})),
+	}
+}
+
+// NewUserLookup returns a function that retrieves the User identified by
+// verified token claims.
+func NewUserLookup(ds *graphql.DataService) web.UserLookup {
+	return func(claims *jwt.Claims) (*models.User, error) {
+		var u *models.User
+		err := ds.Database.Transaction(false, func(tx db.Tx) error {
+			var err error
+			u, err = ds.UserService.GetByUsername(claims.Username, tx)
+			if err != nil {
+				return fmt.Errorf("failed to get User by username %q: %w",
+					claims.Username, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return u, nil
+	}
+}
+
+// NewAPITokenLookup returns a function that retrieves the User and the
+// APIToken identified by an API token and records the use of the APIToken.
+// The APIToken is retrieved in a read transaction; a write transaction is
+// only opened when its last used time is stale.
+func NewAPITokenLookup(ds *graphql.DataService) web.APITokenLookup {
+	return func(tkn string) (*models.User, *models.APIToken, error) {
+		var u *models.User
+		var t *models.APIToken
+		err := ds.Database.Transaction(false, func(tx db.Tx) error {
+			var err error
+			t, err = ds.APITokenService.Authenticate(tkn, tx)
+			if err != nil {
+				return fmt.Errorf("failed to authenticate API token: %w", err)
+			}
+
+			u, err = ds.UserService.GetByID(t.UserID, tx)
+			if err != nil {
+				return fmt.Errorf("failed to get User by id %d: %w", t.UserID, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		now := time.Now()
+		if ds.APITokenService.UseIsStale(t, now) {
+			err = ds.Database.Transaction(true, func(tx db.Tx) error {
+				return ds.APITokenService.RecordUse(t.Meta.ID, now, tx)
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to record use of API token: %w",
+					err)
+			}
+			t.LastUsed = &now
+		}
+
+		return u, t, nil
+	}
+}
+
+// SessionRevocationList is a jwt.RevocationList that rejects tokens issued
+// for Sessions that have been revoked or have expired.
+type SessionRevocationList struct {
+	DataService *graphql.DataService
+}
+
+// IsRevoked returns true if the Session the token with the given claims was
+// issued for is no longer active.
+func (l *SessionRevocationList) IsRevoked(claims *jwt.Claims) (bool, error) {
+	var revoked bool
+	err := l.DataService.Database.Transaction(false, func(tx db.Tx) error {
+		ser := l.DataService.SessionService
+		s, err := ser.GetByID(claims.SessionID, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get Session by id %d: %w",
+				claims.SessionID, err)
+		}
+
+		revoked = !ser.IsActive(s)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return revoked, nil
+}
+
+// NewJWKSHandler returns a GET endpoint handler that serves the public keys
+// tokens may be verified with as a JSON web key set.
+func NewJWKSHandler(path []string, keys *jwt.KeyRing) web.Handler {
+	return web.Handler{
+		Method: http.MethodGet,
+		Path:   path,
 		Func: func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-			r = r.WithContext(ctx)
-			gqlHandler.ServeHTTP(w, r)
+			web.EncodeResponseBody(keys.JWKS(), w)
+		},
+		ResponseHeaders: map[string]string{
+			web.HeaderContentType: web.HeaderContentTypeValJSON,
 		},
 	}
 }
@@ -1,21 +1,70 @@
 package naos
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/Dophin2009/nao/internal/data"
 	"github.com/Dophin2009/nao/internal/graphql"
+	"github.com/Dophin2009/nao/internal/jwt"
+	"github.com/Dophin2009/nao/internal/notify"
 	"github.com/Dophin2009/nao/internal/web"
 	"github.com/Dophin2009/nao/pkg/db"
+	"github.com/Dophin2009/nao/pkg/models"
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// defaultTokenDuration is the duration issued access tokens are valid for
+	// if not configured.
+	defaultTokenDuration = 15 * time.Minute
+	// defaultRefreshDuration is the duration Sessions remain valid for after
+	// being started or refreshed if not configured.
+	defaultRefreshDuration = 30 * 24 * time.Hour
+	// defaultKeyAlgorithm is the algorithm of generated JWT signing keys if
+	// not configured.
+	defaultKeyAlgorithm = jwt.AlgorithmRS256
+	// defaultResetDuration is the duration password reset tokens are valid
+	// for if not configured.
+	defaultResetDuration = time.Hour
+	// defaultVerificationDuration is the duration email verification tokens
+	// are valid for if not configured.
+	defaultVerificationDuration = 24 * time.Hour
+	// defaultTwoFactorIssuer is the issuer shown by authenticator apps for
+	// TOTP secrets if not configured.
+	defaultTwoFactorIssuer = "nao"
+	// defaultSMTPPort is the port of the SMTP server if not configured.
+	defaultSMTPPort = 587
+	// defaultLockoutFailures is the number of failed password attempts after
+	// which an account or IP address is locked out if not configured.
+	defaultLockoutFailures = 5
+	// defaultLockoutWindow is the duration failed password attempts are
+	// counted over if not configured.
+	defaultLockoutWindow = 15 * time.Minute
+	// defaultLockoutDuration is the duration an account or IP address is
+	// locked out for if not configured.
+	defaultLockoutDuration = 15 * time.Minute
+)
+
 // Application is the main naos application.
 type Application struct {
 	Server    *web.Server
 	DataLayer *graphql.DataService
+	Keys      *jwt.KeyRing
+
+	stopKeyRotation func()
+}
+
+// Close stops background tasks and closes the database connection.
+func (a *Application) Close() error {
+	if a.stopKeyRotation != nil {
+		a.stopKeyRotation()
+	}
+	return a.DataLayer.Database.Close()
 }
 
 // HTTPServer returns the application's HTTP server.
@@ -25,64 +74,267 @@ func (a *Application) HTTPServer() http.Server {
 
 // NewApplication returns a new naos Application.
 func NewApplication(c *Configuration) (*Application, error) {
-	// Open database connection
-	log.WithFields(log.Fields{
-		"path":     c.DB.Path,
-		"filemode": c.DB.Filemode,
-	}).Info("Establishing database connection")
-
 	// Create the API controller and HTTP server
 	address := fmt.Sprintf("%s:%s", c.Hostname, c.Port)
 	s := web.NewServer(address)
 
-	characterService := &data.CharacterService{}
-	episodeService := &data.EpisodeService{}
-	episodeSetService := &data.EpisodeSetService{}
-	genreService := &data.GenreService{}
-	mediaService := &data.MediaService{}
-	personService := &data.PersonService{}
-	producerService := &data.ProducerService{}
-	userService := &data.UserService{}
+	ds, err := openDataService(c, true)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenDuration := time.Duration(c.JWT.Duration) * time.Minute
+	if tokenDuration <= 0 {
+		tokenDuration = defaultTokenDuration
+	}
+	refreshDuration := time.Duration(c.JWT.RefreshDuration) * time.Minute
+	if refreshDuration <= 0 {
+		refreshDuration = defaultRefreshDuration
+	}
+
+	// Load JWT signing keys, generating one if necessary; replaced keys must
+	// remain valid for at least as long as the tokens they signed
+	keyDir := c.JWT.KeyDir
+	if keyDir == "" {
+		keyDir = KeyDir()
+	}
+	algorithm := c.JWT.Algorithm
+	if algorithm == "" {
+		algorithm = defaultKeyAlgorithm
+	}
+	gracePeriod := time.Duration(c.JWT.GracePeriod) * time.Minute
+	if gracePeriod < tokenDuration {
+		gracePeriod = tokenDuration
+	}
+
+	if c.JWT.KeyPath != "" {
+		err = importLegacyKey(keyDir, c.JWT.KeyPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"dir":       keyDir,
+		"algorithm": algorithm,
+	}).Info("Loading JWT signing keys")
+	keys, err := jwt.LoadKeyRing(keyDir, algorithm, gracePeriod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+
+	au := jwt.NewAuthenticator(keys, &SessionRevocationList{DataService: ds})
+
+	resetDuration := time.Duration(c.Password.ResetDuration) * time.Minute
+	if resetDuration <= 0 {
+		resetDuration = defaultResetDuration
+	}
+	verificationDuration :=
+		time.Duration(c.Registration.VerificationDuration) * time.Minute
+	if verificationDuration <= 0 {
+		verificationDuration = defaultVerificationDuration
+	}
+	twoFactorIssuer := c.TwoFactor.Issuer
+	if twoFactorIssuer == "" {
+		twoFactorIssuer = defaultTwoFactorIssuer
+	}
+	registrationMode, err := parseRegistrationMode(c.Registration.Mode)
+	if err != nil {
+		return nil, err
+	}
+	notifier, err := newNotifier(c)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := graphql.Resolver{
+		Authenticator:             au,
+		TokenDuration:             tokenDuration,
+		RefreshDuration:           refreshDuration,
+		Notifier:                  notifier,
+		PasswordResetDuration:     resetDuration,
+		PasswordResetURL:          c.Password.ResetURL,
+		Lockout:                   newLockout(c),
+		RegistrationMode:          registrationMode,
+		EmailVerificationDuration: verificationDuration,
+		EmailVerificationURL:      c.Registration.VerificationURL,
+		TwoFactorIssuer:           twoFactorIssuer,
+	}
+	graphqlHandler := NewGraphQLHandler([]string{"graphql"}, ds, &resolver,
+		c.Lockout.TrustProxy)
+	s.RegisterHandler(graphqlHandler)
+
+	graphiqlHandler, err := NewGraphiQLHandler(
+		[]string{"graphiql"}, graphqlHandler.PathString(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GraphiQL handler: %w", err)
+	}
+
+	s.RegisterHandler(graphiqlHandler)
+
+	jwksHandler := NewJWKSHandler([]string{".well-known", "jwks.json"}, keys)
+	s.RegisterHandler(jwksHandler)
+
+	exportHandler := NewExportHandler(
+		[]string{"users", ":id", "export", ":format"}, ds, au,
+		c.Lockout.TrustProxy)
+	s.RegisterHandler(exportHandler)
+
+	userCalendarHandler := NewUserCalendarHandler(
+		[]string{"calendars", ":token"}, ds)
+	s.RegisterHandler(userCalendarHandler)
+
+	mediaCalendarHandler := NewMediaCalendarHandler(
+		[]string{"media", ":id", "calendar.ics"}, ds)
+	s.RegisterHandler(mediaCalendarHandler)
+
+	var stopKeyRotation func()
+	if c.JWT.RotationInterval > 0 {
+		interval := time.Duration(c.JWT.RotationInterval) * time.Hour
+		stopKeyRotation = keys.RotateEvery(interval, func(err error) {
+			log.Errorf("Failed to rotate JWT keys: %v", err)
+		})
+	}
+
+	return &Application{
+		Server:          &s,
+		DataLayer:       ds,
+		Keys:            keys,
+		stopKeyRotation: stopKeyRotation,
+	}, nil
+}
 
-	mediaCharacterService := &data.MediaCharacterService{
-		MediaService:     mediaService,
-		CharacterService: characterService,
-		PersonService:    personService,
+// importLegacyKey imports the secret key in the .env file at the given path
+// into the given key directory if it contains no keys yet.
+func importLegacyKey(keyDir string, keyPath string) error {
+	key, err := jwt.ReadKeyFromEnv(keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read legacy JWT key: %w", err)
 	}
-	mediaGenreService := &data.MediaGenreService{
-		MediaService: mediaService,
-		GenreService: genreService,
+	if key == "" {
+		return fmt.Errorf("legacy JWT key in %q is empty", keyPath)
 	}
-	mediaProducerService := &data.MediaProducerService{
-		MediaService:    mediaService,
-		ProducerService: producerService,
+
+	imported, err := jwt.ImportLegacySecret(keyDir, []byte(key))
+	if err != nil {
+		return fmt.Errorf("failed to import legacy JWT key: %w", err)
 	}
-	mediaRelationService := &data.MediaRelationService{
-		MediaService: mediaService,
+	if imported {
+		log.WithFields(log.Fields{
+			"path": keyPath,
+			"dir":  keyDir,
+		}).Info("Imported legacy JWT key")
 	}
-	userMediaService := &data.UserMediaService{
-		UserService:  userService,
-		MediaService: mediaService,
+	return nil
+}
+
+// openDataService connects to the configured database and returns the
+// services of the data layer. The database is cleared when closed if
+// clearOnClose is true.
+func openDataService(c *Configuration,
+	clearOnClose bool) (*graphql.DataService, error) {
+	// Open database connection
+	log.WithFields(log.Fields{
+		"path":     c.DB.Path,
+		"filemode": c.DB.Filemode,
+	}).Info("Establishing database connection")
+
+	characterService := data.NewCharacterService(db.PersistHooks{})
+	episodeService := data.NewEpisodeService(db.PersistHooks{})
+	genreService := data.NewGenreService(db.PersistHooks{})
+	mediaService := data.NewMediaService(db.PersistHooks{})
+	personService := data.NewPersonService(db.PersistHooks{})
+	producerService := data.NewProducerService(db.PersistHooks{})
+	userService := data.NewUserService(db.PersistHooks{})
+
+	episodeSetService := data.NewEpisodeSetService(db.PersistHooks{},
+		episodeService, mediaService)
+	mediaCharacterService := data.NewMediaCharacterService(db.PersistHooks{},
+		mediaService, characterService, personService)
+	mediaGenreService := data.NewMediaGenreService(db.PersistHooks{},
+		mediaService, genreService)
+	mediaProducerService := data.NewMediaProducer(db.PersistHooks{},
+		mediaService, producerService)
+	mediaRelationService := data.NewMediaRelationService(db.PersistHooks{},
+		mediaService)
+	volumeService := data.NewVolumeService(db.PersistHooks{}, mediaService)
+	chapterService := data.NewChapterService(db.PersistHooks{},
+		mediaService, volumeService)
+	broadcastSlotService := data.NewBroadcastSlotService(db.PersistHooks{},
+		mediaService)
+	airingService := data.NewAiringService(db.PersistHooks{},
+		mediaService, episodeService)
+	scheduleService := data.NewScheduleService(broadcastSlotService,
+		airingService)
+	userCharacterService := data.NewUserCharacterService(db.PersistHooks{},
+		userService, characterService)
+	userEpisodeService := data.NewUserEpisodeService(db.PersistHooks{},
+		userService, episodeService)
+	userMediaService := data.NewUserMediaService(db.PersistHooks{},
+		userService, mediaService)
+	seasonChartService := data.NewSeasonChartService(mediaService,
+		userMediaService)
+	userMediaListService := data.NewUserMediaListService(db.PersistHooks{},
+		userService, userMediaService)
+	userPersonService := data.NewUserPersonService(db.PersistHooks{},
+		userService, personService)
+	episodeProgressService := data.NewEpisodeProgressService(db.PersistHooks{},
+		userMediaService, episodeSetService)
+	sessionService := data.NewSessionService(db.PersistHooks{}, userService)
+	apiTokenService := data.NewAPITokenService(db.PersistHooks{}, userService)
+	calendarTokenService := data.NewCalendarTokenService(db.PersistHooks{},
+		userService)
+	passwordResetService := data.NewPasswordResetService(db.PersistHooks{},
+		userService)
+	emailVerificationService := data.NewEmailVerificationService(
+		db.PersistHooks{}, userService)
+	invitationService := data.NewInvitationService(db.PersistHooks{},
+		userService)
+
+	userService.PasswordPolicy = passwordPolicy(c)
+	requiredRoles, err := twoFactorRoles(c)
+	if err != nil {
+		return nil, err
 	}
-	userMediaListService := &data.UserMediaListService{
-		UserService:      userService,
-		UserMediaService: userMediaService,
+	userService.TwoFactorRoles = requiredRoles
+	// The first User to register is only trusted with administration if
+	// strangers cannot register before it; otherwise, admins must be granted
+	// with the admin command
+	registrationMode, err := parseRegistrationMode(c.Registration.Mode)
+	if err != nil {
+		return nil, err
 	}
+	userService.FirstUserAdmin = registrationMode != graphql.RegistrationModeOpen
 
 	buckets := []string{
 		characterService.Bucket(), episodeService.Bucket(), episodeSetService.Bucket(),
 		genreService.Bucket(), mediaService.Bucket(), personService.Bucket(),
 		producerService.Bucket(), userService.Bucket(), mediaCharacterService.Bucket(),
 		mediaGenreService.Bucket(), mediaProducerService.Bucket(),
-		mediaRelationService.Bucket(), userMediaService.Bucket(),
-		userMediaListService.Bucket(),
+		mediaRelationService.Bucket(), userCharacterService.Bucket(),
+		userEpisodeService.Bucket(), userMediaService.Bucket(),
+		userMediaListService.Bucket(), userPersonService.Bucket(),
+		episodeProgressService.Bucket(),
+		sessionService.Bucket(), apiTokenService.Bucket(),
+		passwordResetService.Bucket(), emailVerificationService.Bucket(),
+		invitationService.Bucket(), volumeService.Bucket(),
+		chapterService.Bucket(), broadcastSlotService.Bucket(),
+		airingService.Bucket(), calendarTokenService.Bucket(),
 	}
+	buckets = append(buckets, userService.IndexBuckets()...)
+	buckets = append(buckets, mediaService.IndexBuckets()...)
+	buckets = append(buckets, characterService.IndexBuckets()...)
+	buckets = append(buckets, personService.IndexBuckets()...)
+	buckets = append(buckets, producerService.IndexBuckets()...)
+	buckets = append(buckets, apiTokenService.IndexBuckets()...)
+	buckets = append(buckets, calendarTokenService.IndexBuckets()...)
 
 	driver, err := db.ConnectBoltDatabase(&db.BoltDatabaseConfig{
 		Path:         c.DB.Path,
 		FileMode:     os.FileMode(c.DB.Filemode),
 		Buckets:      buckets,
-		ClearOnClose: true,
+		ClearOnClose: clearOnClose,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -91,38 +343,197 @@ func NewApplication(c *Configuration) (*Application, error) {
 	database := db.DatabaseService{
 		DatabaseDriver: driver,
 	}
-	ds := graphql.DataService{
-		Database:              database,
-		CharacterService:      characterService,
-		EpisodeService:        episodeService,
-		EpisodeSetService:     episodeSetService,
-		GenreService:          genreService,
-		MediaService:          mediaService,
-		MediaCharacterService: mediaCharacterService,
-		MediaGenreService:     mediaGenreService,
-		MediaProducerService:  mediaProducerService,
-		MediaRelationSerivce:  mediaRelationService,
-		PersonService:         personService,
-		ProducerService:       producerService,
-		UserService:           userService,
-		UserMediaService:      userMediaService,
-		UserMediaListService:  userMediaListService,
-	}
-
-	graphqlHandler := NewGraphQLHandler([]string{"graphql"}, &ds)
-	s.RegisterHandler(graphqlHandler)
 
-	graphiqlHandler, err := NewGraphiQLHandler(
-		[]string{"graphiql"}, graphqlHandler.PathString(),
-	)
+	// Rewrite Media persisted with free-form Types and Sources
+	err = database.Transaction(true, func(tx db.Tx) error {
+		n, err := mediaService.MigrateLegacy(tx,
+			func(id int, field string, value string) {
+				log.WithFields(log.Fields{
+					"media": id,
+					"field": field,
+					"value": value,
+				}).Warn("Dropped unknown Media value")
+			})
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			log.WithFields(log.Fields{
+				"count": n,
+			}).Info("Migrated Media")
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create GraphiQL handler: %w", err)
+		return nil, fmt.Errorf("failed to migrate Media: %w", err)
 	}
 
-	s.RegisterHandler(graphiqlHandler)
+	// Rebuild indexes in case models were persisted before they were
+	// introduced
+	err = database.Transaction(true, func(tx db.Tx) error {
+		return userService.Reindex(tx)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild User indexes: %w", err)
+	}
+	err = database.Transaction(true, func(tx db.Tx) error {
+		for _, r := range []interface{ Reindex(db.Tx) error }{
+			mediaService, characterService, personService, producerService,
+		} {
+			err := r.Reindex(tx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild external ID indexes: %w", err)
+	}
+	err = database.Transaction(true, func(tx db.Tx) error {
+		err := apiTokenService.Reindex(tx)
+		if err != nil {
+			return err
+		}
+		return calendarTokenService.Reindex(tx)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild token indexes: %w", err)
+	}
 
-	return &Application{
-		Server:    &s,
-		DataLayer: &ds,
+	return &graphql.DataService{
+		Database:                 database,
+		AiringService:            airingService,
+		APITokenService:          apiTokenService,
+		BroadcastSlotService:     broadcastSlotService,
+		CalendarTokenService:     calendarTokenService,
+		ChapterService:           chapterService,
+		CharacterService:         characterService,
+		EmailVerificationService: emailVerificationService,
+		EpisodeService:           episodeService,
+		EpisodeProgressService:   episodeProgressService,
+		EpisodeSetService:        episodeSetService,
+		GenreService:             genreService,
+		InvitationService:        invitationService,
+		MediaService:             mediaService,
+		MediaCharacterService:    mediaCharacterService,
+		MediaGenreService:        mediaGenreService,
+		MediaProducerService:     mediaProducerService,
+		MediaRelationSerivce:     mediaRelationService,
+		PasswordResetService:     passwordResetService,
+		PersonService:            personService,
+		ProducerService:          producerService,
+		ScheduleService:          scheduleService,
+		SeasonChartService:       seasonChartService,
+		SessionService:           sessionService,
+		UserService:              userService,
+		UserCharacterService:     userCharacterService,
+		UserEpisodeService:       userEpisodeService,
+		UserMediaService:         userMediaService,
+		UserMediaListService:     userMediaListService,
+		UserPersonService:        userPersonService,
+		VolumeService:            volumeService,
 	}, nil
 }
+
+// passwordPolicy returns the configured password policy.
+func passwordPolicy(c *Configuration) data.PasswordPolicy {
+	policy := data.PasswordPolicy{
+		MinLength:      c.Password.MinLength,
+		MaxLength:      c.Password.MaxLength,
+		RequireUpper:   c.Password.RequireUpper,
+		RequireLower:   c.Password.RequireLower,
+		RequireDigit:   c.Password.RequireDigit,
+		RequireSymbol:  c.Password.RequireSymbol,
+		ForbidUsername: !c.Password.AllowUsername,
+	}
+	if policy.MinLength <= 0 {
+		policy.MinLength = data.DefaultPasswordPolicy.MinLength
+	}
+	if policy.MaxLength <= 0 {
+		policy.MaxLength = data.DefaultPasswordPolicy.MaxLength
+	}
+	return policy
+}
+
+// twoFactorRoles returns the configured Roles that require two-factor
+// authentication.
+func twoFactorRoles(c *Configuration) ([]models.Role, error) {
+	roles := make([]models.Role, len(c.TwoFactor.RequiredRoles))
+	for i, name := range c.TwoFactor.RequiredRoles {
+		r, err := models.ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("unknown two-factor required role %q: %w",
+				name, err)
+		}
+		roles[i] = r
+	}
+	return roles, nil
+}
+
+// newLockout returns the configured Lockout of failed password attempts, or
+// nil if disabled.
+func newLockout(c *Configuration) *web.Lockout {
+	maxFailures := c.Lockout.MaxFailures
+	if maxFailures < 0 {
+		return nil
+	}
+	if maxFailures == 0 {
+		maxFailures = defaultLockoutFailures
+	}
+	window := time.Duration(c.Lockout.Window) * time.Minute
+	if window <= 0 {
+		window = defaultLockoutWindow
+	}
+	duration := time.Duration(c.Lockout.Duration) * time.Minute
+	if duration <= 0 {
+		duration = defaultLockoutDuration
+	}
+	return web.NewLockout(maxFailures, window, duration)
+}
+
+// newNotifier returns the configured Notifier that delivers messages to
+// Users.
+func newNotifier(c *Configuration) (notify.Notifier, error) {
+	switch c.Notify.Method {
+	case "", "log":
+		return &notify.LogNotifier{Logger: log.StandardLogger()}, nil
+	case "file":
+		dir := c.Notify.Dir
+		if dir == "" {
+			dir = MessageDir()
+		}
+		return &notify.FileNotifier{Dir: dir}, nil
+	case "smtp":
+		smtp := c.Notify.SMTP
+		if smtp.Host == "" || smtp.From == "" {
+			return nil, errors.New("smtp notify method requires host and from")
+		}
+		port := smtp.Port
+		if port <= 0 {
+			port = defaultSMTPPort
+		}
+		return &notify.SMTPNotifier{
+			Host:     smtp.Host,
+			Port:     port,
+			Username: smtp.Username,
+			Password: smtp.Password,
+			From:     smtp.From,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown notify method %q", c.Notify.Method)
+}
+
+// parseRegistrationMode returns the RegistrationMode of the given configured
+// mode; registration is open if not configured.
+func parseRegistrationMode(mode string) (graphql.RegistrationMode, error) {
+	switch strings.ToLower(mode) {
+	case "", "open":
+		return graphql.RegistrationModeOpen, nil
+	case "invite":
+		return graphql.RegistrationModeInvite, nil
+	case "closed":
+		return graphql.RegistrationModeClosed, nil
+	}
+	return "", fmt.Errorf("unknown registration mode %q", mode)
+}
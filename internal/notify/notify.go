@@ -0,0 +1,86 @@
+// Package notify delivers messages, such as password reset instructions, to
+// Users.
+package notify
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Message is a notification addressed to a User.
+type Message struct {
+	// To is the email address of the recipient.
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers Messages to Users.
+type Notifier interface {
+	Notify(m *Message) error
+}
+
+// LogNotifier writes Messages to a logger instead of delivering them, for
+// instances without a mail server where the operator relays messages.
+type LogNotifier struct {
+	Logger log.FieldLogger
+}
+
+// Notify writes the given Message to the logger.
+func (n *LogNotifier) Notify(m *Message) error {
+	logger := n.Logger
+	if logger == nil {
+		logger = log.StandardLogger()
+	}
+
+	logger.WithFields(log.Fields{
+		"to":      m.To,
+		"subject": m.Subject,
+	}).Info(m.Body)
+	return nil
+}
+
+// FileNotifier writes each Message to a separate file in a directory instead
+// of delivering it, for instances without a mail server where the operator
+// relays messages.
+type FileNotifier struct {
+	Dir string
+}
+
+// Notify writes the given Message to a new file in the directory.
+func (n *FileNotifier) Notify(m *Message) error {
+	err := os.MkdirAll(n.Dir, 0700)
+	if err != nil {
+		return fmt.Errorf("failed to create message directory %q: %w", n.Dir, err)
+	}
+
+	name := fmt.Sprintf("%s-%s.txt", time.Now().UTC().Format("20060102T150405.000000000"),
+		sanitizeFilename(m.To))
+	path := filepath.Join(n.Dir, name)
+
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", m.To, m.Subject, m.Body)
+	err = ioutil.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write message file %q: %w", path, err)
+	}
+	return nil
+}
+
+// sanitizeFilename replaces characters of the given string that are not
+// safe to use in file names.
+func sanitizeFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '@', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPNotifier delivers Messages by email through an SMTP server.
+type SMTPNotifier struct {
+	Host string
+	Port int
+	// Username and Password authenticate with the server using PLAIN
+	// authentication, which is only done over TLS or to localhost. There is
+	// no authentication if Username is empty.
+	Username string
+	Password string
+	// From is the address Messages are sent from.
+	From string
+}
+
+// Notify sends the given Message to its recipient.
+func (n *SMTPNotifier) Notify(m *Message) error {
+	addr := net.JoinHostPort(n.Host, strconv.Itoa(n.Port))
+
+	var auth smtp.Auth
+	if n.Username != "" {
+		auth = smtp.PlainAuth("", n.Username, n.Password, n.Host)
+	}
+
+	err := smtp.SendMail(addr, auth, n.From, []string{m.To}, n.compose(m))
+	if err != nil {
+		return fmt.Errorf("failed to send message to %q: %w", m.To, err)
+	}
+	return nil
+}
+
+// compose returns the given Message as a plain text email.
+func (n *SMTPNotifier) compose(m *Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue(n.From))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(m.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n",
+		mime.QEncoding.Encode("utf-8", headerValue(m.Subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+
+	body := strings.ReplaceAll(m.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
+
+// headerValue removes line breaks from the given string so that it cannot
+// inject other headers.
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeSMTPServer accepts a single SMTP session on a local port and records
+// the envelope and data of the message sent.
+type fakeSMTPServer struct {
+	ln   net.Listener
+	from string
+	to   []string
+	data string
+	done chan struct{}
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	s := &fakeSMTPServer{ln: ln, done: make(chan struct{})}
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		conn.Write([]byte(line + "\r\n"))
+	}
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.to = append(s.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(l)
+			}
+			s.data = b.String()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// TestSMTPNotifier tests that Messages are sent to the recipient with the
+// subject and body.
+func TestSMTPNotifier(t *testing.T) {
+	s := newFakeSMTPServer(t)
+	defer s.ln.Close()
+
+	addr := s.ln.Addr().(*net.TCPAddr)
+	n := &SMTPNotifier{
+		Host: "127.0.0.1",
+		Port: addr.Port,
+		From: "nao@example.com",
+	}
+
+	err := n.Notify(&Message{
+		To:      "user@example.com",
+		Subject: "Verify your email\r\nBcc: other@example.com",
+		Body:    "Line one\nLine two",
+	})
+	if err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	<-s.done
+
+	if s.from != "nao@example.com" {
+		t.Errorf("expected sender %q, got %q", "nao@example.com", s.from)
+	}
+	if len(s.to) != 1 || s.to[0] != "user@example.com" {
+		t.Errorf("expected recipient %q, got %v", "user@example.com", s.to)
+	}
+	if !strings.Contains(s.data, "To: user@example.com\r\n") {
+		t.Errorf("expected To header, got %q", s.data)
+	}
+	if strings.Contains(s.data, "\r\nBcc:") {
+		t.Errorf("expected subject to not inject headers, got %q", s.data)
+	}
+	if !strings.Contains(s.data, "\r\n\r\nLine one\r\nLine two\r\n") {
+		t.Errorf("expected body with CRLF line endings, got %q", s.data)
+	}
+}
@@ -0,0 +1,122 @@
+// Package totp implements time-based one-time passwords as specified in RFC
+// 6238, compatible with common authenticator apps.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Digits is the number of digits of generated codes.
+	Digits = 6
+	// Period is the duration each code is valid for.
+	Period = 30 * time.Second
+	// Skew is the number of periods before and after the current one whose
+	// codes are also accepted, to allow for clock drift.
+	Skew = 1
+
+	secretSize = 20
+)
+
+// encoding is the encoding of secrets, unpadded as expected by authenticator
+// apps.
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random secret encoded in base32.
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// Counter returns the number of periods elapsed at the given time.
+func Counter(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code returns the code of the given base32-encoded secret for the given
+// counter.
+func Code(secret string, counter int64) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	bin := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, bin%mod), nil
+}
+
+// Validate checks the given code against the codes of the secret for the
+// periods around the given time. The counter of the matching period is
+// returned, which callers should record so that the code cannot be used again;
+// codes of periods at or before the given last counter are rejected.
+func Validate(secret string, code string, t time.Time,
+	last int64) (int64, bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, false, nil
+	}
+
+	now := Counter(t)
+	for c := now - Skew; c <= now+Skew; c++ {
+		if c <= last {
+			continue
+		}
+
+		expected, err := Code(secret, c)
+		if err != nil {
+			return 0, false, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return c, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// URI returns the provisioning URI of the given secret, to be encoded as a QR
+// code and scanned by authenticator apps.
+func URI(issuer string, account string, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprint(Digits))
+	v.Set("period", fmt.Sprint(int(Period/time.Second)))
+	return "otpauth://totp/" + label + "?" + v.Encode()
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	s := strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, err := encoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,75 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 secret of the test vectors of RFC 6238.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).
+	EncodeToString([]byte("12345678901234567890"))
+
+// TestCode tests that generated codes match the SHA1 test vectors of RFC
+// 6238, truncated to 6 digits.
+func TestCode(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, test := range tests {
+		code, err := Code(rfcSecret, Counter(time.Unix(test.unix, 0)))
+		if err != nil {
+			t.Fatalf("failed to generate code: %v", err)
+		}
+		if code != test.code {
+			t.Errorf("expected code %s at %d, got %s", test.code, test.unix, code)
+		}
+	}
+}
+
+// TestValidate tests that codes of adjacent periods are accepted and that
+// codes at or before the last used counter are rejected.
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	prev, _ := Code(rfcSecret, Counter(now)-1)
+
+	c, ok, err := Validate(rfcSecret, prev, now, 0)
+	if err != nil || !ok {
+		t.Fatalf("expected code of previous period to be accepted")
+	}
+	if c != Counter(now)-1 {
+		t.Fatalf("expected counter %d, got %d", Counter(now)-1, c)
+	}
+
+	_, ok, _ = Validate(rfcSecret, prev, now, c)
+	if ok {
+		t.Fatalf("expected used code to be rejected")
+	}
+
+	old, _ := Code(rfcSecret, Counter(now)-2)
+	_, ok, _ = Validate(rfcSecret, old, now, 0)
+	if ok {
+		t.Fatalf("expected code outside of skew to be rejected")
+	}
+}
+
+// TestURI tests that the provisioning URI contains the secret and issuer.
+func TestURI(t *testing.T) {
+	uri := URI("nao", "alice", "ABC")
+	if !strings.HasPrefix(uri, "otpauth://totp/nao:alice?") {
+		t.Fatalf("unexpected URI %q", uri)
+	}
+	if !strings.Contains(uri, "secret=ABC") || !strings.Contains(uri, "issuer=nao") {
+		t.Fatalf("expected secret and issuer in URI %q", uri)
+	}
+}
@@ -0,0 +1,113 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Dophin2009/nao/internal/jwt"
+	"github.com/Dophin2009/nao/pkg/models"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	// UserKey is the context key value for the authenticated User making the
+	// request.
+	UserKey = "UserKey"
+	// ClaimsKey is the context key value for the claims of the token the
+	// request was authenticated with.
+	ClaimsKey = "ClaimsKey"
+	// APITokenKey is the context key value for the API token the request was
+	// authenticated with, if not a JSON web token.
+	APITokenKey = "APITokenKey"
+
+	// HeaderAuthorization is a HTTP header name that contains the credentials
+	// of the request.
+	HeaderAuthorization = "Authorization"
+	// HeaderAuthorizationBearer is the prefix of the authorization header
+	// value for bearer tokens.
+	HeaderAuthorizationBearer = "Bearer "
+)
+
+// UserLookup retrieves the User identified by the given verified token claims.
+type UserLookup = func(claims *jwt.Claims) (*models.User, error)
+
+// APITokenLookup retrieves the User and the API token model identified by the
+// given API token.
+type APITokenLookup = func(tkn string) (*models.User, *models.APIToken, error)
+
+// Middleware wraps a HTTPReciever with additional logic.
+type Middleware = func(next HTTPReciever) HTTPReciever
+
+// AuthMiddleware returns a middleware that verifies the bearer token in the
+// Authorization header of requests and stores the authenticated User and the
+// token claims in the request context. Bearer tokens with the given API token
+// prefix are instead looked up as API tokens, which are stored in the context
+// in place of claims. Requests without an Authorization header are passed
+// through unauthenticated; requests with an invalid token are rejected.
+func AuthMiddleware(au *jwt.Authenticator, lookup UserLookup,
+	apiTokenPrefix string, apiTokenLookup APITokenLookup) Middleware {
+	return func(next HTTPReciever) HTTPReciever {
+		return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+			header := r.Header.Get(HeaderAuthorization)
+			if header == "" {
+				next(w, r, ps)
+				return
+			}
+
+			tknstr, err := ParseBearerToken(header)
+			if err != nil {
+				EncodeResponseErrorUnauthorized(ErrorAuthentication, err, w)
+				return
+			}
+
+			if strings.HasPrefix(tknstr, apiTokenPrefix) {
+				u, t, err := apiTokenLookup(tknstr)
+				if err != nil {
+					EncodeResponseErrorUnauthorized(ErrorAuthentication,
+						&AuthenticationError{Debug: err.Error()}, w)
+					return
+				}
+
+				ctx := context.WithValue(r.Context(), UserKey, u)
+				ctx = context.WithValue(ctx, APITokenKey, t)
+				next(w, r.WithContext(ctx), ps)
+				return
+			}
+
+			claims, err := au.Verify(tknstr)
+			if err != nil {
+				EncodeResponseErrorUnauthorized(ErrorAuthentication,
+					&AuthenticationError{Debug: err.Error()}, w)
+				return
+			}
+
+			u, err := lookup(claims)
+			if err != nil {
+				EncodeResponseErrorUnauthorized(ErrorAuthentication,
+					&AuthenticationError{Debug: err.Error()}, w)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), UserKey, u)
+			ctx = context.WithValue(ctx, ClaimsKey, claims)
+			next(w, r.WithContext(ctx), ps)
+		}
+	}
+}
+
+// ParseBearerToken returns the token in the given Authorization header value.
+func ParseBearerToken(header string) (string, error) {
+	if !strings.HasPrefix(header, HeaderAuthorizationBearer) {
+		return "", fmt.Errorf("authorization header: %w",
+			errors.New("not a bearer token"))
+	}
+
+	tknstr := strings.TrimSpace(strings.TrimPrefix(header, HeaderAuthorizationBearer))
+	if tknstr == "" {
+		return "", fmt.Errorf("bearer token: %w", errors.New("is empty"))
+	}
+	return tknstr, nil
+}
@@ -0,0 +1,146 @@
+package web
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// RemoteAddrKey is the context key value for the IP address of the client
+// making the request.
+const RemoteAddrKey = "RemoteAddrKey"
+
+// lockoutSweepSize is the number of tracked keys past which entries that no
+// longer affect lockout are removed.
+const lockoutSweepSize = 4096
+
+// Lockout tracks failed authentication attempts by key, such as a username
+// or IP address, and locks keys out after too many failures.
+type Lockout struct {
+	// MaxFailures is the number of failures within the window after which a
+	// key is locked out.
+	MaxFailures int
+	// Window is the duration failures are counted over.
+	Window time.Duration
+	// Duration is the duration a key is locked out for.
+	Duration time.Duration
+
+	entries map[string]*lockoutEntry
+	mu      sync.Mutex
+}
+
+type lockoutEntry struct {
+	failures    int
+	firstFailed time.Time
+	lockedUntil time.Time
+}
+
+// NewLockout returns a Lockout that locks keys out for the given duration
+// after the given number of failures within the given window.
+func NewLockout(maxFailures int, window time.Duration, duration time.Duration) *Lockout {
+	return &Lockout{
+		MaxFailures: maxFailures,
+		Window:      window,
+		Duration:    duration,
+		entries:     map[string]*lockoutEntry{},
+	}
+}
+
+// Locked returns the time until which the given key is locked out and true
+// if it is currently locked out.
+func (l *Lockout) Locked(key string) (time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[key]
+	if !ok {
+		return time.Time{}, false
+	}
+	if time.Now().Before(e.lockedUntil) {
+		return e.lockedUntil, true
+	}
+	return time.Time{}, false
+}
+
+// Fail records a failed attempt for the given key and returns true if the key
+// is now locked out.
+func (l *Lockout) Fail(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if len(l.entries) >= lockoutSweepSize {
+		l.sweep(now)
+	}
+
+	e, ok := l.entries[key]
+	if !ok || now.Sub(e.firstFailed) > l.Window {
+		e = &lockoutEntry{firstFailed: now}
+		l.entries[key] = e
+	}
+
+	e.failures++
+	if e.failures >= l.MaxFailures {
+		e.lockedUntil = now.Add(l.Duration)
+		// Failures are counted again from zero once the lockout ends
+		e.failures = 0
+		e.firstFailed = e.lockedUntil
+	}
+	return now.Before(e.lockedUntil)
+}
+
+// Reset clears the failed attempts of the given key.
+func (l *Lockout) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.entries, key)
+}
+
+// sweep removes entries that are neither locked out nor within the window.
+func (l *Lockout) sweep(now time.Time) {
+	for k, e := range l.entries {
+		if now.After(e.lockedUntil) && now.Sub(e.firstFailed) > l.Window {
+			delete(l.entries, k)
+		}
+	}
+}
+
+// RemoteAddrMiddleware returns a middleware that stores the IP address of the
+// client in the request context. The X-Forwarded-For header is only
+// respected if trustProxy is true.
+func RemoteAddrMiddleware(trustProxy bool) Middleware {
+	return func(next HTTPReciever) HTTPReciever {
+		return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+			ctx := context.WithValue(r.Context(), RemoteAddrKey,
+				RemoteAddr(r, trustProxy))
+			next(w, r.WithContext(ctx), ps)
+		}
+	}
+}
+
+// RemoteAddr returns the IP address of the client making the given request.
+// If trustProxy is true, the first address of the X-Forwarded-For header is
+// used if present.
+func RemoteAddr(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		fwd := r.Header.Get("X-Forwarded-For")
+		if fwd != "" {
+			if i := strings.IndexByte(fwd, ','); i >= 0 {
+				fwd = fwd[:i]
+			}
+			return strings.TrimSpace(fwd)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,44 @@
+package web
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLockout tests that keys are locked out after the maximum number of
+// failures and that resetting a key clears its failures.
+func TestLockout(t *testing.T) {
+	l := NewLockout(3, time.Minute, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if l.Fail("a") {
+			t.Fatalf("expected key to not be locked out after %d failures", i+1)
+		}
+	}
+	if !l.Fail("a") {
+		t.Fatalf("expected key to be locked out after 3 failures")
+	}
+	if _, locked := l.Locked("a"); !locked {
+		t.Fatalf("expected key to be locked out")
+	}
+	if _, locked := l.Locked("b"); locked {
+		t.Fatalf("expected other key to not be locked out")
+	}
+
+	l.Reset("a")
+	if _, locked := l.Locked("a"); locked {
+		t.Fatalf("expected key to not be locked out after reset")
+	}
+}
+
+// TestLockoutWindow tests that failures outside of the window are not
+// counted.
+func TestLockoutWindow(t *testing.T) {
+	l := NewLockout(2, 10*time.Millisecond, time.Minute)
+
+	l.Fail("a")
+	time.Sleep(20 * time.Millisecond)
+	if l.Fail("a") {
+		t.Fatalf("expected failure outside of window to not be counted")
+	}
+}
@@ -176,6 +176,14 @@ const (
 	// failed to authenticate.
 	ErrorAuthentication = "error authenticating user"
 
+	// ErrorForbidden is the generic error message given when the user does not
+	// have sufficient permissions.
+	ErrorForbidden = "insufficient permissions"
+
+	// ErrorNotFound is the generic error message given when the requested
+	// resource does not exist.
+	ErrorNotFound = "resource not found"
+
 	// ErrorPathVariableParsing is the generic error message given when some path
 	// variable could not be parsed properly.
 	ErrorPathVariableParsing = "error parsing path variable"
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 
@@ -265,9 +266,7 @@ func (db *BoltDatabase) Delete(id int, ser Service, tx Tx) error {
 		return fmt.Errorf("%s %q: %w", errmsgBucketOpen, ser.Bucket(), err)
 	}
 
-	m, err := db.GetByID(id, ser, tx)
-
-	// Store existing model to return
+	// Delete model
 	err = b.Delete(itob(id))
 	if err != nil {
 		return fmt.Errorf("failed to delete by id %d: %w", id, err)
@@ -325,6 +324,116 @@ func (db *BoltDatabase) GetRawByID(id int, ser Service, tx Tx) ([]byte, error) {
 	return v, nil
 }
 
+// GetIndex returns the ID mapped to the given key in the index bucket, or 0
+// if the key is not mapped.
+func (db *BoltDatabase) GetIndex(bucket string, key []byte, tx Tx) (int, error) {
+	// Get bucket, exit if error
+	b, err := db.Bucket(bucket, tx)
+	if err != nil {
+		return 0, fmt.Errorf("%s %q: %w", errmsgBucketOpen, bucket, err)
+	}
+
+	v := b.Get(key)
+	if v == nil {
+		return 0, nil
+	}
+	return btoi(v), nil
+}
+
+// PutIndex maps the given key to the ID in the index bucket.
+func (db *BoltDatabase) PutIndex(bucket string, key []byte, id int, tx Tx) error {
+	b, err := db.writableBucket(bucket, tx)
+	if err != nil {
+		return err
+	}
+
+	err = b.Put(key, itob(id))
+	if err != nil {
+		return fmt.Errorf("%s %q: %w", errmsgBucketPut, bucket, err)
+	}
+	return nil
+}
+
+// DeleteIndex removes the given key from the index bucket.
+func (db *BoltDatabase) DeleteIndex(bucket string, key []byte, tx Tx) error {
+	b, err := db.writableBucket(bucket, tx)
+	if err != nil {
+		return err
+	}
+
+	err = b.Delete(key)
+	if err != nil {
+		return fmt.Errorf("%s %q: %w", errmsgBucketDelete, bucket, err)
+	}
+	return nil
+}
+
+// ClearIndex removes all keys from the index bucket.
+func (db *BoltDatabase) ClearIndex(bucket string, tx Tx) error {
+	b, err := db.writableBucket(bucket, tx)
+	if err != nil {
+		return err
+	}
+
+	// Collect keys first; deleting while iterating invalidates the cursor
+	var keys [][]byte
+	err = b.ForEach(func(k, _ []byte) error {
+		keys = append(keys, append([]byte(nil), k...))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to iterate through keys: %w", err)
+	}
+
+	for _, k := range keys {
+		err = b.Delete(k)
+		if err != nil {
+			return fmt.Errorf("%s %q: %w", errmsgBucketDelete, bucket, err)
+		}
+	}
+	return nil
+}
+
+// ScanIndex calls do with each key that begins with the given prefix in the
+// index bucket and the ID mapped to it, in order of key, until do returns
+// true or an error.
+func (db *BoltDatabase) ScanIndex(bucket string, prefix []byte, tx Tx,
+	do func(key []byte, id int) (exit bool, err error)) error {
+	b, err := db.Bucket(bucket, tx)
+	if err != nil {
+		return fmt.Errorf("%s %q: %w", errmsgBucketOpen, bucket, err)
+	}
+
+	c := b.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		exit, err := do(k, btoi(v))
+		if err != nil {
+			return err
+		}
+		if exit {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (db *BoltDatabase) writableBucket(bucket string, tx Tx) (*bolt.Bucket, error) {
+	btx, err := db.unwrapTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !btx.Writable() {
+		return nil, errUnwritableTx
+	}
+
+	b, err := db.Bucket(bucket, tx)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q: %w", errmsgBucketOpen, bucket, err)
+	}
+	return b, nil
+}
+
 // DoMultiple unmarshals and performs some function on the persisted elements
 // that pass the given filter function specified by the given IDs.
 func (db *BoltDatabase) DoMultiple(ids []int, ser Service, tx Tx,
@@ -348,16 +457,8 @@ func (db *BoltDatabase) DoMultiple(ids []int, ser Service, tx Tx,
 		}
 	}
 
-	// Calculate start and end numbers
-	start, end := db.calculatePaginationBounds(nil, nil)
-
 	// Iterate through values
-	i := start
 	for _, id := range ids {
-		if i >= end {
-			break
-		}
-
 		m, err := db.GetByID(id, ser, tx)
 		if err != nil {
 			return fmt.Errorf("failed to get Model by id %d: %w", id, err)
@@ -372,7 +473,6 @@ func (db *BoltDatabase) DoMultiple(ids []int, ser Service, tx Tx,
 		if exit {
 			return err
 		}
-		i++
 	}
 
 	return nil
@@ -403,35 +503,22 @@ func (db *BoltDatabase) DoEach(first *int, skip *int, ser Service, tx Tx,
 	// Calculate start and end numbers
 	start, end := db.calculatePaginationBounds(first, skip)
 
-	// Get cursor for bucket
-	c := b.Cursor()
-
-	// Move cursor to starting element
-	var k, v []byte
-	c.First()
-	for i := 0; i < start; k, v = c.Next() {
-		if iff == nil {
-			i++
-		} else {
-			m, err := ser.Unmarshal(v)
-			if err != nil {
-				return fmt.Errorf("%s: %w", errmsgModelUnmarshal, err)
-			}
-
-			if iff(m) {
-				i++
-			}
-		}
-	}
-
+	// If filter function is nil, filter nothing
 	if iff == nil {
 		iff = func(_ Model) bool {
 			return true
 		}
 	}
 
-	// Iterate until end is reached
-	for i := start; i < end && k != nil; k, v = c.Next() {
+	// Iterate until end is reached, counting only elements that pass the
+	// filter
+	c := b.Cursor()
+	i := 0
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if end >= 0 && i >= end {
+			break
+		}
+
 		// Unmarshal element
 		m, err := ser.Unmarshal(v)
 		if err != nil {
@@ -443,9 +530,12 @@ func (db *BoltDatabase) DoEach(first *int, skip *int, ser Service, tx Tx,
 			continue
 		}
 
-		exit, err := do(m, ser, tx)
-		if exit {
-			return err
+		// Skip elements before start
+		if i >= start {
+			exit, err := do(m, ser, tx)
+			if exit {
+				return err
+			}
 		}
 		i++
 	}
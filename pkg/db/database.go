@@ -203,11 +203,20 @@ func (dbs *DatabaseService) DeleteMultiple(ids []int, first *int,
 // that pass the filer function.
 func (dbs *DatabaseService) DeleteFilter(ser Service, tx Tx,
 	iff func(Model) bool) error {
-	err := dbs.DoEach(nil, nil, ser, tx, dbs.deleteWrapper(), iff)
+	// Collect matching IDs first; deleting while iterating through the bucket
+	// invalidates the cursor
+	list, err := dbs.GetFilter(nil, nil, ser, tx, iff)
 	if err != nil {
 		return err
 	}
 
+	for _, m := range list {
+		err = dbs.Delete(m.Metadata().ID, ser, tx)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -292,6 +301,21 @@ type DatabaseDriver interface {
 	Delete(id int, ser Service, tx Tx) error
 	GetByID(id int, ser Service, tx Tx) (Model, error)
 	GetRawByID(id int, ser Service, tx Tx) ([]byte, error)
+
+	// GetIndex returns the ID mapped to the given key in the index bucket, or
+	// 0 if the key is not mapped.
+	GetIndex(bucket string, key []byte, tx Tx) (int, error)
+	// PutIndex maps the given key to the ID in the index bucket.
+	PutIndex(bucket string, key []byte, id int, tx Tx) error
+	// DeleteIndex removes the given key from the index bucket.
+	DeleteIndex(bucket string, key []byte, tx Tx) error
+	// ClearIndex removes all keys from the index bucket.
+	ClearIndex(bucket string, tx Tx) error
+	// ScanIndex calls do with each key that begins with the given prefix in
+	// the index bucket and the ID mapped to it, in order of key, until do
+	// returns true or an error.
+	ScanIndex(bucket string, prefix []byte, tx Tx,
+		do func(key []byte, id int) (exit bool, err error)) error
 }
 
 // Tx defines a wrapper for database transactions objects.
@@ -340,3 +364,7 @@ func itob(v int) []byte {
 	binary.BigEndian.PutUint64(b, uint64(v))
 	return b
 }
+
+func btoi(b []byte) int {
+	return int(binary.BigEndian.Uint64(b))
+}
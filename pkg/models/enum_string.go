@@ -0,0 +1,1338 @@
+// Code generated by "enumgen -type WatchStatus,Quarter,TitlePriority,Role,Action,Visibility,ScoreSystem,MediaKind,MediaType,MediaSource,Weekday,SeasonSort -output enum_string.go -test enum_string_test.go"; DO NOT EDIT.
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WatchStatusValues returns every valid WatchStatus in order of declaration.
+func WatchStatusValues() []WatchStatus {
+	return []WatchStatus{WatchStatusCurrent, WatchStatusCompleted, WatchStatusPlanning, WatchStatusDropped, WatchStatusHold}
+}
+
+// ParseWatchStatus returns the WatchStatus with the given written name.
+func ParseWatchStatus(s string) (WatchStatus, error) {
+	switch s {
+	case "Current":
+		return WatchStatusCurrent, nil
+	case "Completed":
+		return WatchStatusCompleted, nil
+	case "Planning":
+		return WatchStatusPlanning, nil
+	case "Dropped":
+		return WatchStatusDropped, nil
+	case "Hold":
+		return WatchStatusHold, nil
+	}
+	return 0, fmt.Errorf("invalid WatchStatus: %q", s)
+}
+
+// IsValid checks if the WatchStatus has a value that is a valid one.
+func (v WatchStatus) IsValid() bool {
+	switch v {
+	case WatchStatusCurrent, WatchStatusCompleted, WatchStatusPlanning, WatchStatusDropped, WatchStatusHold:
+		return true
+	}
+	return false
+}
+
+// String returns the written name of the WatchStatus.
+func (v WatchStatus) String() string {
+	switch v {
+	case WatchStatusCurrent:
+		return "Current"
+	case WatchStatusCompleted:
+		return "Completed"
+	case WatchStatusPlanning:
+		return "Planning"
+	case WatchStatusDropped:
+		return "Dropped"
+	case WatchStatusHold:
+		return "Hold"
+	}
+	return fmt.Sprintf("%d", int(v))
+}
+
+// MarshalText encodes the WatchStatus as its written name.
+func (v WatchStatus) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid WatchStatus: %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes the WatchStatus from its written name.
+func (v *WatchStatus) UnmarshalText(text []byte) error {
+	p, err := ParseWatchStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*v = p
+	return nil
+}
+
+// MarshalJSON encodes the WatchStatus as a JSON string of its written name.
+func (v WatchStatus) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes the WatchStatus from a JSON string of its written name or,
+// as persisted by earlier versions, from its integer value.
+func (v *WatchStatus) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+
+	var i int
+	err = json.Unmarshal(data, &i)
+	if err != nil || !WatchStatus(i).IsValid() {
+		return fmt.Errorf("invalid WatchStatus: %s", data)
+	}
+	*v = WatchStatus(i)
+	return nil
+}
+
+// UnmarshalGQL casts the type of the given value to a WatchStatus.
+func (v *WatchStatus) UnmarshalGQL(i interface{}) error {
+	s, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", i)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// MarshalGQL serializes the WatchStatus into a GraphQL readable form.
+func (v WatchStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(v.String()))
+}
+
+// QuarterValues returns every valid Quarter in order of declaration.
+func QuarterValues() []Quarter {
+	return []Quarter{QuarterWinter, QuarterSpring, QuarterSummer, QuarterFall}
+}
+
+// ParseQuarter returns the Quarter with the given written name.
+func ParseQuarter(s string) (Quarter, error) {
+	switch s {
+	case "Winter":
+		return QuarterWinter, nil
+	case "Spring":
+		return QuarterSpring, nil
+	case "Summer":
+		return QuarterSummer, nil
+	case "Fall":
+		return QuarterFall, nil
+	}
+	return 0, fmt.Errorf("invalid Quarter: %q", s)
+}
+
+// IsValid checks if the Quarter has a value that is a valid one.
+func (v Quarter) IsValid() bool {
+	switch v {
+	case QuarterWinter, QuarterSpring, QuarterSummer, QuarterFall:
+		return true
+	}
+	return false
+}
+
+// String returns the written name of the Quarter.
+func (v Quarter) String() string {
+	switch v {
+	case QuarterWinter:
+		return "Winter"
+	case QuarterSpring:
+		return "Spring"
+	case QuarterSummer:
+		return "Summer"
+	case QuarterFall:
+		return "Fall"
+	}
+	return fmt.Sprintf("%d", int(v))
+}
+
+// MarshalText encodes the Quarter as its written name.
+func (v Quarter) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid Quarter: %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes the Quarter from its written name.
+func (v *Quarter) UnmarshalText(text []byte) error {
+	p, err := ParseQuarter(string(text))
+	if err != nil {
+		return err
+	}
+	*v = p
+	return nil
+}
+
+// MarshalJSON encodes the Quarter as a JSON string of its written name.
+func (v Quarter) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes the Quarter from a JSON string of its written name or,
+// as persisted by earlier versions, from its integer value.
+func (v *Quarter) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+
+	var i int
+	err = json.Unmarshal(data, &i)
+	if err != nil || !Quarter(i).IsValid() {
+		return fmt.Errorf("invalid Quarter: %s", data)
+	}
+	*v = Quarter(i)
+	return nil
+}
+
+// UnmarshalGQL casts the type of the given value to a Quarter.
+func (v *Quarter) UnmarshalGQL(i interface{}) error {
+	s, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", i)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// MarshalGQL serializes the Quarter into a GraphQL readable form.
+func (v Quarter) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(v.String()))
+}
+
+// TitlePriorityValues returns every valid TitlePriority in order of declaration.
+func TitlePriorityValues() []TitlePriority {
+	return []TitlePriority{TitlePriorityPrimary, TitlePrioritySecondary, TitlePriorityOther}
+}
+
+// ParseTitlePriority returns the TitlePriority with the given written name.
+func ParseTitlePriority(s string) (TitlePriority, error) {
+	switch s {
+	case "Primary":
+		return TitlePriorityPrimary, nil
+	case "Secondary":
+		return TitlePrioritySecondary, nil
+	case "Other":
+		return TitlePriorityOther, nil
+	}
+	return 0, fmt.Errorf("invalid TitlePriority: %q", s)
+}
+
+// IsValid checks if the TitlePriority has a value that is a valid one.
+func (v TitlePriority) IsValid() bool {
+	switch v {
+	case TitlePriorityPrimary, TitlePrioritySecondary, TitlePriorityOther:
+		return true
+	}
+	return false
+}
+
+// String returns the written name of the TitlePriority.
+func (v TitlePriority) String() string {
+	switch v {
+	case TitlePriorityPrimary:
+		return "Primary"
+	case TitlePrioritySecondary:
+		return "Secondary"
+	case TitlePriorityOther:
+		return "Other"
+	}
+	return fmt.Sprintf("%d", int(v))
+}
+
+// MarshalText encodes the TitlePriority as its written name.
+func (v TitlePriority) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid TitlePriority: %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes the TitlePriority from its written name.
+func (v *TitlePriority) UnmarshalText(text []byte) error {
+	p, err := ParseTitlePriority(string(text))
+	if err != nil {
+		return err
+	}
+	*v = p
+	return nil
+}
+
+// MarshalJSON encodes the TitlePriority as a JSON string of its written name.
+func (v TitlePriority) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes the TitlePriority from a JSON string of its written name or,
+// as persisted by earlier versions, from its integer value.
+func (v *TitlePriority) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+
+	var i int
+	err = json.Unmarshal(data, &i)
+	if err != nil || !TitlePriority(i).IsValid() {
+		return fmt.Errorf("invalid TitlePriority: %s", data)
+	}
+	*v = TitlePriority(i)
+	return nil
+}
+
+// UnmarshalGQL casts the type of the given value to a TitlePriority.
+func (v *TitlePriority) UnmarshalGQL(i interface{}) error {
+	s, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", i)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// MarshalGQL serializes the TitlePriority into a GraphQL readable form.
+func (v TitlePriority) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(v.String()))
+}
+
+// RoleValues returns every valid Role in order of declaration.
+func RoleValues() []Role {
+	return []Role{RoleViewer, RoleContributor, RoleModerator, RoleAdmin}
+}
+
+// ParseRole returns the Role with the given written name.
+func ParseRole(s string) (Role, error) {
+	switch s {
+	case "Viewer":
+		return RoleViewer, nil
+	case "Contributor":
+		return RoleContributor, nil
+	case "Moderator":
+		return RoleModerator, nil
+	case "Admin":
+		return RoleAdmin, nil
+	}
+	return 0, fmt.Errorf("invalid Role: %q", s)
+}
+
+// IsValid checks if the Role has a value that is a valid one.
+func (v Role) IsValid() bool {
+	switch v {
+	case RoleViewer, RoleContributor, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// String returns the written name of the Role.
+func (v Role) String() string {
+	switch v {
+	case RoleViewer:
+		return "Viewer"
+	case RoleContributor:
+		return "Contributor"
+	case RoleModerator:
+		return "Moderator"
+	case RoleAdmin:
+		return "Admin"
+	}
+	return fmt.Sprintf("%d", int(v))
+}
+
+// MarshalText encodes the Role as its written name.
+func (v Role) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid Role: %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes the Role from its written name.
+func (v *Role) UnmarshalText(text []byte) error {
+	p, err := ParseRole(string(text))
+	if err != nil {
+		return err
+	}
+	*v = p
+	return nil
+}
+
+// MarshalJSON encodes the Role as a JSON string of its written name.
+func (v Role) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes the Role from a JSON string of its written name or,
+// as persisted by earlier versions, from its integer value.
+func (v *Role) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+
+	var i int
+	err = json.Unmarshal(data, &i)
+	if err != nil || !Role(i).IsValid() {
+		return fmt.Errorf("invalid Role: %s", data)
+	}
+	*v = Role(i)
+	return nil
+}
+
+// UnmarshalGQL casts the type of the given value to a Role.
+func (v *Role) UnmarshalGQL(i interface{}) error {
+	s, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", i)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// MarshalGQL serializes the Role into a GraphQL readable form.
+func (v Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(v.String()))
+}
+
+// ActionValues returns every valid Action in order of declaration.
+func ActionValues() []Action {
+	return []Action{ActionRead, ActionCreate, ActionUpdate, ActionDelete}
+}
+
+// ParseAction returns the Action with the given written name.
+func ParseAction(s string) (Action, error) {
+	switch s {
+	case "Read":
+		return ActionRead, nil
+	case "Create":
+		return ActionCreate, nil
+	case "Update":
+		return ActionUpdate, nil
+	case "Delete":
+		return ActionDelete, nil
+	}
+	return 0, fmt.Errorf("invalid Action: %q", s)
+}
+
+// IsValid checks if the Action has a value that is a valid one.
+func (v Action) IsValid() bool {
+	switch v {
+	case ActionRead, ActionCreate, ActionUpdate, ActionDelete:
+		return true
+	}
+	return false
+}
+
+// String returns the written name of the Action.
+func (v Action) String() string {
+	switch v {
+	case ActionRead:
+		return "Read"
+	case ActionCreate:
+		return "Create"
+	case ActionUpdate:
+		return "Update"
+	case ActionDelete:
+		return "Delete"
+	}
+	return fmt.Sprintf("%d", int(v))
+}
+
+// MarshalText encodes the Action as its written name.
+func (v Action) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid Action: %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes the Action from its written name.
+func (v *Action) UnmarshalText(text []byte) error {
+	p, err := ParseAction(string(text))
+	if err != nil {
+		return err
+	}
+	*v = p
+	return nil
+}
+
+// MarshalJSON encodes the Action as a JSON string of its written name.
+func (v Action) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes the Action from a JSON string of its written name or,
+// as persisted by earlier versions, from its integer value.
+func (v *Action) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+
+	var i int
+	err = json.Unmarshal(data, &i)
+	if err != nil || !Action(i).IsValid() {
+		return fmt.Errorf("invalid Action: %s", data)
+	}
+	*v = Action(i)
+	return nil
+}
+
+// UnmarshalGQL casts the type of the given value to a Action.
+func (v *Action) UnmarshalGQL(i interface{}) error {
+	s, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", i)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// MarshalGQL serializes the Action into a GraphQL readable form.
+func (v Action) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(v.String()))
+}
+
+// VisibilityValues returns every valid Visibility in order of declaration.
+func VisibilityValues() []Visibility {
+	return []Visibility{VisibilityPublic, VisibilityFriends, VisibilityPrivate}
+}
+
+// ParseVisibility returns the Visibility with the given written name.
+func ParseVisibility(s string) (Visibility, error) {
+	switch s {
+	case "Public":
+		return VisibilityPublic, nil
+	case "Friends":
+		return VisibilityFriends, nil
+	case "Private":
+		return VisibilityPrivate, nil
+	}
+	return 0, fmt.Errorf("invalid Visibility: %q", s)
+}
+
+// IsValid checks if the Visibility has a value that is a valid one.
+func (v Visibility) IsValid() bool {
+	switch v {
+	case VisibilityPublic, VisibilityFriends, VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+// String returns the written name of the Visibility.
+func (v Visibility) String() string {
+	switch v {
+	case VisibilityPublic:
+		return "Public"
+	case VisibilityFriends:
+		return "Friends"
+	case VisibilityPrivate:
+		return "Private"
+	}
+	return fmt.Sprintf("%d", int(v))
+}
+
+// MarshalText encodes the Visibility as its written name.
+func (v Visibility) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid Visibility: %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes the Visibility from its written name.
+func (v *Visibility) UnmarshalText(text []byte) error {
+	p, err := ParseVisibility(string(text))
+	if err != nil {
+		return err
+	}
+	*v = p
+	return nil
+}
+
+// MarshalJSON encodes the Visibility as a JSON string of its written name.
+func (v Visibility) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes the Visibility from a JSON string of its written name or,
+// as persisted by earlier versions, from its integer value.
+func (v *Visibility) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+
+	var i int
+	err = json.Unmarshal(data, &i)
+	if err != nil || !Visibility(i).IsValid() {
+		return fmt.Errorf("invalid Visibility: %s", data)
+	}
+	*v = Visibility(i)
+	return nil
+}
+
+// UnmarshalGQL casts the type of the given value to a Visibility.
+func (v *Visibility) UnmarshalGQL(i interface{}) error {
+	s, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", i)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// MarshalGQL serializes the Visibility into a GraphQL readable form.
+func (v Visibility) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(v.String()))
+}
+
+// ScoreSystemValues returns every valid ScoreSystem in order of declaration.
+func ScoreSystemValues() []ScoreSystem {
+	return []ScoreSystem{ScoreSystemPoint100, ScoreSystemPoint10, ScoreSystemPoint10Decimal, ScoreSystemStar5, ScoreSystemSmiley3}
+}
+
+// ParseScoreSystem returns the ScoreSystem with the given written name.
+func ParseScoreSystem(s string) (ScoreSystem, error) {
+	switch s {
+	case "Point100":
+		return ScoreSystemPoint100, nil
+	case "Point10":
+		return ScoreSystemPoint10, nil
+	case "Point10Decimal":
+		return ScoreSystemPoint10Decimal, nil
+	case "Star5":
+		return ScoreSystemStar5, nil
+	case "Smiley3":
+		return ScoreSystemSmiley3, nil
+	}
+	return 0, fmt.Errorf("invalid ScoreSystem: %q", s)
+}
+
+// IsValid checks if the ScoreSystem has a value that is a valid one.
+func (v ScoreSystem) IsValid() bool {
+	switch v {
+	case ScoreSystemPoint100, ScoreSystemPoint10, ScoreSystemPoint10Decimal, ScoreSystemStar5, ScoreSystemSmiley3:
+		return true
+	}
+	return false
+}
+
+// String returns the written name of the ScoreSystem.
+func (v ScoreSystem) String() string {
+	switch v {
+	case ScoreSystemPoint100:
+		return "Point100"
+	case ScoreSystemPoint10:
+		return "Point10"
+	case ScoreSystemPoint10Decimal:
+		return "Point10Decimal"
+	case ScoreSystemStar5:
+		return "Star5"
+	case ScoreSystemSmiley3:
+		return "Smiley3"
+	}
+	return fmt.Sprintf("%d", int(v))
+}
+
+// MarshalText encodes the ScoreSystem as its written name.
+func (v ScoreSystem) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid ScoreSystem: %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes the ScoreSystem from its written name.
+func (v *ScoreSystem) UnmarshalText(text []byte) error {
+	p, err := ParseScoreSystem(string(text))
+	if err != nil {
+		return err
+	}
+	*v = p
+	return nil
+}
+
+// MarshalJSON encodes the ScoreSystem as a JSON string of its written name.
+func (v ScoreSystem) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes the ScoreSystem from a JSON string of its written name or,
+// as persisted by earlier versions, from its integer value.
+func (v *ScoreSystem) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+
+	var i int
+	err = json.Unmarshal(data, &i)
+	if err != nil || !ScoreSystem(i).IsValid() {
+		return fmt.Errorf("invalid ScoreSystem: %s", data)
+	}
+	*v = ScoreSystem(i)
+	return nil
+}
+
+// UnmarshalGQL casts the type of the given value to a ScoreSystem.
+func (v *ScoreSystem) UnmarshalGQL(i interface{}) error {
+	s, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", i)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// MarshalGQL serializes the ScoreSystem into a GraphQL readable form.
+func (v ScoreSystem) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(v.String()))
+}
+
+// MediaKindValues returns every valid MediaKind in order of declaration.
+func MediaKindValues() []MediaKind {
+	return []MediaKind{MediaKindAnime, MediaKindManga}
+}
+
+// ParseMediaKind returns the MediaKind with the given written name.
+func ParseMediaKind(s string) (MediaKind, error) {
+	switch s {
+	case "Anime":
+		return MediaKindAnime, nil
+	case "Manga":
+		return MediaKindManga, nil
+	}
+	return 0, fmt.Errorf("invalid MediaKind: %q", s)
+}
+
+// IsValid checks if the MediaKind has a value that is a valid one.
+func (v MediaKind) IsValid() bool {
+	switch v {
+	case MediaKindAnime, MediaKindManga:
+		return true
+	}
+	return false
+}
+
+// String returns the written name of the MediaKind.
+func (v MediaKind) String() string {
+	switch v {
+	case MediaKindAnime:
+		return "Anime"
+	case MediaKindManga:
+		return "Manga"
+	}
+	return fmt.Sprintf("%d", int(v))
+}
+
+// MarshalText encodes the MediaKind as its written name.
+func (v MediaKind) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid MediaKind: %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes the MediaKind from its written name.
+func (v *MediaKind) UnmarshalText(text []byte) error {
+	p, err := ParseMediaKind(string(text))
+	if err != nil {
+		return err
+	}
+	*v = p
+	return nil
+}
+
+// MarshalJSON encodes the MediaKind as a JSON string of its written name.
+func (v MediaKind) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes the MediaKind from a JSON string of its written name or,
+// as persisted by earlier versions, from its integer value.
+func (v *MediaKind) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+
+	var i int
+	err = json.Unmarshal(data, &i)
+	if err != nil || !MediaKind(i).IsValid() {
+		return fmt.Errorf("invalid MediaKind: %s", data)
+	}
+	*v = MediaKind(i)
+	return nil
+}
+
+// UnmarshalGQL casts the type of the given value to a MediaKind.
+func (v *MediaKind) UnmarshalGQL(i interface{}) error {
+	s, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", i)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// MarshalGQL serializes the MediaKind into a GraphQL readable form.
+func (v MediaKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(v.String()))
+}
+
+// MediaTypeValues returns every valid MediaType in order of declaration.
+func MediaTypeValues() []MediaType {
+	return []MediaType{MediaTypeTV, MediaTypeTVShort, MediaTypeMovie, MediaTypeSpecial, MediaTypeOVA, MediaTypeONA, MediaTypeMusic, MediaTypeManga, MediaTypeManhwa, MediaTypeManhua, MediaTypeOneShot, MediaTypeDoujinshi, MediaTypeLightNovel, MediaTypeNovel}
+}
+
+// ParseMediaType returns the MediaType with the given written name.
+func ParseMediaType(s string) (MediaType, error) {
+	switch s {
+	case "TV":
+		return MediaTypeTV, nil
+	case "TVShort":
+		return MediaTypeTVShort, nil
+	case "Movie":
+		return MediaTypeMovie, nil
+	case "Special":
+		return MediaTypeSpecial, nil
+	case "OVA":
+		return MediaTypeOVA, nil
+	case "ONA":
+		return MediaTypeONA, nil
+	case "Music":
+		return MediaTypeMusic, nil
+	case "Manga":
+		return MediaTypeManga, nil
+	case "Manhwa":
+		return MediaTypeManhwa, nil
+	case "Manhua":
+		return MediaTypeManhua, nil
+	case "OneShot":
+		return MediaTypeOneShot, nil
+	case "Doujinshi":
+		return MediaTypeDoujinshi, nil
+	case "LightNovel":
+		return MediaTypeLightNovel, nil
+	case "Novel":
+		return MediaTypeNovel, nil
+	}
+	return 0, fmt.Errorf("invalid MediaType: %q", s)
+}
+
+// IsValid checks if the MediaType has a value that is a valid one.
+func (v MediaType) IsValid() bool {
+	switch v {
+	case MediaTypeTV, MediaTypeTVShort, MediaTypeMovie, MediaTypeSpecial, MediaTypeOVA, MediaTypeONA, MediaTypeMusic, MediaTypeManga, MediaTypeManhwa, MediaTypeManhua, MediaTypeOneShot, MediaTypeDoujinshi, MediaTypeLightNovel, MediaTypeNovel:
+		return true
+	}
+	return false
+}
+
+// String returns the written name of the MediaType.
+func (v MediaType) String() string {
+	switch v {
+	case MediaTypeTV:
+		return "TV"
+	case MediaTypeTVShort:
+		return "TVShort"
+	case MediaTypeMovie:
+		return "Movie"
+	case MediaTypeSpecial:
+		return "Special"
+	case MediaTypeOVA:
+		return "OVA"
+	case MediaTypeONA:
+		return "ONA"
+	case MediaTypeMusic:
+		return "Music"
+	case MediaTypeManga:
+		return "Manga"
+	case MediaTypeManhwa:
+		return "Manhwa"
+	case MediaTypeManhua:
+		return "Manhua"
+	case MediaTypeOneShot:
+		return "OneShot"
+	case MediaTypeDoujinshi:
+		return "Doujinshi"
+	case MediaTypeLightNovel:
+		return "LightNovel"
+	case MediaTypeNovel:
+		return "Novel"
+	}
+	return fmt.Sprintf("%d", int(v))
+}
+
+// MarshalText encodes the MediaType as its written name.
+func (v MediaType) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid MediaType: %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes the MediaType from its written name.
+func (v *MediaType) UnmarshalText(text []byte) error {
+	p, err := ParseMediaType(string(text))
+	if err != nil {
+		return err
+	}
+	*v = p
+	return nil
+}
+
+// MarshalJSON encodes the MediaType as a JSON string of its written name.
+func (v MediaType) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes the MediaType from a JSON string of its written name or,
+// as persisted by earlier versions, from its integer value.
+func (v *MediaType) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+
+	var i int
+	err = json.Unmarshal(data, &i)
+	if err != nil || !MediaType(i).IsValid() {
+		return fmt.Errorf("invalid MediaType: %s", data)
+	}
+	*v = MediaType(i)
+	return nil
+}
+
+// UnmarshalGQL casts the type of the given value to a MediaType.
+func (v *MediaType) UnmarshalGQL(i interface{}) error {
+	s, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", i)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// MarshalGQL serializes the MediaType into a GraphQL readable form.
+func (v MediaType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(v.String()))
+}
+
+// MediaSourceValues returns every valid MediaSource in order of declaration.
+func MediaSourceValues() []MediaSource {
+	return []MediaSource{MediaSourceOriginal, MediaSourceManga, MediaSourceWebManga, MediaSourceFourKomaManga, MediaSourceLightNovel, MediaSourceWebNovel, MediaSourceNovel, MediaSourceVisualNovel, MediaSourceVideoGame, MediaSourceCardGame, MediaSourceBook, MediaSourcePictureBook, MediaSourceAnime, MediaSourceDoujinshi, MediaSourceMusic, MediaSourceRadio, MediaSourceOther}
+}
+
+// ParseMediaSource returns the MediaSource with the given written name.
+func ParseMediaSource(s string) (MediaSource, error) {
+	switch s {
+	case "Original":
+		return MediaSourceOriginal, nil
+	case "Manga":
+		return MediaSourceManga, nil
+	case "WebManga":
+		return MediaSourceWebManga, nil
+	case "FourKomaManga":
+		return MediaSourceFourKomaManga, nil
+	case "LightNovel":
+		return MediaSourceLightNovel, nil
+	case "WebNovel":
+		return MediaSourceWebNovel, nil
+	case "Novel":
+		return MediaSourceNovel, nil
+	case "VisualNovel":
+		return MediaSourceVisualNovel, nil
+	case "VideoGame":
+		return MediaSourceVideoGame, nil
+	case "CardGame":
+		return MediaSourceCardGame, nil
+	case "Book":
+		return MediaSourceBook, nil
+	case "PictureBook":
+		return MediaSourcePictureBook, nil
+	case "Anime":
+		return MediaSourceAnime, nil
+	case "Doujinshi":
+		return MediaSourceDoujinshi, nil
+	case "Music":
+		return MediaSourceMusic, nil
+	case "Radio":
+		return MediaSourceRadio, nil
+	case "Other":
+		return MediaSourceOther, nil
+	}
+	return 0, fmt.Errorf("invalid MediaSource: %q", s)
+}
+
+// IsValid checks if the MediaSource has a value that is a valid one.
+func (v MediaSource) IsValid() bool {
+	switch v {
+	case MediaSourceOriginal, MediaSourceManga, MediaSourceWebManga, MediaSourceFourKomaManga, MediaSourceLightNovel, MediaSourceWebNovel, MediaSourceNovel, MediaSourceVisualNovel, MediaSourceVideoGame, MediaSourceCardGame, MediaSourceBook, MediaSourcePictureBook, MediaSourceAnime, MediaSourceDoujinshi, MediaSourceMusic, MediaSourceRadio, MediaSourceOther:
+		return true
+	}
+	return false
+}
+
+// String returns the written name of the MediaSource.
+func (v MediaSource) String() string {
+	switch v {
+	case MediaSourceOriginal:
+		return "Original"
+	case MediaSourceManga:
+		return "Manga"
+	case MediaSourceWebManga:
+		return "WebManga"
+	case MediaSourceFourKomaManga:
+		return "FourKomaManga"
+	case MediaSourceLightNovel:
+		return "LightNovel"
+	case MediaSourceWebNovel:
+		return "WebNovel"
+	case MediaSourceNovel:
+		return "Novel"
+	case MediaSourceVisualNovel:
+		return "VisualNovel"
+	case MediaSourceVideoGame:
+		return "VideoGame"
+	case MediaSourceCardGame:
+		return "CardGame"
+	case MediaSourceBook:
+		return "Book"
+	case MediaSourcePictureBook:
+		return "PictureBook"
+	case MediaSourceAnime:
+		return "Anime"
+	case MediaSourceDoujinshi:
+		return "Doujinshi"
+	case MediaSourceMusic:
+		return "Music"
+	case MediaSourceRadio:
+		return "Radio"
+	case MediaSourceOther:
+		return "Other"
+	}
+	return fmt.Sprintf("%d", int(v))
+}
+
+// MarshalText encodes the MediaSource as its written name.
+func (v MediaSource) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid MediaSource: %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes the MediaSource from its written name.
+func (v *MediaSource) UnmarshalText(text []byte) error {
+	p, err := ParseMediaSource(string(text))
+	if err != nil {
+		return err
+	}
+	*v = p
+	return nil
+}
+
+// MarshalJSON encodes the MediaSource as a JSON string of its written name.
+func (v MediaSource) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes the MediaSource from a JSON string of its written name or,
+// as persisted by earlier versions, from its integer value.
+func (v *MediaSource) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+
+	var i int
+	err = json.Unmarshal(data, &i)
+	if err != nil || !MediaSource(i).IsValid() {
+		return fmt.Errorf("invalid MediaSource: %s", data)
+	}
+	*v = MediaSource(i)
+	return nil
+}
+
+// UnmarshalGQL casts the type of the given value to a MediaSource.
+func (v *MediaSource) UnmarshalGQL(i interface{}) error {
+	s, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", i)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// MarshalGQL serializes the MediaSource into a GraphQL readable form.
+func (v MediaSource) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(v.String()))
+}
+
+// WeekdayValues returns every valid Weekday in order of declaration.
+func WeekdayValues() []Weekday {
+	return []Weekday{WeekdaySunday, WeekdayMonday, WeekdayTuesday, WeekdayWednesday, WeekdayThursday, WeekdayFriday, WeekdaySaturday}
+}
+
+// ParseWeekday returns the Weekday with the given written name.
+func ParseWeekday(s string) (Weekday, error) {
+	switch s {
+	case "Sunday":
+		return WeekdaySunday, nil
+	case "Monday":
+		return WeekdayMonday, nil
+	case "Tuesday":
+		return WeekdayTuesday, nil
+	case "Wednesday":
+		return WeekdayWednesday, nil
+	case "Thursday":
+		return WeekdayThursday, nil
+	case "Friday":
+		return WeekdayFriday, nil
+	case "Saturday":
+		return WeekdaySaturday, nil
+	}
+	return 0, fmt.Errorf("invalid Weekday: %q", s)
+}
+
+// IsValid checks if the Weekday has a value that is a valid one.
+func (v Weekday) IsValid() bool {
+	switch v {
+	case WeekdaySunday, WeekdayMonday, WeekdayTuesday, WeekdayWednesday, WeekdayThursday, WeekdayFriday, WeekdaySaturday:
+		return true
+	}
+	return false
+}
+
+// String returns the written name of the Weekday.
+func (v Weekday) String() string {
+	switch v {
+	case WeekdaySunday:
+		return "Sunday"
+	case WeekdayMonday:
+		return "Monday"
+	case WeekdayTuesday:
+		return "Tuesday"
+	case WeekdayWednesday:
+		return "Wednesday"
+	case WeekdayThursday:
+		return "Thursday"
+	case WeekdayFriday:
+		return "Friday"
+	case WeekdaySaturday:
+		return "Saturday"
+	}
+	return fmt.Sprintf("%d", int(v))
+}
+
+// MarshalText encodes the Weekday as its written name.
+func (v Weekday) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid Weekday: %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes the Weekday from its written name.
+func (v *Weekday) UnmarshalText(text []byte) error {
+	p, err := ParseWeekday(string(text))
+	if err != nil {
+		return err
+	}
+	*v = p
+	return nil
+}
+
+// MarshalJSON encodes the Weekday as a JSON string of its written name.
+func (v Weekday) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes the Weekday from a JSON string of its written name or,
+// as persisted by earlier versions, from its integer value.
+func (v *Weekday) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+
+	var i int
+	err = json.Unmarshal(data, &i)
+	if err != nil || !Weekday(i).IsValid() {
+		return fmt.Errorf("invalid Weekday: %s", data)
+	}
+	*v = Weekday(i)
+	return nil
+}
+
+// UnmarshalGQL casts the type of the given value to a Weekday.
+func (v *Weekday) UnmarshalGQL(i interface{}) error {
+	s, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", i)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// MarshalGQL serializes the Weekday into a GraphQL readable form.
+func (v Weekday) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(v.String()))
+}
+
+// SeasonSortValues returns every valid SeasonSort in order of declaration.
+func SeasonSortValues() []SeasonSort {
+	return []SeasonSort{SeasonSortPopularity, SeasonSortTitle, SeasonSortStartDate}
+}
+
+// ParseSeasonSort returns the SeasonSort with the given written name.
+func ParseSeasonSort(s string) (SeasonSort, error) {
+	switch s {
+	case "Popularity":
+		return SeasonSortPopularity, nil
+	case "Title":
+		return SeasonSortTitle, nil
+	case "StartDate":
+		return SeasonSortStartDate, nil
+	}
+	return 0, fmt.Errorf("invalid SeasonSort: %q", s)
+}
+
+// IsValid checks if the SeasonSort has a value that is a valid one.
+func (v SeasonSort) IsValid() bool {
+	switch v {
+	case SeasonSortPopularity, SeasonSortTitle, SeasonSortStartDate:
+		return true
+	}
+	return false
+}
+
+// String returns the written name of the SeasonSort.
+func (v SeasonSort) String() string {
+	switch v {
+	case SeasonSortPopularity:
+		return "Popularity"
+	case SeasonSortTitle:
+		return "Title"
+	case SeasonSortStartDate:
+		return "StartDate"
+	}
+	return fmt.Sprintf("%d", int(v))
+}
+
+// MarshalText encodes the SeasonSort as its written name.
+func (v SeasonSort) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid SeasonSort: %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes the SeasonSort from its written name.
+func (v *SeasonSort) UnmarshalText(text []byte) error {
+	p, err := ParseSeasonSort(string(text))
+	if err != nil {
+		return err
+	}
+	*v = p
+	return nil
+}
+
+// MarshalJSON encodes the SeasonSort as a JSON string of its written name.
+func (v SeasonSort) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes the SeasonSort from a JSON string of its written name or,
+// as persisted by earlier versions, from its integer value.
+func (v *SeasonSort) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+
+	var i int
+	err = json.Unmarshal(data, &i)
+	if err != nil || !SeasonSort(i).IsValid() {
+		return fmt.Errorf("invalid SeasonSort: %s", data)
+	}
+	*v = SeasonSort(i)
+	return nil
+}
+
+// UnmarshalGQL casts the type of the given value to a SeasonSort.
+func (v *SeasonSort) UnmarshalGQL(i interface{}) error {
+	s, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", i)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// MarshalGQL serializes the SeasonSort into a GraphQL readable form.
+func (v SeasonSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(v.String()))
+}
@@ -0,0 +1,117 @@
+// Code generated by "enumgen -type WatchStatus,Quarter,TitlePriority,Role,Action,Visibility,ScoreSystem,MediaKind,MediaType,MediaSource,Weekday,SeasonSort -output enum_string.go -test enum_string_test.go"; DO NOT EDIT.
+
+package models
+
+// enumTypes returns the enumType of each generated enum type.
+func enumTypes() []enumType {
+	return []enumType{
+		{
+			name:   "WatchStatus",
+			values: []int{int(WatchStatusCurrent), int(WatchStatusCompleted), int(WatchStatusPlanning), int(WatchStatusDropped), int(WatchStatusHold)},
+			value:  func(i int) enumValue { return WatchStatus(i) },
+			decoder: func() (enumPtr, func() int) {
+				var v WatchStatus
+				return &v, func() int { return int(v) }
+			},
+		},
+		{
+			name:   "Quarter",
+			values: []int{int(QuarterWinter), int(QuarterSpring), int(QuarterSummer), int(QuarterFall)},
+			value:  func(i int) enumValue { return Quarter(i) },
+			decoder: func() (enumPtr, func() int) {
+				var v Quarter
+				return &v, func() int { return int(v) }
+			},
+		},
+		{
+			name:   "TitlePriority",
+			values: []int{int(TitlePriorityPrimary), int(TitlePrioritySecondary), int(TitlePriorityOther)},
+			value:  func(i int) enumValue { return TitlePriority(i) },
+			decoder: func() (enumPtr, func() int) {
+				var v TitlePriority
+				return &v, func() int { return int(v) }
+			},
+		},
+		{
+			name:   "Role",
+			values: []int{int(RoleViewer), int(RoleContributor), int(RoleModerator), int(RoleAdmin)},
+			value:  func(i int) enumValue { return Role(i) },
+			decoder: func() (enumPtr, func() int) {
+				var v Role
+				return &v, func() int { return int(v) }
+			},
+		},
+		{
+			name:   "Action",
+			values: []int{int(ActionRead), int(ActionCreate), int(ActionUpdate), int(ActionDelete)},
+			value:  func(i int) enumValue { return Action(i) },
+			decoder: func() (enumPtr, func() int) {
+				var v Action
+				return &v, func() int { return int(v) }
+			},
+		},
+		{
+			name:   "Visibility",
+			values: []int{int(VisibilityPublic), int(VisibilityFriends), int(VisibilityPrivate)},
+			value:  func(i int) enumValue { return Visibility(i) },
+			decoder: func() (enumPtr, func() int) {
+				var v Visibility
+				return &v, func() int { return int(v) }
+			},
+		},
+		{
+			name:   "ScoreSystem",
+			values: []int{int(ScoreSystemPoint100), int(ScoreSystemPoint10), int(ScoreSystemPoint10Decimal), int(ScoreSystemStar5), int(ScoreSystemSmiley3)},
+			value:  func(i int) enumValue { return ScoreSystem(i) },
+			decoder: func() (enumPtr, func() int) {
+				var v ScoreSystem
+				return &v, func() int { return int(v) }
+			},
+		},
+		{
+			name:   "MediaKind",
+			values: []int{int(MediaKindAnime), int(MediaKindManga)},
+			value:  func(i int) enumValue { return MediaKind(i) },
+			decoder: func() (enumPtr, func() int) {
+				var v MediaKind
+				return &v, func() int { return int(v) }
+			},
+		},
+		{
+			name:   "MediaType",
+			values: []int{int(MediaTypeTV), int(MediaTypeTVShort), int(MediaTypeMovie), int(MediaTypeSpecial), int(MediaTypeOVA), int(MediaTypeONA), int(MediaTypeMusic), int(MediaTypeManga), int(MediaTypeManhwa), int(MediaTypeManhua), int(MediaTypeOneShot), int(MediaTypeDoujinshi), int(MediaTypeLightNovel), int(MediaTypeNovel)},
+			value:  func(i int) enumValue { return MediaType(i) },
+			decoder: func() (enumPtr, func() int) {
+				var v MediaType
+				return &v, func() int { return int(v) }
+			},
+		},
+		{
+			name:   "MediaSource",
+			values: []int{int(MediaSourceOriginal), int(MediaSourceManga), int(MediaSourceWebManga), int(MediaSourceFourKomaManga), int(MediaSourceLightNovel), int(MediaSourceWebNovel), int(MediaSourceNovel), int(MediaSourceVisualNovel), int(MediaSourceVideoGame), int(MediaSourceCardGame), int(MediaSourceBook), int(MediaSourcePictureBook), int(MediaSourceAnime), int(MediaSourceDoujinshi), int(MediaSourceMusic), int(MediaSourceRadio), int(MediaSourceOther)},
+			value:  func(i int) enumValue { return MediaSource(i) },
+			decoder: func() (enumPtr, func() int) {
+				var v MediaSource
+				return &v, func() int { return int(v) }
+			},
+		},
+		{
+			name:   "Weekday",
+			values: []int{int(WeekdaySunday), int(WeekdayMonday), int(WeekdayTuesday), int(WeekdayWednesday), int(WeekdayThursday), int(WeekdayFriday), int(WeekdaySaturday)},
+			value:  func(i int) enumValue { return Weekday(i) },
+			decoder: func() (enumPtr, func() int) {
+				var v Weekday
+				return &v, func() int { return int(v) }
+			},
+		},
+		{
+			name:   "SeasonSort",
+			values: []int{int(SeasonSortPopularity), int(SeasonSortTitle), int(SeasonSortStartDate)},
+			value:  func(i int) enumValue { return SeasonSort(i) },
+			decoder: func() (enumPtr, func() int) {
+				var v SeasonSort
+				return &v, func() int { return int(v) }
+			},
+		},
+	}
+}
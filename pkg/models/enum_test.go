@@ -0,0 +1,210 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+	"testing/quick"
+)
+
+// enumValue is implemented by values of the generated enum types.
+type enumValue interface {
+	IsValid() bool
+	String() string
+	MarshalText() ([]byte, error)
+	MarshalJSON() ([]byte, error)
+	MarshalGQL(w io.Writer)
+}
+
+// enumPtr is implemented by pointers to the generated enum types.
+type enumPtr interface {
+	UnmarshalText(text []byte) error
+	UnmarshalJSON(data []byte) error
+	UnmarshalGQL(v interface{}) error
+}
+
+// enumType describes a generated enum type in terms of its integer values.
+type enumType struct {
+	name   string
+	values []int
+	// value returns the given integer as the enum type.
+	value func(i int) enumValue
+	// decoder returns a pointer to a zero value of the enum type to decode
+	// into and a function returning the decoded value.
+	decoder func() (enumPtr, func() int)
+}
+
+func (et *enumType) contains(i int) bool {
+	for _, v := range et.values {
+		if v == i {
+			return true
+		}
+	}
+	return false
+}
+
+// roundTrip returns an error if the given valid value does not decode back to
+// itself from each of its text, JSON, legacy integer JSON and GraphQL
+// encodings.
+func (et *enumType) roundTrip(i int) error {
+	v := et.value(i)
+
+	text, err := v.MarshalText()
+	if err != nil {
+		return fmt.Errorf("failed to marshal text: %w", err)
+	}
+	if string(text) != v.String() {
+		return fmt.Errorf("text %q does not match name %q", text, v.String())
+	}
+	p, get := et.decoder()
+	if err := p.UnmarshalText(text); err != nil || get() != i {
+		return fmt.Errorf("text %q decoded to %d: %v", text, get(), err)
+	}
+
+	data, err := v.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	p, get = et.decoder()
+	if err := json.Unmarshal(data, p); err != nil || get() != i {
+		return fmt.Errorf("JSON %s decoded to %d: %v", data, get(), err)
+	}
+
+	legacy := []byte(strconv.Itoa(i))
+	p, get = et.decoder()
+	if err := p.UnmarshalJSON(legacy); err != nil || get() != i {
+		return fmt.Errorf("JSON %s decoded to %d: %v", legacy, get(), err)
+	}
+
+	var buf bytes.Buffer
+	v.MarshalGQL(&buf)
+	s, err := strconv.Unquote(buf.String())
+	if err != nil {
+		return fmt.Errorf("GraphQL %s is not a string: %w", buf.String(), err)
+	}
+	p, get = et.decoder()
+	if err := p.UnmarshalGQL(s); err != nil || get() != i {
+		return fmt.Errorf("GraphQL %q decoded to %d: %v", s, get(), err)
+	}
+
+	return nil
+}
+
+// TestEnumRoundTrip tests that every value of each enum type round-trips
+// through each of its encodings, and that the written names are unique.
+func TestEnumRoundTrip(t *testing.T) {
+	for _, et := range enumTypes() {
+		if len(et.values) == 0 {
+			t.Errorf("%s: no values", et.name)
+		}
+
+		names := map[string]bool{}
+		for _, i := range et.values {
+			err := et.roundTrip(i)
+			if err != nil {
+				t.Errorf("%s %d: %v", et.name, i, err)
+			}
+
+			name := et.value(i).String()
+			if names[name] {
+				t.Errorf("%s: duplicate name %q", et.name, name)
+			}
+			names[name] = true
+		}
+	}
+}
+
+// TestEnumArbitrary tests that arbitrary integers are valid values of each
+// enum type exactly when they are declared, that valid ones round-trip, and
+// that invalid ones are neither encoded nor decoded.
+func TestEnumArbitrary(t *testing.T) {
+	for _, et := range enumTypes() {
+		et := et
+		property := func(n int8) bool {
+			i := int(n)
+			v := et.value(i)
+			if v.IsValid() != et.contains(i) {
+				t.Logf("%s %d: IsValid is %t", et.name, i, v.IsValid())
+				return false
+			}
+
+			if v.IsValid() {
+				err := et.roundTrip(i)
+				if err != nil {
+					t.Logf("%s %d: %v", et.name, i, err)
+				}
+				return err == nil
+			}
+
+			if _, err := v.MarshalText(); err == nil {
+				return false
+			}
+			if _, err := v.MarshalJSON(); err == nil {
+				return false
+			}
+			p, _ := et.decoder()
+			if p.UnmarshalJSON([]byte(strconv.Itoa(i))) == nil {
+				return false
+			}
+			return v.String() == strconv.Itoa(i)
+		}
+
+		err := quick.Check(property, &quick.Config{MaxCount: 500})
+		if err != nil {
+			t.Errorf("%s: %v", et.name, err)
+		}
+	}
+}
+
+// TestEnumInvalidNames tests that arbitrary strings only decode to values of
+// each enum type when they are written names.
+func TestEnumInvalidNames(t *testing.T) {
+	for _, et := range enumTypes() {
+		et := et
+		names := map[string]int{}
+		for _, i := range et.values {
+			names[et.value(i).String()] = i
+		}
+
+		property := func(s string) bool {
+			p, get := et.decoder()
+			err := p.UnmarshalText([]byte(s))
+			i, ok := names[s]
+			if ok {
+				return err == nil && get() == i
+			}
+			return err != nil && p.UnmarshalGQL(s) != nil
+		}
+
+		err := quick.Check(property, nil)
+		if err != nil {
+			t.Errorf("%s: %v", et.name, err)
+		}
+	}
+}
+
+// TestWatchStatusCurrentJSON tests that UserMedia that are Current are
+// persisted with its written name and read back, which the hand-written
+// mappings replaced by the generated ones did not do.
+func TestWatchStatusCurrentJSON(t *testing.T) {
+	status := WatchStatusCurrent
+	data, err := json.Marshal(&UserMedia{Status: &status})
+	if err != nil {
+		t.Fatalf("failed to marshal UserMedia: %v", err)
+	}
+	if !bytes.Contains(data, []byte(`"Status":"Current"`)) {
+		t.Errorf("expected Status written as Current, got %s", data)
+	}
+
+	var um UserMedia
+	err = json.Unmarshal(data, &um)
+	if err != nil {
+		t.Fatalf("failed to unmarshal UserMedia: %v", err)
+	}
+	if um.Status == nil || *um.Status != WatchStatusCurrent {
+		t.Errorf("expected Current, got %v", um.Status)
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+// MediaKind is an enum that describes whether Media are watched, in
+// Episodes, or read, in Volumes and Chapters.
+type MediaKind int
+
+const (
+	// MediaKindAnime means the Media is watched, and progress through it is
+	// counted in Episodes.
+	MediaKindAnime MediaKind = iota
+
+	// MediaKindManga means the Media is read, and progress through it is
+	// counted in Chapters and Volumes.
+	MediaKindManga
+)
+
+// Kind returns the MediaKind of the Media, determined by its Type. Media
+// without a Type are anime.
+func (m *Media) Kind() MediaKind {
+	if m.Type == nil {
+		return MediaKindAnime
+	}
+	switch *m.Type {
+	case MediaTypeManga, MediaTypeManhwa, MediaTypeManhua, MediaTypeOneShot,
+		MediaTypeDoujinshi, MediaTypeLightNovel, MediaTypeNovel:
+		return MediaKindManga
+	}
+	return MediaKindAnime
+}
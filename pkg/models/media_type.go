@@ -0,0 +1,201 @@
+package models
+
+import "strings"
+
+// MediaType is an enum that describes the format of Media.
+type MediaType int
+
+const (
+	// MediaTypeTV is an anime series broadcast on television.
+	MediaTypeTV MediaType = iota + 1
+
+	// MediaTypeTVShort is an anime series broadcast on television with
+	// episodes shorter than 15 minutes.
+	MediaTypeTVShort
+
+	// MediaTypeMovie is an anime film.
+	MediaTypeMovie
+
+	// MediaTypeSpecial is an anime special, usually bundled with or broadcast
+	// alongside a series.
+	MediaTypeSpecial
+
+	// MediaTypeOVA is an original video animation, released directly to home
+	// video.
+	MediaTypeOVA
+
+	// MediaTypeONA is an original net animation, released online.
+	MediaTypeONA
+
+	// MediaTypeMusic is an anime music video.
+	MediaTypeMusic
+
+	// MediaTypeManga is a Japanese comic.
+	MediaTypeManga
+
+	// MediaTypeManhwa is a Korean comic.
+	MediaTypeManhwa
+
+	// MediaTypeManhua is a Chinese comic.
+	MediaTypeManhua
+
+	// MediaTypeOneShot is a comic of a single chapter.
+	MediaTypeOneShot
+
+	// MediaTypeDoujinshi is a self-published comic.
+	MediaTypeDoujinshi
+
+	// MediaTypeLightNovel is a light novel.
+	MediaTypeLightNovel
+
+	// MediaTypeNovel is a novel.
+	MediaTypeNovel
+)
+
+// MediaSource is an enum that describes the source material of Media.
+type MediaSource int
+
+const (
+	// MediaSourceOriginal means the Media is not adapted from anything.
+	MediaSourceOriginal MediaSource = iota + 1
+
+	// MediaSourceManga means the Media is adapted from a comic.
+	MediaSourceManga
+
+	// MediaSourceWebManga means the Media is adapted from a comic published
+	// online.
+	MediaSourceWebManga
+
+	// MediaSourceFourKomaManga means the Media is adapted from a four-panel
+	// comic strip.
+	MediaSourceFourKomaManga
+
+	// MediaSourceLightNovel means the Media is adapted from a light novel.
+	MediaSourceLightNovel
+
+	// MediaSourceWebNovel means the Media is adapted from a novel published
+	// online.
+	MediaSourceWebNovel
+
+	// MediaSourceNovel means the Media is adapted from a novel.
+	MediaSourceNovel
+
+	// MediaSourceVisualNovel means the Media is adapted from a visual novel.
+	MediaSourceVisualNovel
+
+	// MediaSourceVideoGame means the Media is adapted from a video game.
+	MediaSourceVideoGame
+
+	// MediaSourceCardGame means the Media is adapted from a card game.
+	MediaSourceCardGame
+
+	// MediaSourceBook means the Media is adapted from a book other than a
+	// novel.
+	MediaSourceBook
+
+	// MediaSourcePictureBook means the Media is adapted from a picture book.
+	MediaSourcePictureBook
+
+	// MediaSourceAnime means the Media is adapted from anime.
+	MediaSourceAnime
+
+	// MediaSourceDoujinshi means the Media is adapted from a self-published
+	// work.
+	MediaSourceDoujinshi
+
+	// MediaSourceMusic means the Media is adapted from music.
+	MediaSourceMusic
+
+	// MediaSourceRadio means the Media is adapted from a radio programme.
+	MediaSourceRadio
+
+	// MediaSourceOther means the Media is adapted from something else.
+	MediaSourceOther
+)
+
+// mediaTypeNames maps the normalized names of formats used by nao and other
+// services to MediaTypes.
+var mediaTypeNames = map[string]MediaType{
+	"tv":         MediaTypeTV,
+	"tvseries":   MediaTypeTV,
+	"series":     MediaTypeTV,
+	"tvshort":    MediaTypeTVShort,
+	"movie":      MediaTypeMovie,
+	"film":       MediaTypeMovie,
+	"special":    MediaTypeSpecial,
+	"tvspecial":  MediaTypeSpecial,
+	"ova":        MediaTypeOVA,
+	"oav":        MediaTypeOVA,
+	"ona":        MediaTypeONA,
+	"web":        MediaTypeONA,
+	"music":      MediaTypeMusic,
+	"musicvideo": MediaTypeMusic,
+	"pv":         MediaTypeMusic,
+	"manga":      MediaTypeManga,
+	"comic":      MediaTypeManga,
+	"oel":        MediaTypeManga,
+	"manhwa":     MediaTypeManhwa,
+	"manhua":     MediaTypeManhua,
+	"oneshot":    MediaTypeOneShot,
+	"doujinshi":  MediaTypeDoujinshi,
+	"doujin":     MediaTypeDoujinshi,
+	"lightnovel": MediaTypeLightNovel,
+	"novel":      MediaTypeNovel,
+}
+
+// mediaSourceNames maps the normalized names of source materials used by nao
+// and other services to MediaSources.
+var mediaSourceNames = map[string]MediaSource{
+	"original":      MediaSourceOriginal,
+	"manga":         MediaSourceManga,
+	"comic":         MediaSourceManga,
+	"webmanga":      MediaSourceWebManga,
+	"webcomic":      MediaSourceWebManga,
+	"webtoon":       MediaSourceWebManga,
+	"4koma":         MediaSourceFourKomaManga,
+	"4komamanga":    MediaSourceFourKomaManga,
+	"fourkomamanga": MediaSourceFourKomaManga,
+	"lightnovel":    MediaSourceLightNovel,
+	"webnovel":      MediaSourceWebNovel,
+	"novel":         MediaSourceNovel,
+	"visualnovel":   MediaSourceVisualNovel,
+	"videogame":     MediaSourceVideoGame,
+	"game":          MediaSourceVideoGame,
+	"cardgame":      MediaSourceCardGame,
+	"book":          MediaSourceBook,
+	"picturebook":   MediaSourcePictureBook,
+	"anime":         MediaSourceAnime,
+	"doujinshi":     MediaSourceDoujinshi,
+	"music":         MediaSourceMusic,
+	"radio":         MediaSourceRadio,
+	"other":         MediaSourceOther,
+	"mixedmedia":    MediaSourceOther,
+	"multimedia":    MediaSourceOther,
+	"liveaction":    MediaSourceOther,
+}
+
+// NormalizeMediaType returns the MediaType with the given free-form name,
+// such as "TV Series", "tv" or "LIGHT_NOVEL", and false if there is none.
+func NormalizeMediaType(name string) (MediaType, bool) {
+	t, ok := mediaTypeNames[normalizeEnumName(name)]
+	return t, ok
+}
+
+// NormalizeMediaSource returns the MediaSource with the given free-form
+// name, such as "Web Novel" or "VIDEO_GAME", and false if there is none.
+func NormalizeMediaSource(name string) (MediaSource, bool) {
+	s, ok := mediaSourceNames[normalizeEnumName(name)]
+	return s, ok
+}
+
+// normalizeEnumName lower-cases the given name and removes spaces,
+// underscores, hyphens and dots from it.
+func normalizeEnumName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '_', '-', '.':
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(name)))
+}
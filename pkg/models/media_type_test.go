@@ -0,0 +1,53 @@
+package models
+
+import "testing"
+
+func TestNormalizeMediaType(t *testing.T) {
+	cases := map[string]MediaType{
+		"TV":          MediaTypeTV,
+		" tv ":        MediaTypeTV,
+		"Tv Series":   MediaTypeTV,
+		"TV_SHORT":    MediaTypeTVShort,
+		"one-shot":    MediaTypeOneShot,
+		"Light Novel": MediaTypeLightNovel,
+	}
+	for name, expected := range cases {
+		v, ok := NormalizeMediaType(name)
+		if !ok || v != expected {
+			t.Errorf("expected %q to be %v, got %v %t", name, expected, v, ok)
+		}
+	}
+	if _, ok := NormalizeMediaType("Drama CD"); ok {
+		t.Error("expected unknown type not to be normalized")
+	}
+
+	// Written names normalize to themselves
+	for _, v := range MediaTypeValues() {
+		n, ok := NormalizeMediaType(v.String())
+		if !ok || n != v {
+			t.Errorf("expected %v to normalize to itself, got %v %t", v, n, ok)
+		}
+	}
+}
+
+func TestNormalizeMediaSource(t *testing.T) {
+	cases := map[string]MediaSource{
+		"Original":     MediaSourceOriginal,
+		"WEB_NOVEL":    MediaSourceWebNovel,
+		"4-koma manga": MediaSourceFourKomaManga,
+		"Video Game":   MediaSourceVideoGame,
+	}
+	for name, expected := range cases {
+		v, ok := NormalizeMediaSource(name)
+		if !ok || v != expected {
+			t.Errorf("expected %q to be %v, got %v %t", name, expected, v, ok)
+		}
+	}
+
+	for _, v := range MediaSourceValues() {
+		n, ok := NormalizeMediaSource(v.String())
+		if !ok || n != v {
+			t.Errorf("expected %v to normalize to itself, got %v %t", v, n, ok)
+		}
+	}
+}
@@ -1,10 +1,8 @@
 package models
 
+//go:generate go run ../../scripts/enumgen.go -type WatchStatus,Quarter,TitlePriority,Role,Action,Visibility,ScoreSystem,MediaKind,MediaType,MediaSource,Weekday,SeasonSort -output enum_string.go -test enum_string_test.go
+
 import (
-	"encoding/json"
-	"fmt"
-	"io"
-	"strconv"
 	"time"
 
 	"github.com/Dophin2009/nao/pkg/db"
@@ -18,9 +16,11 @@ type Media struct {
 	StartDate       *time.Time
 	EndDate         *time.Time
 	SeasonPremiered Season
-	Type            *string
-	Source          *string
-	Meta            db.ModelMetadata
+	Type            *MediaType
+	Source          *MediaSource
+	// ExternalIDs identify the Media in other services.
+	ExternalIDs []ExternalID
+	Meta        db.ModelMetadata
 }
 
 // Metadata returns Meta.
@@ -28,6 +28,16 @@ func (m *Media) Metadata() *db.ModelMetadata {
 	return &m.Meta
 }
 
+// ExternalID identifies a model in another service.
+type ExternalID struct {
+	// Source is the name of the service, such as "myanimelist", which
+	// namespaces ID.
+	Source string
+	ID     string
+	// URL is the address of the page of the model in the service, if known.
+	URL *string
+}
+
 // Season contains information about the quarter and year.
 type Season struct {
 	Quarter *Quarter
@@ -55,61 +65,12 @@ const (
 	QuarterFall
 )
 
-// IsValid checks if the Quarter has a value that is a valid one.
-func (q Quarter) IsValid() bool {
-	switch q {
-	case QuarterWinter, QuarterSpring, QuarterSummer, QuarterFall:
-		return true
-	}
-	return false
-}
-
-// String returns the written name of the Quarter.
-func (q Quarter) String() string {
-	switch q {
-	case QuarterWinter:
-		return "Winter"
-	case QuarterSpring:
-		return "Spring"
-	case QuarterSummer:
-		return "Summer"
-	case QuarterFall:
-		return "Fall"
-	}
-	return fmt.Sprintf("%d", int(q))
-}
-
-// UnmarshalGQL casts the type of the given value to a Quarter.
-func (q *Quarter) UnmarshalGQL(v interface{}) error {
-	str, ok := v.(string)
-	if !ok {
-		return fmt.Errorf("invalid value: %v", v)
-	}
-
-	switch str {
-	case "Winter":
-		*q = QuarterWinter
-	case "Spring":
-		*q = QuarterSpring
-	case "Summer":
-		*q = QuarterSummer
-	case "Fall":
-		*q = QuarterFall
-	default:
-		return fmt.Errorf("invalid value: %s", str)
-	}
-	return nil
-}
-
-// MarshalGQL serializes the Quarter into a GraphQL readable form.
-func (q Quarter) MarshalGQL(w io.Writer) {
-	fmt.Fprint(w, strconv.Quote(q.String()))
-}
-
 // Character represents a single character.
 type Character struct {
 	Names       []Title
 	Information []Title
+	// ExternalIDs identify the Character in other services.
+	ExternalIDs []ExternalID
 	Meta        db.ModelMetadata
 }
 
@@ -147,6 +108,89 @@ func (set *EpisodeSet) Metadata() *db.ModelMetadata {
 	return &set.Meta
 }
 
+// Volume represents a single volume of some manga.
+type Volume struct {
+	MediaID     int
+	Number      int
+	Titles      []Title
+	ReleaseDate *time.Time
+	Meta        db.ModelMetadata
+}
+
+// Metadata returns Meta.
+func (v *Volume) Metadata() *db.ModelMetadata {
+	return &v.Meta
+}
+
+// Chapter represents a single chapter of some manga.
+type Chapter struct {
+	MediaID int
+	// VolumeID is the ID of the Volume the Chapter is collected in, if any.
+	VolumeID *int
+	// Number is the number of the Chapter, which may have a fractional part
+	// for chapters released between others, such as 10.5.
+	Number      float64
+	Titles      []Title
+	Pages       *int
+	ReleaseDate *time.Time
+	Meta        db.ModelMetadata
+}
+
+// Metadata returns Meta.
+func (ch *Chapter) Metadata() *db.ModelMetadata {
+	return &ch.Meta
+}
+
+// BroadcastSlot is a recurring weekly time at which new episodes of some
+// Media are broadcast, in the local time of a timezone.
+type BroadcastSlot struct {
+	MediaID int
+	// Weekday is the day of the week of the broadcast, as written by the
+	// broadcaster.
+	Weekday Weekday
+	// Hour and Minute are the local time of the broadcast on Weekday. Hours
+	// past 23 are those of late-night broadcasts written as belonging to the
+	// day before, so that 25:30 on a Saturday is 01:30 on the Sunday.
+	Hour   int
+	Minute int
+	// Timezone is the IANA name of the timezone of the broadcast, such as
+	// "Asia/Tokyo".
+	Timezone string
+	// Station is the name of the broadcaster, if known.
+	Station *string
+	// StartDate is the date of the first broadcast in the slot, and EndDate
+	// the date of the last, if known. Only the dates are used.
+	StartDate time.Time
+	EndDate   *time.Time
+	// FirstEpisode is the number of the episode first broadcast in the slot,
+	// and Episodes the number of episodes broadcast in it, if known.
+	FirstEpisode int
+	Episodes     *int
+	Meta         db.ModelMetadata
+}
+
+// Metadata returns Meta.
+func (s *BroadcastSlot) Metadata() *db.ModelMetadata {
+	return &s.Meta
+}
+
+// Airing is the time at which an episode of some Media is broadcast,
+// recorded for episodes not broadcast in a BroadcastSlot or broadcast at
+// another time than it gives, such as when delayed.
+type Airing struct {
+	MediaID       int
+	EpisodeNumber int
+	// EpisodeID is the ID of the Episode broadcast, if it exists.
+	EpisodeID *int
+	AiringAt  time.Time
+	Meta      db.ModelMetadata
+}
+
+// Metadata returns Meta.
+func (a *Airing) Metadata() *db.ModelMetadata {
+	return &a.Meta
+}
+
 // Genre represents a single instance of a genre.
 type Genre struct {
 	Names        []Title
@@ -163,6 +207,8 @@ func (g *Genre) Metadata() *db.ModelMetadata {
 type Person struct {
 	Names       []Title
 	Information []Title
+	// ExternalIDs identify the Person in other services.
+	ExternalIDs []ExternalID
 	Meta        db.ModelMetadata
 }
 
@@ -175,7 +221,9 @@ func (p *Person) Metadata() *db.ModelMetadata {
 type Producer struct {
 	Titles []Title
 	Types  []string
-	Meta   db.ModelMetadata
+	// ExternalIDs identify the Producer in other services.
+	ExternalIDs []ExternalID
+	Meta        db.ModelMetadata
 }
 
 // Metadata return Meta.
@@ -242,11 +290,41 @@ func (mr *MediaRelation) Metadata() *db.ModelMetadata {
 
 // User represents a single user.
 type User struct {
-	Username    string
-	Email       string
-	Password    []byte
-	Permissions UserPermission
-	Meta        db.ModelMetadata
+	Username string
+	Email    string
+	// EmailVerified is true once the User has confirmed that it receives
+	// messages at Email.
+	EmailVerified bool
+	Password      []byte
+	// Roles are the Roles assigned to the User, which determine its
+	// Permissions.
+	Roles []Role
+	// Privacy determines which Users may view the data of the User.
+	Privacy UserPrivacy
+	// Friends are the IDs of the Users that may view data with friends-only
+	// visibility.
+	Friends []int
+	// ScoreSystem is the scale the User views and gives scores in; zero if
+	// not chosen.
+	ScoreSystem ScoreSystem
+	// TwoFactor contains the second authentication factor of the User.
+	TwoFactor TwoFactor
+	Meta      db.ModelMetadata
+}
+
+// TwoFactor contains the time-based one-time password settings of a User,
+// which are required to log in once enabled.
+type TwoFactor struct {
+	// Secret is the base32-encoded TOTP secret, set on enrollment.
+	Secret string
+	// Enabled is true once enrollment has been confirmed with a code.
+	Enabled bool
+	// LastCounter is the TOTP counter of the last accepted code, so that
+	// codes may not be used again.
+	LastCounter int64
+	// RecoveryCodeHashes are the hashes of the unused one-time recovery codes
+	// that may be used in place of a TOTP code.
+	RecoveryCodeHashes []string
 }
 
 // Metadata returns Meta.
@@ -254,13 +332,131 @@ func (u *User) Metadata() *db.ModelMetadata {
 	return &u.Meta
 }
 
-// UserPermission contains a number of permissions for users for
-// reading/writing data.
-type UserPermission struct {
-	// WriteMedia is the ability modify global Media.
+// Session represents a login session of a User on a single device. Access
+// tokens are issued for a Session, and new ones are obtained with its rotating
+// refresh token.
+type Session struct {
+	UserID int
+	// RefreshTokenHash is the hash of the current refresh token of the
+	// Session.
+	RefreshTokenHash string
+	// Device describes the client the Session was started on.
+	Device    string
+	LastUsed  time.Time
+	ExpiresAt time.Time
+	Revoked   bool
+	Meta      db.ModelMetadata
+}
+
+// Metadata returns Meta.
+func (s *Session) Metadata() *db.ModelMetadata {
+	return &s.Meta
+}
+
+// APIToken represents a named, long-lived token created by a User for use by
+// scripts and integrations in place of a password.
+type APIToken struct {
+	UserID int
+	Name   string
+	// TokenHash is the hash of the token.
+	TokenHash string
+	Scope     APITokenScope
+	LastUsed  *time.Time
+	// ExpiresAt is the time the APIToken expires, or nil if it does not.
+	ExpiresAt *time.Time
+	Revoked   bool
+	Meta      db.ModelMetadata
+}
+
+// Metadata returns Meta.
+func (t *APIToken) Metadata() *db.ModelMetadata {
+	return &t.Meta
+}
+
+// APITokenScope contains the operations allowed to requests authenticated
+// with an APIToken. An APIToken with no scopes is read-only.
+type APITokenScope struct {
+	// WriteLists is the ability to modify the User's own data, such as
+	// UserMedia and UserMediaLists.
+	WriteLists bool
+	// WriteMedia is the ability to modify global Media, if the User has
+	// permission to.
 	WriteMedia bool
-	// WriteUsers is the ability to modify other Users.
-	WriteUsers bool
+}
+
+// CalendarToken represents the token that authenticates requests for the
+// calendar feed of a User, which is given in the address of the feed since
+// calendar applications cannot send credentials.
+type CalendarToken struct {
+	UserID int
+	// TokenHash is the hash of the token.
+	TokenHash string
+	Meta      db.ModelMetadata
+}
+
+// Metadata returns Meta.
+func (t *CalendarToken) Metadata() *db.ModelMetadata {
+	return &t.Meta
+}
+
+// EmailVerification represents a request to confirm the email of a User.
+// The verification token delivered to the email may be used once before it
+// expires.
+type EmailVerification struct {
+	UserID int
+	// Email is the address the verification token was delivered to.
+	Email string
+	// TokenHash is the hash of the verification token.
+	TokenHash string
+	ExpiresAt time.Time
+	// Used is true once the verification token has been used or replaced by a
+	// newer one.
+	Used bool
+	Meta db.ModelMetadata
+}
+
+// Metadata returns Meta.
+func (ev *EmailVerification) Metadata() *db.ModelMetadata {
+	return &ev.Meta
+}
+
+// Invitation represents permission for one person to register while
+// registration is invite-only. The invitation code given to the person may be
+// used once before it expires.
+type Invitation struct {
+	// CreatedBy is the ID of the User that created the Invitation.
+	CreatedBy int
+	// TokenHash is the hash of the invitation code.
+	TokenHash string
+	// ExpiresAt is the time the Invitation expires, or nil if it does not.
+	ExpiresAt *time.Time
+	// UsedBy is the ID of the User that registered with the Invitation, or
+	// nil if it has not been used.
+	UsedBy *int
+	Meta   db.ModelMetadata
+}
+
+// Metadata returns Meta.
+func (inv *Invitation) Metadata() *db.ModelMetadata {
+	return &inv.Meta
+}
+
+// PasswordReset represents a request to reset the password of a User. The
+// reset token delivered to the User may be used once before it expires.
+type PasswordReset struct {
+	UserID int
+	// TokenHash is the hash of the reset token.
+	TokenHash string
+	ExpiresAt time.Time
+	// Used is true once the reset token has been used or replaced by a newer
+	// one.
+	Used bool
+	Meta db.ModelMetadata
+}
+
+// Metadata returns Meta.
+func (pr *PasswordReset) Metadata() *db.ModelMetadata {
+	return &pr.Meta
 }
 
 // UserCharacter represents a relationship between a User and a Character,
@@ -302,6 +498,7 @@ type UserMedia struct {
 	Score          *int
 	Recommended    *int
 	Status         *WatchStatus
+	StatusHistory  []StatusChange
 	WatchInstances []WatchedInstance
 	Comments       []Title
 	Meta           db.ModelMetadata
@@ -312,15 +509,53 @@ func (um *UserMedia) Metadata() *db.ModelMetadata {
 	return &um.Meta
 }
 
-// WatchedInstance contains information about a single watch of some Media.
+// WatchedInstance contains information about a single watch or read of some
+// Media. Progress through anime is counted in Episodes, and through manga in
+// Chapters and Volumes.
 type WatchedInstance struct {
 	Episodes  int
+	Chapters  int
+	Volumes   int
 	Ongoing   bool
 	StartDate *time.Time
 	EndDate   *time.Time
 	Comments  []Title
 }
 
+// StatusChange records a transition of the WatchStatus of some UserMedia.
+type StatusChange struct {
+	From *WatchStatus
+	To   *WatchStatus
+	At   time.Time
+}
+
+// EpisodeProgress represents the progress of a User through an EpisodeSet of
+// the Media of some UserMedia.
+type EpisodeProgress struct {
+	UserID       int
+	UserMediaID  int
+	EpisodeSetID int
+	// Rewatches is the number of times the EpisodeSet was started again after
+	// being finished.
+	Rewatches int
+	Episodes  []WatchedEpisode
+	Meta      db.ModelMetadata
+}
+
+// Metadata returns Meta.
+func (ep *EpisodeProgress) Metadata() *db.ModelMetadata {
+	return &ep.Meta
+}
+
+// WatchedEpisode contains information about the watches of a single Episode
+// by a User.
+type WatchedEpisode struct {
+	EpisodeID int
+	// Count is the number of times the Episode was watched.
+	Count     int
+	WatchedAt time.Time
+}
+
 // WatchStatus is an enum that represents the status of a Media's consumption
 // by a User.
 type WatchStatus int
@@ -346,47 +581,6 @@ const (
 	WatchStatusHold
 )
 
-// UnmarshalJSON defines custom JSON deserialization for WatchStatus.
-func (ws *WatchStatus) UnmarshalJSON(data []byte) error {
-	var s string
-	err := json.Unmarshal(data, &s)
-	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
-	}
-
-	value, ok := map[string]WatchStatus{
-		"Completed": WatchStatusCompleted,
-		"Planning":  WatchStatusPlanning,
-		"Dropped":   WatchStatusDropped,
-		"Hold":      WatchStatusHold,
-	}[s]
-	if !ok {
-		return fmt.Errorf("invalid value: %q", s)
-	}
-	*ws = value
-	return nil
-}
-
-// MarshalJSON defines custom JSON serialization for WatchStatus.
-func (ws *WatchStatus) MarshalJSON() ([]byte, error) {
-	value, ok := map[WatchStatus]string{
-		WatchStatusCompleted: "Completed",
-		WatchStatusPlanning:  "Planning",
-		WatchStatusDropped:   "Dropped",
-		WatchStatusHold:      "Hold",
-	}[*ws]
-	if !ok {
-		return nil, fmt.Errorf("invalid value: %d", *ws)
-	}
-
-	v, err := json.Marshal(value)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
-	}
-
-	return v, nil
-}
-
 // UserMediaList represents a User-created list of UserMedia.
 type UserMediaList struct {
 	UserID       int
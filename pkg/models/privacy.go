@@ -0,0 +1,26 @@
+package models
+
+// Visibility is an enum that describes which Users may view some data of a
+// User.
+type Visibility int
+
+const (
+	// VisibilityPublic data may be viewed by all Users, including
+	// unauthenticated ones.
+	VisibilityPublic Visibility = 1
+	// VisibilityFriends data may only be viewed by the Users the owner has
+	// added as friends.
+	VisibilityFriends Visibility = 2
+	// VisibilityPrivate data may only be viewed by the owner.
+	VisibilityPrivate Visibility = 3
+)
+
+// UserPrivacy contains the settings that determine which Users may view the
+// data of a User.
+type UserPrivacy struct {
+	// Profile is the visibility of the User's relationships with Media,
+	// Characters, and People.
+	Profile Visibility
+	// Lists is the visibility of the User's UserMediaLists.
+	Lists Visibility
+}
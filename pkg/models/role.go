@@ -0,0 +1,43 @@
+package models
+
+// Role is an enum that describes a named set of Permissions that may be
+// assigned to Users.
+type Role int
+
+const (
+	// RoleViewer may read all data.
+	RoleViewer Role = 1
+	// RoleContributor may additionally create and update global Media data.
+	RoleContributor Role = 2
+	// RoleModerator may additionally delete global Media data and modify the
+	// data of other Users.
+	RoleModerator Role = 3
+	// RoleAdmin may perform all actions, including managing Users and Role
+	// assignments.
+	RoleAdmin Role = 4
+)
+
+// Roles is the list of all Roles, ordered from least to most privileged.
+var Roles = RoleValues()
+
+// Action is an enum that describes an operation performed on a type of
+// model.
+type Action int
+
+const (
+	// ActionRead is the reading of models.
+	ActionRead Action = 1
+	// ActionCreate is the creation of new models.
+	ActionCreate Action = 2
+	// ActionUpdate is the modification of existing models.
+	ActionUpdate Action = 3
+	// ActionDelete is the deletion of existing models.
+	ActionDelete Action = 4
+)
+
+// Permission is the ability to perform an Action on a type of model, given
+// by its name.
+type Permission struct {
+	Model  string
+	Action Action
+}
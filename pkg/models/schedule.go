@@ -0,0 +1,121 @@
+package models
+
+import "time"
+
+// Weekday is an enum that represents a day of the week, with the same values
+// as time.Weekday.
+type Weekday int
+
+const (
+	// WeekdaySunday is Sunday.
+	WeekdaySunday Weekday = iota
+
+	// WeekdayMonday is Monday.
+	WeekdayMonday
+
+	// WeekdayTuesday is Tuesday.
+	WeekdayTuesday
+
+	// WeekdayWednesday is Wednesday.
+	WeekdayWednesday
+
+	// WeekdayThursday is Thursday.
+	WeekdayThursday
+
+	// WeekdayFriday is Friday.
+	WeekdayFriday
+
+	// WeekdaySaturday is Saturday.
+	WeekdaySaturday
+)
+
+// MaxBroadcastHour is the latest hour at which broadcasts in a BroadcastSlot
+// may be written to begin.
+const MaxBroadcastHour = 29
+
+// ScheduledAiring is the broadcast of an episode of some Media, either
+// recorded by an Airing or given by a BroadcastSlot.
+type ScheduledAiring struct {
+	MediaID       int
+	EpisodeNumber int
+	// EpisodeID is the ID of the Episode broadcast, if it exists.
+	EpisodeID *int
+	AiringAt  time.Time
+	// AiringID is the ID of the Airing that recorded the broadcast, if any.
+	AiringID *int
+	// BroadcastSlotID is the ID of the BroadcastSlot that gave the broadcast,
+	// if any.
+	BroadcastSlotID *int
+}
+
+// Location returns the timezone of the BroadcastSlot.
+func (s *BroadcastSlot) Location() (*time.Location, error) {
+	return time.LoadLocation(s.Timezone)
+}
+
+// Occurrences calls do with the number and time of each episode broadcast in
+// the BroadcastSlot at or after from, in order, until do returns false or the
+// slot ends. Each time is the local time of the slot on the day of the
+// broadcast, so that broadcasts keep their local time across daylight saving
+// time transitions.
+func (s *BroadcastSlot) Occurrences(
+	from time.Time, do func(episode int, at time.Time) bool,
+) error {
+	loc, err := s.Location()
+	if err != nil {
+		return err
+	}
+
+	// The date of the first broadcast is the first one on Weekday
+	y, m, d := s.StartDate.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	d += (int(s.Weekday) - int(start.Weekday()) + 7) % 7
+	var end *time.Time
+	if s.EndDate != nil {
+		ey, em, ed := s.EndDate.Date()
+		t := time.Date(ey, em, ed, 0, 0, 0, 0, time.UTC)
+		end = &t
+	}
+
+	at := func(k int) time.Time {
+		return localTime(y, m, d+7*k, s.Hour, s.Minute, loc)
+	}
+	k := 0
+	if first := at(0); from.After(first) {
+		// Skip to the week before from, since broadcasts are a week apart
+		// give or take a transition
+		k = int(from.Sub(first)/(7*24*time.Hour)) - 1
+		if k < 0 {
+			k = 0
+		}
+	}
+	for ; s.Episodes == nil || k < *s.Episodes; k++ {
+		if end != nil && time.Date(y, m, d+7*k, 0, 0, 0, 0, time.UTC).After(*end) {
+			return nil
+		}
+		t := at(k)
+		if t.Before(from) {
+			continue
+		}
+		if !do(s.FirstEpisode+k, t) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// localTime returns the time with the given local date and time of day in
+// the given timezone, normalized as by time.Date. As in calendars, a local
+// time repeated by a daylight saving time transition is its first
+// occurrence, and one skipped by a transition is taken with the offset
+// before it, so that it is moved forward by the length of the transition.
+func localTime(y int, m time.Month, d int, hour int, min int,
+	loc *time.Location) time.Time {
+	t := time.Date(y, m, d, hour, min, 0, 0, loc)
+	wall := time.Date(y, m, d, hour, min, 0, 0, time.UTC)
+	if t.Hour() == wall.Hour() && t.Minute() == wall.Minute() {
+		return t
+	}
+	_, offset := wall.Add(-24 * time.Hour).In(loc).Zone()
+	return wall.Add(-time.Duration(offset) * time.Second).In(loc)
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// occurrences returns the first n occurrences of the BroadcastSlot at or
+// after from.
+func occurrences(t *testing.T, s *BroadcastSlot, from time.Time, n int) ([]int, []time.Time) {
+	var eps []int
+	var times []time.Time
+	err := s.Occurrences(from, func(ep int, at time.Time) bool {
+		eps = append(eps, ep)
+		times = append(times, at)
+		return len(eps) < n
+	})
+	if err != nil {
+		t.Fatalf("failed to get occurrences: %v", err)
+	}
+	return eps, times
+}
+
+func TestBroadcastSlotOccurrencesLateNight(t *testing.T) {
+	// Saturdays at 25:30 in Tokyo are Sundays at 01:30
+	s := &BroadcastSlot{
+		Weekday:      WeekdaySaturday,
+		Hour:         25,
+		Minute:       30,
+		Timezone:     "Asia/Tokyo",
+		StartDate:    time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		FirstEpisode: 1,
+	}
+	eps, times := occurrences(t, s, time.Time{}, 2)
+	expected := time.Date(2024, 4, 6, 16, 30, 0, 0, time.UTC)
+	if eps[0] != 1 || !times[0].Equal(expected) {
+		t.Errorf("expected episode 1 at %s, got %d at %s", expected, eps[0], times[0])
+	}
+	if eps[1] != 2 || !times[1].Equal(expected.AddDate(0, 0, 7)) {
+		t.Errorf("unexpected episode %d at %s", eps[1], times[1])
+	}
+
+	// Broadcasts before from are skipped
+	eps, times = occurrences(t, s, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), 1)
+	expected = time.Date(2024, 6, 1, 16, 30, 0, 0, time.UTC)
+	if eps[0] != 9 || !times[0].Equal(expected) {
+		t.Errorf("expected episode 9 at %s, got %d at %s", expected, eps[0], times[0])
+	}
+}
+
+func TestBroadcastSlotOccurrencesDST(t *testing.T) {
+	// Sundays at 02:30 in New York, which is skipped on 10 March 2024 and
+	// is an hour later in UTC after it
+	s := &BroadcastSlot{
+		Weekday:      WeekdaySunday,
+		Hour:         2,
+		Minute:       30,
+		Timezone:     "America/New_York",
+		StartDate:    time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC),
+		FirstEpisode: 1,
+	}
+	_, times := occurrences(t, s, time.Time{}, 3)
+	expected := []time.Time{
+		time.Date(2024, 3, 3, 7, 30, 0, 0, time.UTC),
+		// Moved forward to 03:30 EDT
+		time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC),
+		time.Date(2024, 3, 17, 6, 30, 0, 0, time.UTC),
+	}
+	for i := range expected {
+		if !times[i].Equal(expected[i]) {
+			t.Errorf("expected broadcast %d at %s, got %s", i, expected[i], times[i])
+		}
+	}
+
+	// Sundays at 01:30, which is repeated on 3 November 2024, are the first
+	// occurrence
+	s.Hour = 1
+	s.StartDate = time.Date(2024, 10, 27, 0, 0, 0, 0, time.UTC)
+	_, times = occurrences(t, s, time.Time{}, 3)
+	expected = []time.Time{
+		time.Date(2024, 10, 27, 5, 30, 0, 0, time.UTC),
+		time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC),
+		time.Date(2024, 11, 10, 6, 30, 0, 0, time.UTC),
+	}
+	for i := range expected {
+		if !times[i].Equal(expected[i]) {
+			t.Errorf("expected broadcast %d at %s, got %s", i, expected[i], times[i])
+		}
+	}
+}
+
+func TestBroadcastSlotOccurrencesEnd(t *testing.T) {
+	episodes := 3
+	end := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	s := &BroadcastSlot{
+		Weekday:      WeekdayMonday,
+		Hour:         12,
+		Timezone:     "UTC",
+		StartDate:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		FirstEpisode: 13,
+		Episodes:     &episodes,
+	}
+	eps, _ := occurrences(t, s, time.Time{}, 10)
+	if len(eps) != 3 || eps[0] != 13 || eps[2] != 15 {
+		t.Errorf("expected episodes 13 to 15, got %v", eps)
+	}
+
+	s.Episodes, s.EndDate = nil, &end
+	eps, _ = occurrences(t, s, time.Time{}, 10)
+	if len(eps) != 3 || eps[2] != 15 {
+		t.Errorf("expected episodes 13 to 15, got %v", eps)
+	}
+
+	s.Timezone = "Nowhere/Nothing"
+	err := s.Occurrences(time.Time{}, func(int, time.Time) bool { return true })
+	if err == nil {
+		t.Error("expected error for unknown timezone")
+	}
+}
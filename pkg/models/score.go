@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// ScoreMax is the maximum score given by Users, which are stored on a scale
+// of 0 to ScoreMax regardless of how they are displayed.
+const ScoreMax = 100
+
+// ValidScore checks if the given stored score is within the scale.
+func ValidScore(score int) bool {
+	return score >= 0 && score <= ScoreMax
+}
+
+// ScoreSystem is an enum that describes the scale scores are displayed and
+// given in.
+type ScoreSystem int
+
+const (
+	// ScoreSystemPoint100 is the scale of whole numbers from 0 to 100, the same
+	// as that scores are stored in.
+	ScoreSystemPoint100 ScoreSystem = iota + 1
+
+	// ScoreSystemPoint10 is the scale of whole numbers from 0 to 10.
+	ScoreSystemPoint10
+
+	// ScoreSystemPoint10Decimal is the scale of numbers from 0 to 10 with a
+	// single decimal place.
+	ScoreSystemPoint10Decimal
+
+	// ScoreSystemStar5 is the scale of whole numbers of stars from 0 to 5.
+	ScoreSystemStar5
+
+	// ScoreSystemSmiley3 is the scale of three smileys, from 1 for a frown to
+	// 3 for a smile.
+	ScoreSystemSmiley3
+)
+
+// DefaultScoreSystem is the ScoreSystem used for Users that have not chosen
+// one.
+const DefaultScoreSystem = ScoreSystemPoint100
+
+// smileyScores are the stored scores of each smiley of ScoreSystemSmiley3.
+var smileyScores = []int{35, 60, 85}
+
+// FromInternal converts the given stored score to the scale of the
+// ScoreSystem.
+func (s ScoreSystem) FromInternal(score int) float64 {
+	switch s {
+	case ScoreSystemPoint10:
+		return math.Round(float64(score) / 10)
+	case ScoreSystemPoint10Decimal:
+		return float64(score) / 10
+	case ScoreSystemStar5:
+		return math.Round(float64(score) / 20)
+	case ScoreSystemSmiley3:
+		// Scores are given the nearest smiley
+		for i := 0; i < len(smileyScores)-1; i++ {
+			if 2*score < smileyScores[i]+smileyScores[i+1] {
+				return float64(i + 1)
+			}
+		}
+		return float64(len(smileyScores))
+	}
+	return float64(score)
+}
+
+// ToInternal converts the given score in the scale of the ScoreSystem to a
+// stored score. An error is returned if it is not in the scale.
+func (s ScoreSystem) ToInternal(value float64) (int, error) {
+	whole := value == math.Trunc(value)
+	switch s {
+	case ScoreSystemPoint100:
+		if whole && value >= 0 && value <= ScoreMax {
+			return int(value), nil
+		}
+	case ScoreSystemPoint10:
+		if whole && value >= 0 && value <= 10 {
+			return int(value) * 10, nil
+		}
+	case ScoreSystemPoint10Decimal:
+		tenths := math.Round(value * 10)
+		if math.Abs(value*10-tenths) < 1e-9 && tenths >= 0 && tenths <= ScoreMax {
+			return int(tenths), nil
+		}
+	case ScoreSystemStar5:
+		if whole && value >= 0 && value <= 5 {
+			return int(value) * 20, nil
+		}
+	case ScoreSystemSmiley3:
+		if whole && value >= 1 && value <= float64(len(smileyScores)) {
+			return smileyScores[int(value)-1], nil
+		}
+	default:
+		return 0, fmt.Errorf("invalid ScoreSystem: %d", int(s))
+	}
+	return 0, fmt.Errorf("score %v not in %s scale", value, s)
+}
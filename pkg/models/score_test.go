@@ -0,0 +1,72 @@
+package models
+
+import (
+	"testing"
+	"testing/quick"
+)
+
+// TestScoreSystemRoundTrip tests that every value in the scale of each
+// ScoreSystem converts to a stored score and back to itself.
+func TestScoreSystemRoundTrip(t *testing.T) {
+	scales := map[ScoreSystem][]float64{
+		ScoreSystemPoint100:       {0, 1, 50, 99, 100},
+		ScoreSystemPoint10:        {0, 1, 5, 10},
+		ScoreSystemPoint10Decimal: {0, 0.1, 5.5, 9.9, 10},
+		ScoreSystemStar5:          {0, 1, 3, 5},
+		ScoreSystemSmiley3:        {1, 2, 3},
+	}
+
+	for s, values := range scales {
+		for _, v := range values {
+			score, err := s.ToInternal(v)
+			if err != nil {
+				t.Errorf("%s %v: %v", s, v, err)
+				continue
+			}
+			if !ValidScore(score) {
+				t.Errorf("%s %v: stored score %d not valid", s, v, score)
+			}
+			if d := s.FromInternal(score); d != v {
+				t.Errorf("%s %v: converted back to %v", s, v, d)
+			}
+		}
+	}
+}
+
+// TestScoreSystemOutOfScale tests that values outside the scale of each
+// ScoreSystem are rejected.
+func TestScoreSystemOutOfScale(t *testing.T) {
+	invalid := map[ScoreSystem][]float64{
+		ScoreSystemPoint100:       {-1, 101, 50.5},
+		ScoreSystemPoint10:        {-1, 11, 5.5},
+		ScoreSystemPoint10Decimal: {-0.1, 10.1, 5.55},
+		ScoreSystemStar5:          {-1, 6, 2.5},
+		ScoreSystemSmiley3:        {0, 4, 1.5},
+	}
+
+	for s, values := range invalid {
+		for _, v := range values {
+			if score, err := s.ToInternal(v); err == nil {
+				t.Errorf("%s %v: expected error, got %d", s, v, score)
+			}
+		}
+	}
+}
+
+// TestScoreSystemFromInternal tests that every stored score converts to a
+// value in the scale of each ScoreSystem.
+func TestScoreSystemFromInternal(t *testing.T) {
+	for _, s := range ScoreSystemValues() {
+		s := s
+		property := func(n uint8) bool {
+			score := int(n) % (ScoreMax + 1)
+			_, err := s.ToInternal(s.FromInternal(score))
+			return err == nil
+		}
+
+		err := quick.Check(property, nil)
+		if err != nil {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// SeasonSort is an enum that describes the order of the Media in a season
+// chart.
+type SeasonSort int
+
+const (
+	// SeasonSortPopularity orders Media by the number of Users that have
+	// them in their lists, most first.
+	SeasonSortPopularity SeasonSort = iota
+
+	// SeasonSortTitle orders Media by their preferred titles.
+	SeasonSortTitle
+
+	// SeasonSortStartDate orders Media by their start dates, earliest first.
+	// Media without a start date are last.
+	SeasonSortStartDate
+)
+
+// SeasonCount is the number of Media that premiered in some Season.
+type SeasonCount struct {
+	Season Season
+	Count  int
+}
+
+// NewSeason returns the Season of the given Quarter of the given year.
+func NewSeason(year int, quarter Quarter) Season {
+	return Season{Quarter: &quarter, Year: &year}
+}
+
+// SeasonOf returns the Season of the date of the given time.
+func SeasonOf(t time.Time) Season {
+	return NewSeason(t.Year(), Quarter((int(t.Month())-1)/3+1))
+}
+
+// IsComplete returns true if both the Quarter and the year of the Season are
+// known.
+func (s Season) IsComplete() bool {
+	return s.Quarter != nil && s.Quarter.IsValid() && s.Year != nil
+}
+
+// Previous returns the Season before the Season, or nil if it is not
+// complete.
+func (s Season) Previous() *Season {
+	if !s.IsComplete() {
+		return nil
+	}
+	p := NewSeason(*s.Year, *s.Quarter-1)
+	if *s.Quarter == QuarterWinter {
+		p = NewSeason(*s.Year-1, QuarterFall)
+	}
+	return &p
+}
+
+// Next returns the Season after the Season, or nil if it is not complete.
+func (s Season) Next() *Season {
+	if !s.IsComplete() {
+		return nil
+	}
+	n := NewSeason(*s.Year, *s.Quarter+1)
+	if *s.Quarter == QuarterFall {
+		n = NewSeason(*s.Year+1, QuarterWinter)
+	}
+	return &n
+}
+
+// Premiered returns the Season the Media premiered in, which is
+// SeasonPremiered if it is complete, and otherwise the Season of StartDate.
+// False is returned if neither is known.
+func (m *Media) Premiered() (Season, bool) {
+	if m.SeasonPremiered.IsComplete() {
+		return m.SeasonPremiered, true
+	}
+	if m.StartDate != nil {
+		return SeasonOf(*m.StartDate), true
+	}
+	return Season{}, false
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeasonNavigation(t *testing.T) {
+	s := NewSeason(2026, QuarterFall)
+	next := s.Next()
+	if next == nil || *next.Year != 2027 || *next.Quarter != QuarterWinter {
+		t.Errorf("expected Winter 2027 after Fall 2026, got %+v", next)
+	}
+	prev := next.Previous()
+	if prev == nil || *prev.Year != 2026 || *prev.Quarter != QuarterFall {
+		t.Errorf("expected Fall 2026 before Winter 2027, got %+v", prev)
+	}
+	prev = NewSeason(2026, QuarterSpring).Previous()
+	if prev == nil || *prev.Year != 2026 || *prev.Quarter != QuarterWinter {
+		t.Errorf("expected Winter 2026 before Spring 2026, got %+v", prev)
+	}
+
+	year := 2026
+	if (Season{Year: &year}).Next() != nil {
+		t.Error("expected no Season after one without a quarter")
+	}
+}
+
+func TestMediaPremiered(t *testing.T) {
+	start := time.Date(2026, time.October, 3, 0, 0, 0, 0, time.UTC)
+	md := Media{StartDate: &start}
+	s, ok := md.Premiered()
+	if !ok || *s.Year != 2026 || *s.Quarter != QuarterFall {
+		t.Errorf("expected Fall 2026 from start date, got %+v %t", s, ok)
+	}
+
+	md.SeasonPremiered = NewSeason(2026, QuarterSummer)
+	s, ok = md.Premiered()
+	if !ok || *s.Quarter != QuarterSummer {
+		t.Errorf("expected premiere season to be preferred, got %+v %t", s, ok)
+	}
+
+	md = Media{}
+	if _, ok = md.Premiered(); ok {
+		t.Error("expected no Season without premiere season or start date")
+	}
+}
@@ -0,0 +1,309 @@
+// +build ignore
+
+// enumgen generates the string mappings of integer enum types. The written
+// name of each value is the name of its constant without the type name
+// prefix; e.g. WatchStatusCurrent is written as "Current". The mapping is
+// shared by the text, JSON and GraphQL encodings of each type.
+//
+// If a test file is given, the enumTypes function returning the values and
+// decoders of each type is written to it, for the tests of the package that
+// are run against every enum type.
+//
+// Usage, from the directory of the package:
+//
+//	go run enumgen.go -type WatchStatus,Quarter -output enum_string.go \
+//		-test enum_string_test.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// enum is an integer type and its constants, in order of declaration.
+type enum struct {
+	Type   string
+	Values []value
+}
+
+// value is a constant of an enum and its written name.
+type value struct {
+	Const string
+	Name  string
+}
+
+func main() {
+	types := flag.String("type", "", "comma-separated list of enum type names")
+	output := flag.String("output", "enum_string.go", "output file name")
+	test := flag.String("test", "", "test file name, if any")
+	flag.Parse()
+	if *types == "" {
+		log.Fatal("enumgen: -type is required")
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		log.Fatalf("enumgen: %v", err)
+	}
+
+	pkg, consts, err := parseConsts(dir, *output)
+	if err != nil {
+		log.Fatalf("enumgen: %v", err)
+	}
+
+	var enums []enum
+	for _, t := range strings.Split(*types, ",") {
+		e := enum{Type: t}
+		for _, c := range consts[t] {
+			if !strings.HasPrefix(c, t) || len(c) == len(t) {
+				continue
+			}
+			e.Values = append(e.Values, value{Const: c, Name: c[len(t):]})
+		}
+		if len(e.Values) == 0 {
+			log.Fatalf("enumgen: no constants of type %s", t)
+		}
+		enums = append(enums, e)
+	}
+
+	params := struct {
+		Args    string
+		Package string
+		Enums   []enum
+	}{strings.Join(os.Args[1:], " "), pkg, enums}
+	err = generate(filepath.Join(dir, *output), tmpl, params)
+	if err != nil {
+		log.Fatalf("enumgen: %v", err)
+	}
+	if *test != "" {
+		err = generate(filepath.Join(dir, *test), testTmpl, params)
+		if err != nil {
+			log.Fatalf("enumgen: %v", err)
+		}
+	}
+}
+
+// generate executes the given template with the given parameters and writes
+// the formatted result to the file with the given name.
+func generate(name string, t *template.Template, params interface{}) error {
+	var buf bytes.Buffer
+	err := t.Execute(&buf, params)
+	if err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format output: %w", err)
+	}
+	return ioutil.WriteFile(name, src, 0644)
+}
+
+// parseConsts returns the package name and the names of the typed constants
+// declared in the non-test Go files of the given directory, by type name.
+func parseConsts(dir string, output string) (string, map[string][]string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		name := fi.Name()
+		return name != output && !strings.HasSuffix(name, "_test.go")
+	}, 0)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse package: %w", err)
+	}
+	if len(pkgs) != 1 {
+		return "", nil, fmt.Errorf("expected 1 package, found %d", len(pkgs))
+	}
+
+	var name string
+	var files []*ast.File
+	for n, pkg := range pkgs {
+		name = n
+		for _, f := range pkg.Files {
+			files = append(files, f)
+		}
+	}
+	// Map iteration order is random; sort files for deterministic output
+	sortFiles(fset, files)
+
+	consts := map[string][]string{}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.CONST {
+				continue
+			}
+
+			// A spec without a type or values repeats the previous one
+			var typ string
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if vs.Type != nil {
+					ident, ok := vs.Type.(*ast.Ident)
+					if ok {
+						typ = ident.Name
+					} else {
+						typ = ""
+					}
+				} else if len(vs.Values) > 0 {
+					typ = ""
+				}
+				if typ == "" {
+					continue
+				}
+				for _, n := range vs.Names {
+					consts[typ] = append(consts[typ], n.Name)
+				}
+			}
+		}
+	}
+	return name, consts, nil
+}
+
+func sortFiles(fset *token.FileSet, files []*ast.File) {
+	for i := 1; i < len(files); i++ {
+		for j := i; j > 0 && fset.File(files[j].Pos()).Name() <
+			fset.File(files[j-1].Pos()).Name(); j-- {
+			files[j], files[j-1] = files[j-1], files[j]
+		}
+	}
+}
+
+var tmpl = template.Must(template.New("enum").Parse(`// Code generated by "enumgen {{.Args}}"; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+{{range .Enums}}{{$t := .Type}}
+// {{$t}}Values returns every valid {{$t}} in order of declaration.
+func {{$t}}Values() []{{$t}} {
+	return []{{$t}}{ {{- range .Values}}{{.Const}}, {{end -}} }
+}
+
+// Parse{{$t}} returns the {{$t}} with the given written name.
+func Parse{{$t}}(s string) ({{$t}}, error) {
+	switch s {
+	{{- range .Values}}
+	case "{{.Name}}":
+		return {{.Const}}, nil
+	{{- end}}
+	}
+	return 0, fmt.Errorf("invalid {{$t}}: %q", s)
+}
+
+// IsValid checks if the {{$t}} has a value that is a valid one.
+func (v {{$t}}) IsValid() bool {
+	switch v {
+	case {{range $i, $v := .Values}}{{if $i}}, {{end}}{{$v.Const}}{{end}}:
+		return true
+	}
+	return false
+}
+
+// String returns the written name of the {{$t}}.
+func (v {{$t}}) String() string {
+	switch v {
+	{{- range .Values}}
+	case {{.Const}}:
+		return "{{.Name}}"
+	{{- end}}
+	}
+	return fmt.Sprintf("%d", int(v))
+}
+
+// MarshalText encodes the {{$t}} as its written name.
+func (v {{$t}}) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid {{$t}}: %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes the {{$t}} from its written name.
+func (v *{{$t}}) UnmarshalText(text []byte) error {
+	p, err := Parse{{$t}}(string(text))
+	if err != nil {
+		return err
+	}
+	*v = p
+	return nil
+}
+
+// MarshalJSON encodes the {{$t}} as a JSON string of its written name.
+func (v {{$t}}) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes the {{$t}} from a JSON string of its written name or,
+// as persisted by earlier versions, from its integer value.
+func (v *{{$t}}) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+
+	var i int
+	err = json.Unmarshal(data, &i)
+	if err != nil || !{{$t}}(i).IsValid() {
+		return fmt.Errorf("invalid {{$t}}: %s", data)
+	}
+	*v = {{$t}}(i)
+	return nil
+}
+
+// UnmarshalGQL casts the type of the given value to a {{$t}}.
+func (v *{{$t}}) UnmarshalGQL(i interface{}) error {
+	s, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", i)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// MarshalGQL serializes the {{$t}} into a GraphQL readable form.
+func (v {{$t}}) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(v.String()))
+}
+{{end}}`))
+
+var testTmpl = template.Must(template.New("enumtest").Parse(`// Code generated by "enumgen {{.Args}}"; DO NOT EDIT.
+
+package {{.Package}}
+
+// enumTypes returns the enumType of each generated enum type.
+func enumTypes() []enumType {
+	return []enumType{
+	{{- range .Enums}}{{$t := .Type}}
+		{
+			name: "{{$t}}",
+			values: []int{ {{- range .Values}}int({{.Const}}), {{end -}} },
+			value: func(i int) enumValue { return {{$t}}(i) },
+			decoder: func() (enumPtr, func() int) {
+				var v {{$t}}
+				return &v, func() int { return int(v) }
+			},
+		},
+	{{- end}}
+	}
+}
+`))